@@ -0,0 +1,175 @@
+package gormodata
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// arithmeticOperators
+// are the binary arithmetic operators of odata, their operands are numbers or properties (e.g. price sub discount)
+var arithmeticOperators = []string{"add", "sub", "mul", "div", "divby", "mod"}
+
+// negationFunction
+// is the unary minus (e.g. -price), it is parsed as a function with one argument like the other unary functions
+const negationFunction = "-"
+
+// arithmeticSQL
+// are the sql operators of the arithmetic operators, div is the division of the database (integer division of integer columns,
+// except on MySQL) and divby always divides as decimals
+var arithmeticSQL = map[string]string{
+	"add":   "+",
+	"sub":   "-",
+	"mul":   "*",
+	"div":   "/",
+	"divby": "* 1.0 /",
+	"mod":   "%",
+}
+
+// isArithmeticNode
+// returns whether the node is a binary arithmetic operator
+func isArithmeticNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && slices.Contains(arithmeticOperators, node.Value)
+}
+
+// isNegationNode
+// returns whether the node is a unary minus
+func isNegationNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.UnaryOperator && node.Value == negationFunction
+}
+
+// isNegationPrefix
+// returns whether the word starts with a unary minus that is not followed by a bracket (e.g. -price or - price),
+// negative numbers (e.g. -5 or -2.5M) are literals
+func isNegationPrefix(word string) bool {
+	if len(word) == 0 || word[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(word, 64)
+
+	return err != nil && !isNumericSuffixLiteral(word)
+}
+
+// isNumberLiteral
+// returns whether the value of a node is a finite number
+func isNumberLiteral(value string) bool {
+	switch number := literalValue(value).(type) {
+	case int64, float32, DecimalLiteral:
+		return true
+	case float64:
+		return !math.IsInf(number, 0) && !math.IsNaN(number)
+	default:
+		return false
+	}
+}
+
+// buildArithmetic
+// returns the sql of an arithmetic expression, every operator is grouped so the precedence of the filter is kept in every dialect
+//
+//	(price sub discount) mul quantity  ->  ((price - discount) * quantity)
+//
+// the operands are checked before the expression is built (see checkArithmetic)
+func buildArithmetic(translation *queryTranslation, node *syntaxtree.Node) string {
+	switch {
+	case isArithmeticNode(node):
+		return "(" + buildArithmetic(translation, node.LeftChild) + " " + arithmeticSQL[node.Value] + " " + buildArithmetic(translation, node.RightChild) + ")"
+	case node.Type == syntaxtree.UnaryOperator:
+		return buildUnaryFuncChain(translation, node)
+	case isNumericFunctionNode(node):
+		numeric, _ := buildNumericFunction(translation, node)
+
+		return numeric
+	case isNumberLiteral(node.Value):
+		return numericSQL(literalValue(node.Value))
+	default:
+		return translation.columnName(node.Value)
+	}
+}
+
+// buildNumericOperand
+// returns the sql of an operand of a numeric function (e.g. greatest or round), which is a property, a number or another numeric expression
+func buildNumericOperand(translation *queryTranslation, function *syntaxtree.Node, operand *syntaxtree.Node) (string, error) {
+	switch {
+	case isNumericFunctionNode(operand):
+		return buildNumericFunction(translation, operand)
+	case operand.Type == syntaxtree.UnaryOperator && !isNowCall(operand):
+		return buildUnaryFuncChain(translation, operand), nil
+	case isArithmeticNode(operand):
+		if err := checkArithmetic(operand, translation); err != nil {
+			return "", err
+		}
+
+		return buildArithmetic(translation, operand), nil
+	case isNumberLiteral(operand.Value):
+		return numericSQL(literalValue(operand.Value)), nil
+	case isPropertyNode(operand) && !translation.isRelationPath(operand.Value):
+		return translation.columnName(operand.Value), nil
+	default:
+		expression := nodeExpression(operand)
+		if !isStringLiteral(expression) {
+			expression = quote(expression)
+		}
+
+		return "", &InvalidQueryError{
+			Msg:        fmt.Sprintf("operand %s of '%s' must be a number, a property of the model or a function", expression, function.Value),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(function),
+			Node:       operand,
+		}
+	}
+}
+
+// checkArithmetic
+// checks the operands of the arithmetic expressions and unary minuses of the node and its children:
+// they can only be numbers, properties of the model or other numeric expressions,
+// the numeric functions are checked as well, so they can be built inside of arithmetic and functions (see buildArithmetic)
+func checkArithmetic(root *syntaxtree.Node, translation *queryTranslation) error {
+	stack := []*syntaxtree.Node{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil {
+			continue
+		}
+		stack = append(stack, node.LeftChild, node.RightChild)
+
+		if isNumericFunctionNode(node) {
+			if _, err := buildNumericFunction(translation, node); err != nil {
+				return err
+			}
+		}
+		if !isArithmeticNode(node.Parent) && !isNegationNode(node.Parent) {
+			continue
+		}
+		if node.Type != syntaxtree.LeftOperand && node.Type != syntaxtree.RightOperand {
+			continue
+		}
+
+		if !isNumberLiteral(node.Value) && !isPropertyNode(node) {
+			operand := node.Value
+			if !isStringLiteral(operand) {
+				operand = quote(operand)
+			}
+
+			return &InvalidQueryError{
+				Msg:        fmt.Sprintf("operand %s of '%s' must be a number or a property", operand, node.Parent.Value),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(node.Parent),
+				Node:       node,
+			}
+		}
+		if isPropertyNode(node) && translation.isRelationPath(node.Value) {
+			return &InvalidQueryError{
+				Msg:        fmt.Sprintf("relation property '%s' cannot be an operand of '%s'", node.Value, node.Parent.Value),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(node.Parent),
+				Node:       node,
+			}
+		}
+	}
+
+	return nil
+}
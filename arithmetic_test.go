@@ -0,0 +1,278 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type LineItem struct {
+	ID       uuid.UUID
+	Name     string
+	Price    float64
+	Discount float64
+	Quantity int
+}
+
+func Test_BuildQuery_ArithmeticPrecedence(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"brackets before multiplication": {
+			queryString: "(price sub discount) mul quantity gt 100",
+			expectedSql: "SELECT * FROM `line_items` WHERE ((price - discount) * quantity) > 100",
+		},
+		"multiplication before subtraction": {
+			queryString: "price sub discount mul quantity gt 100",
+			expectedSql: "SELECT * FROM `line_items` WHERE (price - (discount * quantity)) > 100",
+		},
+		"left to right with the same precedence": {
+			queryString: "price sub discount sub 5 eq 20",
+			expectedSql: "SELECT * FROM `line_items` WHERE ((price - discount) - 5) = 20",
+		},
+		"brackets on the right": {
+			queryString: "price sub (discount sub 5) eq 20",
+			expectedSql: "SELECT * FROM `line_items` WHERE (price - (discount - 5)) = 20",
+		},
+		"unary minus before multiplication": {
+			queryString: "-price mul 2 lt -10",
+			expectedSql: "SELECT * FROM `line_items` WHERE (-(price) * 2) < -10",
+		},
+		"unary minus of brackets": {
+			queryString: "-(price add discount) lt 0",
+			expectedSql: "SELECT * FROM `line_items` WHERE -((price + discount)) < 0",
+		},
+		"division and modulo": {
+			queryString: "quantity div 2 eq 1 or quantity mod 2 eq 1 or price divby 4 gt 2",
+			expectedSql: "SELECT * FROM `line_items` WHERE ((quantity / 2) = 1 OR (quantity % 2) = 1) OR (price * 1.0 / 4) > 2",
+		},
+		"arithmetic before comparison and logical operators": {
+			queryString: "price add 1 gt 10 and not(quantity mul 2 le 4)",
+			expectedSql: "SELECT * FROM `line_items` WHERE (price + 1) > 10 AND (quantity * 2) > 4",
+		},
+		"functions of arithmetic": {
+			queryString: "round(price mul 1.21) eq 12 and -floor(price) lt 0",
+			expectedSql: "SELECT * FROM `line_items` WHERE ROUND((price * 1.21)) = 12 AND -(FLOOR(price)) < 0",
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = BuildQuery(testData.queryString, tx.Model(&LineItem{}), dbType)
+					return dbQuery.Find(&LineItem{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSql, sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_ArithmeticResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"brackets before multiplication": {
+			queryString:   "(price sub discount) mul quantity gt 100",
+			expectedNames: []string{"lamp"},
+		},
+		"multiplication before subtraction": {
+			queryString:   "price sub discount mul quantity gt 0",
+			expectedNames: []string{"chair", "lamp"},
+		},
+		"unary minus": {
+			queryString:   "-price gt 0",
+			expectedNames: []string{"refund"},
+		},
+		"modulo": {
+			queryString:   "quantity mod 2 eq 0",
+			expectedNames: []string{"chair"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&LineItem{})
+			db.Create(&LineItem{ID: uuid.New(), Name: "lamp", Price: 30, Discount: 5, Quantity: 5})
+			db.Create(&LineItem{ID: uuid.New(), Name: "chair", Price: 10, Discount: 0, Quantity: 4})
+			db.Create(&LineItem{ID: uuid.New(), Name: "refund", Price: -10, Discount: 0, Quantity: 1})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&LineItem{}), SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []LineItem
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, item := range result {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQuery_ArithmeticErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+	}{
+		"string operand": {
+			queryString: "price add 'a' gt 1",
+			expectedErr: "invalid query: operand 'a' of 'add' must be a number or a property",
+		},
+		"null operand": {
+			queryString: "price mul null gt 1",
+			expectedErr: "invalid query: operand 'null' of 'mul' must be a number or a property",
+		},
+		"relation property": {
+			queryString: "metadata/price add 1 gt 1",
+			expectedErr: "invalid query: relation property 'metadata/price' cannot be an operand of 'add'",
+		},
+		"right operand": {
+			queryString: "price gt discount add 1",
+			expectedErr: "invalid query: arithmetic 'discount add 1' cannot be the right operand of 'gt', only values can",
+		},
+		"root": {
+			queryString: "price add 1",
+			expectedErr: "invalid query: arithmetic 'price add 1' cannot be the root of a filter, compare it with a value instead",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db.Model(&LineItem{}), SQLite)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrUnsupportedOperator))
+		})
+	}
+}
+
+func Test_Parse_ArithmeticPrecedence(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedExpr   *Expr
+		expectedString string
+	}{
+		"brackets before multiplication": {
+			queryString: "(price sub discount) mul quantity gt 100",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "gt", Args: []*Expr{
+				{Kind: ArithmeticExpr, Op: "mul", Args: []*Expr{
+					{Kind: ArithmeticExpr, Op: "sub", Args: []*Expr{exprProperty("price"), exprProperty("discount")}},
+					exprProperty("quantity"),
+				}},
+				exprLiteral(int64(100)),
+			}},
+			expectedString: "(price sub discount) mul quantity gt 100",
+		},
+		"multiplication before addition": {
+			queryString: "price add discount mul 2 gt 1",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "gt", Args: []*Expr{
+				{Kind: ArithmeticExpr, Op: "add", Args: []*Expr{
+					exprProperty("price"),
+					{Kind: ArithmeticExpr, Op: "mul", Args: []*Expr{exprProperty("discount"), exprLiteral(int64(2))}},
+				}},
+				exprLiteral(int64(1)),
+			}},
+			expectedString: "price add discount mul 2 gt 1",
+		},
+		"left to right with the same precedence": {
+			queryString: "price sub (discount sub 5) sub 1 eq 0",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{
+				{Kind: ArithmeticExpr, Op: "sub", Args: []*Expr{
+					{Kind: ArithmeticExpr, Op: "sub", Args: []*Expr{
+						exprProperty("price"),
+						{Kind: ArithmeticExpr, Op: "sub", Args: []*Expr{exprProperty("discount"), exprLiteral(int64(5))}},
+					}},
+					exprLiteral(int64(1)),
+				}},
+				exprLiteral(int64(0)),
+			}},
+			expectedString: "price sub (discount sub 5) sub 1 eq 0",
+		},
+		"unary minus": {
+			queryString: "-price mul 2 lt -10",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "lt", Args: []*Expr{
+				{Kind: ArithmeticExpr, Op: "mul", Args: []*Expr{
+					{Kind: FunctionExpr, Func: "-", Args: []*Expr{exprProperty("price")}},
+					exprLiteral(int64(2)),
+				}},
+				exprLiteral(int64(-10)),
+			}},
+			expectedString: "-(price) mul 2 lt -10",
+		},
+		"unary minus of a function": {
+			queryString: "- round(price) lt 0",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "lt", Args: []*Expr{
+				{Kind: FunctionExpr, Func: "-", Args: []*Expr{
+					{Kind: FunctionExpr, Func: "round", Args: []*Expr{exprProperty("price")}},
+				}},
+				exprLiteral(int64(0)),
+			}},
+			expectedString: "-(round(price)) lt 0",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			expr, err := Parse(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedExpr, expr)
+			assert.Equal(t, testData.expectedString, expr.String())
+
+			reparsedExpr, err := Parse(expr.String())
+			assert.NoError(t, err)
+			assert.Equal(t, expr, reparsedExpr)
+		})
+	}
+}
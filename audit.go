@@ -0,0 +1,84 @@
+package gormodata
+
+import (
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// FilterAudit
+// describes a filter that was built (see WithAuditHook), so it can be logged and analyzed
+type FilterAudit struct {
+	// Filter is the normalized filter (see Expr.String), with the field aliases resolved
+	Filter string `json:"filter"`
+
+	// Properties are the properties the filter refers to (see Expr.Properties)
+	Properties []string `json:"properties"`
+
+	// Relations are the relations the properties go through, e.g. "metadata" and "metadata/tag" for "metadata/tag/value"
+	Relations []string `json:"relations"`
+
+	// RelationHops is the largest number of relations a property of the filter goes through, e.g. 2 for "metadata/tag/value"
+	RelationHops int `json:"relationHops"`
+
+	// Complexity is the number of operators, functions, properties and literals of the filter,
+	// every relation a property goes through counts as one more since it is resolved with a subquery
+	Complexity int `json:"complexity"`
+}
+
+// AuditHook
+// is called with every filter that was built on the db (see WithAuditHook)
+type AuditHook func(db *gorm.DB, audit FilterAudit)
+
+// WithAuditHook
+// calls the hook with every filter that is built, e.g. so security teams can log and analyze what clients filter on
+//
+//	builder := gormodata.New(gormodata.WithAuditHook(func(db *gorm.DB, audit gormodata.FilterAudit) {
+//		slog.InfoContext(db.Statement.Context, "odata filter", "filter", audit.Filter, "complexity", audit.Complexity)
+//	}))
+//
+// filters that are rejected are not reported to the hook, they are logged by the logger of the builder (see WithLogger)
+func WithAuditHook(hook AuditHook) Option {
+	return func(b *Builder) {
+		b.auditHook = hook
+	}
+}
+
+// newFilterAudit
+// returns the audit of the syntax tree of a built filter
+func newFilterAudit(tree *syntaxtree.SyntaxTree) FilterAudit {
+	expr := newExpr(tree.Root)
+	audit := FilterAudit{
+		Filter:     expr.String(),
+		Properties: expr.Properties(),
+		Relations:  []string{},
+	}
+
+	seen := map[string]bool{}
+	Inspect(expr, func(expr *Expr) bool {
+		if expr == nil {
+			return false
+		}
+		audit.Complexity++
+
+		if expr.Kind == PropertyExpr {
+			parts := strings.Split(expr.Property, "/")
+			hops := len(parts) - 1
+			audit.Complexity += hops
+			audit.RelationHops = max(audit.RelationHops, hops)
+
+			for i := 1; i < len(parts); i++ {
+				relation := strings.Join(parts[:i], "/")
+				if !seen[relation] {
+					seen[relation] = true
+					audit.Relations = append(audit.Relations, relation)
+				}
+			}
+		}
+
+		return true
+	})
+
+	return audit
+}
@@ -0,0 +1,113 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_WithAuditHook(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedAudit FilterAudit
+	}{
+		"comparison": {
+			queryString: "name eq 'test'",
+			expectedAudit: FilterAudit{
+				Filter:     "name eq 'test'",
+				Properties: []string{"name"},
+				Relations:  []string{},
+				Complexity: 3,
+			},
+		},
+		"functions and relations": {
+			queryString: "(length(name) gt 3 or metadata/tag/value eq 'x')   and contains(metadata/name,'prd')",
+			expectedAudit: FilterAudit{
+				Filter:       "(length(name) gt 3 or metadata/tag/value eq 'x') and contains(metadata/name,'prd')",
+				Properties:   []string{"name", "metadata/tag/value", "metadata/name"},
+				Relations:    []string{"metadata", "metadata/tag"},
+				RelationHops: 2,
+				Complexity:   15,
+			},
+		},
+		"field alias": {
+			queryString: "tag eq 'x'",
+			expectedAudit: FilterAudit{
+				Filter:       "metadata/tag/value eq 'x'",
+				Properties:   []string{"metadata/tag/value"},
+				Relations:    []string{"metadata", "metadata/tag"},
+				RelationHops: 2,
+				Complexity:   5,
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+
+			var audits []FilterAudit
+			builder := New(
+				WithDatabaseType(SQLite),
+				WithFieldAliases(map[string]string{"tag": "metadata/tag/value"}),
+				WithAuditHook(func(_ *gorm.DB, audit FilterAudit) {
+					audits = append(audits, audit)
+				}),
+			)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&MockModel{}))
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, []FilterAudit{testData.expectedAudit}, audits)
+		})
+	}
+}
+
+func Test_WithAuditHook_RejectedFilter(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+	calls := 0
+	builder := New(WithDatabaseType(SQLite), WithAllowedFields("name"), WithAuditHook(func(*gorm.DB, FilterAudit) {
+		calls++
+	}))
+
+	// Act
+	_, err := builder.Build("testValue eq 'x'", db.Model(&MockModel{}))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, calls)
+}
+
+func Test_WithAuditHook_Validate(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	calls := 0
+	builder := New(WithDatabaseType(SQLite), WithAuditHook(func(*gorm.DB, FilterAudit) {
+		calls++
+	}))
+
+	// Act
+	err := builder.Validate("name eq 'x'", &MockModel{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, calls)
+}
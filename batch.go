@@ -0,0 +1,112 @@
+package gormodata
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// FilterError
+// is the error of one of the filters of BuildQueries, with the position of the filter in the batch
+type FilterError struct {
+	// Index is the position of the filter in the filters that were passed to BuildQueries
+	Index int
+
+	Filter string
+
+	// Err is the error of the build, like Build would return it (e.g. *InvalidQueryError or *SyntaxError)
+	Err error
+}
+
+func (f *FilterError) Error() string {
+	return fmt.Sprintf("filter %d: %s", f.Index, f.Err.Error())
+}
+
+func (f *FilterError) Unwrap() error {
+	return f.Err
+}
+
+// BatchErrors
+// are the errors of the filters of BuildQueries that could not be built, in the order of the filters
+//
+// errors.Is and errors.As match any of the errors, e.g. to check if one of the filters has a syntax error
+type BatchErrors []*FilterError
+
+func (b BatchErrors) Error() string {
+	messages := make([]string, len(b))
+	for i, err := range b {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (b BatchErrors) Unwrap() []error {
+	errs := make([]error, len(b))
+	for i, err := range b {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// BuildQueries
+// builds gorm queries for many filters on the same db at once (see Builder.BuildQueries)
+//
+//	dbQueries, err := gormodata.BuildQueries(alertRules, db.Model(&Event{}), gormodata.WithDatabaseType(gormodata.PostgreSQL))
+func BuildQueries(filters []string, db *gorm.DB, opts ...Option) ([]*gorm.DB, error) {
+	return New(opts...).BuildQueries(filters, db)
+}
+
+// BuildQueries
+// builds a gorm query for every filter on the same db, e.g. for services that evaluate many saved filters (alerting rules) against one model,
+// the database type, plugins and translation are prepared once and the filters are parsed and built in parallel
+//
+//	dbQueries, err := builder.BuildQueries(alertRules, db.Model(&Event{}))
+//
+// the queries are returned in the order of the filters, a filter that cannot be built is nil in the result
+// and its error is returned as a *FilterError in BatchErrors, the other filters are still built
+func (b *Builder) BuildQueries(filters []string, db *gorm.DB) ([]*gorm.DB, error) {
+	translation, db, err := b.prepareDB(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every build continues on its own copy of the statement, so the builds do not change the statement they share
+	db = db.Session(&gorm.Session{})
+
+	results := make([]*gorm.DB, len(filters))
+	errs := make([]error, len(filters))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(filters)) {
+		wg.Go(func() {
+			for index := int(next.Add(1) - 1); index < len(filters); index = int(next.Add(1) - 1) {
+				results[index], _, errs[index] = b.buildLogged(db, "query", filters[index], func(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+					return b.buildTranslated(filters[index], db, translation)
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	var batchErrors BatchErrors
+	for index, err := range errs {
+		if err != nil {
+			results[index] = nil
+			batchErrors = append(batchErrors, &FilterError{Index: index, Filter: filters[index], Err: err})
+		}
+	}
+
+	if len(batchErrors) > 0 {
+		return results, batchErrors
+	}
+
+	return results, nil
+}
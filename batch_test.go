@@ -0,0 +1,139 @@
+package gormodata
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+// dryRunSQL
+// returns the SQL that a find on the query would execute, with the variables in it
+func dryRunSQL(query *gorm.DB) string {
+	statement := query.Session(&gorm.Session{DryRun: true}).Find(&[]MockModel{}).Statement
+
+	return query.Dialector.Explain(statement.SQL.String(), statement.Vars...)
+}
+
+func Test_Builder_BuildQueries(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		filters      []string
+		base         func(db *gorm.DB) *gorm.DB
+		expectedSqls []string
+	}{
+		"no filters": {
+			filters:      []string{},
+			expectedSqls: []string{},
+		},
+		"several filters": {
+			filters: []string{"name eq 'test'", "length(name) gt 3 or testValue ne 'a'", "metadata/name eq 'prd'"},
+			expectedSqls: []string{
+				"SELECT * FROM `mock_models` WHERE name = \"test\"",
+				"SELECT * FROM `mock_models` WHERE LENGTH(name) > 3 OR test_value != \"a\"",
+				"SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+			},
+		},
+		"filters on a query with conditions": {
+			filters: []string{"name eq 'test'", "name eq 'other'"},
+			base: func(db *gorm.DB) *gorm.DB {
+				return db.Where("test_value = ?", "tenant")
+			},
+			expectedSqls: []string{
+				"SELECT * FROM `mock_models` WHERE test_value = \"tenant\" AND name = \"test\"",
+				"SELECT * FROM `mock_models` WHERE test_value = \"tenant\" AND name = \"other\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			query := db.Model(&MockModel{})
+			if testData.base != nil {
+				query = testData.base(query)
+			}
+
+			// Act
+			results, err := New(WithDatabaseType(SQLite)).BuildQueries(testData.filters, query)
+
+			// Assert
+			assert.NoError(t, err)
+			sqls := make([]string, len(results))
+			for index, result := range results {
+				sqls[index] = dryRunSQL(result)
+			}
+			assert.Equal(t, testData.expectedSqls, sqls)
+		})
+	}
+}
+
+func Test_Builder_BuildQueriesErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	builder := New(WithDatabaseType(SQLite), WithAllowedFields("name"))
+	filters := []string{"name eq 'test'", "(name eq 'test'", "name eq 'other'", "testValue eq 'a'"}
+
+	// Act
+	results, err := builder.BuildQueries(filters, db.Model(&MockModel{}))
+
+	// Assert
+	var batchErrors BatchErrors
+	assert.True(t, errors.As(err, &batchErrors))
+	assert.Len(t, batchErrors, 2)
+	assert.Equal(t, 1, batchErrors[0].Index)
+	assert.Equal(t, "(name eq 'test'", batchErrors[0].Filter)
+	assert.True(t, errors.Is(batchErrors[0], ErrUnbalancedParens))
+	assert.Equal(t, 3, batchErrors[1].Index)
+	assert.True(t, errors.Is(batchErrors[1], ErrFieldNotAllowed))
+	assert.True(t, errors.Is(err, ErrInvalidSyntax))
+	assert.Contains(t, err.Error(), "filter 1: ")
+
+	assert.Len(t, results, 4)
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE name = \"test\"", dryRunSQL(results[0]))
+	assert.Nil(t, results[1])
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE name = \"other\"", dryRunSQL(results[2]))
+	assert.Nil(t, results[3])
+}
+
+func Test_BuildQueries_Parallel(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	filters := make([]string, 200)
+	expectedSqls := make([]string, len(filters))
+	for index := range filters {
+		filters[index] = fmt.Sprintf("name eq 'rule%d' or metadata/name eq 'rule%d'", index, index)
+		expectedSqls[index] = fmt.Sprintf("SELECT * FROM `mock_models` WHERE name = \"rule%d\" OR metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"rule%d\")", index, index)
+	}
+
+	// Act
+	results, err := BuildQueries(filters, db.Model(&MockModel{}), WithDatabaseType(SQLite))
+
+	// Assert
+	assert.NoError(t, err)
+	sqls := make([]string, len(results))
+	for index, result := range results {
+		sqls[index] = dryRunSQL(result)
+	}
+	assert.Equal(t, expectedSqls, sqls)
+	for _, result := range results[:3] {
+		assert.NoError(t, result.Find(&[]MockModel{}).Error)
+	}
+}
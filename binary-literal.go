@@ -0,0 +1,45 @@
+package gormodata
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// binaryLiteralPrefix
+// starts the binary literals of a filter, the bytes follow as hexadecimal digits between quotes (e.g. binary'00FF')
+const binaryLiteralPrefix = "binary"
+
+// binaryLiteralPattern
+// matches the binary literals of a filter, an even number of hexadecimal digits between quotes (e.g. binary'00FF')
+var binaryLiteralPattern = regexp.MustCompile(`^(?i:binary)'((?:[0-9A-Fa-f]{2})*)'$`)
+
+// BinaryLiteral
+// is the value of a binary literal of a filter (e.g. binary'00FF'), it is compared with binary columns (bytea, VARBINARY, BLOB)
+type BinaryLiteral []byte
+
+// String
+// returns the literal as it is written in a filter, the bytes as uppercase hexadecimal digits
+func (b BinaryLiteral) String() string {
+	return binaryLiteralPrefix + "'" + strings.ToUpper(hex.EncodeToString(b)) + "'"
+}
+
+// MarshalText
+// encodes the literal as it is written in a filter, so the json of expressions keeps binary literals apart from base64 strings
+func (b BinaryLiteral) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// isBinaryLiteral
+// returns whether the value of a node is a binary literal
+func isBinaryLiteral(value string) bool {
+	return binaryLiteralPattern.MatchString(value)
+}
+
+// binaryLiteral
+// returns the bytes of a binary literal, the pattern only matches valid hexadecimal digits
+func binaryLiteral(value string) BinaryLiteral {
+	bytes, _ := hex.DecodeString(binaryLiteralPattern.FindStringSubmatch(value)[1])
+
+	return bytes
+}
@@ -0,0 +1,194 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type ApiToken struct {
+	ID   uuid.UUID
+	Name string
+	Hash []byte
+}
+
+func Test_BuildQuery_BinaryLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"printable bytes": {
+			queryString: "hash eq binary'414243'",
+			expectedSql: "SELECT * FROM `api_tokens` WHERE hash = \"ABC\"",
+		},
+		"lowercase digits and prefix": {
+			queryString: "hash ne BINARY'6162'",
+			expectedSql: "SELECT * FROM `api_tokens` WHERE hash != \"ab\"",
+		},
+		"bytes that cannot be printed": {
+			queryString: "hash eq binary'00FF' and name eq 'ci'",
+			expectedSql: "SELECT * FROM `api_tokens` WHERE hash = \"<binary>\" AND name = \"ci\"",
+		},
+		"empty": {
+			queryString: "hash eq binary''",
+			expectedSql: "SELECT * FROM `api_tokens` WHERE hash = \"\"",
+		},
+		"string literal that looks like a binary literal": {
+			queryString: "name eq 'binary''00'''",
+			expectedSql: "SELECT * FROM `api_tokens` WHERE name = \"binary'00'\"",
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = BuildQuery(testData.queryString, tx.Model(&ApiToken{}), dbType)
+					return dbQuery.Find(&ApiToken{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSql, sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_BinaryLiteralResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"equal": {
+			queryString:   "hash eq binary'00FF'",
+			expectedNames: []string{"ci"},
+		},
+		"lowercase digits": {
+			queryString:   "hash eq binary'00ff10'",
+			expectedNames: []string{"deploy"},
+		},
+		"not equal": {
+			queryString:   "hash ne binary'00FF'",
+			expectedNames: []string{"deploy"},
+		},
+		"no match": {
+			queryString:   "hash eq binary'FF00'",
+			expectedNames: []string{},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&ApiToken{})
+			db.Create(&ApiToken{ID: uuid.New(), Name: "ci", Hash: []byte{0x00, 0xFF}})
+			db.Create(&ApiToken{ID: uuid.New(), Name: "deploy", Hash: []byte{0x00, 0xFF, 0x10}})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&ApiToken{}), SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []ApiToken
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, token := range result {
+				names = append(names, token.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQuery_BinaryLiteralErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"odd number of digits": {
+			queryString: "hash eq binary'0F0'",
+			expectedErr: "failed to parse query: invalid binary literal binary'0F0', expected pairs of hexadecimal digits",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"no hexadecimal digits": {
+			queryString: "hash eq binary'token'",
+			expectedErr: "failed to parse query: invalid binary literal binary'token', expected pairs of hexadecimal digits",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation property": {
+			queryString: "metadata/hash eq binary'00FF'",
+			expectedErr: "invalid query: relation property 'metadata/hash' cannot be compared with literal binary'00FF'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db.Model(&ApiToken{}), SQLite)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_BinaryLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("hash eq binary'00ff' or name eq 'binary''00'''")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Expr{Kind: LogicalExpr, Op: "or", Args: []*Expr{
+		{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("hash"), exprLiteral(BinaryLiteral{0x00, 0xFF})}},
+		{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("name"), exprLiteral("binary'00'")}},
+	}}, expr)
+	assert.Equal(t, "hash eq binary'00FF' or name eq 'binary''00'''", expr.String())
+	assert.Equal(t, Field("hash").Eq([]byte{0x00, 0xFF}), expr.Args[0])
+
+	data, err := json.Marshal(expr.Args[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"hash"},{"kind":"literal","value":"binary'00FF'"}]}`, string(data))
+
+	var decoded Expr
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, expr.Args[0], &decoded)
+}
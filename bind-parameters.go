@@ -0,0 +1,87 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// boundLiteralOperators
+// are the operators and functions whose right operand is bound as a parameter when it is a literal
+var boundLiteralOperators = append([]string{"has", "soundslike", "contains", "startswith", "endswith", levenshteinFunction, nullIfFunction}, comparisonOperators...)
+
+// WithBindParametersOnly
+// rejects filters with literals that would be written into the SQL instead of bound as parameters (e.g. the strings of concat
+// or the numbers of arithmetic and round), so every value of a client reaches the database as a bind parameter and the SQL
+// of a filter does not change with its values, e.g. for strict injection-prevention policies or the statement cache of pgbouncer,
+// the filters fail with ErrLiteralNotBound
+//
+//	builder := gormodata.New(gormodata.WithBindParametersOnly())
+//
+//	name eq 'rex'                    // name = ?
+//	concat(name,'x') eq 'rexx'       // rejected, 'x' would be written into the SQL
+//
+// the literals are only allowed where they are known to be bound: the values properties and functions are compared with,
+// the arguments of contains, startswith, endswith, soundslike, levenshtein, nullif and regexreplace and the flags of has,
+// every other literal is rejected, so a position that is not known to bind its literal fails closed
+func WithBindParametersOnly() Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, bindParametersValidation)
+	}
+}
+
+// bindParametersValidation
+// is the QueryValidation that rejects the literals that are not bound as parameters (see WithBindParametersOnly),
+// it does not use the db, so it can check filters without one
+func bindParametersValidation(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+	validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+		if !isLiteralNode(currentNode) || isBoundLiteral(currentNode) {
+			return nil
+		}
+
+		literal := currentNode.Value
+		if !isStringLiteral(literal) {
+			literal = quote(literal)
+		}
+		expression := currentNode.Value
+		if currentNode.Parent != nil {
+			expression = nodeExpression(currentNode.Parent)
+		}
+
+		return &InvalidQueryError{
+			Msg:        fmt.Sprintf("literal %s in '%s' cannot be bound as a parameter, only the values that are compared with can", literal, expression),
+			Err:        ErrLiteralNotBound,
+			Expression: expression,
+			Node:       currentNode,
+		}
+	}
+
+	return validateQueryDepthFirstSearch(db, tree, validationCheck)
+}
+
+// isLiteralNode
+// returns whether the node is a string, number, binary, geo, time or datetimeoffset literal
+func isLiteralNode(node *syntaxtree.Node) bool {
+	if node.Type != syntaxtree.LeftOperand && node.Type != syntaxtree.RightOperand {
+		return false
+	}
+
+	return isStringLiteral(node.Value) || isNumberLiteral(node.Value) || isBinaryLiteral(node.Value) ||
+		isGeoLiteral(node.Value) || isTimeOfDayLiteral(node.Value) || isDateTimeOffsetLiteral(node.Value)
+}
+
+// isBoundLiteral
+// returns whether the literal is in a position where the builder binds it as a parameter (see WithBindParametersOnly)
+func isBoundLiteral(node *syntaxtree.Node) bool {
+	parent := node.Parent
+	if parent == nil || parent.Type != syntaxtree.Operator {
+		return false
+	}
+	if parent.Value == regexReplaceArguments {
+		return true
+	}
+
+	return parent.RightChild == node && slices.Contains(boundLiteralOperators, parent.Value)
+}
@@ -0,0 +1,123 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_WithBindParametersOnly(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSql  string
+		expectedVars []any
+	}{
+		"comparisons": {
+			queryString:  "name eq 'Alice' and id gt 5",
+			expectedSql:  "SELECT * FROM `members` WHERE name = ? AND id > ?",
+			expectedVars: []any{"Alice", 5},
+		},
+		"string functions": {
+			queryString:  "contains(name,'li') or not(endswith(email,'.org'))",
+			expectedSql:  "SELECT * FROM `members` WHERE name LIKE ? OR email NOT LIKE ?",
+			expectedVars: []any{"%li%", "%.org"},
+		},
+		"functions of properties": {
+			queryString:  "concat(name,email) eq 'x' and id add id gt 4",
+			expectedSql:  "SELECT * FROM `members` WHERE name || email = ? AND (id + id) > ?",
+			expectedVars: []any{"x", 4},
+		},
+		"null": {
+			queryString:  "name eq null",
+			expectedSql:  "SELECT * FROM `members` WHERE name IS NULL",
+			expectedVars: nil,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithBindParametersOnly())
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db.Session(&gorm.Session{DryRun: true}))
+			statement := dbQuery.Find(&Member{}).Statement
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, statement.SQL.String())
+			assert.Equal(t, testData.expectedVars, statement.Vars)
+		})
+	}
+}
+
+func Test_Builder_WithBindParametersOnlyRejected(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"string literal of concat": {
+			queryString:   "concat(name,'x') eq 'Alicex'",
+			expectedError: "invalid query: literal 'x' in 'concat(name,'x')' cannot be bound as a parameter, only the values that are compared with can",
+		},
+		"number of arithmetic": {
+			queryString:   "id add 1 gt 5",
+			expectedError: "invalid query: literal '1' in 'id add 1' cannot be bound as a parameter, only the values that are compared with can",
+		},
+		"precision of round": {
+			queryString:   "round(id,2) eq 3",
+			expectedError: "invalid query: literal '2' in 'round(id,2)' cannot be bound as a parameter, only the values that are compared with can",
+		},
+		"literal as the first argument": {
+			queryString:   "name eq 'Alice' and concat('x',email) eq 'xa'",
+			expectedError: "invalid query: literal 'x' in 'concat('x',email)' cannot be bound as a parameter, only the values that are compared with can",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithBindParametersOnly())
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrLiteralNotBound))
+		})
+	}
+}
+
+func Test_Builder_WithBindParametersOnlyCollectAllErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithBindParametersOnly(), WithCollectAllErrors())
+
+	// Act
+	_, err := builder.Build("id add 1 gt 5 and concat(name,'x') eq 'a'", db)
+
+	// Assert
+	var queryErrors QueryErrors
+	assert.True(t, errors.As(err, &queryErrors))
+	assert.Len(t, queryErrors, 2)
+	assert.True(t, errors.Is(err, ErrLiteralNotBound))
+}
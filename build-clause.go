@@ -0,0 +1,47 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BuildClause
+// builds the filter into a clause.Expression instead of adding it to the db,
+// so it can be used in any statement (e.g. updates, deletes or subqueries)
+//
+//	expr, err := builder.BuildClause(queryString, db.Model(&MyModel{}))
+//	db.Where(expr).Delete(&MyModel{})
+//
+// the filters on relations and the typed literals are resolved with the model of the db (db.Model(...)),
+// the conditions that are already on the db are not part of the expression
+func (b *Builder) BuildClause(query string, db *gorm.DB) (clause.Expression, error) {
+	tx := db.Session(&gorm.Session{NewDB: true}).Model(db.Statement.Model)
+	if db.Statement.Table != "" {
+		tx = tx.Table(db.Statement.Table)
+	}
+
+	dbQuery, err := b.Build(query, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The callbacks that resolve the nested filters, typed literals and operator prefixes only run on queries, so they are run here
+	if dbQuery.Statement.Model != nil {
+		if err := dbQuery.Statement.Parse(dbQuery.Statement.Model); err != nil {
+			return nil, err
+		}
+	}
+	if plugin, ok := dbQuery.Plugins[nestedFilterPluginName].(*nestedFilterPlugin); ok {
+		plugin.queryCallback(dbQuery)
+	}
+	if convert := dbQuery.Callback().Query().Get("gormQonvert:query"); convert != nil {
+		convert(dbQuery)
+	}
+	if dbQuery.Error != nil {
+		return nil, dbQuery.Error
+	}
+
+	where, _ := dbQuery.Statement.Clauses["WHERE"].Expression.(clause.Where)
+
+	return clause.And(where.Exprs...), nil
+}
@@ -0,0 +1,130 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_BuildClause(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		statement   func(tx *gorm.DB, expr any) *gorm.DB
+		expectedSql string
+	}{
+		"delete": {
+			queryString: "name eq 'test' and metadata/name eq 'prd'",
+			statement: func(tx *gorm.DB, expr any) *gorm.DB {
+				return tx.Where(expr).Delete(&MockModel{})
+			},
+			expectedSql: "DELETE FROM `mock_models` WHERE name = \"test\" AND metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+		},
+		"update": {
+			queryString: "id eq '885B50A8-F2D2-4FC2-B8E8-4DB54F5EF5B6' or contains(testValue,'prd')",
+			statement: func(tx *gorm.DB, expr any) *gorm.DB {
+				return tx.Model(&MockModel{}).Where("name != ?", "locked").Where(expr).Update("name", "updated")
+			},
+			expectedSql: "UPDATE `mock_models` SET `name`=\"updated\" WHERE name != \"locked\" AND (id = \"885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6\" OR test_value LIKE \"%prd%\")",
+		},
+		"combined with other conditions": {
+			queryString: "length(name) gt 3",
+			statement: func(tx *gorm.DB, expr any) *gorm.DB {
+				return tx.Where("test_value = ?", "prd").Or(expr).Find(&[]MockModel{})
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"prd\" OR LENGTH(name) > 3",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			expr, err := builder.BuildClause(testData.queryString, db.Model(&MockModel{}))
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return testData.statement(tx, expr)
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_BuildClauseDelete(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	metadataID := uuid.MustParse("1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b")
+	db.Create(&MockModel{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "first", MetadataID: &metadataID, Metadata: &Metadata{ID: metadataID, Name: "prd"}})
+	db.Create(&MockModel{ID: uuid.MustParse("e2b1b0c9-3d6f-4b7e-9a51-7f0a2e3c4d5e"), Name: "second"})
+	builder := New(WithDatabaseType(SQLite))
+
+	// Act
+	expr, err := builder.BuildClause("metadata/name eq 'prd'", db.Model(&MockModel{}))
+	deleteResult := db.Where(expr).Delete(&MockModel{})
+	var result []MockModel
+	db.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, deleteResult.Error)
+	assert.Equal(t, int64(1), deleteResult.RowsAffected)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "second", result[0].Name)
+}
+
+func Test_Builder_BuildClauseError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+	}{
+		"invalid filter": {
+			queryString: "length(name)",
+			expectedErr: "invalid query: unary function 'length' cannot be the root of a filter, compare it with a value instead",
+		},
+		"unknown relation": {
+			queryString: "owner/name eq 'test'",
+			expectedErr: "invalid query: unknown relation 'owner' on 'mock_models'",
+		},
+		"invalid literal": {
+			queryString: "id eq 'not-a-uuid'",
+			expectedErr: "invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			expr, err := builder.BuildClause(testData.queryString, db.Model(&MockModel{}))
+
+			// Assert
+			assert.Nil(t, expr)
+			assert.EqualError(t, err, testData.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,863 @@
+package gormodata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Builder
+// builds gorm queries from odata query strings with a configuration that is prepared once,
+// create it once per service with New and reuse it for every request
+//
+//	builder := gormodata.New(gormodata.WithDatabaseType(gormodata.PostgreSQL), gormodata.WithQueryValidations(gormodata.WithMaxTreeDepth(10)))
+//	dbQuery, err := builder.Build(queryString, db)
+type Builder struct {
+	databaseType    DbType
+	databaseTypeSet bool
+
+	fieldAliases map[string]string
+
+	maxDepth  int
+	maxLength int
+	maxTokens int
+
+	disabledFunctions map[string]bool
+
+	// extensionFunctions are the functions that are not part of odata that filters can use (see WithExtensionFunctions)
+	extensionFunctions map[string]bool
+
+	logger Logger
+
+	metrics Metrics
+
+	collectAllErrors bool
+
+	// treeCache holds the parsed filters (see WithTreeCache), nil if filters are parsed on every build
+	treeCache *TreeCache
+
+	// qonvert is the translation of the gormqonvert config of WithQonvertConfig, nil if the config is not given
+	qonvert *qonvertTranslation
+
+	// validateLiterals checks the literals against the model before the query is executed (see Validate)
+	validateLiterals bool
+
+	// inListChunkSize is the maximum number of values of an IN list (see WithInListChunkSize), 0 for the default
+	inListChunkSize int
+
+	// columnNames remembers the column names of the properties of the built filters
+	columnNames *columnNameCache
+
+	// queryHints are the hints that are added to the queries of matching filters (see WithQueryHints)
+	queryHints []queryHintRule
+
+	// systemFilters are added to every built query (see WithSystemFilter)
+	systemFilters []SystemFilter
+
+	// auditHook is called with every built filter (see WithAuditHook), nil if there is no hook
+	auditHook AuditHook
+
+	// rootEntitySets are the models of the entity sets that filters can refer to with $root (see WithRootEntitySets)
+	rootEntitySets map[string]any
+
+	// enums are the members of the flag enum types that filters can check with 'has' (see WithEnum)
+	enums map[string]map[string]int64
+
+	// dateTimeOffsetMode sets how datetimeoffset literals are compared (see WithDateTimeOffsetMode)
+	dateTimeOffsetMode DateTimeOffsetMode
+
+	// fractionalSecondsPrecision sets the unit of fractionalsecond (see WithFractionalSecondsPrecision)
+	fractionalSecondsPrecision FractionalSecondsPrecision
+
+	// caseInsensitiveFields are the fields whose equality comparisons ignore the case (see WithCaseInsensitiveFields)
+	caseInsensitiveFields []string
+
+	// maxExpandDepth is the maximum number of relations a property goes through (see WithMaxExpandDepth), 0 if there is no limit
+	maxExpandDepth int
+
+	// maxOrBranches is the maximum number of conditions joined by 'or' (see WithMaxOrBranches), 0 if there is no limit
+	maxOrBranches int
+
+	// maxListSize is the maximum number of values in the list of a property (see WithMaxListSize), 0 if there is no limit
+	maxListSize int
+
+	// partialFilters drops the conditions on unknown properties and properties that are not allowed (see WithPartialFilters)
+	partialFilters bool
+
+	// statementTimeout is the timeout of the queries that run on built queries (see WithStatementTimeout), 0 if there is no timeout
+	statementTimeout time.Duration
+
+	// clock evaluates now() when the filter is built (see WithServerNow), nil if the database evaluates it
+	clock func() time.Time
+
+	queryValidations []QueryValidation
+
+	// hasUserQueryValidations is whether the options added query validations, next to the ones every Builder validates (see New)
+	hasUserQueryValidations bool
+}
+
+// collectAllErrorsSetting
+// is the gorm setting a Builder sets on the db when it collects all errors (see WithCollectAllErrors)
+const collectAllErrorsSetting = "gormodata:collect_all_errors"
+
+// comparisonOperators
+// are the operators that compare a column with a literal
+var comparisonOperators = []string{"eq", "ne", "lt", "le", "gt", "ge"}
+
+// Logger
+// receives the diagnostics of a Builder (see WithLogger), *slog.Logger implements it
+type Logger interface {
+	Debug(msg string, args ...any)
+}
+
+// noopLogger
+// is the default Logger of a Builder, it discards all diagnostics
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any) {}
+
+// logging
+// returns whether the Builder has a Logger, the arguments of the diagnostics are only built when they are logged
+func (b *Builder) logging() bool {
+	_, discard := b.logger.(noopLogger)
+
+	return !discard
+}
+
+// Option
+// configures a Builder (see New)
+type Option func(*Builder)
+
+// WithDatabaseType
+// sets the database type the queries are built for,
+// without this option the database type is detected from the gorm dialector on every Build
+func WithDatabaseType(databaseType DbType) Option {
+	return func(b *Builder) {
+		b.databaseType = databaseType
+		b.databaseTypeSet = true
+	}
+}
+
+// WithQueryValidations
+// adds query validations that are run on every query before it is built (see WithInputModelValidation, WithMaxObjectExpansion...)
+func WithQueryValidations(queryValidations ...QueryValidation) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, queryValidations...)
+	}
+}
+
+// WithAllowedFields
+// only allows filters on the given fields (e.g. "name", "createdAt", "metadata/name"),
+// filters that reference any other field, also inside functions, fail with an InvalidQueryError wrapping ErrFieldNotAllowed
+//
+// relation paths need to be allowed as a whole, allowing "metadata/name" does not allow "metadata/id"
+func WithAllowedFields(fields ...string) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, allowedFieldsValidation(fields))
+	}
+}
+
+// WithDeniedFields
+// never allows filters on the given fields (e.g. "passwordHash", "metadata/secret"), also inside functions,
+// these filters fail with an InvalidQueryError wrapping ErrFieldNotAllowed
+//
+// a field name denies that field on every model of a relation path (e.g. "passwordHash" also denies "owner/passwordHash"),
+// a relation path denies that path and everything below it (e.g. "metadata/tag" also denies "metadata/tag/value")
+func WithDeniedFields(fields ...string) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, deniedFieldsValidation(fields))
+	}
+}
+
+// WithFieldAliases
+// maps the property names used in filters to the fields of the model (e.g. "created": "createdAt", "tagValue": "metadata/tag/value"),
+// so the names exposed to clients can differ from the go field names
+//
+// an alias also replaces the start of a relation path (e.g. "meta": "metadata" maps "meta/name" to "metadata/name"),
+// aliases are resolved before any other validation, so WithAllowedFields and WithDeniedFields refer to the fields of the model
+func WithFieldAliases(aliases map[string]string) Option {
+	return func(b *Builder) {
+		if b.fieldAliases == nil {
+			b.fieldAliases = make(map[string]string, len(aliases))
+		}
+		maps.Copy(b.fieldAliases, aliases)
+	}
+}
+
+// WithMaxDepth
+// rejects filters whose syntax tree is deeper than maxDepth (see WithMaxTreeDepth),
+// the nesting of brackets is checked before parsing as well, so deeply nested filters are rejected without being parsed
+func WithMaxDepth(maxDepth int) Option {
+	return func(b *Builder) {
+		b.maxDepth = maxDepth
+		b.queryValidations = append(b.queryValidations, WithMaxTreeDepth(maxDepth))
+	}
+}
+
+// WithMaxLength
+// rejects filters longer than maxLength characters before they are parsed
+func WithMaxLength(maxLength int) Option {
+	return func(b *Builder) {
+		b.maxLength = maxLength
+	}
+}
+
+// WithMaxTokens
+// rejects filters with more than maxTokens tokens (operators, functions, brackets, operands...) before they are parsed
+func WithMaxTokens(maxTokens int) Option {
+	return func(b *Builder) {
+		b.maxTokens = maxTokens
+	}
+}
+
+// WithDisabledFunctions
+// rejects filters that use one of the given functions (e.g. "now", "concat") before they are parsed,
+// with an InvalidQueryError wrapping ErrFunctionNotAllowed
+func WithDisabledFunctions(functions ...string) Option {
+	return func(b *Builder) {
+		if b.disabledFunctions == nil {
+			b.disabledFunctions = make(map[string]bool, len(functions))
+		}
+		for _, function := range functions {
+			b.disabledFunctions[strings.ToLower(function)] = true
+		}
+	}
+}
+
+// WithLogger
+// routes the diagnostics of the Builder (e.g. why a filter was rejected) to the given logger,
+// diagnostics are discarded by default
+func WithLogger(logger Logger) Option {
+	return func(b *Builder) {
+		b.logger = logger
+	}
+}
+
+// WithCollectAllErrors
+// makes Build return all problems of a filter as QueryErrors instead of only the first one,
+// so clients can show every issue at once (e.g. unknown fields, disabled functions, invalid uuid and datetime literals)
+//
+// filters that cannot be parsed or exceed WithMaxLength, WithMaxTokens or WithMaxDepth still fail on the first problem,
+// literals are only checked when the model is set on the db (db.Model(...))
+func WithCollectAllErrors() Option {
+	return func(b *Builder) {
+		b.collectAllErrors = true
+	}
+}
+
+// WithQonvertConfig
+// sets the gormqonvert config the filters on relations are built with (e.g. metadata/name ne 'test' becomes {"name": "<NotEqualToPrefix>test"}),
+// gormqonvert is registered with this config on dbs where it is not registered yet
+//
+// pass the same config when you register gormqonvert yourself (db.Use(gormqonvert.New(config))),
+// its config cannot be read from the plugin, so without this option the filters fail
+func WithQonvertConfig(config gormqonvert.CharacterConfig) Option {
+	return func(b *Builder) {
+		b.qonvert = newQonvertTranslation(config)
+	}
+}
+
+// WithTreeCache
+// keeps the parsed filters in the given cache, so filters that are built again are not parsed again,
+// use it when the same filters are built often (e.g. filters from a UI or fixed filters of a service)
+//
+//	cache := gormodata.NewTreeCache(1000)
+//	builder := gormodata.New(gormodata.WithTreeCache(cache))
+//	fmt.Println(cache.Stats().Hits)
+func WithTreeCache(cache *TreeCache) Option {
+	return func(b *Builder) {
+		b.treeCache = cache
+	}
+}
+
+// New
+// creates a Builder with the given options
+func New(opts ...Option) *Builder {
+	builder := &Builder{columnNames: &columnNameCache{}}
+	for _, opt := range opts {
+		opt(builder)
+	}
+
+	if builder.logger == nil {
+		builder.logger = noopLogger{}
+	}
+	if builder.metrics == nil {
+		builder.metrics = noopMetrics{}
+	}
+
+	// Extra protection against SQL injection
+	builder.hasUserQueryValidations = len(builder.queryValidations) > 0
+	builder.queryValidations = append(builder.queryValidations, operandBadPatternValidation, extensionFunctionValidation(builder.extensionFunctions))
+
+	return builder
+}
+
+// Build
+// builds a gorm query based on an odata query string
+func (b *Builder) Build(query string, db *gorm.DB) (*gorm.DB, error) {
+	result, _, err := b.build(query, db)
+
+	return result, err
+}
+
+// BuildContext
+// builds a gorm query based on an odata query string like Build, with the context on the returned gorm session,
+// the build is stopped with the error of the context when it is canceled or its deadline is exceeded
+func (b *Builder) BuildContext(ctx context.Context, query string, db *gorm.DB) (*gorm.DB, error) {
+	return b.Build(query, db.WithContext(ctx))
+}
+
+// build
+// builds the gorm query and returns it with the syntax tree it was built from
+func (b *Builder) build(query string, db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	return b.buildLogged(db, "query", query, func(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+		return b.buildTree(query, db)
+	})
+}
+
+// BuildExpr
+// builds a gorm query based on a parsed filter (see Parse), e.g. a filter that was stored as JSON,
+// the expression is validated like a filter string, except for WithMaxLength and WithMaxTokens which only apply to strings
+func (b *Builder) BuildExpr(expr *Expr, db *gorm.DB) (*gorm.DB, error) {
+	result, _, err := b.buildLogged(db, "expr", expr, func(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+		return b.buildExprTree(expr, db)
+	})
+
+	return result, err
+}
+
+// buildLogged
+// runs the build function and logs whether the filter was built or rejected
+func (b *Builder) buildLogged(db *gorm.DB, filterKey string, filter any, buildFunc func(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error)) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	if b.collectAllErrors {
+		db = db.Set(collectAllErrorsSetting, true)
+	}
+
+	start := time.Now()
+	existingConditions := len(whereConditions(db))
+	result, tree, err := buildFunc(db)
+	if err == nil && len(b.systemFilters) > 0 {
+		result, err = b.applySystemFilters(db, result, existingConditions)
+	}
+	b.metrics.ObserveBuildDuration(time.Since(start))
+
+	if err != nil {
+		var queryErrors QueryErrors
+		if b.collectAllErrors && !errors.As(err, &queryErrors) {
+			err = QueryErrors{err}
+		}
+
+		b.metrics.IncRejectedQuery(err)
+
+		if b.logging() {
+			b.logger.Debug("odata filter rejected", filterKey, filter, "error", err)
+		}
+
+		return result, nil, err
+	}
+
+	if b.logging() {
+		b.logger.Debug("odata filter built", filterKey, filter)
+	}
+
+	if b.auditHook != nil && tree != nil {
+		b.auditHook(db, newFilterAudit(tree))
+	}
+
+	return b.applyQonvertConfig(b.applyStatementTimeout(b.applyQueryHints(result, tree))), tree, nil
+}
+
+func (b *Builder) buildTree(query string, db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	translation, db, err := b.prepareDB(db)
+	if err != nil {
+		return db, nil, err
+	}
+
+	return b.buildTranslated(query, db, translation)
+}
+
+// buildTranslated
+// parses and builds the query on a db that is already prepared with the translation (see prepareDB)
+func (b *Builder) buildTranslated(query string, db *gorm.DB, translation *queryTranslation) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	if err := contextError(db); err != nil {
+		return db, nil, err
+	}
+
+	tree, queryErrors, err := b.parseChecked(query)
+	if err != nil {
+		return db, nil, err
+	}
+
+	return b.buildSyntaxTree(tree, db, translation, queryErrors)
+}
+
+// parseChecked
+// parses the query after checking its size and functions (see WithMaxLength, WithMaxTokens, WithDisabledFunctions, WithMaxDepth),
+// the disabled functions are returned as query errors when all errors are collected
+func (b *Builder) parseChecked(query string) (*syntaxtree.SyntaxTree, QueryErrors, error) {
+	if b.maxLength > 0 && len(query) > b.maxLength {
+		return nil, nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("query length exceeds the maximum of %d characters", b.maxLength),
+			Err: ErrComplexityExceeded,
+		}
+	}
+
+	queryErrors := QueryErrors{}
+	if b.maxTokens > 0 || len(b.disabledFunctions) > 0 {
+		tokens, _ := odataParser.tokenize(query)
+		if b.maxTokens > 0 && len(tokens) > b.maxTokens {
+			return nil, nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("query contains %d tokens, which exceeds the maximum of %d", len(tokens), b.maxTokens),
+				Err: ErrComplexityExceeded,
+			}
+		}
+
+		for _, token := range tokens {
+			function := strings.ToLower(token.Value)
+			// Deprecated functions are disabled with the function that replaces them
+			if (token.Type == syntaxtree.UnaryFunc || token.Type == syntaxtree.BinaryFunc) && (b.disabledFunctions[function] || b.disabledFunctions[deprecatedFunctions[function]]) {
+				err := &InvalidQueryError{
+					Msg:        fmt.Sprintf("function '%s' is disabled", token.Value),
+					Err:        ErrFunctionNotAllowed,
+					Expression: token.Value,
+				}
+				if !b.collectAllErrors {
+					return nil, nil, err
+				}
+				queryErrors = append(queryErrors, err)
+			}
+		}
+	}
+
+	if b.maxDepth > 0 && bracketDepth(query) > b.maxDepth {
+		return nil, nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("maximum query complexity exceeded: >%d", b.maxDepth),
+			Err: ErrComplexityExceeded,
+		}
+	}
+
+	tree, err := b.parse(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tree, queryErrors, nil
+}
+
+// parse
+// returns the syntax tree of the query, from the tree cache if the builder has one
+func (b *Builder) parse(query string) (*syntaxtree.SyntaxTree, error) {
+	start := time.Now()
+	defer func() {
+		b.metrics.ObserveParseDuration(time.Since(start))
+	}()
+
+	if b.treeCache == nil {
+		return GetAST(query)
+	}
+
+	tree, hit, err := b.treeCache.parse(query)
+	b.metrics.IncTreeCacheLookup(hit)
+
+	return tree, err
+}
+
+// buildExprTree
+// builds the gorm query of a parsed filter, disabled functions are checked on the expression instead of the tokens
+func (b *Builder) buildExprTree(expr *Expr, db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	translation, db, err := b.prepareDB(db)
+	if err != nil {
+		return db, nil, err
+	}
+
+	tree, err := expr.syntaxTree()
+	if err != nil {
+		return db, nil, err
+	}
+
+	queryErrors := QueryErrors{}
+	Inspect(expr, func(expr *Expr) bool {
+		if expr != nil && expr.Kind == FunctionExpr && (b.disabledFunctions[strings.ToLower(expr.Func)] || b.disabledFunctions[deprecatedFunctions[strings.ToLower(expr.Func)]]) {
+			queryErrors = append(queryErrors, &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' is disabled", expr.Func),
+				Err:        ErrFunctionNotAllowed,
+				Expression: expr.Func,
+			})
+		}
+
+		return true
+	})
+	if len(queryErrors) > 0 && !b.collectAllErrors {
+		return db, nil, queryErrors[0]
+	}
+
+	return b.buildSyntaxTree(tree, db, translation, queryErrors)
+}
+
+// prepareDB
+// returns the db with the plugins that the built queries need and the translation of the queries for the db
+func (b *Builder) prepareDB(db *gorm.DB) (*queryTranslation, *gorm.DB, error) {
+	databaseType, err := b.resolveDatabaseType(db)
+	if err != nil {
+		return nil, db, err
+	}
+
+	db, err = checkDbPlugins(db, b.qonvert)
+	if err != nil {
+		return nil, db, err
+	}
+
+	// The config of the builder is used as is, so builders with different configs on the same db do not share it
+	qonvert := b.qonvert
+	if qonvert == nil {
+		qonvert, err = registeredQonvertTranslation(db)
+		if err != nil {
+			return nil, db, err
+		}
+	}
+
+	translation := newQueryTranslation(db, databaseType, qonvert, b.columnNames.forBuild())
+	translation.inListChunkSize = b.inListChunkSize
+	translation.rootEntitySets = b.rootEntitySets
+	translation.enums = b.enums
+	translation.dateTimeOffsetMode = b.dateTimeOffsetMode
+	translation.fractionalSecondsPrecision = b.fractionalSecondsPrecision
+	translation.caseInsensitiveFields = b.caseInsensitiveFields
+	if b.clock != nil {
+		now := b.clock()
+		translation.now = &now
+	}
+
+	return translation, db, nil
+}
+
+// buildSyntaxTree
+// validates the syntax tree and builds the gorm query, the query errors that were already collected are returned with the errors of the validations
+func (b *Builder) buildSyntaxTree(tree *syntaxtree.SyntaxTree, db *gorm.DB, translation *queryTranslation, queryErrors QueryErrors) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	if err := b.resolveFieldAliases(tree); err != nil {
+		return db, nil, err
+	}
+	rewriteDeprecatedFunctions(tree)
+
+	if b.partialFilters {
+		db = db.Set(droppedConditionsSetting, b.dropInvalidConditions(tree, db))
+		if tree.Root == nil {
+			if len(queryErrors) > 0 {
+				return db, nil, queryErrors
+			}
+
+			return db, nil, nil
+		}
+	}
+
+	queryErrors, err := b.runQueryValidations(tree, db, queryErrors)
+	if err != nil {
+		return db, nil, err
+	}
+
+	if err := validateHiddenFields(tree, db); err != nil {
+		if !b.collectAllErrors {
+			return db, nil, err
+		}
+		queryErrors = appendHiddenFieldErrors(queryErrors, err)
+	}
+
+	if b.collectAllErrors || b.validateLiterals {
+		if err := validateLiterals(tree, db); err != nil {
+			if !b.collectAllErrors {
+				return db, nil, err
+			}
+			queryErrors = appendQueryErrors(queryErrors, err)
+		}
+	}
+
+	if len(queryErrors) > 0 {
+		return db, nil, queryErrors
+	}
+
+	if err := contextError(db); err != nil {
+		return db, nil, err
+	}
+
+	db, err = buildGormQuery(tree.Root, db, translation, operatorTranslation, false)
+
+	return db, tree, err
+}
+
+// runQueryValidations
+// runs the query validations of the builder on the tree (see WithQueryValidations),
+// their errors are added to the query errors when all errors are collected, otherwise the first one is returned
+func (b *Builder) runQueryValidations(tree *syntaxtree.SyntaxTree, db *gorm.DB, queryErrors QueryErrors) (QueryErrors, error) {
+	for _, validateQuery := range b.queryValidations {
+		if err := contextError(db); err != nil {
+			return queryErrors, err
+		}
+
+		if err := validateQuery(tree, db); err != nil {
+			if !b.collectAllErrors {
+				return queryErrors, err
+			}
+			queryErrors = appendQueryErrors(queryErrors, err)
+		}
+	}
+
+	return queryErrors, nil
+}
+
+// contextError
+// returns the error of the context of the db when it is canceled or its deadline is exceeded (see BuildContext)
+func contextError(db *gorm.DB) error {
+	if db.Statement.Context == nil {
+		return nil
+	}
+
+	return db.Statement.Context.Err()
+}
+
+// appendQueryErrors
+// appends the error to the query errors, the errors of QueryErrors are appended one by one
+func appendQueryErrors(queryErrors QueryErrors, err error) QueryErrors {
+	var nestedErrors QueryErrors
+	if errors.As(err, &nestedErrors) {
+		return append(queryErrors, nestedErrors...)
+	}
+
+	return append(queryErrors, err)
+}
+
+// collectAllErrors
+// returns whether the validations on the db need to return all errors instead of the first one (see WithCollectAllErrors)
+func collectAllErrors(db *gorm.DB) bool {
+	if db == nil {
+		return false
+	}
+	collectAll, _ := db.Get(collectAllErrorsSetting)
+	enabled, _ := collectAll.(bool)
+
+	return enabled
+}
+
+// validateLiterals
+// checks the literals compared with uuid and time columns of the model on the db, like they are converted once the query is executed
+func validateLiterals(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+	if db.Statement.Model == nil {
+		return nil
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(db.Statement.Model); err != nil {
+		return nil
+	}
+
+	validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+		isComparison := slices.Contains(comparisonOperators, currentNode.Value) || currentNode.Value == inOperator
+		if currentNode.Type != syntaxtree.Operator || !isComparison || currentNode.LeftChild == nil || currentNode.RightChild == nil {
+			return nil
+		}
+		leftChild, rightChild := currentNode.LeftChild, currentNode.RightChild
+		// The values compared with greatest or least have the type of their properties (see extremumProperty)
+		if property := extremumProperty(leftChild); property != nil {
+			leftChild = property
+		}
+		if !isPropertyNode(leftChild) || strings.Contains(modelProperty(leftChild.Value), "/") || rightChild.Type != syntaxtree.RightOperand || inLambdaElement(leftChild) ||
+			isRootReference(rightChild.Value) {
+			return nil
+		}
+
+		field := statement.Schema.LookUpField(db.NamingStrategy.ColumnName("", modelProperty(leftChild.Value)))
+		if field == nil {
+			return nil
+		}
+
+		literals := []string{rightChild.Value}
+		if isListLiteral(rightChild.Value) {
+			literals = listLiteralElements(rightChild.Value)
+		}
+		for _, literal := range literals {
+			literal = unquote(literal)
+			if isNumericSuffixLiteral(literal) {
+				literal = numericSQL(literalValue(literal))
+			}
+			if _, err := convertLiteral(field, literal); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return validateQueryDepthFirstSearch(db, tree, validationCheck)
+}
+
+// resolveFieldAliases
+// replaces the aliases in the property nodes of the tree by the fields they map to
+func (b *Builder) resolveFieldAliases(tree *syntaxtree.SyntaxTree) error {
+	if len(b.fieldAliases) == 0 {
+		return nil
+	}
+
+	return validateQueryDepthFirstSearch(nil, tree, func(depth int, currentNode *syntaxtree.Node) error {
+		if !isPropertyNode(currentNode) {
+			return nil
+		}
+
+		if field, ok := b.fieldAliases[currentNode.Value]; ok {
+			currentNode.Value = field
+
+			return nil
+		}
+
+		// The longest alias that matches the start of the relation path wins
+		parts := strings.Split(currentNode.Value, "/")
+		for i := len(parts) - 1; i > 0; i-- {
+			if field, ok := b.fieldAliases[strings.Join(parts[:i], "/")]; ok {
+				currentNode.Value = strings.Join(append([]string{field}, parts[i:]...), "/")
+
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+// resolveDatabaseType
+// returns the configured database type or detects it from the name of the gorm dialector
+func (b *Builder) resolveDatabaseType(db *gorm.DB) (DbType, error) {
+	if b.databaseTypeSet {
+		return b.databaseType, nil
+	}
+
+	if db.Dialector != nil {
+		switch db.Dialector.Name() {
+		case "postgres":
+			return PostgreSQL, nil
+		case "mysql":
+			return MySQL, nil
+		case "sqlite":
+			return SQLite, nil
+		case "sqlserver":
+			return SQLServer, nil
+		}
+	}
+
+	return 0, errors.New("unable to detect the database type of the gorm dialector, use WithDatabaseType")
+}
+
+func allowedFieldsValidation(fields []string) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		allowedFields := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			allowedFields[propertyPath(db.NamingStrategy, field)] = true
+		}
+
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			if isPropertyNode(currentNode) && !allowedFields[propertyPath(db.NamingStrategy, currentNode.Value)] {
+				return &InvalidQueryError{
+					Msg:        fmt.Sprintf("field '%s' is not allowed", currentNode.Value),
+					Err:        ErrFieldNotAllowed,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
+
+func deniedFieldsValidation(fields []string) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		deniedFields := make([]string, len(fields))
+		for i, field := range fields {
+			deniedFields[i] = propertyPath(db.NamingStrategy, field)
+		}
+
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			if !isPropertyNode(currentNode) {
+				return nil
+			}
+
+			path := propertyPath(db.NamingStrategy, currentNode.Value)
+			parts := strings.Split(path, "/")
+			for _, deniedField := range deniedFields {
+				denied := path == deniedField || strings.HasPrefix(path, deniedField+"/")
+				if !strings.Contains(deniedField, "/") {
+					denied = slices.Contains(parts, deniedField)
+				}
+				if denied {
+					return &InvalidQueryError{
+						Msg:        fmt.Sprintf("field '%s' is not allowed", currentNode.Value),
+						Err:        ErrFieldNotAllowed,
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
+					}
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
+
+// bracketDepth
+// returns the deepest nesting of brackets in the query, brackets inside string literals are ignored
+func bracketDepth(query string) int {
+	depth, maxDepth := 0, 0
+	inLiteral := false
+	for _, character := range query {
+		switch {
+		case character == '\'':
+			inLiteral = !inLiteral
+		case inLiteral:
+		case character == '(':
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case character == ')':
+			depth--
+		}
+	}
+
+	return maxDepth
+}
+
+// isPropertyNode
+// returns whether the node refers to a property of the model instead of a literal
+func isPropertyNode(node *syntaxtree.Node) bool {
+	if isNowArgument(node) || strings.HasPrefix(node.Value, "'") || isBinaryLiteral(node.Value) || isGeoLiteral(node.Value) || isTimeOfDayLiteral(node.Value) || isDateTimeOffsetLiteral(node.Value) {
+		return false
+	}
+	// The operands of arithmetic and numeric functions are properties unless they are literals (e.g. price mul 2)
+	if isArithmeticNode(node.Parent) || isNegationNode(node.Parent) || isNumericFunctionNode(node.Parent) {
+		_, isWord := literalValue(node.Value).(string)
+
+		return isWord && (node.Type == syntaxtree.LeftOperand || node.Type == syntaxtree.RightOperand)
+	}
+
+	return node.Type == syntaxtree.LeftOperand ||
+		(node.Type == syntaxtree.RightOperand && node.Parent != nil && node.Parent.Value == "concat")
+}
+
+// propertyPath
+// returns the column names of every part of a (relation) property path, e.g. metadata/tagValue -> metadata/tag_value
+func propertyPath(namer schema.Namer, property string) string {
+	parts := strings.Split(modelProperty(property), "/")
+	for i, part := range parts {
+		parts[i] = namer.ColumnName("", part)
+	}
+
+	return strings.Join(parts, "/")
+}
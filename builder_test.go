@@ -0,0 +1,677 @@
+package gormodata
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"github.com/test-go/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_Build(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		opts        []Option
+		queryString string
+		expectedSql string
+	}{
+		"database type": {
+			opts:        []Option{WithDatabaseType(PostgreSQL)},
+			queryString: "year(createdAt) gt 2025",
+			expectedSql: "SELECT * FROM `mock_time_models` WHERE EXTRACT(YEAR FROM created_at) > 2025",
+		},
+		"detected database type": {
+			queryString: "year(createdAt) gt 2025",
+			expectedSql: "SELECT * FROM `mock_time_models` WHERE YEAR(created_at) > 2025",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockTimeModel{})
+			builder := New(testData.opts...)
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&MockTimeModel{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_WithQonvertConfig(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	config := gormqonvert.CharacterConfig{
+		GreaterThanPrefix:      "+",
+		GreaterOrEqualToPrefix: "+=",
+		LessThanPrefix:         "-",
+		LessOrEqualToPrefix:    "-=",
+		NotEqualToPrefix:       "/=",
+		LikePrefix:             "::",
+		NotLikePrefix:          "!::",
+	}
+
+	tests := map[string]struct {
+		registerPlugin bool
+	}{
+		"registers gormqonvert with the config": {},
+		"gormqonvert registered with the config": {
+			registerPlugin: true,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			if testData.registerPlugin {
+				_ = db.Use(gormqonvert.New(config))
+			}
+			metadataID := uuid.MustParse("1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b")
+			db.Create(&MockModel{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "first", MetadataID: &metadataID, Metadata: &Metadata{ID: metadataID, Name: "prd"}})
+			otherMetadataID := uuid.MustParse("6afa4aef-a646-415b-ae2d-1ab7fc554c08")
+			db.Create(&MockModel{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "second", MetadataID: &otherMetadataID, Metadata: &Metadata{ID: otherMetadataID, Name: "acc"}})
+			builder := New(WithDatabaseType(SQLite), WithQonvertConfig(config))
+
+			queryString := "metadata/id ne '1EA3CF2F-5C1F-47C6-B0C3-78F0CEE2007B' and contains(metadata/name,'c')"
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(queryString, tx)
+				return dbQuery.Find(&[]MockModel{})
+			})
+
+			var result []MockModel
+			dbQuery, _ := builder.Build(queryString, db)
+			dbQuery.Find(&result)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE id != \"1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b\") AND metadata_id IN (SELECT `id` FROM `metadata` WHERE name LIKE \"%c%\")", sqlQuery)
+			if assert.Len(t, result, 1) {
+				assert.Equal(t, "second", result[0].Name)
+			}
+		})
+	}
+}
+
+func Test_Builder_WithQonvertConfigPerBuilder(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	// The config of a plugin registered outside of the package is unknown, so the nested filters only know the configs of the builders
+	_ = db.Use(gormqonvert.New(gormqonvert.CharacterConfig{NotEqualToPrefix: "/="}))
+	slashBuilder := New(WithDatabaseType(SQLite), WithQonvertConfig(gormqonvert.CharacterConfig{NotEqualToPrefix: "/="}))
+	angleBuilder := New(WithDatabaseType(SQLite), WithQonvertConfig(gormqonvert.CharacterConfig{NotEqualToPrefix: "<>"}))
+
+	queryString := "metadata/id ne '1EA3CF2F-5C1F-47C6-B0C3-78F0CEE2007B'"
+	expectedSql := "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE id != \"1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b\")"
+
+	// Act
+	sqlQueries := []string{}
+	errs := []error{}
+	for _, builder := range []*Builder{slashBuilder, angleBuilder, slashBuilder} {
+		sqlQueries = append(sqlQueries, db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			dbQuery, err := builder.Build(queryString, tx)
+			errs = append(errs, err)
+			return dbQuery.Find(&[]MockModel{})
+		}))
+	}
+
+	// Assert
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.Equal(t, []string{expectedSql, expectedSql, expectedSql}, sqlQueries)
+}
+
+func Test_Builder_ConcurrentFirstBuilds(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	builder := New(WithDatabaseType(SQLite))
+
+	// Act
+	errs := make(chan error, 20)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dbQuery, err := builder.Build("metadata/name ne 'prd'", db)
+			if err == nil {
+				err = dbQuery.Find(&[]MockModel{}).Error
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	setup, ok := pluginSetups.Load(db.Callback())
+	assert.True(t, ok)
+	assert.True(t, setup.done.Load())
+	assert.Contains(t, db.Plugins, nestedFilterPluginName)
+	assert.Contains(t, db.Plugins, gormqonvertPluginName)
+}
+
+func Test_Builder_BuildReused(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	db.Create(&MockModel{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test"})
+	db.Create(&MockModel{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "prd"})
+	builder := New(WithDatabaseType(SQLite), WithQueryValidations(WithInputModelValidation(MockModel{})))
+
+	// Act
+	var testResult []MockModel
+	testQuery, testErr := builder.Build("name eq 'test'", db)
+	_ = testQuery.Find(&testResult)
+
+	var prdResult []MockModel
+	prdQuery, prdErr := builder.Build("name eq 'prd'", db)
+	_ = prdQuery.Find(&prdResult)
+
+	_, invalidErr := builder.Build("unknown eq 'test'", db)
+
+	// Assert
+	assert.NoError(t, testErr)
+	assert.NoError(t, prdErr)
+	assert.Equal(t, []MockModel{{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test"}}, testResult)
+	assert.Equal(t, []MockModel{{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "prd"}}, prdResult)
+	assert.EqualError(t, invalidErr, "invalid query: unknown column name 'unknown'")
+}
+
+func Test_Builder_WithAllowedFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"allowed fields": {
+			queryString: "name eq 'test' and contains(metadata/name,'prd')",
+		},
+		"allowed field inside functions": {
+			queryString: "length(tolower(name)) gt 2 and concat(name,testValue) eq 'test'",
+		},
+		"field not allowed": {
+			queryString:   "name eq 'test' or testValues eq 'test'",
+			expectedError: "invalid query: field 'testValues' is not allowed",
+		},
+		"field not allowed inside function": {
+			queryString:   "length(tolower(id)) gt 2",
+			expectedError: "invalid query: field 'id' is not allowed",
+		},
+		"field not allowed inside concat": {
+			queryString:   "concat(name,id) eq 'test'",
+			expectedError: "invalid query: field 'id' is not allowed",
+		},
+		"relation field not allowed": {
+			queryString:   "metadata/id eq '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6'",
+			expectedError: "invalid query: field 'metadata/id' is not allowed",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithAllowedFields("name", "testValue", "metadata/name"))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+		})
+	}
+}
+
+func Test_Builder_WithDeniedFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"no denied fields": {
+			queryString: "name eq 'test' and contains(metadata/name,'prd')",
+		},
+		"denied field": {
+			queryString:   "name eq 'test' or testValue eq 'secret'",
+			expectedError: "invalid query: field 'testValue' is not allowed",
+		},
+		"denied field inside function": {
+			queryString:   "length(tolower(testValue)) gt 2",
+			expectedError: "invalid query: field 'testValue' is not allowed",
+		},
+		"denied field inside concat": {
+			queryString:   "concat(name,testValue) eq 'test'",
+			expectedError: "invalid query: field 'testValue' is not allowed",
+		},
+		"denied field on relation": {
+			queryString:   "metadata/testValue eq 'secret'",
+			expectedError: "invalid query: field 'metadata/testValue' is not allowed",
+		},
+		"denied relation path": {
+			queryString:   "metadata/tag/value eq 'secret'",
+			expectedError: "invalid query: field 'metadata/tag/value' is not allowed",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithDeniedFields("testValue", "metadata/tag"))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+		})
+	}
+}
+
+func Test_Builder_WithFieldAliases(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		opts          []Option
+		queryString   string
+		expectedSql   string
+		expectedError string
+	}{
+		"field alias": {
+			queryString: "title eq 'test' and length(value) gt 2",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\" AND LENGTH(test_value) > 2",
+		},
+		"relation path alias": {
+			queryString: "tagValue eq 'prd'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = \"prd\"))",
+		},
+		"relation prefix alias": {
+			queryString: "meta/name eq 'prd'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+		},
+		"denied aliased field": {
+			opts:          []Option{WithDeniedFields("testValue")},
+			queryString:   "value eq 'test'",
+			expectedError: "invalid query: field 'testValue' is not allowed",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+			opts := append([]Option{
+				WithDatabaseType(SQLite),
+				WithFieldAliases(map[string]string{
+					"title":    "name",
+					"value":    "testValue",
+					"tagValue": "metadata/tag/value",
+					"meta":     "metadata",
+				}),
+			}, testData.opts...)
+			builder := New(opts...)
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&MockModel{})
+			})
+
+			// Assert
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_WithMaxDepth(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"within depth": {
+			queryString: "length(tolower(name)) gt 2",
+		},
+		"brackets in literal": {
+			queryString: "name eq '((((((test))))))'",
+		},
+		"tree too deep": {
+			queryString:   "length(tolower(toupper(trim(name)))) gt 2",
+			expectedError: "invalid query: maximum query complexity exceeded: >3",
+		},
+		"brackets too deep": {
+			queryString:   "not(not(not(not(not(not(not(not(not(not(name eq 'test'))))))))))",
+			expectedError: "invalid query: maximum query complexity exceeded: >3",
+		},
+		"unparsable brackets too deep": {
+			queryString:   "((((((((((name eq 'test'",
+			expectedError: "invalid query: maximum query complexity exceeded: >3",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithMaxDepth(3))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+		})
+	}
+}
+
+func Test_Builder_ComplexityLimits(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		opts          []Option
+		queryString   string
+		expectedError string
+	}{
+		"within length": {
+			opts:        []Option{WithMaxLength(14)},
+			queryString: "name eq 'test'",
+		},
+		"length exceeded": {
+			opts:          []Option{WithMaxLength(13)},
+			queryString:   "name eq 'test'",
+			expectedError: "invalid query: query length exceeds the maximum of 13 characters",
+		},
+		"within tokens": {
+			opts:        []Option{WithMaxTokens(3)},
+			queryString: "name eq 'test'",
+		},
+		"tokens exceeded": {
+			opts:          []Option{WithMaxTokens(3)},
+			queryString:   "length(name) eq 4",
+			expectedError: "invalid query: query contains 6 tokens, which exceeds the maximum of 3",
+		},
+		"depth exceeded": {
+			opts:          []Option{WithMaxDepth(1)},
+			queryString:   "length(name) eq 4",
+			expectedError: "invalid query: maximum query complexity exceeded: >1",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.opts...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrComplexityExceeded))
+		})
+	}
+}
+
+func Test_Builder_WithDisabledFunctions(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"enabled function": {
+			queryString: "tolower(name) eq 'test'",
+		},
+		"function name in literal": {
+			queryString: "name eq 'concat'",
+		},
+		"disabled binary function": {
+			queryString:   "concat(name,testValue) eq 'test'",
+			expectedError: "invalid query: function 'concat' is disabled",
+		},
+		"disabled unary function": {
+			queryString:   "name eq 'test' or year(now()) gt 2025",
+			expectedError: "invalid query: function 'now' is disabled",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithDisabledFunctions("now", "matchesPattern", "concat"))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrFunctionNotAllowed))
+		})
+	}
+}
+
+func Test_Builder_WithCollectAllErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedErrors []string
+	}{
+		"valid filter": {
+			queryString: "name eq 'test' and id eq '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6'",
+		},
+		"single problem": {
+			queryString: "unknown eq 'test'",
+			expectedErrors: []string{
+				"invalid query: unknown column name 'unknown'",
+			},
+		},
+		"all problems": {
+			queryString: "unknown eq 'test' and id eq 'not-a-uuid' or other eq 'test' and length(trim(name)) gt 2",
+			expectedErrors: []string{
+				"invalid query: function 'trim' is disabled",
+				"invalid query: unknown column name 'unknown'",
+				"invalid query: unknown column name 'other'",
+				"invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+			},
+		},
+		"syntax error": {
+			queryString: "length(name",
+			expectedErrors: []string{
+				"failed to parse query: expected closing bracket after unary function length, got \"\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(
+				WithDatabaseType(SQLite),
+				WithCollectAllErrors(),
+				WithDisabledFunctions("trim"),
+				WithQueryValidations(WithInputModelValidation(MockModel{})),
+			)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&MockModel{}))
+
+			// Assert
+			if len(testData.expectedErrors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var queryErrors QueryErrors
+			assert.True(t, errors.As(err, &queryErrors))
+
+			errorMessages := make([]string, len(queryErrors))
+			for i, queryError := range queryErrors {
+				errorMessages[i] = queryError.Error()
+			}
+			assert.Equal(t, testData.expectedErrors, errorMessages)
+		})
+	}
+}
+
+func Test_Builder_WithLogger(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedOutput string
+	}{
+		"built": {
+			queryString:    "name eq 'test'",
+			expectedOutput: "level=DEBUG msg=\"odata filter built\" query=\"name eq 'test'\"\n",
+		},
+		"rejected": {
+			queryString:    "(name eq 'test'",
+			expectedOutput: "level=DEBUG msg=\"odata filter rejected\" query=\"(name eq 'test'\" error=\"failed to parse query: expected closing bracket but got \\\"\\\"\"\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			output := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+					if attr.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return attr
+				},
+			}))
+			builder := New(WithDatabaseType(SQLite), WithLogger(logger))
+
+			// Act
+			_, _ = builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.Equal(t, testData.expectedOutput, output.String())
+		})
+	}
+}
+
+func Benchmark_Builder_Build(b *testing.B) {
+	for name, query := range benchmarkFilters {
+		b.Run(name, func(b *testing.B) {
+			db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+			builder := New(WithDatabaseType(SQLite))
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = builder.Build(query, db)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package gormodata
+
+import (
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// PackageCacheStats
+// are the statistics of the caches the package keeps for every db it built queries on (see CacheStats)
+type PackageCacheStats struct {
+	// PluginSetups is the cache of the dbs the plugins were registered on, a miss is the first build on a db
+	PluginSetups CacheCounters
+
+	// QonvertTranslations is the cache of the gormqonvert configs of the dbs,
+	// a miss is a build on a db with a gormqonvert plugin that was not registered by this package (the default config is used)
+	QonvertTranslations CacheCounters
+}
+
+// CacheCounters
+// are the lookups and entries of a cache
+type CacheCounters struct {
+	Hits   uint64
+	Misses uint64
+
+	// Entries is the number of entries in the cache
+	Entries int
+}
+
+// cacheCounters
+// counts the lookups of a cache, safe for concurrent use
+type cacheCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+var (
+	pluginSetupCounters        cacheCounters
+	qonvertTranslationCounters cacheCounters
+)
+
+// record
+// counts a lookup as a hit or a miss
+func (c *cacheCounters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// counters
+// returns the counters of the lookups with the number of entries of the cache
+func (c *cacheCounters) counters(entries int) CacheCounters {
+	return CacheCounters{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// CacheStats
+// returns the statistics of the caches the package keeps for every db it built queries on,
+// the counters are kept since the start of the process, also when the caches are cleared
+func CacheStats() PackageCacheStats {
+	setups := 0
+	pluginSetups.Range(func(any, *pluginSetup) bool {
+		setups++
+
+		return true
+	})
+
+	translations := 0
+	qonvertTranslations.Range(func(gorm.Plugin, *qonvertTranslation) bool {
+		translations++
+
+		return true
+	})
+
+	return PackageCacheStats{
+		PluginSetups:        pluginSetupCounters.counters(setups),
+		QonvertTranslations: qonvertTranslationCounters.counters(translations),
+	}
+}
+
+// ClearCaches
+// removes the entries of all dbs from the caches of the package, e.g. when many short-lived dbs were opened,
+// the next build on a db checks its plugins again, the plugins that are registered on a db stay registered
+//
+// parsed filters (see TreeCache) and prepared filters (see PreparedFilter) have their own Clear and Invalidate
+func ClearCaches() {
+	pluginSetups.Clear()
+	qonvertTranslations.Clear()
+}
+
+// InvalidateCaches
+// removes the entries of the db from the caches of the package, e.g. when the db is closed
+func InvalidateCaches(db *gorm.DB) {
+	pluginSetups.Delete(db.Callback())
+	if plugin, ok := db.Plugins[gormqonvertPluginName]; ok {
+		qonvertTranslations.Delete(plugin)
+	}
+}
@@ -0,0 +1,74 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_CacheStats(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	builder := New(WithDatabaseType(SQLite))
+	before := CacheStats()
+
+	// Act
+	for range 3 {
+		_, _ = builder.Build("metadata/name eq 'test'", db)
+	}
+	after := CacheStats()
+
+	// Assert
+	// Other tests build and clear the caches in parallel, so only the lower bounds of the changes are known
+	assert.True(t, after.PluginSetups.Misses >= before.PluginSetups.Misses+1)
+	assert.True(t, after.PluginSetups.Hits+after.PluginSetups.Misses >= before.PluginSetups.Hits+before.PluginSetups.Misses+3)
+	// The config of gormqonvert is stored when it is registered on the first build and looked up by every build
+	assert.True(t, after.QonvertTranslations.Hits+after.QonvertTranslations.Misses >= before.QonvertTranslations.Hits+before.QonvertTranslations.Misses+3)
+}
+
+func Test_InvalidateCaches(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	builder := New(WithDatabaseType(SQLite), WithQonvertConfig(gormqonvert.CharacterConfig{NotEqualToPrefix: "<>"}))
+	_, _ = builder.Build("name eq 'test'", db)
+
+	// Act
+	InvalidateCaches(db)
+
+	// Assert
+	_, setupCached := pluginSetups.Load(db.Callback())
+	assert.False(t, setupCached)
+	_, translationCached := qonvertTranslations.Load(db.Plugins[gormqonvertPluginName])
+	assert.False(t, translationCached)
+
+	result, err := builder.Build("name ne 'test'", db)
+	assert.NoError(t, err)
+	assert.NoError(t, result.Find(&[]MockModel{}).Error)
+}
+
+func Test_ClearCaches(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	_, _ = New(WithDatabaseType(SQLite)).Build("name eq 'test'", db)
+
+	// Act
+	ClearCaches()
+
+	// Assert
+	_, ok := pluginSetups.Load(db.Callback())
+	assert.False(t, ok)
+}
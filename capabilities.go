@@ -0,0 +1,205 @@
+package gormodata
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// logicalOperators
+// are the operators that combine or negate conditions
+var logicalOperators = []string{"and", "or", "not"}
+
+// Capabilities
+// are the properties, operators and functions that the filters of a Builder accept on a model (see Builder.Capabilities),
+// e.g. to generate the documentation of an endpoint or the client SDKs of a service
+type Capabilities struct {
+	Properties []FilterableProperty `json:"properties"`
+	Operators  []string             `json:"operators"`
+	Functions  []string             `json:"functions"`
+
+	// MaxLength, MaxTokens and MaxDepth are the limits of the filters (see WithMaxLength, WithMaxTokens, WithMaxDepth), 0 if there is no limit
+	MaxLength int `json:"maxLength,omitempty"`
+	MaxTokens int `json:"maxTokens,omitempty"`
+	MaxDepth  int `json:"maxDepth,omitempty"`
+
+	// MaxExpandDepth is the maximum number of relations a property can go through (see WithMaxExpandDepth), 0 if there is no limit
+	MaxExpandDepth int `json:"maxExpandDepth,omitempty"`
+
+	// MaxOrBranches is the maximum number of conditions joined by 'or' (see WithMaxOrBranches), 0 if there is no limit
+	MaxOrBranches int `json:"maxOrBranches,omitempty"`
+
+	// MaxListSize is the maximum number of values in the list of a property (see WithMaxListSize), 0 if there is no limit
+	MaxListSize int `json:"maxListSize,omitempty"`
+}
+
+// FilterableProperty
+// is a property or relation path that filters can use (e.g. "name", "address/city", "owner/name")
+type FilterableProperty struct {
+	Name string `json:"name"`
+
+	// Type is the primitive type of the property (e.g. "Edm.String", see NewCSDL)
+	Type string `json:"type"`
+}
+
+// Capabilities
+// returns the properties, operators and functions that the filters of the Builder accept on the model,
+// the properties are found by checking every property and relation path of the model (see NewCSDL) and every field alias
+// against the validations of the Builder, so the allowed and denied fields and the custom validations are taken into account
+//
+//	capabilities, err := builder.Capabilities(&Pet{})
+//
+// relations are followed until they lead back to a model of the path, the columns are named with the default gorm naming strategy
+func (b *Builder) Capabilities(model any) (*Capabilities, error) {
+	db, err := gorm.Open(nil, &gorm.Config{DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+
+	csdl, err := NewCSDL(db, "Model", model)
+	if err != nil {
+		return nil, err
+	}
+
+	types := map[string]string{}
+	csdl.collectPropertyTypes(types, csdl.EntityTypes[0], "", map[string]bool{})
+
+	// An alias of a relation also replaces the start of the paths below it (see WithFieldAliases)
+	for _, path := range slices.Collect(maps.Keys(types)) {
+		for alias, field := range b.fieldAliases {
+			if path == field || strings.HasPrefix(path, field+"/") {
+				types[alias+strings.TrimPrefix(path, field)] = types[path]
+			}
+		}
+	}
+
+	// The limits are not about the properties, so they are left out of the checks,
+	// and the checks are not filters of clients, so they are not measured
+	validator := *b
+	validator.maxLength = 0
+	validator.maxTokens = 0
+	validator.metrics = noopMetrics{}
+
+	capabilities := &Capabilities{
+		Properties:     []FilterableProperty{},
+		Operators:      slices.Concat(comparisonOperators, arithmeticOperators, []string{negationFunction}, logicalOperators),
+		Functions:      []string{},
+		MaxLength:      b.maxLength,
+		MaxTokens:      b.maxTokens,
+		MaxDepth:       b.maxDepth,
+		MaxExpandDepth: b.maxExpandDepth,
+		MaxOrBranches:  b.maxOrBranches,
+		MaxListSize:    b.maxListSize,
+	}
+	// Flags can only be checked when there are enum types to check them with (see WithEnum)
+	if len(b.enums) > 0 {
+		capabilities.Operators = append(capabilities.Operators, "has")
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(types)) {
+		err := validator.Validate(path+" eq null", model)
+		// Paths through too many relations are left out as well (see WithMaxExpandDepth)
+		if errors.Is(err, ErrFieldNotAllowed) || errors.Is(err, ErrUnknownProperty) || errors.Is(err, ErrComplexityExceeded) {
+			continue
+		}
+
+		capabilities.Properties = append(capabilities.Properties, FilterableProperty{Name: path, Type: types[path]})
+	}
+
+	for _, function := range slices.Concat(odataLexer.BinaryFunctions, odataLexer.UnaryFunctions) {
+		_, deprecated := deprecatedFunctions[function]
+		if function != "not" && function != negationFunction && !deprecated && !b.disabledFunctions[function] && (!extensionFunctions[function] || b.extensionFunctions[function]) {
+			capabilities.Functions = append(capabilities.Functions, function)
+		}
+	}
+
+	return capabilities, nil
+}
+
+// collectPropertyTypes
+// adds the paths of the properties of the entity type and of the entity types it is related to with their types,
+// visited are the entity types of the path, so relations that lead back to them are not followed
+func (c *CSDL) collectPropertyTypes(types map[string]string, entityType CSDLEntityType, prefix string, visited map[string]bool) {
+	visited[entityType.Name] = true
+	defer delete(visited, entityType.Name)
+
+	c.collectComplexPropertyTypes(types, entityType.Properties, prefix)
+
+	for _, navigationProperty := range entityType.NavigationProperties {
+		name := strings.TrimPrefix(navigationProperty.Type, c.Namespace+".")
+		if visited[name] {
+			continue
+		}
+
+		for _, related := range c.EntityTypes {
+			if related.Name == name {
+				c.collectPropertyTypes(types, related, prefix+navigationProperty.Name+"/", visited)
+			}
+		}
+	}
+}
+
+// collectComplexPropertyTypes
+// adds the paths of the properties with their types, the properties of complex types are added with their own paths
+func (c *CSDL) collectComplexPropertyTypes(types map[string]string, properties []CSDLProperty, prefix string) {
+	for _, property := range properties {
+		if !strings.HasPrefix(property.Type, c.Namespace+".") {
+			types[prefix+property.Name] = property.Type
+
+			continue
+		}
+
+		for _, complexType := range c.ComplexTypes {
+			if c.Namespace+"."+complexType.Name == property.Type {
+				c.collectComplexPropertyTypes(types, complexType.Properties, prefix+property.Name+"/")
+			}
+		}
+	}
+}
+
+// OpenAPIParameter
+// is the OpenAPI description of the $filter query parameter (see Capabilities.OpenAPIParameter)
+type OpenAPIParameter struct {
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description"`
+	Required    bool                   `json:"required"`
+	Schema      OpenAPIParameterSchema `json:"schema"`
+
+	// Capabilities is the machine-readable description of the filters, as the x-odata-filter extension of the parameter
+	Capabilities *Capabilities `json:"x-odata-filter"`
+}
+
+// OpenAPIParameterSchema
+// is the schema of the $filter query parameter
+type OpenAPIParameterSchema struct {
+	Type      string `json:"type"`
+	MaxLength int    `json:"maxLength,omitempty"`
+}
+
+// OpenAPIParameter
+// returns the OpenAPI description of the $filter query parameter, to add to the parameters of the operations of the model
+//
+//	parameter := capabilities.OpenAPIParameter()
+//	document, err := json.Marshal(parameter)
+func (c *Capabilities) OpenAPIParameter() *OpenAPIParameter {
+	properties := make([]string, len(c.Properties))
+	for i, property := range c.Properties {
+		properties[i] = fmt.Sprintf("%s (%s)", property.Name, property.Type)
+	}
+
+	description := fmt.Sprintf("OData filter expression.\n\nProperties: %s.\n\nOperators: %s.\n\nFunctions: %s.",
+		strings.Join(properties, ", "), strings.Join(c.Operators, ", "), strings.Join(c.Functions, ", "))
+
+	return &OpenAPIParameter{
+		Name:         "$filter",
+		In:           "query",
+		Description:  description,
+		Schema:       OpenAPIParameterSchema{Type: "string", MaxLength: c.MaxLength},
+		Capabilities: c,
+	}
+}
@@ -0,0 +1,155 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Builder_Capabilities(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		model              any
+		options            []Option
+		expectedProperties []FilterableProperty
+	}{
+		"relations are followed": {
+			model: &MockModel{},
+			expectedProperties: []FilterableProperty{
+				{Name: "id", Type: "Edm.Guid"},
+				{Name: "metadata/id", Type: "Edm.Guid"},
+				{Name: "metadata/name", Type: "Edm.String"},
+				{Name: "metadata/tag/id", Type: "Edm.Guid"},
+				{Name: "metadata/tag/value", Type: "Edm.String"},
+				{Name: "metadata/tagId", Type: "Edm.Guid"},
+				{Name: "metadataId", Type: "Edm.Guid"},
+				{Name: "name", Type: "Edm.String"},
+				{Name: "testValue", Type: "Edm.String"},
+				{Name: "testValues", Type: "Edm.String"},
+			},
+		},
+		"relations back to the path are not followed": {
+			model: &Category{},
+			expectedProperties: []FilterableProperty{
+				{Name: "id", Type: "Edm.Guid"},
+				{Name: "name", Type: "Edm.String"},
+				{Name: "parentId", Type: "Edm.Guid"},
+			},
+		},
+		"allowed fields": {
+			model:   &Company{},
+			options: []Option{WithAllowedFields("name", "address/city")},
+			expectedProperties: []FilterableProperty{
+				{Name: "address/city", Type: "Edm.String"},
+				{Name: "name", Type: "Edm.String"},
+			},
+		},
+		"denied fields and aliases": {
+			model:   &MockModel{},
+			options: []Option{WithDeniedFields("id", "metadata/tag", "testValues"), WithFieldAliases(map[string]string{"meta": "metadata", "title": "name"})},
+			expectedProperties: []FilterableProperty{
+				{Name: "meta/name", Type: "Edm.String"},
+				{Name: "meta/tagId", Type: "Edm.Guid"},
+				{Name: "metadata/name", Type: "Edm.String"},
+				{Name: "metadata/tagId", Type: "Edm.Guid"},
+				{Name: "metadataId", Type: "Edm.Guid"},
+				{Name: "name", Type: "Edm.String"},
+				{Name: "testValue", Type: "Edm.String"},
+				{Name: "title", Type: "Edm.String"},
+			},
+		},
+		"max expand depth": {
+			model:   &MockModel{},
+			options: []Option{WithMaxExpandDepth(1)},
+			expectedProperties: []FilterableProperty{
+				{Name: "id", Type: "Edm.Guid"},
+				{Name: "metadata/id", Type: "Edm.Guid"},
+				{Name: "metadata/name", Type: "Edm.String"},
+				{Name: "metadata/tagId", Type: "Edm.Guid"},
+				{Name: "metadataId", Type: "Edm.Guid"},
+				{Name: "name", Type: "Edm.String"},
+				{Name: "testValue", Type: "Edm.String"},
+				{Name: "testValues", Type: "Edm.String"},
+			},
+		},
+		"limits do not hide properties": {
+			model:   &Owner{},
+			options: []Option{WithMaxLength(4), WithMaxTokens(1)},
+			expectedProperties: []FilterableProperty{
+				{Name: "name", Type: "Edm.String"},
+				{Name: "ownerKey", Type: "Edm.String"},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := New(testData.options...).Capabilities(testData.model)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedProperties, result.Properties)
+			assert.Equal(t, []string{"eq", "ne", "lt", "le", "gt", "ge", "add", "sub", "mul", "div", "divby", "mod", "-", "and", "or", "not"}, result.Operators)
+		})
+	}
+}
+
+func Test_Builder_CapabilitiesFunctionsAndLimits(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	builder := New(WithDisabledFunctions("now", "Concat"), WithMaxLength(200), WithMaxTokens(50), WithMaxDepth(5), WithMaxExpandDepth(2), WithMaxOrBranches(8), WithMaxListSize(20))
+
+	// Act
+	result, err := builder.Capabilities(&Owner{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"contains", "endswith", "startswith", "nullif", "greatest", "least", "length", "indexof", "tolower", "toupper", "trim", "year", "month", "day",
+		"hour", "minute", "second", "fractionalsecond", "date", "time", "round", "floor", "ceiling", "abs", "sign",
+	}, result.Functions)
+	assert.Equal(t, 200, result.MaxLength)
+	assert.Equal(t, 50, result.MaxTokens)
+	assert.Equal(t, 5, result.MaxDepth)
+	assert.Equal(t, 2, result.MaxExpandDepth)
+	assert.Equal(t, 8, result.MaxOrBranches)
+	assert.Equal(t, 20, result.MaxListSize)
+}
+
+func Test_Capabilities_OpenAPIParameter(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	capabilities := &Capabilities{
+		Properties: []FilterableProperty{{Name: "name", Type: "Edm.String"}, {Name: "owner/name", Type: "Edm.String"}},
+		Operators:  []string{"eq", "and"},
+		Functions:  []string{"contains"},
+		MaxLength:  100,
+	}
+
+	// Act
+	result := capabilities.OpenAPIParameter()
+
+	// Assert
+	document, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "$filter",
+		"in": "query",
+		"description": "OData filter expression.\n\nProperties: name (Edm.String), owner/name (Edm.String).\n\nOperators: eq, and.\n\nFunctions: contains.",
+		"required": false,
+		"schema": {"type": "string", "maxLength": 100},
+		"x-odata-filter": {
+			"properties": [{"name": "name", "type": "Edm.String"}, {"name": "owner/name", "type": "Edm.String"}],
+			"operators": ["eq", "and"],
+			"functions": ["contains"],
+			"maxLength": 100
+		}
+	}`, string(document))
+}
@@ -0,0 +1,63 @@
+package gormodata
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// odataTag
+// is the struct tag with the filter settings of a field, separated by commas (e.g. `odata:"caseinsensitive"`)
+const odataTag = "odata"
+
+// caseInsensitiveSetting
+// is the setting of the odata tag that makes the equality comparisons of a field ignore the case
+const caseInsensitiveSetting = "caseinsensitive"
+
+// WithCaseInsensitiveFields
+// makes eq and ne with a string literal ignore the case on the given fields (e.g. "name", "email"),
+// the column and the literal are compared in lowercase:
+//
+//	name eq 'Test'  ->  LOWER(name) = 'test'
+//
+// fields can also be marked in the model with the odata tag, which is applied once the query is executed on the model:
+//
+//	type User struct {
+//		Email string `odata:"caseinsensitive"`
+//	}
+//
+// other fields keep the comparison of the database, only plain columns of the model ignore the case (not relation paths)
+func WithCaseInsensitiveFields(fields ...string) Option {
+	return func(b *Builder) {
+		b.caseInsensitiveFields = append(b.caseInsensitiveFields, fields...)
+	}
+}
+
+// isCaseInsensitive
+// returns whether the property is one of the case-insensitive fields of the builder
+func (t *queryTranslation) isCaseInsensitive(property string) bool {
+	for _, field := range t.caseInsensitiveFields {
+		if propertyPath(t.namer, field) == propertyPath(t.namer, property) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCaseInsensitiveField
+// returns whether the field is a text field marked as case-insensitive with the odata tag
+func isCaseInsensitiveField(field *schema.Field) bool {
+	return field.DataType == schema.String && !isUUIDField(field) && HasODataTag(field, caseInsensitiveSetting)
+}
+
+// fold
+// returns the comparison of the lowercase column with the lowercase literal
+func (c columnComparison) fold() columnComparison {
+	c.SQL = c.FoldedSQL
+	if value, ok := c.Value.(string); ok {
+		c.Value = strings.ToLower(value)
+	}
+
+	return c
+}
@@ -0,0 +1,144 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Member struct {
+	ID    int
+	Name  string
+	Email string `odata:"caseinsensitive"`
+}
+
+func Test_Builder_CaseInsensitiveFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		options      []Option
+		expectedSqls map[DbType]string
+	}{
+		"option": {
+			queryString: "name eq 'Test'",
+			options:     []Option{WithCaseInsensitiveFields("name")},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE LOWER(name) = \"test\"",
+				MySQL:      "SELECT * FROM `members` WHERE LOWER(name) = \"test\"",
+				SQLite:     "SELECT * FROM `members` WHERE LOWER(name) = \"test\"",
+				SQLServer:  "SELECT * FROM `members` WHERE LOWER(name) = \"test\"",
+			},
+		},
+		"negated option": {
+			queryString: "not(name eq 'Test')",
+			options:     []Option{WithCaseInsensitiveFields("Name")},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE LOWER(name) != \"test\"",
+				MySQL:      "SELECT * FROM `members` WHERE LOWER(name) != \"test\"",
+				SQLite:     "SELECT * FROM `members` WHERE LOWER(name) != \"test\"",
+				SQLServer:  "SELECT * FROM `members` WHERE LOWER(name) != \"test\"",
+			},
+		},
+		"struct tag": {
+			queryString: "email eq 'Info@Example.be' and name eq 'Test'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE LOWER(email) = \"info@example.be\" AND name = \"Test\"",
+				MySQL:      "SELECT * FROM `members` WHERE LOWER(email) = \"info@example.be\" AND name = \"Test\"",
+				SQLite:     "SELECT * FROM `members` WHERE LOWER(email) = \"info@example.be\" AND name = \"Test\"",
+				SQLServer:  "SELECT * FROM `members` WHERE LOWER(email) = \"info@example.be\" AND name = \"Test\"",
+			},
+		},
+		"ordering keeps the case": {
+			queryString: "email gt 'M' and name ne 'Test'",
+			options:     []Option{WithCaseInsensitiveFields("email")},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE email > \"M\" AND name != \"Test\"",
+				MySQL:      "SELECT * FROM `members` WHERE email > \"M\" AND name != \"Test\"",
+				SQLite:     "SELECT * FROM `members` WHERE email > \"M\" AND name != \"Test\"",
+				SQLServer:  "SELECT * FROM `members` WHERE email > \"M\" AND name != \"Test\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(append([]Option{WithDatabaseType(dbType)}, testData.options...)...)
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Member{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_CaseInsensitiveFieldsResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		options       []Option
+		expectedNames []string
+	}{
+		"case-sensitive field": {
+			queryString:   "name eq 'ALICE'",
+			expectedNames: []string{},
+		},
+		"option": {
+			queryString:   "name eq 'ALICE'",
+			options:       []Option{WithCaseInsensitiveFields("name")},
+			expectedNames: []string{"Alice"},
+		},
+		"struct tag": {
+			queryString:   "email ne 'BOB@example.be'",
+			expectedNames: []string{"Alice"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Member{})
+			db.Create(&Member{ID: 1, Name: "Alice", Email: "alice@example.be"})
+			db.Create(&Member{ID: 2, Name: "Bob", Email: "Bob@Example.be"})
+
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Member
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, member := range result {
+				names = append(names, member.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
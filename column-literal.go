@@ -0,0 +1,275 @@
+package gormodata
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	uuidType = reflect.TypeFor[uuid.UUID]()
+	timeType = reflect.TypeFor[time.Time]()
+
+	// timeLiteralLayouts
+	// are the accepted layouts of datetime literals, next to epoch milliseconds
+	timeLiteralLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999",
+		time.DateOnly,
+	}
+)
+
+// columnComparison
+// is a comparison of a column with a literal from the query (e.g. "id = ?")
+//
+// the type of the column is only known once the query is executed on a model,
+// the nestedFilterPlugin then converts the literal to the type of the column (see convertLiteral)
+type columnComparison struct {
+	Column string
+	SQL    string
+	Value  any
+
+	// FoldedSQL compares the lowercase column instead (e.g. "LOWER(name) = ?"), it is used with the lowercase literal
+	// when the field is case-insensitive (see WithCaseInsensitiveFields), empty if the comparison cannot ignore the case
+	FoldedSQL string
+}
+
+func (c columnComparison) Build(builder clause.Builder) {
+	clause.Expr{SQL: c.SQL, Vars: []any{c.Value}}.Build(builder)
+}
+
+// columnPattern
+// is a like pattern on a column (e.g. "%s LIKE ?" with the column for %s),
+// columns that are not text (e.g. uuid or integer columns) are cast to text once the type of the column is known,
+// since postgres cannot match a pattern on them
+type columnPattern struct {
+	Column       string
+	SQL          string
+	Value        any
+	DatabaseType DbType
+}
+
+func (c columnPattern) Build(builder clause.Builder) {
+	clause.Expr{SQL: fmt.Sprintf(c.SQL, c.Column), Vars: []any{c.Value}}.Build(builder)
+}
+
+// textCastTranslation
+// casts a column to the text type of every database type
+var textCastTranslation = map[DbType]string{
+	PostgreSQL: "CAST(%s AS text)",
+	MySQL:      "CAST(%s AS CHAR)",
+	SQLite:     "CAST(%s AS TEXT)",
+	SQLServer:  "CAST(%s AS nvarchar(max))",
+}
+
+// convertColumnLiterals
+// converts the literals of all column comparisons in the expressions to the type of their column,
+// and casts the columns of patterns that are not text to text
+func convertColumnLiterals(modelSchema *schema.Schema, exprs []clause.Expression) error {
+	if modelSchema == nil {
+		return nil
+	}
+
+	for index, expr := range exprs {
+		switch expr := expr.(type) {
+		case clause.AndConditions:
+			if err := convertColumnLiterals(modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case clause.OrConditions:
+			if err := convertColumnLiterals(modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case clause.NotConditions:
+			if err := convertColumnLiterals(modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case columnComparison:
+			field := modelSchema.LookUpField(expr.Column)
+			if field == nil {
+				continue
+			}
+
+			value, err := convertLiteral(field, expr.Value)
+			if err != nil {
+				return err
+			}
+			expr.Value = value
+			if expr.FoldedSQL != "" && isCaseInsensitiveField(field) {
+				expr = expr.fold()
+			}
+			exprs[index] = expr
+		case columnPattern:
+			field := modelSchema.LookUpField(expr.Column)
+			// uuid columns have the string data type in gorm, since uuid.UUID is a string value
+			if field == nil || (field.DataType == schema.String && !isUUIDField(field)) {
+				continue
+			}
+
+			expr.Column = fmt.Sprintf(textCastTranslation[expr.DatabaseType], expr.Column)
+			exprs[index] = expr
+		}
+	}
+
+	return nil
+}
+
+// convertLiteral
+// converts a literal from the query to the type of the field, so the database driver can format it
+//
+//	uuid columns: the literal must be a valid uuid and is bound as uuid.UUID
+//	time columns: the literal must be an RFC3339 timestamp, a date (2006-01-02) or epoch milliseconds and is bound as time.Time
+//
+// the values of a list (e.g. of name in ('a','b')) are converted one by one
+func convertLiteral(field *schema.Field, value any) (any, error) {
+	if values, ok := value.([]any); ok {
+		converted := make([]any, len(values))
+		for index, element := range values {
+			var err error
+			if converted[index], err = convertLiteral(field, element); err != nil {
+				return nil, err
+			}
+		}
+
+		return converted, nil
+	}
+
+	// Expressions and times of typed literals (e.g. CAST('13:45:00' AS time)) already have the type of their literal
+	switch value.(type) {
+	case clause.Expression, time.Time:
+		return value, nil
+	}
+
+	switch {
+	case isUUIDField(field):
+		literal := fmt.Sprint(value)
+		parsed, err := uuid.Parse(literal)
+		if err != nil {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("invalid uuid literal '%s' for column '%s'", literal, field.DBName),
+			}
+		}
+
+		return parsed, nil
+	case isTimeField(field):
+		literal := fmt.Sprint(value)
+		parsed, err := parseTimeLiteral(literal)
+		if err != nil {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("invalid datetime literal '%s' for column '%s'", literal, field.DBName),
+			}
+		}
+
+		return parsed, nil
+	}
+
+	return value, nil
+}
+
+// parseTimeLiteral
+// parses a datetime literal, literals without a time zone are in UTC
+//
+// the result is always in UTC, so databases that store timestamps as text (SQLite) compare them in the same time zone
+func parseTimeLiteral(literal string) (time.Time, error) {
+	for _, layout := range timeLiteralLayouts {
+		if parsed, err := time.Parse(layout, literal); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+
+	epochMillis, err := strconv.ParseInt(literal, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMilli(epochMillis).UTC(), nil
+}
+
+// convertFilterLiterals
+// converts the literals of a nested filter to the type of the columns of the related schema,
+// nested maps are left as is since they are converted once their own subquery is resolved
+func convertFilterLiterals(relatedSchema *schema.Schema, qonvert *qonvertTranslation, filter map[string]any) (map[string]any, error) {
+	converted := make(map[string]any, len(filter))
+	for key, value := range filter {
+		converted[key] = value
+		if _, ok := value.(map[string]any); ok {
+			continue
+		}
+
+		field := relatedSchema.LookUpField(key)
+		if field == nil {
+			continue
+		}
+
+		value, err := convertPrefixedLiteral(field, qonvert, key, value)
+		if err != nil {
+			return nil, err
+		}
+		converted[key] = value
+	}
+
+	return converted, nil
+}
+
+// convertPrefixedLiteral
+// converts a literal of a nested filter that can start with a gormqonvert comparison prefix (e.g. "!=<uuid>")
+//
+// gormqonvert only converts string values, so prefixed literals become a columnComparison with the converted value,
+// literals with a like prefix (contains, startswith, endswith) can be partial values and are left as is
+func convertPrefixedLiteral(field *schema.Field, qonvert *qonvertTranslation, column string, value any) (any, error) {
+	literal, ok := value.(string)
+	if !ok || (!isUUIDField(field) && !isTimeField(field)) {
+		return value, nil
+	}
+
+	if likePrefix := qonvert.prefixes["contains"]; likePrefix != "" && strings.HasPrefix(literal, likePrefix) {
+		return value, nil
+	}
+
+	// Longest prefixes first, so "<=" is not mistaken for "<"
+	operators := []string{}
+	for _, operator := range []string{"ne", "lt", "le", "gt", "ge"} {
+		if qonvert.prefixes[operator] != "" {
+			operators = append(operators, operator)
+		}
+	}
+	slices.SortStableFunc(operators, func(a, b string) int {
+		return len(qonvert.prefixes[b]) - len(qonvert.prefixes[a])
+	})
+
+	for _, operator := range operators {
+		prefix := qonvert.prefixes[operator]
+		if !strings.HasPrefix(literal, prefix) {
+			continue
+		}
+
+		converted, err := convertLiteral(field, strings.TrimPrefix(literal, prefix))
+		if err != nil {
+			return nil, err
+		}
+
+		return columnComparison{
+			Column: column,
+			SQL:    fmt.Sprintf("%s %s ?", column, operatorTranslation[operator]),
+			Value:  converted,
+		}, nil
+	}
+
+	return convertLiteral(field, literal)
+}
+
+func isUUIDField(field *schema.Field) bool {
+	return field.IndirectFieldType == uuidType || strings.EqualFold(string(field.DataType), "uuid")
+}
+
+func isTimeField(field *schema.Field) bool {
+	return field.IndirectFieldType == timeType
+}
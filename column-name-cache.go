@@ -0,0 +1,68 @@
+package gormodata
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"github.com/survivorbat/go-tsyncmap"
+	"gorm.io/gorm/schema"
+)
+
+// maxColumnNames
+// is the maximum number of column names a builder remembers,
+// so filters with many different (e.g. unknown) properties cannot grow the memory of a builder without bound
+const maxColumnNames = 4096
+
+// columnNameKey
+// identifies a property of a model on a db
+type columnNameKey struct {
+	// callbacks are the callbacks of the db (db.Callback()), which identify the db and its naming strategy (see preparedFilterKey)
+	callbacks any
+
+	model    reflect.Type
+	property string
+}
+
+// columnNameCache
+// remembers the column names of the properties of the filters of a builder,
+// so the naming strategy does not translate the same properties on every build, it is safe for concurrent use
+type columnNameCache struct {
+	names tsyncmap.Map[columnNameKey, string]
+	size  atomic.Int64
+
+	// built is set by the first build of the builder
+	built atomic.Bool
+}
+
+// forBuild
+// returns the cache for a build of the builder, or nil for its first build,
+// so builders that build only once (e.g. BuildQuery) do not fill a cache that is never used again
+func (c *columnNameCache) forBuild() *columnNameCache {
+	if c == nil {
+		return nil
+	}
+
+	if c.built.Load() {
+		return c
+	}
+	c.built.Store(true)
+
+	return nil
+}
+
+// columnName
+// returns the column name of the property of the key, it is translated with the namer the first time
+func (c *columnNameCache) columnName(key columnNameKey, namer schema.Namer) string {
+	if name, ok := c.names.Load(key); ok {
+		return name
+	}
+
+	name := namer.ColumnName("", key.property)
+	if c.size.Load() < maxColumnNames {
+		if _, loaded := c.names.LoadOrStore(key, name); !loaded {
+			c.size.Add(1)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,100 @@
+package gormodata
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// countingNamer
+// counts how often the property testValue is translated into a column name
+type countingNamer struct {
+	schema.NamingStrategy
+
+	calls *atomic.Int32
+}
+
+func (c countingNamer) ColumnName(table string, column string) string {
+	if column == "testValue" {
+		c.calls.Add(1)
+	}
+
+	return c.NamingStrategy.ColumnName(table, column)
+}
+
+func Test_Builder_ColumnNamesRemembered(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	var calls atomic.Int32
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	db.NamingStrategy = countingNamer{calls: &calls}
+	_ = db.AutoMigrate(&MockModel{})
+	builder := New(WithDatabaseType(SQLite))
+
+	// Act
+	for range 5 {
+		_, err := builder.Build("testValue eq 'test' or length(testValue) gt 3", db.Model(&MockModel{}))
+		assert.NoError(t, err)
+	}
+
+	// Assert
+	// The first build does not remember the column names (2 calls), the second build remembers them for the later builds (1 call)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func Test_Builder_ColumnNamesPerDB(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	upperDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_upper"))
+	upperDB.NamingStrategy = schema.NamingStrategy{NameReplacer: CustomReplacer{}, NoLowerCase: true}
+	_ = upperDB.AutoMigrate(&MockModel{})
+	builder := New(WithDatabaseType(SQLite))
+
+	// Act
+	sqls := map[string]string{}
+	for range 3 {
+		for name, buildDB := range map[string]*gorm.DB{"default": db, "upper": upperDB} {
+			sqls[name] = buildDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				tx, _ = builder.Build("testValue eq 'test'", tx)
+
+				return tx.Find(&[]MockModel{})
+			})
+		}
+	}
+
+	// Assert
+	assert.Equal(t, map[string]string{
+		"default": "SELECT * FROM `mock_models` WHERE test_value = \"test\"",
+		"upper":   "SELECT * FROM `MOCK_MODELS` WHERE TEST_VALUE = \"test\"",
+	}, sqls)
+}
+
+func Test_ColumnNameCache_Bounded(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cache := &columnNameCache{}
+	namer := schema.NamingStrategy{}
+
+	// Act
+	names := []string{}
+	for index := range maxColumnNames + 10 {
+		names = append(names, cache.columnName(columnNameKey{property: fmt.Sprintf("property%dName", index)}, namer))
+	}
+
+	// Assert
+	assert.Equal(t, int64(maxColumnNames), cache.size.Load())
+	assert.Equal(t, "property0_name", names[0])
+	assert.Equal(t, fmt.Sprintf("property%d_name", maxColumnNames+9), names[len(names)-1])
+}
@@ -0,0 +1,121 @@
+package gormodata
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// CompileToSQL
+// translates the filter on the model into an SQL condition with its arguments for the database type,
+// without a database connection, so the translation can be used with database/sql, sqlx or query logging
+//
+//	condition, args, err := gormodata.CompileToSQL("name eq 'test'", gormodata.PostgreSQL, MyModel{})
+//	// condition: name = $1, args: []any{"test"}
+//	rows, err := sqlDB.Query("SELECT * FROM my_models WHERE "+condition, args...)
+//
+// the placeholders are the ones of the database driver ($1 for PostgreSQL, @p1 for SQL Server and ? otherwise)
+func CompileToSQL(query string, dbType DbType, model any) (string, []any, error) {
+	db, err := gorm.Open(compileDialector{databaseType: dbType}, &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	dbQuery, err := New(WithDatabaseType(dbType)).Build(query, db.Model(model))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The dry run resolves the nested filters and typed literals, the condition is built again without the rest of the SELECT
+	result := dbQuery.Find(&[]map[string]any{})
+	if result.Error != nil {
+		return "", nil, result.Error
+	}
+
+	statement := &gorm.Statement{
+		DB:     result,
+		Table:  result.Statement.Table,
+		Schema: result.Statement.Schema,
+	}
+	result.Statement.Clauses["WHERE"].Expression.Build(statement)
+
+	return statement.SQL.String(), statement.Vars, nil
+}
+
+// compileDialector
+// is the gorm dialector of CompileToSQL, it only formats SQL like the dialector of the database type
+type compileDialector struct {
+	databaseType DbType
+}
+
+func (c compileDialector) Name() string {
+	switch c.databaseType {
+	case PostgreSQL:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLServer:
+		return "sqlserver"
+	default:
+		return "sqlite"
+	}
+}
+
+func (c compileDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+
+	return nil
+}
+
+func (c compileDialector) Migrator(*gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (c compileDialector) DataTypeOf(*schema.Field) string {
+	return ""
+}
+
+func (c compileDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (c compileDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, _ any) {
+	switch c.databaseType {
+	case PostgreSQL:
+		_, _ = writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+	case SQLServer:
+		_, _ = writer.WriteString("@p" + strconv.Itoa(len(stmt.Vars)))
+	default:
+		_ = writer.WriteByte('?')
+	}
+}
+
+func (c compileDialector) QuoteTo(writer clause.Writer, str string) {
+	open, close := "`", "`"
+	switch c.databaseType {
+	case PostgreSQL:
+		open, close = `"`, `"`
+	case SQLServer:
+		open, close = "[", "]"
+	}
+
+	for i, part := range strings.Split(str, ".") {
+		if i > 0 {
+			_ = writer.WriteByte('.')
+		}
+		_, _ = writer.WriteString(open + part + close)
+	}
+}
+
+func (c compileDialector) Explain(sql string, vars ...any) string {
+	return logger.ExplainSQL(sql, nil, "'", vars...)
+}
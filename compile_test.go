@@ -0,0 +1,89 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_CompileToSQL(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	queryString := "name eq 'test' and (metadata/tag/value eq 'x' or length(testValue) gt 3) and id ne '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6'"
+	expectedArgs := []any{"test", "x", 3, uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6")}
+
+	tests := map[string]struct {
+		databaseType DbType
+		expectedSql  string
+	}{
+		"postgresql": {
+			databaseType: PostgreSQL,
+			expectedSql:  `(name = $1 AND (metadata_id IN (SELECT "id" FROM "metadata" WHERE tag_id IN (SELECT "id" FROM "tags" WHERE "tags"."value" = $2)) OR LENGTH(test_value) > $3)) AND id != $4`,
+		},
+		"mysql": {
+			databaseType: MySQL,
+			expectedSql:  "(name = ? AND (metadata_id IN (SELECT `id` FROM `metadata` WHERE tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = ?)) OR LENGTH(test_value) > ?)) AND id != ?",
+		},
+		"sqlite": {
+			databaseType: SQLite,
+			expectedSql:  "(name = ? AND (metadata_id IN (SELECT `id` FROM `metadata` WHERE tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = ?)) OR LENGTH(test_value) > ?)) AND id != ?",
+		},
+		"sqlserver": {
+			databaseType: SQLServer,
+			expectedSql:  "(name = @p1 AND (metadata_id IN (SELECT [id] FROM [metadata] WHERE tag_id IN (SELECT [id] FROM [tags] WHERE [tags].[value] = @p2)) OR LENGTH(test_value) > @p3)) AND id != @p4",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			sqlCondition, args, err := CompileToSQL(queryString, testData.databaseType, MockModel{})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlCondition)
+			assert.Equal(t, expectedArgs, args)
+		})
+	}
+}
+
+func Test_CompileToSQL_Error(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+	}{
+		"invalid filter": {
+			queryString: "length(name)",
+			expectedErr: "invalid query: unary function 'length' cannot be the root of a filter, compare it with a value instead",
+		},
+		"invalid literal": {
+			queryString: "id eq 'not-a-uuid'",
+			expectedErr: "invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+		},
+		"unknown relation": {
+			queryString: "owner/name eq 'test'",
+			expectedErr: "invalid query: unknown relation 'owner' on 'mock_models'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			sqlCondition, args, err := CompileToSQL(testData.queryString, SQLite, MockModel{})
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.Empty(t, sqlCondition)
+			assert.Nil(t, args)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CountWithFilter
+// counts the rows of the model that match the conditions of a built query, e.g. the total count of a list endpoint,
+// the query is the result of Build (or BuildQuery, Scope, PreparedFilter.Apply) and its conditions are reused,
+// so the filter is not parsed and translated a second time
+//
+//	query, err := builder.Build(filter, db.Model(&Pet{}))
+//	err = query.Order("name").Limit(top).Offset(skip).Find(&pets).Error
+//	count, err := gormodata.CountWithFilter(query, db, &Pet{})
+//
+// only the conditions of the query are taken over, its limit, offset, order and selected columns are not,
+// other conditions that were added to the query (e.g. a tenant) are counted with the filter
+func CountWithFilter(query *gorm.DB, db *gorm.DB, model any) (int64, error) {
+	// The scopes of the query (e.g. Scope) only add their conditions when it runs, so a dry run resolves them first,
+	// on a copy of the conditions since the callbacks replace the nested filters in place
+	resolved := query.Session(&gorm.Session{DryRun: true}).Model(model)
+	if conditions := whereConditions(resolved); len(conditions) > 0 {
+		resolved.Statement.Clauses["WHERE"] = clause.Clause{Name: "WHERE", Expression: clause.Where{Exprs: cloneConditions(conditions)}}
+	}
+	if err := resolved.Find(&[]map[string]any{}).Error; err != nil {
+		return 0, err
+	}
+
+	countQuery := db.Session(&gorm.Session{NewDB: true, Context: query.Statement.Context}).Model(model)
+	if conditions := whereConditions(resolved); len(conditions) > 0 {
+		countQuery = countQuery.Clauses(clause.Where{Exprs: conditions})
+	}
+
+	var count int64
+	if err := countQuery.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,121 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_CountWithFilter(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		scope         func(db *gorm.DB) *gorm.DB
+		expectedCount int64
+		expectedNames []string
+	}{
+		"filter": {
+			queryString:   "testValue eq 'x' or name eq 'd'",
+			expectedCount: 3,
+			expectedNames: []string{"a", "c"},
+		},
+		"nested filter": {
+			queryString:   "metadata/name eq 'prd'",
+			expectedCount: 2,
+			expectedNames: []string{"a", "d"},
+		},
+		"with a scope": {
+			queryString: "testValue eq 'x'",
+			scope: func(db *gorm.DB) *gorm.DB {
+				return db.Where("name != ?", "a").Limit(1)
+			},
+			expectedCount: 1,
+			expectedNames: []string{"c"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+			prd := &Metadata{ID: uuid.New(), Name: "prd"}
+			_ = db.Create(prd).Error
+			_ = db.Create(&[]MockModel{
+				{ID: uuid.New(), Name: "a", TestValue: "x", MetadataID: &prd.ID},
+				{ID: uuid.New(), Name: "b", TestValue: "y"},
+				{ID: uuid.New(), Name: "c", TestValue: "x"},
+				{ID: uuid.New(), Name: "d", TestValue: "y", MetadataID: &prd.ID},
+			}).Error
+
+			listQuery := db.Model(&MockModel{})
+			if testData.scope != nil {
+				listQuery = listQuery.Scopes(testData.scope)
+			}
+			query, err := New(WithDatabaseType(SQLite)).Build(testData.queryString, listQuery)
+			assert.NoError(t, err)
+
+			// Act
+			count, err := CountWithFilter(query, db, &MockModel{})
+
+			var results []MockModel
+			listErr := query.Order("name").Limit(2).Find(&results).Error
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, listErr)
+			assert.Equal(t, testData.expectedCount, count)
+
+			names := make([]string, len(results))
+			for i, result := range results {
+				names[i] = result.Name
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_CountWithFilter_IgnoresPaging(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	_ = db.Create(&[]MockModel{{ID: uuid.New(), Name: "a"}, {ID: uuid.New(), Name: "b"}, {ID: uuid.New(), Name: "c"}}).Error
+
+	query, _ := New(WithDatabaseType(SQLite)).Build("name ne 'b'", db.Model(&MockModel{}))
+
+	// Act
+	count, err := CountWithFilter(query.Order("name desc").Limit(1).Offset(1).Select("name"), db, &MockModel{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func Test_CountWithFilter_QueryError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+	query := db.Model(&MockModel{}).Scopes(Scope("name eq", SQLite))
+
+	// Act
+	count, err := CountWithFilter(query, db, &MockModel{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,523 @@
+package gormodata
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+
+	"github.com/stoewer/go-strcase"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// csdlContainer
+// is the name of the entity container of the CSDL documents
+const csdlContainer = "Container"
+
+// CSDL
+// is the entity data model of gorm models (see NewCSDL), served as the $metadata document of an OData service,
+// so OData clients can discover the properties and relations the filters can use
+type CSDL struct {
+	Namespace string
+
+	// EntityTypes are the types of the models and of the models they are related to
+	EntityTypes []CSDLEntityType
+
+	// ComplexTypes are the types of the embedded structs of the models (e.g. `gorm:"embedded"`)
+	ComplexTypes []CSDLComplexType
+
+	// EntitySets are the tables of the models that were passed to NewCSDL
+	EntitySets []CSDLEntitySet
+}
+
+// CSDLEntityType
+// is the type of a model, with the property names that are used in filters (e.g. "birthYear", "owner")
+type CSDLEntityType struct {
+	Name string
+
+	// Key are the names of the properties of the primary key, empty if the model has no primary key
+	Key []string
+
+	Properties []CSDLProperty
+
+	// NavigationProperties are the relations of the model
+	NavigationProperties []CSDLNavigationProperty
+}
+
+// CSDLComplexType
+// is the type of an embedded struct
+type CSDLComplexType struct {
+	Name       string
+	Properties []CSDLProperty
+}
+
+// CSDLProperty
+// is a column of a model or an embedded struct
+type CSDLProperty struct {
+	Name string
+
+	// Type is a primitive type (e.g. "Edm.String") or a complex type of the namespace (e.g. "Pets.Address")
+	Type string
+
+	Nullable bool
+}
+
+// CSDLNavigationProperty
+// is a relation of a model
+type CSDLNavigationProperty struct {
+	Name string
+
+	// Type is the entity type of the related model in the namespace (e.g. "Pets.Owner")
+	Type string
+
+	// Collection is true for has many and many to many relations
+	Collection bool
+}
+
+// CSDLEntitySet
+// is the table of a model
+type CSDLEntitySet struct {
+	Name string
+
+	// EntityType is the entity type of the model in the namespace (e.g. "Pets.Pet")
+	EntityType string
+}
+
+// NewCSDL
+// returns the entity data model of the models with the names the filters use, parsed with the naming strategy of the db,
+// the models that the models are related to are added as entity types as well
+//
+//	csdl, err := gormodata.NewCSDL(db, "Pets", &Pet{}, &Owner{})
+//	document, err := csdl.XML()
+func NewCSDL(db *gorm.DB, namespace string, models ...any) (*CSDL, error) {
+	builder := &csdlBuilder{
+		csdl:         &CSDL{Namespace: namespace},
+		namer:        db.NamingStrategy,
+		entityTypes:  map[reflect.Type]bool{},
+		complexTypes: map[string]bool{},
+	}
+
+	for _, model := range models {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return nil, err
+		}
+
+		builder.csdl.EntitySets = append(builder.csdl.EntitySets, CSDLEntitySet{
+			Name:       statement.Schema.Table,
+			EntityType: builder.qualified(statement.Schema.Name),
+		})
+		builder.queue = append(builder.queue, statement.Schema)
+	}
+
+	// Related models are queued while the entity types are added, so every navigation property has its entity type
+	for len(builder.queue) > 0 {
+		modelSchema := builder.queue[0]
+		builder.queue = builder.queue[1:]
+		builder.addEntityType(modelSchema)
+	}
+
+	return builder.csdl, nil
+}
+
+// csdlBuilder
+// collects the types of the models of a CSDL document
+type csdlBuilder struct {
+	csdl         *CSDL
+	namer        schema.Namer
+	entityTypes  map[reflect.Type]bool
+	complexTypes map[string]bool
+	queue        []*schema.Schema
+}
+
+// csdlPropertyNode
+// is a property of an entity type or a complex type, with the properties of the embedded struct if it is one
+type csdlPropertyNode struct {
+	name       string
+	field      *schema.Field
+	structType reflect.Type
+	children   []*csdlPropertyNode
+}
+
+func (c *csdlBuilder) qualified(name string) string {
+	return c.csdl.Namespace + "." + name
+}
+
+// addEntityType
+// adds the entity type of the model, unless it was already added
+func (c *csdlBuilder) addEntityType(modelSchema *schema.Schema) {
+	if c.entityTypes[modelSchema.ModelType] {
+		return
+	}
+	c.entityTypes[modelSchema.ModelType] = true
+
+	entityType := CSDLEntityType{Name: modelSchema.Name}
+	for _, field := range modelSchema.PrimaryFields {
+		entityType.Key = append(entityType.Key, csdlPropertyName(c.namer, field.DBName))
+	}
+
+	root := &csdlPropertyNode{}
+	for _, field := range modelSchema.Fields {
+		if IsHiddenField(field) {
+			continue
+		}
+
+		if relationship, ok := modelSchema.Relationships.Relations[field.Name]; ok && len(field.BindNames) == 1 {
+			entityType.NavigationProperties = append(entityType.NavigationProperties, CSDLNavigationProperty{
+				Name:       csdlPropertyName(c.namer, c.namer.ColumnName("", relationship.Name)),
+				Type:       c.qualified(relationship.FieldSchema.Name),
+				Collection: relationship.Type == schema.HasMany || relationship.Type == schema.Many2Many,
+			})
+			c.queue = append(c.queue, relationship.FieldSchema)
+
+			continue
+		}
+
+		if field.DBName == "" || !field.Readable {
+			continue
+		}
+
+		root.add(c.namer, modelSchema.ModelType, field)
+	}
+
+	entityType.Properties = c.properties(root)
+	c.csdl.EntityTypes = append(c.csdl.EntityTypes, entityType)
+}
+
+// add
+// adds the field to the properties, in the complex properties of the named embedded structs it is part of,
+// the fields of anonymous embedded structs (e.g. gorm.Model) are properties of the model itself like in filters
+func (n *csdlPropertyNode) add(namer schema.Namer, modelType reflect.Type, field *schema.Field) {
+	node := n
+	structType := modelType
+	for _, bindName := range field.BindNames[:len(field.BindNames)-1] {
+		structField, ok := structType.FieldByName(bindName)
+		if !ok {
+			break
+		}
+		structType = structField.Type
+		for structType.Kind() == reflect.Pointer {
+			structType = structType.Elem()
+		}
+		if structField.Anonymous {
+			continue
+		}
+
+		name := csdlPropertyName(namer, namer.ColumnName("", bindName))
+		child := node.child(name)
+		if child == nil {
+			child = &csdlPropertyNode{name: name, structType: structType}
+			node.children = append(node.children, child)
+		}
+		node = child
+	}
+
+	// Top level columns can have a column name of their own, the columns of embedded structs are matched by their field name
+	name := csdlPropertyName(namer, field.DBName)
+	if node != n {
+		name = csdlPropertyName(namer, namer.ColumnName("", field.Name))
+	}
+	node.children = append(node.children, &csdlPropertyNode{name: name, field: field})
+}
+
+func (n *csdlPropertyNode) child(name string) *csdlPropertyNode {
+	for _, child := range n.children {
+		if child.field == nil && child.name == name {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// properties
+// returns the properties of the node, the embedded structs are added as complex types
+func (c *csdlBuilder) properties(node *csdlPropertyNode) []CSDLProperty {
+	properties := make([]CSDLProperty, 0, len(node.children))
+	for _, child := range node.children {
+		if child.field != nil {
+			properties = append(properties, CSDLProperty{
+				Name:     child.name,
+				Type:     edmType(child.field),
+				Nullable: !child.field.PrimaryKey && !child.field.NotNull,
+			})
+
+			continue
+		}
+
+		typeName := child.structType.Name()
+		if !c.complexTypes[typeName] {
+			c.complexTypes[typeName] = true
+			c.csdl.ComplexTypes = append(c.csdl.ComplexTypes, CSDLComplexType{Name: typeName, Properties: c.properties(child)})
+		}
+		properties = append(properties, CSDLProperty{Name: child.name, Type: c.qualified(typeName)})
+	}
+
+	return properties
+}
+
+// csdlPropertyName
+// returns the name of the column in filters, in lower camel case (e.g. "birthYear") if that maps back to the column
+func csdlPropertyName(namer schema.Namer, column string) string {
+	if name := strcase.LowerCamelCase(column); namer.ColumnName("", name) == column {
+		return name
+	}
+
+	return column
+}
+
+// edmType
+// returns the primitive type of the column, Edm.String for types without a better match
+func edmType(field *schema.Field) string {
+	switch {
+	case isUUIDField(field):
+		return "Edm.Guid"
+	case isTimeField(field) || field.DataType == schema.Time:
+		return "Edm.DateTimeOffset"
+	}
+
+	switch field.DataType {
+	case schema.Bool:
+		return "Edm.Boolean"
+	case schema.Int:
+		switch field.Size {
+		case 8:
+			return "Edm.SByte"
+		case 16:
+			return "Edm.Int16"
+		case 32:
+			return "Edm.Int32"
+		}
+
+		return "Edm.Int64"
+	case schema.Uint:
+		// Edm has no unsigned types, the next signed type holds every value
+		switch field.Size {
+		case 8:
+			return "Edm.Byte"
+		case 16:
+			return "Edm.Int32"
+		}
+
+		return "Edm.Int64"
+	case schema.Float:
+		if field.Size == 32 {
+			return "Edm.Single"
+		}
+
+		return "Edm.Double"
+	case schema.Bytes:
+		return "Edm.Binary"
+	}
+
+	return "Edm.String"
+}
+
+type csdlXMLDocument struct {
+	XMLName      xml.Name `xml:"edmx:Edmx"`
+	Xmlns        string   `xml:"xmlns:edmx,attr"`
+	Version      string   `xml:"Version,attr"`
+	DataServices struct {
+		Schema csdlXMLSchema `xml:"Schema"`
+	} `xml:"edmx:DataServices"`
+}
+
+type csdlXMLSchema struct {
+	Xmlns           string               `xml:"xmlns,attr"`
+	Namespace       string               `xml:"Namespace,attr"`
+	EntityTypes     []csdlXMLEntityType  `xml:"EntityType"`
+	ComplexTypes    []csdlXMLComplexType `xml:"ComplexType"`
+	EntityContainer struct {
+		Name       string             `xml:"Name,attr"`
+		EntitySets []csdlXMLEntitySet `xml:"EntitySet"`
+	} `xml:"EntityContainer"`
+}
+
+type csdlXMLEntityType struct {
+	Name                 string                      `xml:"Name,attr"`
+	Key                  *csdlXMLKey                 `xml:"Key"`
+	Properties           []csdlXMLProperty           `xml:"Property"`
+	NavigationProperties []csdlXMLNavigationProperty `xml:"NavigationProperty"`
+}
+
+type csdlXMLKey struct {
+	PropertyRefs []csdlXMLPropertyRef `xml:"PropertyRef"`
+}
+
+type csdlXMLPropertyRef struct {
+	Name string `xml:"Name,attr"`
+}
+
+type csdlXMLComplexType struct {
+	Name       string            `xml:"Name,attr"`
+	Properties []csdlXMLProperty `xml:"Property"`
+}
+
+type csdlXMLProperty struct {
+	Name     string `xml:"Name,attr"`
+	Type     string `xml:"Type,attr"`
+	Nullable string `xml:"Nullable,attr,omitempty"`
+}
+
+type csdlXMLNavigationProperty struct {
+	Name string `xml:"Name,attr"`
+	Type string `xml:"Type,attr"`
+}
+
+type csdlXMLEntitySet struct {
+	Name       string `xml:"Name,attr"`
+	EntityType string `xml:"EntityType,attr"`
+}
+
+// XML
+// returns the CSDL XML document of the model (OData 4.0)
+func (c *CSDL) XML() ([]byte, error) {
+	document := csdlXMLDocument{Xmlns: "http://docs.oasis-open.org/odata/ns/edmx", Version: "4.0"}
+	xmlSchema := &document.DataServices.Schema
+	xmlSchema.Xmlns = "http://docs.oasis-open.org/odata/ns/edm"
+	xmlSchema.Namespace = c.Namespace
+	xmlSchema.EntityContainer.Name = csdlContainer
+
+	for _, entityType := range c.EntityTypes {
+		xmlEntityType := csdlXMLEntityType{Name: entityType.Name, Properties: csdlXMLProperties(entityType.Properties)}
+		if len(entityType.Key) > 0 {
+			xmlEntityType.Key = &csdlXMLKey{}
+			for _, key := range entityType.Key {
+				xmlEntityType.Key.PropertyRefs = append(xmlEntityType.Key.PropertyRefs, csdlXMLPropertyRef{Name: key})
+			}
+		}
+		for _, navigationProperty := range entityType.NavigationProperties {
+			xmlNavigationProperty := csdlXMLNavigationProperty{Name: navigationProperty.Name, Type: navigationProperty.Type}
+			if navigationProperty.Collection {
+				xmlNavigationProperty.Type = "Collection(" + navigationProperty.Type + ")"
+			}
+			xmlEntityType.NavigationProperties = append(xmlEntityType.NavigationProperties, xmlNavigationProperty)
+		}
+		xmlSchema.EntityTypes = append(xmlSchema.EntityTypes, xmlEntityType)
+	}
+
+	for _, complexType := range c.ComplexTypes {
+		xmlSchema.ComplexTypes = append(xmlSchema.ComplexTypes, csdlXMLComplexType{Name: complexType.Name, Properties: csdlXMLProperties(complexType.Properties)})
+	}
+
+	for _, entitySet := range c.EntitySets {
+		xmlSchema.EntityContainer.EntitySets = append(xmlSchema.EntityContainer.EntitySets, csdlXMLEntitySet(entitySet))
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
+
+func csdlXMLProperties(properties []CSDLProperty) []csdlXMLProperty {
+	xmlProperties := make([]csdlXMLProperty, len(properties))
+	for i, property := range properties {
+		xmlProperties[i] = csdlXMLProperty{Name: property.Name, Type: property.Type}
+		if !property.Nullable {
+			xmlProperties[i].Nullable = "false"
+		}
+	}
+
+	return xmlProperties
+}
+
+// jsonObject
+// is a JSON object that keeps the order of its members, the order of the properties in a CSDL document is the order of the fields
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value any
+}
+
+func (j jsonObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, member := range j {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// JSON
+// returns the CSDL JSON document of the model (OData 4.01),
+// where the types default to Edm.String and the properties default to not nullable
+func (c *CSDL) JSON() ([]byte, error) {
+	namespace := jsonObject{}
+
+	for _, entityType := range c.EntityTypes {
+		object := jsonObject{{key: "$Kind", value: "EntityType"}}
+		if len(entityType.Key) > 0 {
+			object = append(object, jsonMember{key: "$Key", value: entityType.Key})
+		}
+		object = append(object, csdlJSONProperties(entityType.Properties)...)
+		for _, navigationProperty := range entityType.NavigationProperties {
+			property := jsonObject{{key: "$Kind", value: "NavigationProperty"}}
+			if navigationProperty.Collection {
+				property = append(property, jsonMember{key: "$Collection", value: true})
+			} else {
+				property = append(property, jsonMember{key: "$Nullable", value: true})
+			}
+			property = append(property, jsonMember{key: "$Type", value: navigationProperty.Type})
+			object = append(object, jsonMember{key: navigationProperty.Name, value: property})
+		}
+		namespace = append(namespace, jsonMember{key: entityType.Name, value: object})
+	}
+
+	for _, complexType := range c.ComplexTypes {
+		object := append(jsonObject{{key: "$Kind", value: "ComplexType"}}, csdlJSONProperties(complexType.Properties)...)
+		namespace = append(namespace, jsonMember{key: complexType.Name, value: object})
+	}
+
+	container := jsonObject{{key: "$Kind", value: "EntityContainer"}}
+	for _, entitySet := range c.EntitySets {
+		container = append(container, jsonMember{key: entitySet.Name, value: jsonObject{
+			{key: "$Collection", value: true},
+			{key: "$Type", value: entitySet.EntityType},
+		}})
+	}
+	namespace = append(namespace, jsonMember{key: csdlContainer, value: container})
+
+	return json.MarshalIndent(jsonObject{
+		{key: "$Version", value: "4.01"},
+		{key: "$EntityContainer", value: c.Namespace + "." + csdlContainer},
+		{key: c.Namespace, value: namespace},
+	}, "", "  ")
+}
+
+func csdlJSONProperties(properties []CSDLProperty) jsonObject {
+	object := make(jsonObject, 0, len(properties))
+	for _, property := range properties {
+		value := jsonObject{}
+		if property.Type != "Edm.String" {
+			value = append(value, jsonMember{key: "$Type", value: property.Type})
+		}
+		if property.Nullable {
+			value = append(value, jsonMember{key: "$Nullable", value: true})
+		}
+		object = append(object, jsonMember{key: property.Name, value: value})
+	}
+
+	return object
+}
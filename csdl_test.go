@@ -0,0 +1,337 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+type Sensor struct {
+	ID          uint8  `gorm:"primaryKey"`
+	Serial      string `gorm:"column:serial_no;not null"`
+	Active      bool
+	Temperature float32
+	Reading     float64
+	Count       int16
+	Total       uint32
+	Firmware    []byte
+	Location    Address `gorm:"embedded;embeddedPrefix:location_"`
+}
+
+func Test_NewCSDL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		models       []any
+		expectedCSDL *CSDL
+	}{
+		"related models are added": {
+			models: []any{&Pet{}},
+			expectedCSDL: &CSDL{
+				Namespace: "Test",
+				EntityTypes: []CSDLEntityType{
+					{
+						Name: "Pet",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Guid"},
+							{Name: "name", Type: "Edm.String", Nullable: true},
+							{Name: "ownerRef", Type: "Edm.String", Nullable: true},
+						},
+						NavigationProperties: []CSDLNavigationProperty{{Name: "owner", Type: "Test.Owner"}},
+					},
+					{
+						Name: "Owner",
+						Key:  []string{"ownerKey"},
+						Properties: []CSDLProperty{
+							{Name: "ownerKey", Type: "Edm.String"},
+							{Name: "name", Type: "Edm.String", Nullable: true},
+						},
+					},
+				},
+				EntitySets: []CSDLEntitySet{{Name: "pets", EntityType: "Test.Pet"}},
+			},
+		},
+		"collections and self references": {
+			models: []any{&Category{}, &Article{}},
+			expectedCSDL: &CSDL{
+				Namespace: "Test",
+				EntityTypes: []CSDLEntityType{
+					{
+						Name: "Category",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Guid"},
+							{Name: "name", Type: "Edm.String", Nullable: true},
+							{Name: "parentId", Type: "Edm.Guid", Nullable: true},
+						},
+						NavigationProperties: []CSDLNavigationProperty{
+							{Name: "parent", Type: "Test.Category"},
+							{Name: "children", Type: "Test.Category", Collection: true},
+						},
+					},
+					{
+						Name: "Article",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Int64"},
+							{Name: "title", Type: "Edm.String", Nullable: true},
+						},
+						NavigationProperties: []CSDLNavigationProperty{{Name: "labels", Type: "Test.Label", Collection: true}},
+					},
+					{
+						Name: "Label",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Int64"},
+							{Name: "name", Type: "Edm.String", Nullable: true},
+						},
+					},
+				},
+				EntitySets: []CSDLEntitySet{{Name: "categories", EntityType: "Test.Category"}, {Name: "articles", EntityType: "Test.Article"}},
+			},
+		},
+		"embedded structs and primitive types": {
+			models: []any{&Company{}, &Sensor{}},
+			expectedCSDL: &CSDL{
+				Namespace: "Test",
+				EntityTypes: []CSDLEntityType{
+					{
+						Name: "Company",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Int64"},
+							{Name: "name", Type: "Edm.String", Nullable: true},
+							{Name: "address", Type: "Test.Address"},
+							{Name: "email", Type: "Edm.String", Nullable: true},
+						},
+					},
+					{
+						Name: "Sensor",
+						Key:  []string{"id"},
+						Properties: []CSDLProperty{
+							{Name: "id", Type: "Edm.Byte"},
+							{Name: "serialNo", Type: "Edm.String"},
+							{Name: "active", Type: "Edm.Boolean", Nullable: true},
+							{Name: "temperature", Type: "Edm.Single", Nullable: true},
+							{Name: "reading", Type: "Edm.Double", Nullable: true},
+							{Name: "count", Type: "Edm.Int16", Nullable: true},
+							{Name: "total", Type: "Edm.Int64", Nullable: true},
+							{Name: "firmware", Type: "Edm.Binary", Nullable: true},
+							{Name: "location", Type: "Test.Address"},
+						},
+					},
+				},
+				ComplexTypes: []CSDLComplexType{
+					{
+						Name: "Address",
+						Properties: []CSDLProperty{
+							{Name: "street", Type: "Edm.String", Nullable: true},
+							{Name: "city", Type: "Edm.String", Nullable: true},
+						},
+					},
+				},
+				EntitySets: []CSDLEntitySet{{Name: "companies", EntityType: "Test.Company"}, {Name: "sensors", EntityType: "Test.Sensor"}},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			result, err := NewCSDL(db, "Test", testData.models...)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedCSDL, result)
+		})
+	}
+}
+
+func Test_NewCSDL_PropertiesInFilters(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	models := []any{&Pet{}, &Company{}, &Sensor{}, &MockModel{}}
+	csdl, err := NewCSDL(db, "Test", models...)
+	assert.NoError(t, err)
+
+	complexTypes := map[string]CSDLComplexType{}
+	for _, complexType := range csdl.ComplexTypes {
+		complexTypes["Test."+complexType.Name] = complexType
+	}
+
+	for index, entityType := range csdl.EntityTypes[:len(models)] {
+		builder := New(WithQueryValidations(WithModelValidation(models[index])))
+		for _, property := range entityType.Properties {
+			paths := []string{property.Name}
+			if complexType, ok := complexTypes[property.Type]; ok {
+				paths = paths[:0]
+				for _, nested := range complexType.Properties {
+					paths = append(paths, property.Name+"/"+nested.Name)
+				}
+			}
+
+			for _, path := range paths {
+				// Act
+				_, err := builder.Build(path+" eq null", db.Model(models[index]))
+
+				// Assert
+				assert.NoError(t, err, path)
+			}
+		}
+	}
+}
+
+func Test_CSDL_XML(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	csdl, _ := NewCSDL(db, "Pets", &Basket{}, &Company{})
+
+	// Act
+	result, err := csdl.XML()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<edmx:Edmx xmlns:edmx="http://docs.oasis-open.org/odata/ns/edmx" Version="4.0">
+  <edmx:DataServices>
+    <Schema xmlns="http://docs.oasis-open.org/odata/ns/edm" Namespace="Pets">
+      <EntityType Name="Basket">
+        <Key>
+          <PropertyRef Name="id"></PropertyRef>
+        </Key>
+        <Property Name="id" Type="Edm.Guid" Nullable="false"></Property>
+        <Property Name="name" Type="Edm.String"></Property>
+        <NavigationProperty Name="items" Type="Collection(Pets.Item)"></NavigationProperty>
+      </EntityType>
+      <EntityType Name="Company">
+        <Key>
+          <PropertyRef Name="id"></PropertyRef>
+        </Key>
+        <Property Name="id" Type="Edm.Int64" Nullable="false"></Property>
+        <Property Name="name" Type="Edm.String"></Property>
+        <Property Name="address" Type="Pets.Address" Nullable="false"></Property>
+        <Property Name="email" Type="Edm.String"></Property>
+      </EntityType>
+      <EntityType Name="Item">
+        <Key>
+          <PropertyRef Name="id"></PropertyRef>
+        </Key>
+        <Property Name="id" Type="Edm.Guid" Nullable="false"></Property>
+        <Property Name="name" Type="Edm.String"></Property>
+        <Property Name="basketId" Type="Edm.Guid"></Property>
+      </EntityType>
+      <ComplexType Name="Address">
+        <Property Name="street" Type="Edm.String"></Property>
+        <Property Name="city" Type="Edm.String"></Property>
+      </ComplexType>
+      <EntityContainer Name="Container">
+        <EntitySet Name="baskets" EntityType="Pets.Basket"></EntitySet>
+        <EntitySet Name="companies" EntityType="Pets.Company"></EntitySet>
+      </EntityContainer>
+    </Schema>
+  </edmx:DataServices>
+</edmx:Edmx>`, string(result))
+}
+
+func Test_CSDL_JSON(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	csdl, _ := NewCSDL(db, "Pets", &Pet{}, &Company{})
+
+	// Act
+	result, err := csdl.JSON()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$Version": "4.01",
+		"$EntityContainer": "Pets.Container",
+		"Pets": {
+			"Pet": {
+				"$Kind": "EntityType",
+				"$Key": ["id"],
+				"id": {"$Type": "Edm.Guid"},
+				"name": {"$Nullable": true},
+				"ownerRef": {"$Nullable": true},
+				"owner": {"$Kind": "NavigationProperty", "$Nullable": true, "$Type": "Pets.Owner"}
+			},
+			"Company": {
+				"$Kind": "EntityType",
+				"$Key": ["id"],
+				"id": {"$Type": "Edm.Int64"},
+				"name": {"$Nullable": true},
+				"address": {"$Type": "Pets.Address"},
+				"email": {"$Nullable": true}
+			},
+			"Owner": {
+				"$Kind": "EntityType",
+				"$Key": ["ownerKey"],
+				"ownerKey": {},
+				"name": {"$Nullable": true}
+			},
+			"Address": {
+				"$Kind": "ComplexType",
+				"street": {"$Nullable": true},
+				"city": {"$Nullable": true}
+			},
+			"Container": {
+				"$Kind": "EntityContainer",
+				"pets": {"$Collection": true, "$Type": "Pets.Pet"},
+				"companies": {"$Collection": true, "$Type": "Pets.Company"}
+			}
+		}
+	}`, string(result))
+	assert.Regexp(t, `^\{\n  "\$Version": "4.01",\n  "\$EntityContainer": "Pets.Container",\n  "Pets": \{\n    "Pet": \{`, string(result))
+}
+
+func Test_NewCSDL_HiddenFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+	// Act
+	csdl, err := NewCSDL(db, "Test", &HiddenUser{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []CSDLEntityType{
+		{
+			Name: "HiddenUser",
+			Key:  []string{"id"},
+			Properties: []CSDLProperty{
+				{Name: "id", Type: "Edm.Int64"},
+				{Name: "name", Type: "Edm.String", Nullable: true},
+				{Name: "managerId", Type: "Edm.Int64", Nullable: true},
+				{Name: "teamId", Type: "Edm.Int64", Nullable: true},
+			},
+			NavigationProperties: []CSDLNavigationProperty{
+				{Name: "team", Type: "Test.HiddenTeam"},
+			},
+		},
+		{
+			Name: "HiddenTeam",
+			Key:  []string{"id"},
+			Properties: []CSDLProperty{
+				{Name: "id", Type: "Edm.Int64"},
+				{Name: "name", Type: "Edm.String", Nullable: true},
+			},
+		},
+	}, csdl.EntityTypes)
+	assert.Empty(t, csdl.ComplexTypes)
+}
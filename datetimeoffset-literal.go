@@ -0,0 +1,89 @@
+package gormodata
+
+import (
+	"regexp"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// dateTimeOffsetPattern
+// matches the datetimeoffset literals of a filter, timestamps with a time zone offset or Z for UTC (e.g. 2025-01-01T10:00:00+02:00)
+var dateTimeOffsetPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}[Tt][0-9]{2}:[0-9]{2}(?::[0-9]{2}(?:\.[0-9]{1,9})?)?(?:[Zz]|[+-][0-9]{2}:[0-9]{2})$`)
+
+// dateTimeOffsetLayouts
+// are the layouts of datetimeoffset literals with and without seconds
+var dateTimeOffsetLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00"}
+
+// DateTimeOffsetMode
+// sets how the datetimeoffset literals of filters (e.g. 2025-01-01T10:00:00+02:00) are compared (see WithDateTimeOffsetMode)
+type DateTimeOffsetMode int
+
+const (
+	// DateTimeOffsetUTC compares datetimeoffset literals as their time in UTC (e.g. 2025-01-01T08:00:00Z)
+	DateTimeOffsetUTC DateTimeOffsetMode = iota
+	// DateTimeOffsetKeep compares datetimeoffset literals with their own offset on databases with a type for it
+	// (timestamptz on PostgreSQL, datetimeoffset on SQL Server)
+	DateTimeOffsetKeep
+)
+
+// WithDateTimeOffsetMode
+// sets how datetimeoffset literals are compared, they are converted to UTC by default
+//
+//	builder := gormodata.New(gormodata.WithDateTimeOffsetMode(gormodata.DateTimeOffsetKeep))
+//	dbQuery, err := builder.Build("createdAt gt 2025-01-01T10:00:00+02:00", db.Model(&Event{}))
+//
+// MySQL and SQLite have no type with an offset, they always compare the literals in UTC
+func WithDateTimeOffsetMode(mode DateTimeOffsetMode) Option {
+	return func(b *Builder) {
+		b.dateTimeOffsetMode = mode
+	}
+}
+
+// isDateTimeOffsetLiteral
+// returns whether the value of a node is a valid datetimeoffset literal
+func isDateTimeOffsetLiteral(value string) bool {
+	_, ok := dateTimeOffsetLiteral(value)
+
+	return ok
+}
+
+// dateTimeOffsetLiteral
+// returns the time of a datetimeoffset literal with the offset it was written with
+func dateTimeOffsetLiteral(value string) (time.Time, bool) {
+	if !dateTimeOffsetPattern.MatchString(value) {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateTimeOffsetLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// bindDateTimeOffset
+// returns the bind parameter of a datetimeoffset literal, on PostgreSQL and SQL Server it is cast to their type with an offset:
+//
+//	createdAt gt 2025-01-01T10:00:00+02:00  ->  created_at > CAST('2025-01-01T08:00:00Z' AS timestamptz)
+//
+// the literal is in UTC unless the offset is kept (see DateTimeOffsetKeep), MySQL compares it as a DATETIME in UTC
+// and SQLite gets the time in UTC so the driver formats it like the timestamps it stores
+func bindDateTimeOffset(value time.Time, databaseType DbType, mode DateTimeOffsetMode) any {
+	if mode != DateTimeOffsetKeep || databaseType == MySQL || databaseType == SQLite {
+		value = value.UTC()
+	}
+
+	switch databaseType {
+	case PostgreSQL:
+		return clause.Expr{SQL: "CAST(? AS timestamptz)", Vars: []any{value.Format(time.RFC3339Nano)}}
+	case SQLServer:
+		return clause.Expr{SQL: "CAST(? AS datetimeoffset)", Vars: []any{value.Format(time.RFC3339Nano)}}
+	case MySQL:
+		return clause.Expr{SQL: "CAST(? AS DATETIME(6))", Vars: []any{value.Format("2006-01-02 15:04:05.999999")}}
+	default:
+		return value
+	}
+}
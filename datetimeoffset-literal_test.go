@@ -0,0 +1,188 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_DateTimeOffset(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		mode         DateTimeOffsetMode
+		expectedSqls map[DbType]string
+	}{
+		"utc": {
+			queryString: "createdAt gt 2025-01-01T10:00:00+02:00",
+			mode:        DateTimeOffsetUTC,
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01T08:00:00Z\" AS timestamptz)",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01 08:00:00\" AS DATETIME(6))",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE created_at > \"2025-01-01 08:00:00\"",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01T08:00:00Z\" AS datetimeoffset)",
+			},
+		},
+		"keep offset": {
+			queryString: "createdAt gt 2025-01-01T10:00:00.5-05:30",
+			mode:        DateTimeOffsetKeep,
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01T10:00:00.5-05:30\" AS timestamptz)",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01 15:30:00.5\" AS DATETIME(6))",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE created_at > \"2025-01-01 15:30:00.5\"",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE created_at > CAST(\"2025-01-01T10:00:00.5-05:30\" AS datetimeoffset)",
+			},
+		},
+		"utc without seconds": {
+			queryString: "createdAt le 2025-01-01T09:00Z",
+			mode:        DateTimeOffsetKeep,
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE created_at <= CAST(\"2025-01-01T09:00:00Z\" AS timestamptz)",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE created_at <= CAST(\"2025-01-01 09:00:00\" AS DATETIME(6))",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE created_at <= \"2025-01-01 09:00:00\"",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE created_at <= CAST(\"2025-01-01T09:00:00Z\" AS datetimeoffset)",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType), WithDateTimeOffsetMode(testData.mode))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx.Model(&MockTimeModel{}))
+					return dbQuery.Find(&MockTimeModel{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_DateTimeOffsetResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"offset": {
+			queryString:   "createdAt gt 2025-01-01T10:00:00+02:00",
+			expectedNames: []string{"late"},
+		},
+		"utc": {
+			queryString:   "createdAt lt 2025-01-01T08:00Z",
+			expectedNames: []string{"early"},
+		},
+		"negative offset": {
+			queryString:   "createdAt ge 2025-01-01T03:00:00-05:00",
+			expectedNames: []string{"late"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockTimeModel{})
+			db.Create(&MockTimeModel{Name: "early", CreatedAt: time.Date(2025, 1, 1, 7, 0, 0, 0, time.UTC)})
+			db.Create(&MockTimeModel{Name: "late", CreatedAt: time.Date(2025, 1, 1, 8, 30, 0, 0, time.UTC)})
+
+			builder := New(WithDatabaseType(SQLite), WithDateTimeOffsetMode(DateTimeOffsetKeep))
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db.Model(&MockTimeModel{}))
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []MockTimeModel
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, model := range result {
+				names = append(names, model.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQuery_DateTimeOffsetErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+	}{
+		"month out of range": {
+			queryString: "createdAt gt 2025-13-01T10:00:00Z",
+			expectedErr: "failed to parse query: invalid datetimeoffset literal 2025-13-01T10:00:00Z, expected a valid timestamp with an offset (e.g. 2025-01-01T10:00:00+02:00)",
+		},
+		"offset out of range": {
+			queryString: "createdAt gt 2025-01-01T10:00:00+25:00",
+			expectedErr: "failed to parse query: invalid datetimeoffset literal 2025-01-01T10:00:00+25:00, expected a valid timestamp with an offset (e.g. 2025-01-01T10:00:00+02:00)",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db.Model(&MockTimeModel{}), PostgreSQL)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidSyntax))
+		})
+	}
+}
+
+func Test_Parse_DateTimeOffset(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("createdAt gt 2025-01-01T10:00:00+02:00")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "createdAt gt 2025-01-01T10:00:00+02:00", expr.String())
+
+	value, ok := expr.Args[1].Value.(time.Time)
+	assert.True(t, ok)
+	assert.True(t, value.Equal(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)))
+
+	data, err := json.Marshal(expr)
+	assert.NoError(t, err)
+
+	var decoded Expr
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, expr.String(), decoded.String())
+}
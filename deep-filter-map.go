@@ -0,0 +1,208 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm/schema"
+)
+
+// ToDeepFilterMap
+// returns the filter as a map for gorm-deep-filtering (https://github.com/survivorbat/gorm-deep-filtering),
+// with the operators as the prefixes of the default gormqonvert config (see WithQonvertConfig)
+//
+//	filterMap, err := gormodata.ToDeepFilterMap("name ne 'test' and metadata/tag/value eq 'prd'")
+//	// map[string]any{"name": "!=test", "metadata": map[string]any{"tag": map[string]any{"value": "prd"}}}
+//	err = db.Where(filterMap).Find(&result).Error
+//
+// the properties are named with the default gorm naming strategy,
+// only filters that the map can express are accepted: comparisons and contains, startswith and endswith of properties with values joined by 'and',
+// and 'or' between 'eq' comparisons of the same property, which become a list of values
+func ToDeepFilterMap(query string) (map[string]any, error) {
+	return New().ToDeepFilterMap(query)
+}
+
+// ToDeepFilterMap
+// returns the filter as a map for gorm-deep-filtering with the gormqonvert config of the Builder (see ToDeepFilterMap),
+// the size, function and list size checks of the Builder are done, the query validations need a db and are not
+func (b *Builder) ToDeepFilterMap(query string) (map[string]any, error) {
+	tree, queryErrors, err := b.parseChecked(query)
+	if err == nil && len(queryErrors) > 0 {
+		err = queryErrors
+	}
+	if err == nil && b.maxListSize > 0 {
+		err = listSizeValidation(b.maxListSize)(tree, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &queryTranslation{namer: schema.NamingStrategy{}, qonvert: b.qonvert}
+	if translation.qonvert == nil {
+		translation.qonvert = defaultQonvertTranslation
+	}
+
+	filterMap := map[string]any{}
+	if err := addDeepFilter(filterMap, tree.Root, translation); err != nil {
+		return nil, err
+	}
+
+	return filterMap, nil
+}
+
+// addDeepFilter
+// adds the conditions of the node to the deep filter map
+func addDeepFilter(filterMap map[string]any, node *syntaxtree.Node, translation *queryTranslation) error {
+	if node.Type == syntaxtree.Operator && node.Value == "and" {
+		if err := addDeepFilter(filterMap, node.LeftChild, translation); err != nil {
+			return err
+		}
+
+		return addDeepFilter(filterMap, node.RightChild, translation)
+	}
+
+	if node.Type == syntaxtree.Operator && node.Value == "or" {
+		property, values, err := deepFilterAlternatives(node)
+		if err != nil {
+			return err
+		}
+
+		return mergeDeepFilter(filterMap, nestedFilterMap(property, values, translation), "")
+	}
+
+	property, value, err := deepFilterCondition(node)
+	if err != nil {
+		return err
+	}
+
+	var filter any = value
+	if node.Value != "eq" {
+		filter = translation.qonvert.prefixes[node.Value] + value.(string)
+	}
+
+	return mergeDeepFilter(filterMap, nestedFilterMap(property, filter, translation), "")
+}
+
+// deepFilterCondition
+// returns the property and the value of a comparison or a contains, startswith or endswith of a property with a value,
+// the value of 'eq null' is nil
+func deepFilterCondition(node *syntaxtree.Node) (string, any, error) {
+	isComparison := node.Type == syntaxtree.Operator && slices.Contains(comparisonOperators, node.Value)
+	isLike := node.Type == syntaxtree.Operator && likePatterns[node.Value] != ""
+	if (!isComparison && !isLike) || !isPropertyNode(node.LeftChild) || node.RightChild.Type != syntaxtree.RightOperand || isRootReference(node.RightChild.Value) ||
+		(isLike && !isStringLiteral(node.RightChild.Value)) {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, only comparisons of properties with values can", nodeExpression(node)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	value := unquote(node.RightChild.Value)
+	if isNumericSuffixLiteral(node.RightChild.Value) {
+		value = numericSQL(literalValue(node.RightChild.Value))
+	}
+	switch {
+	case node.RightChild.Value == "null" && node.Value == "eq":
+		return node.LeftChild.Value, nil, nil
+	case node.RightChild.Value == "null":
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, only 'eq' can compare with null", nodeExpression(node)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	case isGeoLiteral(node.RightChild.Value) || isTimeOfDayLiteral(node.RightChild.Value):
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, geo and time literals can only be compared in a query", nodeExpression(node)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	case isBinaryLiteral(node.RightChild.Value) && node.Value == "eq":
+		return node.LeftChild.Value, []byte(binaryLiteral(node.RightChild.Value)), nil
+	case isBinaryLiteral(node.RightChild.Value):
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, only 'eq' can compare with binary literals", nodeExpression(node)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	case isLike:
+		value = fmt.Sprintf(likePatterns[node.Value], strings.ReplaceAll(value, "%", "\\%"))
+	}
+
+	return node.LeftChild.Value, value, nil
+}
+
+// deepFilterAlternatives
+// returns the property and the values of 'eq' comparisons of the same property joined by 'or'
+func deepFilterAlternatives(node *syntaxtree.Node) (string, []any, error) {
+	if node.Type == syntaxtree.Operator && node.Value == "or" {
+		leftProperty, leftValues, err := deepFilterAlternatives(node.LeftChild)
+		if err != nil {
+			return "", nil, err
+		}
+		rightProperty, rightValues, err := deepFilterAlternatives(node.RightChild)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if leftProperty != rightProperty {
+			return "", nil, &InvalidQueryError{
+				Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, 'or' can only join 'eq' comparisons of the same property", nodeExpression(node)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(node),
+				Node:       node,
+			}
+		}
+
+		return leftProperty, append(leftValues, rightValues...), nil
+	}
+
+	property, value, err := deepFilterCondition(node)
+	if err != nil {
+		return "", nil, err
+	}
+	if node.Value != "eq" {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be expressed in a deep filter map, 'or' can only join 'eq' comparisons of the same property", nodeExpression(node)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	return property, []any{value}, nil
+}
+
+// mergeDeepFilter
+// merges the nested filter map into the deep filter map, a property can only have one condition
+func mergeDeepFilter(filterMap map[string]any, filter map[string]any, path string) error {
+	for key, value := range filter {
+		existing, ok := filterMap[key]
+		if !ok {
+			filterMap[key] = value
+
+			continue
+		}
+
+		existingMap, existingIsMap := existing.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if !existingIsMap || !valueIsMap {
+			return &InvalidQueryError{
+				Msg: fmt.Sprintf("property '%s' has more than one condition, which cannot be expressed in a deep filter map", path+key),
+				Err: ErrUnsupportedOperator,
+			}
+		}
+
+		if err := mergeDeepFilter(existingMap, valueMap, path+key+"/"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
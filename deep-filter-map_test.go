@@ -0,0 +1,138 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_ToDeepFilterMap(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		options     []Option
+		expectedMap map[string]any
+	}{
+		"comparison": {
+			queryString: "name eq 'test'",
+			expectedMap: map[string]any{"name": "test"},
+		},
+		"operators": {
+			queryString: "name ne 'test' and testValue ge 'a' and contains(metadata/name,'50%')",
+			expectedMap: map[string]any{
+				"name":       "!=test",
+				"test_value": ">=a",
+				"metadata":   map[string]any{"name": "~%50\\%%"},
+			},
+		},
+		"merged relation paths": {
+			queryString: "metadata/name eq 'prd' and startswith(metadata/tag/value,'v') and endswith(metadata/tag/key,'k')",
+			expectedMap: map[string]any{
+				"metadata": map[string]any{
+					"name": "prd",
+					"tag":  map[string]any{"value": "~v%", "key": "~%k"},
+				},
+			},
+		},
+		"alternatives of a property": {
+			queryString: "name eq 'a' or (name eq 'b' or name eq 'c')",
+			expectedMap: map[string]any{"name": []any{"a", "b", "c"}},
+		},
+		"null": {
+			queryString: "metadataId eq null",
+			expectedMap: map[string]any{"metadata_id": nil},
+		},
+		"binary literal": {
+			queryString: "hash eq binary'00FF'",
+			expectedMap: map[string]any{"hash": []byte{0x00, 0xFF}},
+		},
+		"qonvert config": {
+			queryString: "name ne 'test'",
+			options:     []Option{WithQonvertConfig(gormqonvert.CharacterConfig{NotEqualToPrefix: "<>"})},
+			expectedMap: map[string]any{"name": "<>test"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := New(testData.options...).ToDeepFilterMap(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedMap, result)
+		})
+	}
+}
+
+func Test_ToDeepFilterMap_Error(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+		expectedIs    error
+	}{
+		"syntax error": {
+			queryString:   "name eq 'test",
+			expectedError: "failed to parse query: unterminated string literal 'test",
+			expectedIs:    ErrInvalidSyntax,
+		},
+		"not": {
+			queryString:   "not(name eq 'test')",
+			expectedError: "invalid query: 'not(name eq 'test')' cannot be expressed in a deep filter map, only comparisons of properties with values can",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"function of a property": {
+			queryString:   "length(name) gt 3",
+			expectedError: "invalid query: 'length(name) gt 3' cannot be expressed in a deep filter map, only comparisons of properties with values can",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"or of different properties": {
+			queryString:   "name eq 'a' or testValue eq 'b'",
+			expectedError: "invalid query: 'name eq 'a' or testValue eq 'b'' cannot be expressed in a deep filter map, 'or' can only join 'eq' comparisons of the same property",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"or of other operators": {
+			queryString:   "name eq 'a' or name gt 'b'",
+			expectedError: "invalid query: 'name gt 'b'' cannot be expressed in a deep filter map, 'or' can only join 'eq' comparisons of the same property",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"negated null": {
+			queryString:   "name ne null",
+			expectedError: "invalid query: 'name ne null' cannot be expressed in a deep filter map, only 'eq' can compare with null",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"negated binary literal": {
+			queryString:   "hash ne binary'00FF'",
+			expectedError: "invalid query: 'hash ne binary'00FF'' cannot be expressed in a deep filter map, only 'eq' can compare with binary literals",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"more than one condition on a property": {
+			queryString:   "metadata/name gt 'a' and metadata/name lt 'c'",
+			expectedError: "invalid query: property 'metadata/name' has more than one condition, which cannot be expressed in a deep filter map",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := ToDeepFilterMap(testData.queryString)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// WithDeniedRelations
+// never allows filters to go through the given relations (e.g. "auditLogs", "owner/auditLogs"), so none of their properties can be filtered on,
+// even when the properties are allowed by WithAllowedFields or WithPolicy, these filters fail with an InvalidQueryError wrapping ErrFieldNotAllowed
+//
+//	builder := gormodata.New(gormodata.WithDeniedRelations("auditLogs"))
+//
+//	auditLogs/action eq 'delete'            // denied
+//	auditLogs/any(a: a/action eq 'delete')  // denied
+//
+// a relation name denies that relation on every model of a relation path (e.g. "auditLogs" also denies "owner/auditLogs/action"),
+// a relation path only denies that path (e.g. "owner/auditLogs" does not deny "auditLogs/action"),
+// unlike WithDeniedFields a property with the name of the relation (e.g. a column auditLogs) is still allowed
+func WithDeniedRelations(relations ...string) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, deniedRelationsValidation(relations))
+	}
+}
+
+// deniedRelationsValidation
+// returns a QueryValidation that rejects the properties that go through one of the relations (see WithDeniedRelations)
+func deniedRelationsValidation(relations []string) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		deniedRelations := make([]string, len(relations))
+		for i, relation := range relations {
+			deniedRelations[i] = propertyPath(db.NamingStrategy, relation)
+		}
+
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			if !isPropertyNode(currentNode) {
+				return nil
+			}
+
+			names := strings.Split(modelProperty(currentNode.Value), "/")
+			parts := strings.Split(propertyPath(db.NamingStrategy, currentNode.Value), "/")
+
+			// The last part is a column, unless it is the collection of a lambda, whose elements are filtered on
+			traversed := len(parts) - 1
+			if isLambdaNode(currentNode.Parent) && currentNode.Parent.LeftChild == currentNode {
+				traversed = len(parts)
+			}
+
+			for i := range traversed {
+				path := strings.Join(parts[:i+1], "/")
+				for _, deniedRelation := range deniedRelations {
+					if path != deniedRelation && (strings.Contains(deniedRelation, "/") || parts[i] != deniedRelation) {
+						continue
+					}
+
+					return &InvalidQueryError{
+						Msg:        fmt.Sprintf("relation '%s' of '%s' is not allowed", names[i], currentNode.Value),
+						Err:        ErrFieldNotAllowed,
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
+					}
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
@@ -0,0 +1,123 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Builder_WithDeniedRelations(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		model       any
+		options     []Option
+	}{
+		"other relation": {
+			queryString: "metadata/name eq 'test'",
+			model:       &MockModel{},
+			options:     []Option{WithDeniedRelations("tag")},
+		},
+		"other relation path": {
+			queryString: "metadata/name eq 'test' and metadata/tagId eq null",
+			model:       &MockModel{},
+			options:     []Option{WithDeniedRelations("metadata/tag")},
+		},
+		"column with the name of the relation": {
+			queryString: "name eq 'test'",
+			model:       &MockModel{},
+			options:     []Option{WithDeniedRelations("name")},
+		},
+		"collection of another relation": {
+			queryString: "orders/any(o: o/total gt 5)",
+			model:       &Customer{},
+			options:     []Option{WithDeniedRelations("items")},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(testData.model))
+
+			// Assert
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Builder_WithDeniedRelationsRejected(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		model         any
+		options       []Option
+		expectedError string
+	}{
+		"relation": {
+			queryString:   "name eq 'test' and metadata/name eq 'test'",
+			model:         &MockModel{},
+			options:       []Option{WithDeniedRelations("metadata")},
+			expectedError: "invalid query: relation 'metadata' of 'metadata/name' is not allowed",
+		},
+		"relation on every model of a path": {
+			queryString:   "metadata/tag/value eq 'test'",
+			model:         &MockModel{},
+			options:       []Option{WithDeniedRelations("tag")},
+			expectedError: "invalid query: relation 'tag' of 'metadata/tag/value' is not allowed",
+		},
+		"relation path": {
+			queryString:   "contains(metadata/tag/value,'test')",
+			model:         &MockModel{},
+			options:       []Option{WithDeniedRelations("metadata/tag")},
+			expectedError: "invalid query: relation 'tag' of 'metadata/tag/value' is not allowed",
+		},
+		"allowed field": {
+			queryString:   "metadata/name eq 'test'",
+			model:         &MockModel{},
+			options:       []Option{WithAllowedFields("metadata/name"), WithDeniedRelations("metadata")},
+			expectedError: "invalid query: relation 'metadata' of 'metadata/name' is not allowed",
+		},
+		"collection of a lambda": {
+			queryString:   "orders/any(o: o/total gt 5)",
+			model:         &Customer{},
+			options:       []Option{WithDeniedRelations("orders")},
+			expectedError: "invalid query: relation 'orders' of 'orders' is not allowed",
+		},
+		"collection of a nested lambda": {
+			queryString:   "orders/any(o: o/items/any(i: i/sku eq 'X'))",
+			model:         &Customer{},
+			options:       []Option{WithDeniedRelations("orders/items")},
+			expectedError: "invalid query: relation 'items' of 'orders/items' is not allowed",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(testData.model))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+		})
+	}
+}
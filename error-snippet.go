@@ -0,0 +1,227 @@
+package gormodata
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseErrorTokenPatterns
+// find the part of the filter in the messages of the parser errors, the first pattern that matches is used:
+// the token after the token the parser expected another one after (e.g. `unexpected token "qe" (Operand) after "name"`),
+// the typed and string literals (e.g. "invalid datetime literal 2025-13-01, expected ..."), the tokens quoted by the parser
+// (e.g. `unexpected token "qe" (Operand)`), the operators quoted by the lexer and the functions without quotes
+var parseErrorTokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^unexpected token "((?:[^"\\]|\\.)*)" \(\w+\) after "((?:[^"\\]|\\.)*)"`),
+	regexp.MustCompile(`literal (.+?)(?:, expected|$)`),
+	regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`),
+	regexp.MustCompile(`'([^']*)'`),
+	regexp.MustCompile(`^function (\w+)`),
+}
+
+// functionCallPattern
+// finds the calls of functions in a filter, the name of the function is the submatch
+var functionCallPattern = regexp.MustCompile(`([A-Za-z_]\w*)\s*\(`)
+
+// ErrorSnippet
+// renders the filter with a line of carets under the parts the error is about, e.g. for the error payloads of an API or CLI output
+//
+//	name eq 'rex' and lenght(name) gt 3
+//	                  ^^^^^^
+//
+// the parts are found with the Expression of an *InvalidQueryError, the token in the message of a *SyntaxError
+// or the bracket without a match for ErrUnbalancedParens, all errors of QueryErrors are marked,
+// the filter is returned without carets when the error does not point to a part of it (e.g. when it was rewritten by a field alias)
+func ErrorSnippet(filter string, err error) string {
+	ranges := [][2]int{}
+	errs := []error{err}
+	var queryErrors QueryErrors
+	if errors.As(err, &queryErrors) {
+		errs = queryErrors
+	}
+	for _, err := range errs {
+		// Errors about the same expression (e.g. a field that is not allowed) mark the next time it is in the filter
+		for offset := 0; offset < len(filter); {
+			start, end, ok := errorRange(filter[offset:], err)
+			if !ok {
+				break
+			}
+			if errorRange := [2]int{offset + start, offset + end}; !slices.Contains(ranges, errorRange) {
+				ranges = append(ranges, errorRange)
+
+				break
+			}
+			offset += end
+		}
+	}
+	if len(ranges) == 0 {
+		return filter
+	}
+
+	// The carets are drawn under the line of the first part, filters are rarely spread over lines
+	lineStart := strings.LastIndex(filter[:ranges[0][0]], "\n") + 1
+	lineEnd := len(filter)
+	if index := strings.Index(filter[lineStart:], "\n"); index >= 0 {
+		lineEnd = lineStart + index
+	}
+
+	marked := make([]bool, lineEnd-lineStart)
+	for _, errorRange := range ranges {
+		for i := max(errorRange[0], lineStart); i < min(errorRange[1], lineEnd); i++ {
+			marked[i-lineStart] = true
+		}
+	}
+
+	// Tabs are kept in front of the carets, so they line up with the filter in a terminal
+	carets := strings.Builder{}
+	line := filter[lineStart:lineEnd]
+	for i, r := range line {
+		switch {
+		case marked[i]:
+			carets.WriteByte('^')
+		case r == '\t':
+			carets.WriteByte('\t')
+		default:
+			carets.WriteByte(' ')
+		}
+	}
+
+	return filter[:lineEnd] + "\n" + strings.TrimRightFunc(carets.String(), unicode.IsSpace) + filter[lineEnd:]
+}
+
+// errorRange
+// returns the byte offsets of the part of the filter the error is about, ok is false if the error does not point to a part of it
+func errorRange(filter string, err error) (int, int, bool) {
+	if errors.Is(err, ErrUnbalancedParens) {
+		if index := unbalancedBracket(filter); index >= 0 {
+			return index, index + 1, true
+		}
+	}
+	if errors.Is(err, ErrUnknownFunction) {
+		for _, match := range functionCallPattern.FindAllStringSubmatchIndex(filter, -1) {
+			if name := filter[match[2]:match[3]]; !odataParser.isBinaryFunction(name) && !odataParser.isUnaryFunction(name) {
+				return match[2], match[3], true
+			}
+		}
+	}
+
+	var invalidQueryError *InvalidQueryError
+	if errors.As(err, &invalidQueryError) {
+		if start, end, ok := findExpression(filter, invalidQueryError.Expression); ok {
+			return start, end, true
+		}
+		if invalidQueryError.Node != nil {
+			return findExpression(filter, invalidQueryError.Node.Value)
+		}
+
+		return 0, 0, false
+	}
+
+	var syntaxError *SyntaxError
+	if errors.As(err, &syntaxError) {
+		message := strings.TrimPrefix(syntaxError.Err.Error(), "failed to parse query: ")
+		for _, pattern := range parseErrorTokenPatterns {
+			match := pattern.FindStringSubmatch(message)
+			if match == nil {
+				continue
+			}
+
+			token := unquoteToken(match[1])
+			if len(match) > 2 {
+				// The token is marked where it follows the token before it, not where it is in the filter first
+				if start, end, ok := findExpression(filter, unquoteToken(match[2])+" "+token); ok {
+					if tokenStart, tokenEnd, ok := findExpression(filter[start:end], token); ok {
+						return start + tokenStart, start + tokenEnd, true
+					}
+				}
+
+				continue
+			}
+			if start, end, ok := findExpression(filter, token); ok {
+				return start, end, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// unquoteToken
+// returns the token of a parser error, which quotes it like a go string
+func unquoteToken(token string) string {
+	if unquoted, err := strconv.Unquote(`"` + token + `"`); err == nil {
+		return unquoted
+	}
+
+	return token
+}
+
+// findExpression
+// returns the byte offsets of the expression in the filter, the expression is rendered by the builder (see nodeExpression),
+// so the filter can have other whitespace around its tokens than the expression
+func findExpression(filter string, expression string) (int, int, bool) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return 0, 0, false
+	}
+
+	if index := strings.Index(filter, expression); index >= 0 {
+		return index, index + len(expression), true
+	}
+
+	// Whitespace is optional between the tokens, but not in string literals
+	pattern := strings.Builder{}
+	inString := false
+	for i, r := range expression {
+		if !inString && unicode.IsSpace(r) {
+			continue
+		}
+		if i > 0 && !inString {
+			pattern.WriteString(`\s*`)
+		}
+		if r == '\'' {
+			inString = !inString
+		}
+		pattern.WriteString(regexp.QuoteMeta(string(r)))
+	}
+
+	matcher, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return 0, 0, false
+	}
+	if location := matcher.FindStringIndex(filter); location != nil {
+		return location[0], location[1], true
+	}
+
+	return 0, 0, false
+}
+
+// unbalancedBracket
+// returns the byte offset of the first closing bracket without an opening bracket, or else of the last opening bracket without a closing bracket,
+// brackets in string literals are skipped, -1 if the brackets are balanced
+func unbalancedBracket(filter string) int {
+	openBrackets := []int{}
+	inString := false
+	for i, r := range filter {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case inString:
+		case r == '(':
+			openBrackets = append(openBrackets, i)
+		case r == ')':
+			if len(openBrackets) == 0 {
+				return i
+			}
+			openBrackets = openBrackets[:len(openBrackets)-1]
+		}
+	}
+	if len(openBrackets) > 0 {
+		return openBrackets[len(openBrackets)-1]
+	}
+
+	return -1
+}
@@ -0,0 +1,108 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_ErrorSnippet(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString     string
+		options         []Option
+		expectedSnippet string
+	}{
+		"unknown function": {
+			queryString: "name eq 'rex' and lenght(name) gt 3",
+			expectedSnippet: "name eq 'rex' and lenght(name) gt 3\n" +
+				"                  ^^^^^^",
+		},
+		"unknown operator": {
+			queryString: "name eq 'rex' or name qe 'max'",
+			expectedSnippet: "name eq 'rex' or name qe 'max'\n" +
+				"                      ^^",
+		},
+		"missing closing bracket": {
+			queryString: "(name eq 'rex' or name eq 'max'",
+			expectedSnippet: "(name eq 'rex' or name eq 'max'\n" +
+				"^",
+		},
+		"missing opening bracket": {
+			queryString: "name eq ')' and name eq 'rex')",
+			expectedSnippet: "name eq ')' and name eq 'rex')\n" +
+				"                             ^",
+		},
+		"unterminated string literal": {
+			queryString: "name eq 'rex",
+			expectedSnippet: "name eq 'rex\n" +
+				"        ^^^^",
+		},
+		"wrong number of arguments": {
+			queryString: "regexreplace(name,'a') eq 'b'",
+			expectedSnippet: "regexreplace(name,'a') eq 'b'\n" +
+				"^^^^^^^^^^^^",
+		},
+		"invalid expression with other whitespace": {
+			queryString: "length(name) eq concat( name, 'x' )",
+			expectedSnippet: "length(name) eq concat( name, 'x' )\n" +
+				"                ^^^^^^^^^^^^^^^^^^^",
+		},
+		"all errors": {
+			queryString: "email eq 'x' and contains(email,'y')",
+			options:     []Option{WithDeniedFields("email"), WithCollectAllErrors()},
+			expectedSnippet: "email eq 'x' and contains(email,'y')\n" +
+				"^^^^^                     ^^^^^",
+		},
+		"tabs": {
+			queryString: "\tname eq 'rex' or\n\temail eq 'x'",
+			options:     []Option{WithDeniedFields("email")},
+			expectedSnippet: "\tname eq 'rex' or\n" +
+				"\temail eq 'x'\n" +
+				"\t^^^^^",
+		},
+		"multibyte characters": {
+			queryString: "name eq 'zoë' and email eq 'x'",
+			options:     []Option{WithDeniedFields("email")},
+			expectedSnippet: "name eq 'zoë' and email eq 'x'\n" +
+				"                  ^^^^^",
+		},
+		"expression that is not in the filter": {
+			queryString:     "title eq 'rex'",
+			options:         []Option{WithFieldAliases(map[string]string{"title": "email"}), WithDeniedFields("email")},
+			expectedSnippet: "title eq 'rex'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+			_, err := builder.Build(testData.queryString, db)
+
+			// Act
+			snippet := ErrorSnippet(testData.queryString, err)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Equal(t, testData.expectedSnippet, snippet)
+		})
+	}
+}
+
+func Test_ErrorSnippetOtherError(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	snippet := ErrorSnippet("name eq 'rex'", errors.New("connection refused"))
+
+	// Assert
+	assert.Equal(t, "name eq 'rex'", snippet)
+}
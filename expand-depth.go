@@ -0,0 +1,50 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// WithMaxExpandDepth
+// rejects filters with a property that goes through more than maxExpandDepth relations (e.g. 2 for "owner/address/city"),
+// independent of how deeply the filter is nested (see WithMaxDepth), since every relation costs a subquery,
+// the filters fail with ErrComplexityExceeded
+//
+//	builder := gormodata.New(gormodata.WithMaxExpandDepth(1))
+//
+// the relations are counted like the RelationHops of the audit (see WithAuditHook), the properties of the elements of lambdas
+// count the relations of their collection as well
+func WithMaxExpandDepth(maxExpandDepth int) Option {
+	return func(b *Builder) {
+		b.maxExpandDepth = maxExpandDepth
+		b.queryValidations = append(b.queryValidations, expandDepthValidation(maxExpandDepth))
+	}
+}
+
+// expandDepthValidation
+// returns a QueryValidation that checks the number of relations the properties of the filter go through (see WithMaxExpandDepth)
+func expandDepthValidation(maxExpandDepth int) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			if !isPropertyNode(currentNode) {
+				return nil
+			}
+
+			if hops := strings.Count(modelProperty(currentNode.Value), "/"); hops > maxExpandDepth {
+				return &InvalidQueryError{
+					Msg:        fmt.Sprintf("property '%s' goes through %d relations, which exceeds the maximum expand depth of %d", currentNode.Value, hops, maxExpandDepth),
+					Err:        ErrComplexityExceeded,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
@@ -0,0 +1,63 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Builder_MaxExpandDepth(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"properties of the model": {
+			queryString: "name eq 'test' and length(testValue) gt 2",
+		},
+		"relation at the maximum": {
+			queryString: "metadata/name eq 'test' and contains(tolower(metadata/name),'t')",
+		},
+		"deeply nested filter": {
+			queryString: "(((((name eq 'a' or name eq 'b') and testValue eq 'c') or name eq 'd') and testValue eq 'e') or metadata/name eq 'f')",
+		},
+		"relation too deep": {
+			queryString:   "name eq 'test' or metadata/tag/value eq 'prd'",
+			expectedError: "invalid query: property 'metadata/tag/value' goes through 2 relations, which exceeds the maximum expand depth of 1",
+		},
+		"relation too deep in a function": {
+			queryString:   "startswith(metadata/tag/value,'p')",
+			expectedError: "invalid query: property 'metadata/tag/value' goes through 2 relations, which exceeds the maximum expand depth of 1",
+		},
+		"relation too deep in a lambda": {
+			queryString:   "metadata/any(m: m/tag/value eq 'prd')",
+			expectedError: "invalid query: property 'metadata/tag/value' goes through 2 relations, which exceeds the maximum expand depth of 1",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithMaxExpandDepth(1))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrComplexityExceeded))
+		})
+	}
+}
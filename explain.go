@@ -0,0 +1,80 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Explanation
+// describes how a filter was translated, to troubleshoot filters without enabling the gorm SQL logging (see Builder.Explain)
+type Explanation struct {
+	// Tree is the printable syntax tree of the filter after resolving the field aliases (see PrintTree)
+	Tree string
+
+	// NestedFilters are the filters on relations and embedded structs before they are resolved into subqueries and columns
+	NestedFilters []map[string]any
+
+	// SQL is the generated query with placeholders for Vars
+	SQL  string
+	Vars []any
+}
+
+// Explain
+// builds the filter like Build, but returns an Explanation of the translation instead of the query
+//
+// the SQL is generated with a dry run, so the model needs to be set on the db (db.Model(...))
+func (b *Builder) Explain(query string, db *gorm.DB) (*Explanation, error) {
+	if db.Statement.Model == nil && db.Statement.Table == "" {
+		return nil, &InvalidQueryError{
+			Msg: "cannot explain a query without a model, use db.Model(...)",
+		}
+	}
+
+	dbQuery, tree, err := b.build(query, db)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		NestedFilters: []map[string]any{},
+	}
+	// Every condition of a partial filter can be dropped (see WithPartialFilters)
+	if tree != nil {
+		explanation.Tree = tree.String()
+	}
+
+	if where, ok := dbQuery.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		explanation.NestedFilters = collectNestedFilters(where.Exprs, explanation.NestedFilters)
+	}
+
+	var rows []map[string]any
+	dryRun := dbQuery.Session(&gorm.Session{DryRun: true}).Find(&rows)
+	if dryRun.Error != nil {
+		return nil, dryRun.Error
+	}
+	explanation.SQL = dryRun.Statement.SQL.String()
+	explanation.Vars = dryRun.Statement.Vars
+
+	return explanation, nil
+}
+
+// collectNestedFilters
+// returns the nested filter maps of the expressions in the order they appear in the query
+func collectNestedFilters(exprs []clause.Expression, nestedFilters []map[string]any) []map[string]any {
+	for _, expr := range exprs {
+		switch expr := expr.(type) {
+		case clause.AndConditions:
+			nestedFilters = collectNestedFilters(expr.Exprs, nestedFilters)
+		case clause.OrConditions:
+			nestedFilters = collectNestedFilters(expr.Exprs, nestedFilters)
+		case clause.NotConditions:
+			nestedFilters = collectNestedFilters(expr.Exprs, nestedFilters)
+		case clause.Eq:
+			if filter, ok := expr.Value.(map[string]any); ok {
+				nestedFilters = append(nestedFilters, map[string]any{expr.Column.(clause.Column).Name: filter})
+			}
+		}
+	}
+
+	return nestedFilters
+}
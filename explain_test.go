@@ -0,0 +1,45 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Builder_Explain(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	builder := New(WithDatabaseType(SQLite), WithFieldAliases(map[string]string{"title": "name"}))
+	expectedTree, _ := PrintTree("name eq 'test' and metadata/name eq 'prd'")
+
+	// Act
+	explanation, err := builder.Explain("title eq 'test' and metadata/name eq 'prd'", db.Model(&MockModel{}))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTree, explanation.Tree)
+	assert.Equal(t, []map[string]any{{"metadata": map[string]any{"name": "prd"}}}, explanation.NestedFilters)
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE name = ? AND metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = ?)", explanation.SQL)
+	assert.Equal(t, []any{"test", "prd"}, explanation.Vars)
+}
+
+func Test_Builder_ExplainWithoutModel(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite))
+
+	// Act
+	explanation, err := builder.Explain("name eq 'test'", db)
+
+	// Assert
+	assert.Nil(t, explanation)
+	assert.EqualError(t, err, "invalid query: cannot explain a query without a model, use db.Model(...)")
+}
@@ -0,0 +1,160 @@
+package gormodata
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field
+// returns a property expression to build filters in code instead of formatting filter strings (see Expr.Eq, Expr.And, Func),
+// relation paths are separated by '/' (e.g. "metadata/name")
+//
+//	filter := gormodata.Field("name").Eq("test").And(gormodata.Func("contains", gormodata.Field("testValue"), "x"))
+//	dbQuery, err := builder.BuildExpr(filter, db)
+func Field(property string) *Expr {
+	return &Expr{Kind: PropertyExpr, Property: property}
+}
+
+// Lit
+// returns a literal expression, integers are stored as int64 and floats as float64 like in Parse,
+// byte slices become binary literals, times are formatted as RFC3339 in UTC and values that implement fmt.Stringer (e.g. uuid.UUID) are formatted with String
+func Lit(value any) *Expr {
+	switch typedValue := value.(type) {
+	case nil, string, bool, int64, float64, BinaryLiteral, DecimalLiteral, GeoLiteral, TimeOfDayLiteral:
+	case []byte:
+		value = BinaryLiteral(typedValue)
+	case int:
+		value = int64(typedValue)
+	case int8:
+		value = int64(typedValue)
+	case int16:
+		value = int64(typedValue)
+	case int32:
+		value = int64(typedValue)
+	case uint:
+		value = int64(typedValue)
+	case uint8:
+		value = int64(typedValue)
+	case uint16:
+		value = int64(typedValue)
+	case uint32:
+		value = int64(typedValue)
+	case float32:
+		value = float64(typedValue)
+	case time.Time:
+		value = typedValue.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		value = typedValue.String()
+	default:
+		value = fmt.Sprint(typedValue)
+	}
+
+	return &Expr{Kind: LiteralExpr, Value: value}
+}
+
+// Func
+// returns a function expression (e.g. Func("length", Field("name"))),
+// arguments that are not expressions become literals (see Lit)
+func Func(name string, args ...any) *Expr {
+	expr := &Expr{Kind: FunctionExpr, Func: name, Args: make([]*Expr, len(args))}
+	for i, arg := range args {
+		expr.Args[i] = exprOf(arg)
+	}
+
+	return expr
+}
+
+// Not
+// returns the negation of the expression
+func Not(expr *Expr) *Expr {
+	return &Expr{Kind: LogicalExpr, Op: "not", Args: []*Expr{expr}}
+}
+
+// Eq
+// returns the comparison e eq value, values that are not expressions become literals (see Lit)
+func (e *Expr) Eq(value any) *Expr {
+	return e.compare("eq", value)
+}
+
+// Ne
+// returns the comparison e ne value (see Eq)
+func (e *Expr) Ne(value any) *Expr {
+	return e.compare("ne", value)
+}
+
+// Lt
+// returns the comparison e lt value (see Eq)
+func (e *Expr) Lt(value any) *Expr {
+	return e.compare("lt", value)
+}
+
+// Le
+// returns the comparison e le value (see Eq)
+func (e *Expr) Le(value any) *Expr {
+	return e.compare("le", value)
+}
+
+// Gt
+// returns the comparison e gt value (see Eq)
+func (e *Expr) Gt(value any) *Expr {
+	return e.compare("gt", value)
+}
+
+// Ge
+// returns the comparison e ge value (see Eq)
+func (e *Expr) Ge(value any) *Expr {
+	return e.compare("ge", value)
+}
+
+// Contains
+// returns contains(e,value), the value becomes a literal if it is not an expression
+func (e *Expr) Contains(value any) *Expr {
+	return Func("contains", e, value)
+}
+
+// StartsWith
+// returns startswith(e,value) (see Contains)
+func (e *Expr) StartsWith(value any) *Expr {
+	return Func("startswith", e, value)
+}
+
+// EndsWith
+// returns endswith(e,value) (see Contains)
+func (e *Expr) EndsWith(value any) *Expr {
+	return Func("endswith", e, value)
+}
+
+// And
+// combines the expression with the other expressions, e.And(a, b) is (e and a) and b
+func (e *Expr) And(others ...*Expr) *Expr {
+	return e.combine("and", others)
+}
+
+// Or
+// combines the expression with the other expressions, e.Or(a, b) is (e or a) or b
+func (e *Expr) Or(others ...*Expr) *Expr {
+	return e.combine("or", others)
+}
+
+func (e *Expr) compare(op string, value any) *Expr {
+	return &Expr{Kind: ComparisonExpr, Op: op, Args: []*Expr{e, exprOf(value)}}
+}
+
+func (e *Expr) combine(op string, others []*Expr) *Expr {
+	expr := e
+	for _, other := range others {
+		expr = &Expr{Kind: LogicalExpr, Op: op, Args: []*Expr{expr, other}}
+	}
+
+	return expr
+}
+
+// exprOf
+// returns the value if it is an expression and a literal of the value otherwise
+func exprOf(value any) *Expr {
+	if expr, ok := value.(*Expr); ok {
+		return expr
+	}
+
+	return Lit(value)
+}
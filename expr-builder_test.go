@@ -0,0 +1,85 @@
+package gormodata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_ExprBuilder(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		expr        *Expr
+		queryString string
+	}{
+		"comparison": {
+			expr:        Field("name").Eq("test"),
+			queryString: "name eq 'test'",
+		},
+		"and with function": {
+			expr:        Field("name").Eq("test").And(Func("contains", Field("testValue"), "x")),
+			queryString: "name eq 'test' and contains(testValue,'x')",
+		},
+		"multiple or": {
+			expr:        Field("name").Eq("a").Or(Field("name").Eq("b"), Field("name").Eq("c")),
+			queryString: "name eq 'a' or name eq 'b' or name eq 'c'",
+		},
+		"nested logical": {
+			expr:        Field("age").Ge(18).And(Field("name").StartsWith("a").Or(Not(Field("metadata/name").EndsWith("z")))),
+			queryString: "age ge 18 and (startswith(name,'a') or not(endswith(metadata/name,'z')))",
+		},
+		"functions and literals": {
+			expr:        Func("length", Func("tolower", Field("name"))).Gt(uint8(3)).And(Field("score").Lt(float32(2.5)), Field("deletedAt").Ne(nil)),
+			queryString: "length(tolower(name)) gt 3 and score lt 2.5 and deletedAt ne null",
+		},
+		"typed literals": {
+			expr:        Field("id").Eq(uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6")).And(Field("createdAt").Le(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))),
+			queryString: "id eq '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6' and createdAt le '2025-03-01T12:00:00Z'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expectedExpr, _ := Parse(testData.queryString)
+
+			// Act
+			result := testData.expr.String()
+
+			// Assert
+			assert.Equal(t, expectedExpr, testData.expr)
+			assert.Equal(t, testData.queryString, result)
+		})
+	}
+}
+
+func Test_ExprBuilder_BuildExpr(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	builder := New(WithDatabaseType(SQLite))
+	expr := Field("name").Eq("test").And(Func("contains", Field("testValue"), "x"))
+
+	// Act
+	var err error
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var dbQuery *gorm.DB
+		dbQuery, err = builder.BuildExpr(expr, tx)
+		return dbQuery.Find(&MockModel{})
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE name = \"test\" AND test_value LIKE \"%x%\"", sqlQuery)
+}
@@ -0,0 +1,90 @@
+package gormodata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// exprKindNames
+// are the names of the expression kinds in JSON, they are part of the stored format and must not change
+var exprKindNames = map[ExprKind]string{
+	LogicalExpr:    "logical",
+	ComparisonExpr: "comparison",
+	FunctionExpr:   "function",
+	PropertyExpr:   "property",
+	LiteralExpr:    "literal",
+	LambdaExpr:     "lambda",
+	ArithmeticExpr: "arithmetic",
+}
+
+func (e ExprKind) MarshalText() ([]byte, error) {
+	name, ok := exprKindNames[e]
+	if !ok {
+		return nil, fmt.Errorf("unknown expression kind %d", int(e))
+	}
+
+	return []byte(name), nil
+}
+
+func (e *ExprKind) UnmarshalText(text []byte) error {
+	for kind, name := range exprKindNames {
+		if name == string(text) {
+			*e = kind
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown expression kind '%s'", text)
+}
+
+// UnmarshalJSON
+// decodes an expression, numbers become int64 when they are integers and float64 otherwise like in Parse,
+// the string value of 'has' becomes an EnumLiteral and string values written as binary, geo, time or datetimeoffset literals
+// (e.g. "binary'00FF'") become a BinaryLiteral, GeoLiteral, TimeOfDayLiteral or time.Time
+//
+//	{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"name"},{"kind":"literal","value":"test"}]}
+func (e *Expr) UnmarshalJSON(data []byte) error {
+	// plainExpr has the fields of Expr without this method, so decoding it does not recurse
+	type plainExpr Expr
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var expr plainExpr
+	if err := decoder.Decode(&expr); err != nil {
+		return err
+	}
+
+	if number, ok := expr.Value.(json.Number); ok {
+		if value, err := number.Int64(); err == nil {
+			expr.Value = value
+		} else if value, err := number.Float64(); err == nil {
+			expr.Value = value
+		}
+	}
+
+	if literal, ok := expr.Value.(string); ok && expr.Kind == LiteralExpr {
+		switch {
+		case isBinaryLiteral(literal):
+			expr.Value = binaryLiteral(literal)
+		case isGeoLiteral(literal):
+			expr.Value = geoLiteral(literal)
+		case isTimeOfDayLiteral(literal):
+			expr.Value = timeOfDayLiteral(literal)
+		case isDateTimeOffsetLiteral(literal):
+			expr.Value, _ = dateTimeOffsetLiteral(literal)
+		}
+	}
+
+	if expr.Kind == ComparisonExpr && expr.Op == "has" && len(expr.Args) == 2 && expr.Args[1] != nil {
+		if literal, ok := expr.Args[1].Value.(string); ok && expr.Args[1].Kind == LiteralExpr {
+			expr.Args[1].Value = EnumLiteral(literal)
+		}
+	}
+
+	*e = Expr(expr)
+
+	return nil
+}
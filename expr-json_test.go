@@ -0,0 +1,166 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Expr_MarshalJSON(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	expr, _ := Parse("name eq 'test' and (length(name) gt 3 or not(contains(metadata/name,'prd'))) and score le 2.5 and deletedAt eq null")
+
+	// Act
+	data, err := json.Marshal(expr)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"logical","op":"and","args":[
+		{"kind":"logical","op":"and","args":[
+			{"kind":"logical","op":"and","args":[
+				{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"name"},{"kind":"literal","value":"test"}]},
+				{"kind":"logical","op":"or","args":[
+					{"kind":"comparison","op":"gt","args":[{"kind":"function","func":"length","args":[{"kind":"property","property":"name"}]},{"kind":"literal","value":3}]},
+					{"kind":"logical","op":"not","args":[{"kind":"function","func":"contains","args":[{"kind":"property","property":"metadata/name"},{"kind":"literal","value":"prd"}]}]}
+				]}
+			]},
+			{"kind":"comparison","op":"le","args":[{"kind":"property","property":"score"},{"kind":"literal","value":2.5}]}
+		]},
+		{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"deletedAt"},{"kind":"literal"}]}
+	]}`, string(data))
+}
+
+func Test_Expr_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+	}{
+		"strings and functions": {
+			queryString: "name eq 'test' and (length(name) gt 3 or not(contains(metadata/name,'prd')))",
+		},
+		"numbers, booleans and null": {
+			queryString: "score le 2.5 and age gt 3 and active eq true and deletedAt eq null",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expectedExpr, _ := Parse(testData.queryString)
+			data, _ := json.Marshal(expectedExpr)
+
+			// Act
+			var expr *Expr
+			err := json.Unmarshal(data, &expr)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, expectedExpr, expr)
+		})
+	}
+}
+
+func Test_Expr_UnmarshalJSONUnknownKind(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	var expr *Expr
+	err := json.Unmarshal([]byte(`{"kind":"subquery"}`), &expr)
+
+	// Assert
+	assert.EqualError(t, err, "unknown expression kind 'subquery'")
+}
+
+func Test_Builder_BuildExpr(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		exprJSON    string
+		opts        []Option
+		expectedSql string
+		expectedErr string
+		expectedIs  error
+	}{
+		"comparison": {
+			exprJSON:    `{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"name"},{"kind":"literal","value":"test"}]}`,
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"logical and functions": {
+			exprJSON: `{"kind":"logical","op":"or","args":[
+				{"kind":"function","func":"contains","args":[{"kind":"property","property":"testValue"},{"kind":"literal","value":"prd"}]},
+				{"kind":"logical","op":"not","args":[{"kind":"comparison","op":"gt","args":[{"kind":"function","func":"length","args":[{"kind":"property","property":"name"}]},{"kind":"literal","value":3}]}]}
+			]}`,
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value LIKE \"%prd%\" OR LENGTH(name) <= 3",
+		},
+		"validated": {
+			exprJSON:    `{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"testValue"},{"kind":"literal","value":"test"}]}`,
+			opts:        []Option{WithAllowedFields("name")},
+			expectedErr: "invalid query: field 'testValue' is not allowed",
+			expectedIs:  ErrFieldNotAllowed,
+		},
+		"disabled function": {
+			exprJSON:    `{"kind":"comparison","op":"gt","args":[{"kind":"function","func":"length","args":[{"kind":"property","property":"name"}]},{"kind":"literal","value":3}]}`,
+			opts:        []Option{WithDisabledFunctions("length")},
+			expectedErr: "invalid query: function 'length' is disabled",
+			expectedIs:  ErrFunctionNotAllowed,
+		},
+		"unknown function": {
+			exprJSON:    `{"kind":"function","func":"soundex","args":[{"kind":"property","property":"name"}]}`,
+			expectedErr: "invalid query: unknown function 'soundex'",
+			expectedIs:  ErrUnknownFunction,
+		},
+		"unknown operator": {
+			exprJSON:    `{"kind":"comparison","op":"like","args":[{"kind":"property","property":"name"},{"kind":"literal","value":"test"}]}`,
+			expectedErr: "invalid query: unknown comparison operator 'like'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"missing argument": {
+			exprJSON:    `{"kind":"comparison","op":"eq","args":[{"kind":"property","property":"name"}]}`,
+			expectedErr: "invalid query: 'eq' expects 2 arguments, got 1",
+			expectedIs:  ErrInvalidSyntax,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.opts...)...)
+			var expr *Expr
+			_ = json.Unmarshal([]byte(testData.exprJSON), &expr)
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.BuildExpr(expr, tx)
+				return dbQuery.Find(&MockModel{})
+			})
+
+			// Assert
+			if testData.expectedErr != "" {
+				assert.EqualError(t, err, testData.expectedErr)
+				assert.True(t, errors.Is(err, testData.expectedIs))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
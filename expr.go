@@ -0,0 +1,454 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// ExprKind
+// is the kind of node of a parsed filter (see Expr)
+type ExprKind int
+
+const (
+	// LogicalExpr combines or negates filters, Op is "and", "or" or "not"
+	LogicalExpr ExprKind = iota
+
+	// ComparisonExpr compares its two Args, Op is "eq", "ne", "lt", "le", "gt", "ge" or "in",
+	// the second Arg of "in" is a literal with the list of values as a []any,
+	// or "has" to check the flags of the first Arg with the EnumLiteral of the second
+	ComparisonExpr
+
+	// FunctionExpr calls the function Func with its Args (e.g. contains, length, concat), the unary minus is the function "-"
+	FunctionExpr
+
+	// PropertyExpr refers to the Property of the model, relation paths are separated by '/' (e.g. "metadata/name"),
+	// or to a property of an entity of another entity set (e.g. "$root/Products('abc')/price", see WithRootEntitySets)
+	PropertyExpr
+
+	// LiteralExpr is a literal Value from the filter
+	LiteralExpr
+
+	// LambdaExpr checks the elements of a collection, Op is "any" or "all", Args are the collection and the condition,
+	// the properties of the elements in the condition start with the collection instead of the range variable (e.g. "addresses/city")
+	LambdaExpr
+
+	// ArithmeticExpr computes a number from its two Args, Op is "add", "sub", "mul", "div", "divby" or "mod"
+	ArithmeticExpr
+)
+
+func (e ExprKind) String() string {
+	switch e {
+	case LogicalExpr:
+		return "Logical"
+	case ComparisonExpr:
+		return "Comparison"
+	case FunctionExpr:
+		return "Function"
+	case PropertyExpr:
+		return "Property"
+	case LiteralExpr:
+		return "Literal"
+	case LambdaExpr:
+		return "Lambda"
+	case ArithmeticExpr:
+		return "Arithmetic"
+	default:
+		return "Unknown"
+	}
+}
+
+// Expr
+// is a node of a parsed filter (see Parse)
+//
+//	name eq 'test' and length(name) gt 3
+//
+//	{Kind: LogicalExpr, Op: "and", Args: [
+//		{Kind: ComparisonExpr, Op: "eq", Args: [{Kind: PropertyExpr, Property: "name"}, {Kind: LiteralExpr, Value: "test"}]},
+//		{Kind: ComparisonExpr, Op: "gt", Args: [
+//			{Kind: FunctionExpr, Func: "length", Args: [{Kind: PropertyExpr, Property: "name"}]},
+//			{Kind: LiteralExpr, Value: int64(3)},
+//		]},
+//	]}
+type Expr struct {
+	Kind ExprKind `json:"kind"`
+
+	// Op is the operator of logical, comparison and lambda expressions
+	Op string `json:"op,omitempty"`
+
+	// Func is the name of the function of function expressions
+	Func string `json:"func,omitempty"`
+
+	// Args are the operands of logical and comparison expressions and the arguments of function expressions
+	Args []*Expr `json:"args,omitempty"`
+
+	// Property is the property of property expressions
+	Property string `json:"property,omitempty"`
+
+	// Value is the value of literal expressions:
+	// a string for quoted literals, int64 or float64 for numbers, bool for true and false, nil for null
+	// and the literal as it is written for anything else (e.g. 2025-01-01)
+	Value any `json:"value,omitempty"`
+}
+
+// Parse
+// parses an odata filter into an Expr, to handle parts of the filter in the application itself
+func Parse(query string) (*Expr, error) {
+	tree, err := GetAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return newExpr(tree.Root), nil
+}
+
+// newExpr
+// converts a node of the syntax tree and its children into an Expr
+func newExpr(node *syntaxtree.Node) *Expr {
+	switch {
+	case node.Value == "not" && node.Type == syntaxtree.UnaryOperator:
+		return &Expr{Kind: LogicalExpr, Op: node.Value, Args: []*Expr{newExpr(node.LeftChild)}}
+	case isLambdaNode(node):
+		return &Expr{Kind: LambdaExpr, Op: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(node.RightChild)}}
+	case isNowCall(node):
+		return &Expr{Kind: FunctionExpr, Func: node.Value}
+	case node.Type == syntaxtree.UnaryOperator:
+		return &Expr{Kind: FunctionExpr, Func: node.Value, Args: []*Expr{newExpr(node.LeftChild)}}
+	case node.Type == syntaxtree.Operator && node.Value == regexReplaceFunction:
+		arguments := node.RightChild
+
+		return &Expr{Kind: FunctionExpr, Func: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(arguments.LeftChild), newExpr(arguments.RightChild)}}
+	case (node.Type == syntaxtree.Operator && odataParser.isBinaryFunction(node.Value)) || isRoundNode(node):
+		return &Expr{Kind: FunctionExpr, Func: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(node.RightChild)}}
+	case node.Type == syntaxtree.Operator && (node.Value == "and" || node.Value == "or"):
+		return &Expr{Kind: LogicalExpr, Op: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(node.RightChild)}}
+	case isArithmeticNode(node):
+		return &Expr{Kind: ArithmeticExpr, Op: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(node.RightChild)}}
+	case node.Type == syntaxtree.Operator:
+		return &Expr{Kind: ComparisonExpr, Op: node.Value, Args: []*Expr{newExpr(node.LeftChild), newExpr(node.RightChild)}}
+	case isPropertyNode(node) || isRootReference(node.Value):
+		return &Expr{Kind: PropertyExpr, Property: node.Value}
+	default:
+		return &Expr{Kind: LiteralExpr, Value: literalValue(node.Value)}
+	}
+}
+
+// literalValue
+// returns the go value of a literal from the filter
+func literalValue(literal string) any {
+	if isStringLiteral(literal) {
+		return unquote(literal)
+	}
+	if isListLiteral(literal) {
+		elements := listLiteralElements(literal)
+		values := make([]any, len(elements))
+		for index, element := range elements {
+			values[index] = literalValue(element)
+		}
+
+		return values
+	}
+	if isEnumLiteral(literal) {
+		return EnumLiteral(literal)
+	}
+	if isBinaryLiteral(literal) {
+		return binaryLiteral(literal)
+	}
+	if isGeoLiteral(literal) {
+		return geoLiteral(literal)
+	}
+	if isTimeOfDayLiteral(literal) {
+		return timeOfDayLiteral(literal)
+	}
+	if value, ok := dateTimeOffsetLiteral(literal); ok {
+		return value
+	}
+
+	switch literal {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if value, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return value
+	}
+	if value, ok := numericSuffixValue(literal); ok {
+		return value
+	}
+	if value, err := strconv.ParseFloat(literal, 64); err == nil {
+		return value
+	}
+
+	return literal
+}
+
+// syntaxTree
+// converts the expression into a syntax tree that can be validated and built like a parsed filter
+func (e *Expr) syntaxTree() (*syntaxtree.SyntaxTree, error) {
+	tree := odataParser.newTree(nil, nil)
+
+	root, err := e.node(tree, nil, syntaxtree.LeftOperand)
+	if err != nil {
+		return nil, err
+	}
+	tree.Root = root
+
+	return tree, nil
+}
+
+// node
+// converts the expression into a node of the tree, operands get the given operand type
+func (e *Expr) node(tree *syntaxtree.SyntaxTree, parent *syntaxtree.Node, operandType syntaxtree.NodeType) (*syntaxtree.Node, error) {
+	if e == nil {
+		return nil, &InvalidQueryError{
+			Msg: "expression is missing",
+			Err: ErrInvalidSyntax,
+		}
+	}
+
+	node := &syntaxtree.Node{
+		Id:     len(tree.Nodes),
+		Parent: parent,
+	}
+	tree.Nodes = append(tree.Nodes, node)
+
+	expectedArgs := 0
+	switch e.Kind {
+	case LogicalExpr:
+		node.Value, node.Type, expectedArgs = e.Op, syntaxtree.Operator, 2
+		if e.Op == "not" {
+			node.Type, expectedArgs = syntaxtree.UnaryOperator, 1
+		} else if e.Op != "and" && e.Op != "or" {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("unknown logical operator '%s'", e.Op),
+				Err: ErrUnsupportedOperator,
+			}
+		}
+		// Nested logical expressions are grouped like they would be in the filter
+		node.IsGroup = parent != nil
+	case ComparisonExpr:
+		node.Value, node.Type, expectedArgs = e.Op, syntaxtree.Operator, 2
+		if !slices.Contains(comparisonOperators, e.Op) && e.Op != inOperator && e.Op != "has" {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("unknown comparison operator '%s'", e.Op),
+				Err: ErrUnsupportedOperator,
+			}
+		}
+	case ArithmeticExpr:
+		node.Value, node.Type, expectedArgs = e.Op, syntaxtree.Operator, 2
+		if !slices.Contains(arithmeticOperators, e.Op) {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("unknown arithmetic operator '%s'", e.Op),
+				Err: ErrUnsupportedOperator,
+			}
+		}
+	case LambdaExpr:
+		node.Value, node.Type, expectedArgs = e.Op, syntaxtree.Operator, 2
+		if !isLambdaOperator(e.Op) {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("unknown lambda operator '%s'", e.Op),
+				Err: ErrUnsupportedOperator,
+			}
+		}
+		if len(e.Args) > 0 && (e.Args[0] == nil || e.Args[0].Kind != PropertyExpr) {
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("'%s' expects a collection property as its first argument", e.Op),
+				Err: ErrInvalidSyntax,
+			}
+		}
+	case FunctionExpr:
+		node.Value = e.Func
+		switch {
+		case e.Func == nowFunction:
+			node.Type = syntaxtree.UnaryOperator
+		case e.Func == regexReplaceFunction:
+			node.Type, expectedArgs = syntaxtree.Operator, 3
+		case e.Func == roundFunction && len(e.Args) == 2:
+			node.Type, expectedArgs = syntaxtree.Operator, 2
+		case odataParser.isBinaryFunction(e.Func):
+			node.Type, expectedArgs = syntaxtree.Operator, 2
+		case odataParser.isUnaryFunction(e.Func) && e.Func != "not":
+			node.Type, expectedArgs = syntaxtree.UnaryOperator, 1
+		default:
+			return nil, &InvalidQueryError{
+				Msg: fmt.Sprintf("unknown function '%s'", e.Func),
+				Err: ErrUnknownFunction,
+			}
+		}
+	case PropertyExpr:
+		if e.Property == "" {
+			return nil, &InvalidQueryError{
+				Msg: "property expression without a property",
+				Err: ErrInvalidSyntax,
+			}
+		}
+		node.Value, node.Type = e.Property, operandType
+	case LiteralExpr:
+		node.Value, node.Type = literalString(e.Value), operandType
+	default:
+		return nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("unknown expression kind '%s'", e.Kind),
+			Err: ErrInvalidSyntax,
+		}
+	}
+
+	if len(e.Args) != expectedArgs {
+		return nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("'%s' expects %d arguments, got %d", node.Value, expectedArgs, len(e.Args)),
+			Err: ErrInvalidSyntax,
+		}
+	}
+
+	// now() has no arguments, it gets the empty operand the parser gives it (see tokenize)
+	if e.Kind == FunctionExpr && e.Func == nowFunction {
+		node.LeftChild = &syntaxtree.Node{Id: len(tree.Nodes), Parent: node, Type: syntaxtree.LeftOperand}
+		tree.Nodes = append(tree.Nodes, node.LeftChild)
+	}
+
+	var err error
+	if expectedArgs > 0 {
+		if node.LeftChild, err = e.Args[0].node(tree, node, syntaxtree.LeftOperand); err != nil {
+			return nil, err
+		}
+	}
+	if expectedArgs == 2 {
+		if node.RightChild, err = e.Args[1].node(tree, node, syntaxtree.RightOperand); err != nil {
+			return nil, err
+		}
+	}
+	// The pattern and the replacement of regexreplace are the arguments of an inner node (see tokenize)
+	if expectedArgs == 3 {
+		if node.RightChild, err = regexReplaceArgumentsNode(tree, node, e.Args[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// literalString
+// returns the literal as it is written in a filter, strings are quoted
+func literalString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(value)
+	case EnumLiteral:
+		return string(value)
+	case BinaryLiteral:
+		return value.String()
+	case GeoLiteral:
+		return value.String()
+	case TimeOfDayLiteral:
+		return value.String()
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		elements := make([]string, len(value))
+		for index, element := range value {
+			elements[index] = literalString(element)
+		}
+
+		return "(" + strings.Join(elements, ",") + ")"
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32) + "F"
+	case DecimalLiteral:
+		return string(value) + "M"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// String
+// returns the expression as a canonical odata filter, parsing the filter again results in the same expression
+//
+//	name eq 'test' and (length(name) gt 3 or not(contains(metadata/name,'prd'))) and addresses/any(a:a/city eq 'Ghent')
+func (e *Expr) String() string {
+	return e.format(nil)
+}
+
+// format
+// returns the expression as an odata filter, the properties of the elements of the lambdas of the scope are written with their range variable
+func (e *Expr) format(scope *lambdaScope) string {
+	if e == nil {
+		return ""
+	}
+
+	switch e.Kind {
+	case LogicalExpr:
+		if e.Op == "not" && len(e.Args) == 1 {
+			return fmt.Sprintf("not(%s)", e.Args[0].format(scope))
+		}
+		if len(e.Args) == 2 {
+			return fmt.Sprintf("%s %s %s", e.Args[0].operandString(e.Op, false, scope), e.Op, e.Args[1].operandString(e.Op, true, scope))
+		}
+	case ComparisonExpr, ArithmeticExpr:
+		if len(e.Args) == 2 {
+			return fmt.Sprintf("%s %s %s", e.Args[0].operandString(e.Op, false, scope), e.Op, e.Args[1].operandString(e.Op, true, scope))
+		}
+	case FunctionExpr:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.format(scope)
+		}
+
+		return fmt.Sprintf("%s(%s)", e.Func, strings.Join(args, ","))
+	case LambdaExpr:
+		if len(e.Args) == 2 && e.Args[0] != nil && e.Args[0].Kind == PropertyExpr {
+			reserved := map[string]bool{}
+			e.Args[1].modelRoots(&lambdaScope{collection: e.Args[0].Property, outer: scope}, reserved)
+			lambda := scope.withVariable(e.Args[0].Property, reserved)
+
+			return fmt.Sprintf("%s/%s(%s:%s)", e.Args[0].format(scope), e.Op, lambda.variable, e.Args[1].format(lambda))
+		}
+	case PropertyExpr:
+		return scope.unresolve(e.Property)
+	case LiteralExpr:
+		return literalString(e.Value)
+	}
+
+	return fmt.Sprintf("<invalid %s expression>", e.Kind)
+}
+
+// modelRoots
+// adds the first segments of the properties of the model in the expression to the roots,
+// the properties of the elements of the lambdas of the scope are skipped
+func (e *Expr) modelRoots(scope *lambdaScope, roots map[string]bool) {
+	if e == nil {
+		return
+	}
+	if e.Kind == PropertyExpr && scope.element(e.Property) == nil {
+		root, _, _ := strings.Cut(e.Property, "/")
+		roots[root] = true
+	}
+	for _, arg := range e.Args {
+		arg.modelRoots(scope, roots)
+	}
+}
+
+// operandString
+// returns the expression as the operand of a binary operator,
+// in brackets when it would otherwise be parsed with a different operator precedence
+func (e *Expr) operandString(parentOp string, rightOperand bool, scope *lambdaScope) string {
+	if e == nil || (e.Kind != LogicalExpr && e.Kind != ComparisonExpr && e.Kind != ArithmeticExpr) || e.Op == "not" {
+		return e.format(scope)
+	}
+
+	precedence, parentPrecedence := odataPrecedence[e.Op], odataPrecedence[parentOp]
+	if precedence < parentPrecedence || (rightOperand && precedence == parentPrecedence) {
+		return "(" + e.format(scope) + ")"
+	}
+
+	return e.format(scope)
+}
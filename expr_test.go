@@ -0,0 +1,173 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+func exprProperty(name string) *Expr {
+	return &Expr{Kind: PropertyExpr, Property: name}
+}
+
+func exprLiteral(value any) *Expr {
+	return &Expr{Kind: LiteralExpr, Value: value}
+}
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedExpr *Expr
+	}{
+		"comparison": {
+			queryString:  "name eq 'test'",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("name"), exprLiteral("test")}},
+		},
+		"logical": {
+			queryString: "name eq 'test' and (age gt 5 or score le 2.5)",
+			expectedExpr: &Expr{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+				{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("name"), exprLiteral("test")}},
+				{Kind: LogicalExpr, Op: "or", Args: []*Expr{
+					{Kind: ComparisonExpr, Op: "gt", Args: []*Expr{exprProperty("age"), exprLiteral(int64(5))}},
+					{Kind: ComparisonExpr, Op: "le", Args: []*Expr{exprProperty("score"), exprLiteral(2.5)}},
+				}},
+			}},
+		},
+		"not": {
+			queryString: "not(contains(metadata/name,'prd'))",
+			expectedExpr: &Expr{Kind: LogicalExpr, Op: "not", Args: []*Expr{
+				{Kind: FunctionExpr, Func: "contains", Args: []*Expr{exprProperty("metadata/name"), exprLiteral("prd")}},
+			}},
+		},
+		"functions": {
+			queryString: "length(concat('prefix',tolower(name))) ne null",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "ne", Args: []*Expr{
+				{Kind: FunctionExpr, Func: "length", Args: []*Expr{
+					{Kind: FunctionExpr, Func: "concat", Args: []*Expr{
+						exprLiteral("prefix"),
+						{Kind: FunctionExpr, Func: "tolower", Args: []*Expr{exprProperty("name")}},
+					}},
+				}},
+				exprLiteral(nil),
+			}},
+		},
+		"unquoted literals": {
+			queryString: "active eq true and createdAt ge 2025-01-01",
+			expectedExpr: &Expr{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+				{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("active"), exprLiteral(true)}},
+				{Kind: ComparisonExpr, Op: "ge", Args: []*Expr{exprProperty("createdAt"), exprLiteral("2025-01-01")}},
+			}},
+		},
+		"in": {
+			queryString:  "name in ('a', 'b,c', 3)",
+			expectedExpr: &Expr{Kind: ComparisonExpr, Op: "in", Args: []*Expr{exprProperty("name"), exprLiteral([]any{"a", "b,c", int64(3)})}},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			expr, err := Parse(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedExpr, expr)
+		})
+	}
+}
+
+func Test_Parse_Error(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("length(name")
+
+	// Assert
+	assert.Nil(t, expr)
+	assert.True(t, errors.Is(err, ErrUnbalancedParens))
+}
+
+func Test_Expr_String(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedString string
+	}{
+		"comparison": {
+			queryString:    "name eq 'test'",
+			expectedString: "name eq 'test'",
+		},
+		"redundant brackets": {
+			queryString:    "((name eq 'test') and (testValue ne 'prd'))",
+			expectedString: "name eq 'test' and testValue ne 'prd'",
+		},
+		"precedence": {
+			queryString:    "name eq 'test' and (testValue eq 'prd' or testValue eq 'acc')",
+			expectedString: "name eq 'test' and (testValue eq 'prd' or testValue eq 'acc')",
+		},
+		"right nested operator": {
+			queryString:    "name eq 'a' or (name eq 'b' or name eq 'c')",
+			expectedString: "name eq 'a' or (name eq 'b' or name eq 'c')",
+		},
+		"functions": {
+			queryString:    "not(contains(concat(name, 'x'),'testx')) and length(tolower(metadata/name)) ge 2.5",
+			expectedString: "not(contains(concat(name,'x'),'testx')) and length(tolower(metadata/name)) ge 2.5",
+		},
+		"null and booleans": {
+			queryString:    "deletedAt eq null and active ne false",
+			expectedString: "deletedAt eq null and active ne false",
+		},
+		"in": {
+			queryString:    "name in ('a', 'it''s') or not(testValue in (1,2))",
+			expectedString: "name in ('a','it''s') or not(testValue in (1,2))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expr, _ := Parse(testData.queryString)
+
+			// Act
+			result := expr.String()
+
+			// Assert
+			assert.Equal(t, testData.expectedString, result)
+
+			reparsedExpr, err := Parse(result)
+			assert.NoError(t, err)
+			assert.Equal(t, expr, reparsedExpr)
+		})
+	}
+}
+
+func Test_Expr_StringConstructed(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	expr := &Expr{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+		{Kind: FunctionExpr, Func: "startswith", Args: []*Expr{exprProperty("name"), exprLiteral("test")}},
+		{Kind: LogicalExpr, Op: "or", Args: []*Expr{
+			{Kind: ComparisonExpr, Op: "gt", Args: []*Expr{exprProperty("age"), exprLiteral(int64(18))}},
+			{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("guardian"), exprLiteral(true)}},
+		}},
+	}}
+
+	// Act
+	result := expr.String()
+
+	// Assert
+	assert.Equal(t, "startswith(name,'test') and (age gt 18 or guardian eq true)", result)
+}
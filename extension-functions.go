@@ -0,0 +1,58 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// extensionFunctions
+// are the functions that are not part of odata, filters can only use them when they are enabled with WithExtensionFunctions
+var extensionFunctions = map[string]bool{
+	"soundslike":         true,
+	levenshteinFunction:  true,
+	regexReplaceFunction: true,
+	powerFunction:        true,
+	sqrtFunction:         true,
+}
+
+// WithExtensionFunctions
+// enables functions that are not part of odata (e.g. "soundslike"), filters that use an extension function
+// that is not enabled fail with an InvalidQueryError wrapping ErrFunctionNotAllowed
+//
+//	builder := gormodata.New(gormodata.WithExtensionFunctions("soundslike"))
+//	dbQuery, err := builder.Build("soundslike(lastName,'smith')", db.Model(&Person{}))
+func WithExtensionFunctions(functions ...string) Option {
+	return func(b *Builder) {
+		if b.extensionFunctions == nil {
+			b.extensionFunctions = make(map[string]bool, len(functions))
+		}
+		for _, function := range functions {
+			b.extensionFunctions[strings.ToLower(function)] = true
+		}
+	}
+}
+
+// extensionFunctionValidation
+// returns a QueryValidation that rejects the calls of extension functions that are not enabled
+func extensionFunctionValidation(enabled map[string]bool) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			isCall := currentNode.Type == syntaxtree.Operator || currentNode.Type == syntaxtree.UnaryOperator
+			if !isCall || !extensionFunctions[currentNode.Value] || enabled[currentNode.Value] {
+				return nil
+			}
+
+			return &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' is an extension function that is not enabled, enable it with WithExtensionFunctions", currentNode.Value),
+				Err:        ErrFunctionNotAllowed,
+				Expression: nodeExpression(currentNode),
+				Node:       currentNode,
+			}
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
@@ -0,0 +1,328 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FilterIR
+// is a filter translated for a model without SQL or gorm types (see Translate),
+// so data layers other than gorm (ent, sqlc, search engines, ...) can build their own queries from the same odata filters
+//
+//	name eq 'test' and metadata/name ne 'prd'
+//
+//	{Condition: {Op: "and", Conditions: [
+//		{Op: "eq", Operands: [{Column: {Table: "mock_models", Name: "name"}}, {Param: 0}]},
+//		{Op: "ne", Operands: [{Column: {Relations: [{Name: "Metadata", ...}], Table: "metadata", Name: "name"}}, {Param: 1}]},
+//	]}, Params: ["test", "prd"]}
+type FilterIR struct {
+	Condition *IRCondition `json:"condition"`
+
+	// Params are the bind values of the literals of the filter, in the order they appear in the filter
+	Params []any `json:"params"`
+}
+
+// IRCondition
+// is a condition of a translated filter:
+// "and", "or" and "not" combine Conditions, comparisons ("eq", "ne", "lt", "le", "gt", "ge")
+// and boolean functions ("contains", "startswith", "endswith") have two Operands
+type IRCondition struct {
+	Op string `json:"op"`
+
+	// Conditions are the conditions that are combined by "and" and "or", or negated by "not"
+	Conditions []*IRCondition `json:"conditions,omitempty"`
+
+	// Operands are the operands of comparisons and boolean functions
+	Operands []*IROperand `json:"operands,omitempty"`
+}
+
+// IROperand
+// is an operand of a condition, a column, a function call or a bind value
+type IROperand struct {
+	Column *IRColumn `json:"column,omitempty"`
+
+	// Func is the function that is called with Args (e.g. "length", "tolower", "concat")
+	Func string       `json:"func,omitempty"`
+	Args []*IROperand `json:"args,omitempty"`
+
+	// Param is the index of the bind value in FilterIR.Params, nil if the operand is not a literal
+	Param *int `json:"param,omitempty"`
+}
+
+// IRColumn
+// is a column of the model or of a related model
+type IRColumn struct {
+	// Property is the property as it is written in the filter (e.g. "metadata/name")
+	Property string `json:"property"`
+
+	// Relations are the relations from the model to the table of the column, empty for columns of the model itself
+	Relations []IRRelation `json:"relations,omitempty"`
+
+	Table string `json:"table"`
+	Name  string `json:"name"`
+}
+
+// IRRelation
+// is a relation on the path to a column, with the columns that join the tables
+type IRRelation struct {
+	// Name is the name of the relation field (e.g. "Metadata")
+	Name string `json:"name"`
+
+	// Type is the gorm type of the relation: "belongs_to", "has_one", "has_many" or "many_to_many"
+	Type string `json:"type"`
+
+	// Table is the table of the related model
+	Table string `json:"table"`
+
+	// OwnerColumns of the table of the owner match RelatedColumns of the related table,
+	// through JoinTable for many to many relations
+	OwnerColumns   []string `json:"ownerColumns"`
+	RelatedColumns []string `json:"relatedColumns"`
+
+	// JoinTable is the join table of many to many relations, JoinOwnerColumns match OwnerColumns and JoinRelatedColumns match RelatedColumns
+	JoinTable          string   `json:"joinTable,omitempty"`
+	JoinOwnerColumns   []string `json:"joinOwnerColumns,omitempty"`
+	JoinRelatedColumns []string `json:"joinRelatedColumns,omitempty"`
+
+	// PolymorphicColumn of the related table must be PolymorphicValue for polymorphic relations (gorm:"polymorphic:Owner")
+	PolymorphicColumn string `json:"polymorphicColumn,omitempty"`
+	PolymorphicValue  string `json:"polymorphicValue,omitempty"`
+}
+
+// Translate
+// parses the filter, checks it against the model like Validate and returns it as a FilterIR, without a database connection
+//
+//	filter, err := gormodata.Translate("name eq 'test'", MyModel{})
+//	// filter.Condition: {Op: "eq", Operands: [{Column: {Table: "my_models", Name: "name"}}, {Param: 0}]}, filter.Params: []any{"test"}
+//
+// the literals compared with uuid and time columns are converted to uuid.UUID and time.Time like they are in the queries of the Builder
+func Translate(query string, model any, opts ...Option) (*FilterIR, error) {
+	return New(opts...).Translate(query, model)
+}
+
+// Translate
+// parses the filter, checks it against the model with the options of the Builder and returns it as a FilterIR (see Translate)
+func (b *Builder) Translate(query string, model any) (*FilterIR, error) {
+	if err := b.Validate(query, model); err != nil {
+		return nil, err
+	}
+
+	expr, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(nil, &gorm.Config{DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return nil, err
+	}
+
+	translator := &irTranslator{namer: db.NamingStrategy, schema: statement.Schema, filter: &FilterIR{Params: []any{}}}
+	if translator.filter.Condition, err = translator.condition(expr); err != nil {
+		return nil, err
+	}
+
+	return translator.filter, nil
+}
+
+// irTranslator
+// translates the expressions of a filter on the schema of a model into a FilterIR
+type irTranslator struct {
+	namer  schema.Namer
+	schema *schema.Schema
+	filter *FilterIR
+}
+
+// condition
+// translates a logical expression, a comparison or a boolean function
+func (t *irTranslator) condition(expr *Expr) (*IRCondition, error) {
+	switch expr.Kind {
+	case LogicalExpr:
+		condition := &IRCondition{Op: expr.Op}
+		for _, arg := range expr.Args {
+			nested, err := t.condition(arg)
+			if err != nil {
+				return nil, err
+			}
+			condition.Conditions = append(condition.Conditions, nested)
+		}
+
+		return condition, nil
+	case ComparisonExpr, FunctionExpr:
+		if expr.Op == "has" {
+			return nil, &InvalidQueryError{
+				Msg:        fmt.Sprintf("flag check '%s' cannot be translated, only conditions on columns can", expr),
+				Err:        ErrUnsupportedOperator,
+				Expression: expr.String(),
+			}
+		}
+
+		op := expr.Op
+		if expr.Kind == FunctionExpr {
+			op = expr.Func
+		}
+
+		// Literals are converted to the type of the column they are compared with, the arguments of functions keep their own type
+		var field *schema.Field
+		for _, arg := range expr.Args {
+			if expr.Kind == ComparisonExpr && arg.Kind == PropertyExpr && !isRootReference(arg.Property) {
+				_, field = t.column(arg.Property)
+			}
+		}
+
+		condition := &IRCondition{Op: op}
+		for _, arg := range expr.Args {
+			operand, err := t.operand(arg, field)
+			if err != nil {
+				return nil, err
+			}
+			condition.Operands = append(condition.Operands, operand)
+		}
+
+		return condition, nil
+	case LambdaExpr:
+		return nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("lambda '%s' cannot be translated, only conditions on columns can", expr),
+			Err:        ErrUnsupportedOperator,
+			Expression: expr.String(),
+		}
+	default:
+		return nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' is not a condition", expr),
+			Err:        ErrInvalidSyntax,
+			Expression: expr.String(),
+		}
+	}
+}
+
+// operand
+// translates a property, a literal or a function call, literals are converted to the type of the field if it is not nil
+func (t *irTranslator) operand(expr *Expr, field *schema.Field) (*IROperand, error) {
+	switch expr.Kind {
+	case PropertyExpr:
+		if isRootReference(expr.Property) {
+			return nil, &InvalidQueryError{
+				Msg:        fmt.Sprintf("reference '%s' cannot be translated, only columns of the model can", expr.Property),
+				Err:        ErrUnsupportedOperator,
+				Expression: expr.Property,
+			}
+		}
+
+		column, _ := t.column(expr.Property)
+
+		return &IROperand{Column: column}, nil
+	case LiteralExpr:
+		value := expr.Value
+		if binary, ok := value.(BinaryLiteral); ok {
+			value = []byte(binary)
+		}
+		if field != nil && value != nil {
+			converted, err := convertLiteral(field, value)
+			if err != nil {
+				return nil, err
+			}
+			value = converted
+		}
+
+		param := len(t.filter.Params)
+		t.filter.Params = append(t.filter.Params, value)
+
+		return &IROperand{Param: &param}, nil
+	case FunctionExpr:
+		operand := &IROperand{Func: expr.Func}
+		for _, arg := range expr.Args {
+			translated, err := t.operand(arg, nil)
+			if err != nil {
+				return nil, err
+			}
+			operand.Args = append(operand.Args, translated)
+		}
+
+		return operand, nil
+	default:
+		return nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' is not an operand", expr),
+			Err:        ErrInvalidSyntax,
+			Expression: expr.String(),
+		}
+	}
+}
+
+// column
+// returns the column of the property with the relations to its table, and its field,
+// the property path is known to exist since the filter is validated against the schema
+func (t *irTranslator) column(property string) (*IRColumn, *schema.Field) {
+	path := strings.Split(property, "/")
+	for i, part := range path {
+		path[i] = t.namer.ColumnName("", part)
+	}
+
+	column := &IRColumn{Property: property}
+	current := t.schema
+	for len(path) > 1 {
+		if field := findEmbeddedField(t.namer, current, path); field != nil {
+			column.Table, column.Name = current.Table, field.DBName
+
+			return column, field
+		}
+
+		relationship := findRelationship(t.namer, current, path[0])
+		column.Relations = append(column.Relations, newIRRelation(relationship))
+		current, path = relationship.FieldSchema, path[1:]
+	}
+
+	field := current.LookUpField(path[0])
+	column.Table, column.Name = current.Table, field.DBName
+
+	return column, field
+}
+
+// newIRRelation
+// returns the relation with the columns that join its tables
+func newIRRelation(relationship *schema.Relationship) IRRelation {
+	relation := IRRelation{
+		Name:  relationship.Name,
+		Type:  string(relationship.Type),
+		Table: relationship.FieldSchema.Table,
+	}
+	if relationship.JoinTable != nil {
+		relation.JoinTable = relationship.JoinTable.Table
+	}
+
+	for _, reference := range relationship.References {
+		if reference.PrimaryKey == nil {
+			if reference.PrimaryValue != "" {
+				relation.PolymorphicColumn, relation.PolymorphicValue = reference.ForeignKey.DBName, reference.PrimaryValue
+			}
+
+			continue
+		}
+
+		switch relationship.Type {
+		case schema.BelongsTo:
+			relation.OwnerColumns = append(relation.OwnerColumns, reference.ForeignKey.DBName)
+			relation.RelatedColumns = append(relation.RelatedColumns, reference.PrimaryKey.DBName)
+		case schema.HasOne, schema.HasMany:
+			relation.OwnerColumns = append(relation.OwnerColumns, reference.PrimaryKey.DBName)
+			relation.RelatedColumns = append(relation.RelatedColumns, reference.ForeignKey.DBName)
+		case schema.Many2Many:
+			if reference.OwnPrimaryKey {
+				relation.OwnerColumns = append(relation.OwnerColumns, reference.PrimaryKey.DBName)
+				relation.JoinOwnerColumns = append(relation.JoinOwnerColumns, reference.ForeignKey.DBName)
+			} else {
+				relation.RelatedColumns = append(relation.RelatedColumns, reference.PrimaryKey.DBName)
+				relation.JoinRelatedColumns = append(relation.JoinRelatedColumns, reference.ForeignKey.DBName)
+			}
+		}
+	}
+
+	return relation
+}
@@ -0,0 +1,252 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Translate(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	metadata := IRRelation{
+		Name:           "Metadata",
+		Type:           "belongs_to",
+		Table:          "metadata",
+		OwnerColumns:   []string{"metadata_id"},
+		RelatedColumns: []string{"id"},
+	}
+
+	tests := map[string]struct {
+		queryString    string
+		model          any
+		expectedFilter *FilterIR
+	}{
+		"comparison": {
+			queryString: "name eq 'test'",
+			model:       MockModel{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "eq", Operands: []*IROperand{
+					{Column: &IRColumn{Property: "name", Table: "mock_models", Name: "name"}},
+					{Param: ptr(0)},
+				}},
+				Params: []any{"test"},
+			},
+		},
+		"logical operators and functions": {
+			queryString: "not(contains(name,'x')) or length(testValue) gt 3",
+			model:       &MockModel{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "or", Conditions: []*IRCondition{
+					{Op: "not", Conditions: []*IRCondition{
+						{Op: "contains", Operands: []*IROperand{
+							{Column: &IRColumn{Property: "name", Table: "mock_models", Name: "name"}},
+							{Param: ptr(0)},
+						}},
+					}},
+					{Op: "gt", Operands: []*IROperand{
+						{Func: "length", Args: []*IROperand{{Column: &IRColumn{Property: "testValue", Table: "mock_models", Name: "test_value"}}}},
+						{Param: ptr(1)},
+					}},
+				}},
+				Params: []any{"x", int64(3)},
+			},
+		},
+		"typed literals": {
+			queryString: "id eq '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6' and name eq null",
+			model:       MockModel{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "and", Conditions: []*IRCondition{
+					{Op: "eq", Operands: []*IROperand{
+						{Column: &IRColumn{Property: "id", Table: "mock_models", Name: "id"}},
+						{Param: ptr(0)},
+					}},
+					{Op: "eq", Operands: []*IROperand{
+						{Column: &IRColumn{Property: "name", Table: "mock_models", Name: "name"}},
+						{Param: ptr(1)},
+					}},
+				}},
+				Params: []any{uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), nil},
+			},
+		},
+		"time literal": {
+			queryString: "createdAt ge '2025-01-01'",
+			model:       MockTimeModel{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "ge", Operands: []*IROperand{
+					{Column: &IRColumn{Property: "createdAt", Table: "mock_time_models", Name: "created_at"}},
+					{Param: ptr(0)},
+				}},
+				Params: []any{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"relation path": {
+			queryString: "metadata/tag/value eq 'prd'",
+			model:       MockModel{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "eq", Operands: []*IROperand{
+					{Column: &IRColumn{
+						Property: "metadata/tag/value",
+						Relations: []IRRelation{metadata, {
+							Name:           "Tag",
+							Type:           "belongs_to",
+							Table:          "tags",
+							OwnerColumns:   []string{"tag_id"},
+							RelatedColumns: []string{"id"},
+						}},
+						Table: "tags",
+						Name:  "value",
+					}},
+					{Param: ptr(0)},
+				}},
+				Params: []any{"prd"},
+			},
+		},
+		"has many polymorphic relation": {
+			queryString: "toys/name eq 'ball'",
+			model:       Dog{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "eq", Operands: []*IROperand{
+					{Column: &IRColumn{
+						Property: "toys/name",
+						Relations: []IRRelation{{
+							Name:              "Toys",
+							Type:              "has_many",
+							Table:             "toys",
+							OwnerColumns:      []string{"id"},
+							RelatedColumns:    []string{"owner_id"},
+							PolymorphicColumn: "owner_type",
+							PolymorphicValue:  "dogs",
+						}},
+						Table: "toys",
+						Name:  "name",
+					}},
+					{Param: ptr(0)},
+				}},
+				Params: []any{"ball"},
+			},
+		},
+		"many to many relation": {
+			queryString: "labels/name eq 'go'",
+			model:       Article{},
+			expectedFilter: &FilterIR{
+				Condition: &IRCondition{Op: "eq", Operands: []*IROperand{
+					{Column: &IRColumn{
+						Property: "labels/name",
+						Relations: []IRRelation{{
+							Name:               "Labels",
+							Type:               "many_to_many",
+							Table:              "labels",
+							OwnerColumns:       []string{"id"},
+							RelatedColumns:     []string{"id"},
+							JoinTable:          "article_labels",
+							JoinOwnerColumns:   []string{"article_id"},
+							JoinRelatedColumns: []string{"label_id"},
+						}},
+						Table: "labels",
+						Name:  "name",
+					}},
+					{Param: ptr(0)},
+				}},
+				Params: []any{"go"},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := Translate(testData.queryString, testData.model)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedFilter, result)
+		})
+	}
+}
+
+func Test_Translate_Error(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		options       []Option
+		expectedError string
+		expectedIs    error
+	}{
+		"syntax error": {
+			queryString:   "name eq 'test",
+			expectedError: "failed to parse query: unterminated string literal 'test",
+			expectedIs:    ErrInvalidSyntax,
+		},
+		"unknown property": {
+			queryString:   "metadata/unknown eq 'test'",
+			expectedError: "invalid query: unknown column name 'unknown' on 'metadata'",
+			expectedIs:    ErrUnknownProperty,
+		},
+		"invalid uuid literal": {
+			queryString:   "id eq 'abc'",
+			expectedError: "invalid query: invalid uuid literal 'abc' for column 'id'",
+		},
+		"option of the builder": {
+			queryString:   "name eq 'test'",
+			options:       []Option{WithDeniedFields("name")},
+			expectedError: "invalid query: field 'name' is not allowed",
+			expectedIs:    ErrFieldNotAllowed,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := Translate(testData.queryString, MockModel{}, testData.options...)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, testData.expectedError)
+			if testData.expectedIs != nil {
+				assert.True(t, errors.Is(err, testData.expectedIs))
+			}
+		})
+	}
+}
+
+func Test_FilterIR_JSON(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	filter, err := Translate("metadata/name eq 'prd' and length(name) gt 3", MockModel{})
+	assert.NoError(t, err)
+
+	// Act
+	data, err := json.Marshal(filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"condition": {"op": "and", "conditions": [
+			{"op": "eq", "operands": [
+				{"column": {"property": "metadata/name", "relations": [
+					{"name": "Metadata", "type": "belongs_to", "table": "metadata", "ownerColumns": ["metadata_id"], "relatedColumns": ["id"]}
+				], "table": "metadata", "name": "name"}},
+				{"param": 0}
+			]},
+			{"op": "gt", "operands": [
+				{"func": "length", "args": [{"column": {"property": "name", "table": "mock_models", "name": "name"}}]},
+				{"param": 1}
+			]}
+		]},
+		"params": ["prd", 3]
+	}`, string(data))
+}
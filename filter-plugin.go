@@ -0,0 +1,93 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// FilterSetting
+// is the gorm setting with the filter that a Builder registered as gorm plugin adds to the queries of the db (see Builder.Initialize),
+// the filter is a filter string or an *Expr
+//
+//	db.Set(gormodata.FilterSetting, "name eq 'test'").Find(&results)
+const FilterSetting = "odata:filter"
+
+const (
+	filterPluginName = "gormodata:filter"
+
+	// filterAppliedSetting marks the statements the filter of FilterSetting was added to,
+	// so a statement that is executed again (e.g. Count and then Find) does not get the filter twice
+	filterAppliedSetting = "gormodata:filter_applied"
+)
+
+// Name
+// is the name of the gorm plugin of the Builder (see Initialize)
+func (b *Builder) Name() string {
+	return filterPluginName
+}
+
+// Initialize
+// registers the Builder as gorm plugin, which adds the filter of FilterSetting to the queries of the db,
+// so repositories can apply filters declaratively without building them themselves
+//
+//	err := db.Use(gormodata.New(gormodata.WithAllowedFields("name", "birthYear")))
+//	err = db.Set(gormodata.FilterSetting, queryString).Where("owner_id = ?", ownerID).Find(&pets).Error
+//
+// the filter is added as one group of conditions like Scope does and an invalid filter is returned as the error of the query,
+// the filter is only added to queries (e.g. Find, First, Count), not to updates or deletes
+func (b *Builder) Initialize(db *gorm.DB) error {
+	db, err := checkDbPlugins(db, b.qonvert)
+	if err != nil {
+		return err
+	}
+
+	// The filter adds nested filters, so it has to run before the plugin that resolves them, which runs before all other callbacks,
+	// callbacks that run before all others run in the reverse order they are registered in, so it is registered after that plugin
+	return db.Callback().Query().Before("*").Register(filterPluginName+":query", b.filterCallback)
+}
+
+func (b *Builder) filterCallback(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	filter, ok := db.Get(FilterSetting)
+	if !ok {
+		return
+	}
+	if _, applied := db.InstanceGet(filterAppliedSetting); applied {
+		return
+	}
+	db.InstanceSet(filterAppliedSetting, true)
+
+	var result *gorm.DB
+	var tree *syntaxtree.SyntaxTree
+	var err error
+
+	session := db.Session(&gorm.Session{NewDB: true})
+	switch filter := filter.(type) {
+	case string:
+		if strings.TrimSpace(filter) == "" {
+			return
+		}
+		result, tree, err = b.build(filter, session)
+	case *Expr:
+		result, tree, err = b.buildLogged(session, "expr", filter, func(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+			return b.buildExprTree(filter, db)
+		})
+	default:
+		err = fmt.Errorf("gorm setting '%s' must be a filter string or an *Expr, got %T", FilterSetting, filter)
+	}
+
+	if err != nil {
+		_ = db.AddError(err)
+
+		return
+	}
+
+	// The callback runs on the statement itself, so the conditions are added to it in place
+	b.applyQonvertConfig(b.applyStatementTimeout(b.applyQueryHints(db.Where(result), tree)))
+}
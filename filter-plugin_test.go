@@ -0,0 +1,172 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_Plugin(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query       func(db *gorm.DB) *gorm.DB
+		expectedSql string
+	}{
+		"no filter": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where("test_value = ?", "t")
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"t\"",
+		},
+		"filter string": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Set(FilterSetting, "name eq 'a' or name eq 'b'").Where("test_value = ?", "t")
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"t\" AND (name = \"a\" OR name = \"b\")",
+		},
+		"filter on a relation": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Set(FilterSetting, "metadata/name eq 'prd'")
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+		},
+		"filter expression": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Set(FilterSetting, Field("name").StartsWith("a"))
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name LIKE \"a%\"",
+		},
+		"empty filter": {
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Set(FilterSetting, " ")
+			},
+			expectedSql: "SELECT * FROM `mock_models`",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			assert.NoError(t, db.Use(New(WithDatabaseType(SQLite), WithAllowedFields("name", "metadata/name"))))
+
+			// Act
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return testData.query(tx.Model(&MockModel{})).Find(&[]MockModel{})
+			})
+
+			// Assert
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_PluginQueries(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	_ = db.Create(&[]MockModel{{ID: uuid.New(), Name: "a"}, {ID: uuid.New(), Name: "b"}, {ID: uuid.New(), Name: "c"}}).Error
+	_ = db.Use(New(WithDatabaseType(SQLite)))
+
+	query := db.Set(FilterSetting, "name ne 'b'").Order("name").Session(&gorm.Session{})
+
+	// Act
+	var count int64
+	countErr := query.Model(&MockModel{}).Count(&count).Error
+
+	var results []MockModel
+	findErr := query.Find(&results).Error
+
+	var first MockModel
+	firstErr := query.Set(FilterSetting, "name gt 'a'").First(&first).Error
+
+	// Assert
+	assert.NoError(t, countErr)
+	assert.NoError(t, findErr)
+	assert.NoError(t, firstErr)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "b", first.Name)
+}
+
+func Test_Builder_PluginError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		filter        any
+		expectedError string
+		expectedErr   error
+	}{
+		"field not allowed": {
+			filter:        "testValue eq 'a'",
+			expectedError: "invalid query: field 'testValue' is not allowed",
+			expectedErr:   ErrFieldNotAllowed,
+		},
+		"invalid syntax": {
+			filter:      "name eq",
+			expectedErr: ErrInvalidSyntax,
+		},
+		"invalid type": {
+			filter:        42,
+			expectedError: "gorm setting 'odata:filter' must be a filter string or an *Expr, got int",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			_ = db.Use(New(WithDatabaseType(SQLite), WithAllowedFields("name")))
+
+			// Act
+			err := db.Set(FilterSetting, testData.filter).Find(&[]MockModel{}).Error
+
+			// Assert
+			assert.Error(t, err)
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+			}
+			if testData.expectedErr != nil {
+				assert.True(t, errors.Is(err, testData.expectedErr))
+			}
+		})
+	}
+}
+
+func Test_Builder_PluginFilterAddedOnce(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	_ = db.Use(New(WithDatabaseType(SQLite)))
+
+	query := db.Set(FilterSetting, "name eq 'a' or name eq 'b'").Model(&MockModel{})
+
+	// Act
+	var count int64
+	countErr := query.Count(&count).Error
+	findErr := query.Find(&[]MockModel{}).Error
+
+	// Assert
+	assert.NoError(t, countErr)
+	assert.NoError(t, findErr)
+	assert.Len(t, whereConditions(query), 1)
+}
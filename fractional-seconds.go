@@ -0,0 +1,51 @@
+package gormodata
+
+// fractionalSecondsFunction
+// is the function of a filter that returns the fraction of the second of a timestamp
+const fractionalSecondsFunction = "fractionalsecond"
+
+// FractionalSecondsPrecision
+// sets the unit that fractionalsecond returns the fraction of the second in (see WithFractionalSecondsPrecision)
+type FractionalSecondsPrecision int
+
+const (
+	// FractionalSecondsMicroseconds returns the fraction of the second in microseconds (0 to 999999)
+	FractionalSecondsMicroseconds FractionalSecondsPrecision = iota
+	// FractionalSecondsMilliseconds returns the fraction of the second in milliseconds (0 to 999)
+	FractionalSecondsMilliseconds
+)
+
+// fractionalSecondsTranslation
+// translates fractionalsecond for every database type and precision, only the fraction of the second is returned,
+// EXTRACT on PostgreSQL includes the whole seconds so they are removed with MOD
+//
+// SQLite date functions only have milliseconds (STRFTIME('%f') rounds to them), its microseconds are the milliseconds times 1000
+var fractionalSecondsTranslation = map[DbType]map[FractionalSecondsPrecision]string{
+	PostgreSQL: {
+		FractionalSecondsMicroseconds: "MOD(CAST(EXTRACT(MICROSECONDS FROM %s) AS bigint), 1000000)",
+		FractionalSecondsMilliseconds: "MOD(CAST(FLOOR(EXTRACT(MILLISECONDS FROM %s)) AS bigint), 1000)",
+	},
+	MySQL: {
+		FractionalSecondsMicroseconds: "MICROSECOND(%s)",
+		FractionalSecondsMilliseconds: "FLOOR(MICROSECOND(%s) / 1000)",
+	},
+	SQLite: {
+		FractionalSecondsMicroseconds: "CAST(SUBSTR(STRFTIME('%%f', %s), 4) AS INTEGER) * 1000",
+		FractionalSecondsMilliseconds: "CAST(SUBSTR(STRFTIME('%%f', %s), 4) AS INTEGER)",
+	},
+	SQLServer: {
+		FractionalSecondsMicroseconds: "DATEPART(MICROSECOND, %s)",
+		FractionalSecondsMilliseconds: "DATEPART(MILLISECOND, %s)",
+	},
+}
+
+// WithFractionalSecondsPrecision
+// sets the unit that fractionalsecond returns the fraction of the second in, microseconds by default
+//
+//	builder := gormodata.New(gormodata.WithFractionalSecondsPrecision(gormodata.FractionalSecondsMilliseconds))
+//	dbQuery, err := builder.Build("fractionalsecond(createdAt) ge 500", db.Model(&Event{}))
+func WithFractionalSecondsPrecision(precision FractionalSecondsPrecision) Option {
+	return func(b *Builder) {
+		b.fractionalSecondsPrecision = precision
+	}
+}
@@ -0,0 +1,122 @@
+package gormodata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_FractionalSeconds(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		precision    FractionalSecondsPrecision
+		expectedSqls map[DbType]string
+	}{
+		"microseconds": {
+			queryString: "fractionalsecond(createdAt) ge 500000",
+			precision:   FractionalSecondsMicroseconds,
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE MOD(CAST(EXTRACT(MICROSECONDS FROM created_at) AS bigint), 1000000) >= 500000",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE MICROSECOND(created_at) >= 500000",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE CAST(SUBSTR(STRFTIME('%f', created_at), 4) AS INTEGER) * 1000 >= 500000",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE DATEPART(MICROSECOND, created_at) >= 500000",
+			},
+		},
+		"milliseconds": {
+			queryString: "fractionalsecond(createdAt) ge 500",
+			precision:   FractionalSecondsMilliseconds,
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE MOD(CAST(FLOOR(EXTRACT(MILLISECONDS FROM created_at)) AS bigint), 1000) >= 500",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE FLOOR(MICROSECOND(created_at) / 1000) >= 500",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE CAST(SUBSTR(STRFTIME('%f', created_at), 4) AS INTEGER) >= 500",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE DATEPART(MILLISECOND, created_at) >= 500",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType), WithFractionalSecondsPrecision(testData.precision))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx.Model(&MockTimeModel{}))
+					return dbQuery.Find(&MockTimeModel{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_FractionalSecondsResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		precision     FractionalSecondsPrecision
+		expectedNames []string
+	}{
+		"microseconds": {
+			queryString:   "fractionalsecond(createdAt) eq 250000",
+			precision:     FractionalSecondsMicroseconds,
+			expectedNames: []string{"quarter"},
+		},
+		"milliseconds": {
+			queryString:   "fractionalsecond(createdAt) ge 500",
+			precision:     FractionalSecondsMilliseconds,
+			expectedNames: []string{"half"},
+		},
+		"whole seconds are ignored": {
+			queryString:   "fractionalsecond(createdAt) lt 300",
+			precision:     FractionalSecondsMilliseconds,
+			expectedNames: []string{"quarter"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockTimeModel{})
+			db.Create(&MockTimeModel{Name: "quarter", CreatedAt: time.Date(2025, 1, 1, 8, 0, 59, 250000000, time.UTC)})
+			db.Create(&MockTimeModel{Name: "half", CreatedAt: time.Date(2025, 1, 1, 8, 0, 1, 500000000, time.UTC)})
+
+			builder := New(WithDatabaseType(SQLite), WithFractionalSecondsPrecision(testData.precision))
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db.Model(&MockTimeModel{}))
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []MockTimeModel
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, model := range result {
+				names = append(names, model.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package gormodata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// geoLiteralPattern
+// matches the geography and geometry literals of a filter: <type>'SRID=<srid>;<well-known text>' (e.g. geography'SRID=4326;POINT(4.35 50.85)'),
+// the SRID is optional
+var geoLiteralPattern = regexp.MustCompile(`^(?i:(geography|geometry))'(?i:SRID=([0-9]{1,9});)?(.*)'$`)
+
+// wktPattern
+// matches the well-known text of a shape (e.g. POINT(4.35 50.85) or POLYGON((0 0, 1 0, 1 1, 0 0)))
+var wktPattern = regexp.MustCompile(`^(?i:POINT|LINESTRING|POLYGON|MULTIPOINT|MULTILINESTRING|MULTIPOLYGON|GEOMETRYCOLLECTION)\s*\([A-Za-z0-9 .,()+-]*\)$`)
+
+// geoDefaultSRIDs
+// are the SRIDs of geo literals without one: WGS 84 for geography and no reference system for geometry
+var geoDefaultSRIDs = map[string]int{
+	"geography": 4326,
+	"geometry":  0,
+}
+
+// geoFromText
+// are the sql functions that create a shape of the well-known text and SRID of a geo literal per database type,
+// these are the spatial extensions of the databases (PostGIS, SpatiaLite)
+var geoFromText = map[DbType]map[string]string{
+	PostgreSQL: {"geography": "ST_GeomFromText(?, ?)", "geometry": "ST_GeomFromText(?, ?)"},
+	MySQL:      {"geography": "ST_GeomFromText(?, ?)", "geometry": "ST_GeomFromText(?, ?)"},
+	SQLite:     {"geography": "GeomFromText(?, ?)", "geometry": "GeomFromText(?, ?)"},
+	SQLServer:  {"geography": "geography::STGeomFromText(?, ?)", "geometry": "geometry::STGeomFromText(?, ?)"},
+}
+
+// GeoLiteral
+// is the value of a geography or geometry literal of a filter (e.g. geography'SRID=4326;POINT(4.35 50.85)')
+type GeoLiteral struct {
+	// Type is "geography" or "geometry"
+	Type string
+	// SRID is the spatial reference system of the shape (e.g. 4326)
+	SRID int
+	// WKT is the well-known text of the shape (e.g. POINT(4.35 50.85))
+	WKT string
+}
+
+// String
+// returns the literal as it is written in a filter, with its SRID
+func (g GeoLiteral) String() string {
+	return fmt.Sprintf("%s'SRID=%d;%s'", g.Type, g.SRID, g.WKT)
+}
+
+// MarshalText
+// encodes the literal as it is written in a filter, so the json of expressions keeps geo literals apart from objects
+func (g GeoLiteral) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// isGeoLiteral
+// returns whether the value of a node is a geography or geometry literal with a valid shape
+func isGeoLiteral(value string) bool {
+	match := geoLiteralPattern.FindStringSubmatch(value)
+
+	return match != nil && wktPattern.MatchString(strings.TrimSpace(match[3])) && balancedBrackets(match[3])
+}
+
+// geoLiteral
+// returns the structured value of a geo literal, literals without an SRID get the default of their type (see geoDefaultSRIDs)
+func geoLiteral(value string) GeoLiteral {
+	match := geoLiteralPattern.FindStringSubmatch(value)
+	literal := GeoLiteral{Type: strings.ToLower(match[1]), WKT: strings.TrimSpace(match[3])}
+
+	literal.SRID = geoDefaultSRIDs[literal.Type]
+	if match[2] != "" {
+		literal.SRID, _ = strconv.Atoi(match[2])
+	}
+
+	return literal
+}
+
+// bindGeoLiteral
+// returns the shape of a geo literal as an expression of the database, e.g. on PostGIS:
+//
+//	location eq geography'SRID=4326;POINT(4.35 50.85)'  ->  location = ST_GeomFromText('POINT(4.35 50.85)', 4326)
+func bindGeoLiteral(literal GeoLiteral, databaseType DbType) clause.Expr {
+	return clause.Expr{SQL: geoFromText[databaseType][literal.Type], Vars: []any{literal.WKT, literal.SRID}}
+}
+
+// balancedBrackets
+// returns whether every opening bracket of the value is closed in order
+func balancedBrackets(value string) bool {
+	depth := 0
+	for _, char := range value {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+
+	return depth == 0
+}
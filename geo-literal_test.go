@@ -0,0 +1,179 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Venue struct {
+	ID       uuid.UUID
+	Name     string
+	Location string
+}
+
+func Test_BuildQuery_GeoLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"geography": {
+			queryString: "location eq geography'SRID=4326;POINT(4.35 50.85)'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `venues` WHERE location = ST_GeomFromText(\"POINT(4.35 50.85)\", 4326)",
+				MySQL:      "SELECT * FROM `venues` WHERE location = ST_GeomFromText(\"POINT(4.35 50.85)\", 4326)",
+				SQLite:     "SELECT * FROM `venues` WHERE location = GeomFromText(\"POINT(4.35 50.85)\", 4326)",
+				SQLServer:  "SELECT * FROM `venues` WHERE location = geography::STGeomFromText(\"POINT(4.35 50.85)\", 4326)",
+			},
+		},
+		"geometry without an SRID": {
+			queryString: "location ne geometry'POLYGON((0 0, 1 0, 1 1, 0 0))'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `venues` WHERE location != ST_GeomFromText(\"POLYGON((0 0, 1 0, 1 1, 0 0))\", 0)",
+				MySQL:      "SELECT * FROM `venues` WHERE location != ST_GeomFromText(\"POLYGON((0 0, 1 0, 1 1, 0 0))\", 0)",
+				SQLite:     "SELECT * FROM `venues` WHERE location != GeomFromText(\"POLYGON((0 0, 1 0, 1 1, 0 0))\", 0)",
+				SQLServer:  "SELECT * FROM `venues` WHERE location != geometry::STGeomFromText(\"POLYGON((0 0, 1 0, 1 1, 0 0))\", 0)",
+			},
+		},
+		"uppercase type and other conditions": {
+			queryString: "name eq 'Atomium' and location eq Geography'srid=3857;POINT(484000 6594000)'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `venues` WHERE name = \"Atomium\" AND location = ST_GeomFromText(\"POINT(484000 6594000)\", 3857)",
+				MySQL:      "SELECT * FROM `venues` WHERE name = \"Atomium\" AND location = ST_GeomFromText(\"POINT(484000 6594000)\", 3857)",
+				SQLite:     "SELECT * FROM `venues` WHERE name = \"Atomium\" AND location = GeomFromText(\"POINT(484000 6594000)\", 3857)",
+				SQLServer:  "SELECT * FROM `venues` WHERE name = \"Atomium\" AND location = geography::STGeomFromText(\"POINT(484000 6594000)\", 3857)",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = BuildQuery(testData.queryString, tx.Model(&Venue{}), dbType)
+					return dbQuery.Find(&Venue{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_GeoLiteralErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"unknown shape": {
+			queryString: "location eq geography'SRID=4326;CIRCLE(4.35 50.85)'",
+			expectedErr: "failed to parse query: invalid geography literal geography'SRID=4326;CIRCLE(4.35 50.85)', expected SRID=<srid>;<well-known text> (e.g. geography'SRID=4326;POINT(4.35 50.85)')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"unbalanced brackets": {
+			queryString: "location eq geometry'POINT(4.35 50.85))'",
+			expectedErr: "failed to parse query: invalid geometry literal geometry'POINT(4.35 50.85))', expected SRID=<srid>;<well-known text> (e.g. geometry'SRID=4326;POINT(4.35 50.85)')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"invalid SRID": {
+			queryString: "location eq geography'SRID=wgs84;POINT(4.35 50.85)'",
+			expectedErr: "failed to parse query: invalid geography literal geography'SRID=wgs84;POINT(4.35 50.85)', expected SRID=<srid>;<well-known text> (e.g. geography'SRID=4326;POINT(4.35 50.85)')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation property": {
+			queryString: "metadata/location eq geography'SRID=4326;POINT(4.35 50.85)'",
+			expectedErr: "invalid query: relation property 'metadata/location' cannot be compared with literal geography'SRID=4326;POINT(4.35 50.85)'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db.Model(&Venue{}), PostgreSQL)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_GeoLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedValue  GeoLiteral
+		expectedString string
+	}{
+		"geography": {
+			queryString:    "location eq geography'SRID=4326;POINT(4.35 50.85)'",
+			expectedValue:  GeoLiteral{Type: "geography", SRID: 4326, WKT: "POINT(4.35 50.85)"},
+			expectedString: "location eq geography'SRID=4326;POINT(4.35 50.85)'",
+		},
+		"default SRID of geography": {
+			queryString:    "location eq GEOGRAPHY'LINESTRING(4.35 50.85, 4.40 50.90)'",
+			expectedValue:  GeoLiteral{Type: "geography", SRID: 4326, WKT: "LINESTRING(4.35 50.85, 4.40 50.90)"},
+			expectedString: "location eq geography'SRID=4326;LINESTRING(4.35 50.85, 4.40 50.90)'",
+		},
+		"geometry": {
+			queryString:    "location eq geometry'SRID=0;MULTIPOINT((0 0), (1 1))'",
+			expectedValue:  GeoLiteral{Type: "geometry", SRID: 0, WKT: "MULTIPOINT((0 0), (1 1))"},
+			expectedString: "location eq geometry'SRID=0;MULTIPOINT((0 0), (1 1))'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			expr, err := Parse(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, &Expr{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("location"), exprLiteral(testData.expectedValue)}}, expr)
+			assert.Equal(t, testData.expectedString, expr.String())
+
+			reparsedExpr, err := Parse(expr.String())
+			assert.NoError(t, err)
+			assert.Equal(t, expr, reparsedExpr)
+
+			data, err := json.Marshal(expr)
+			assert.NoError(t, err)
+
+			var decoded Expr
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, expr, &decoded)
+		})
+	}
+}
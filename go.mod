@@ -8,9 +8,9 @@ require (
 	github.com/ing-bank/gormtestutil v0.0.1
 	github.com/stoewer/go-strcase v1.3.1
 	github.com/survivorbat/go-tsyncmap v0.0.0
-	github.com/survivorbat/gorm-deep-filtering v0.3.0
 	github.com/survivorbat/gorm-query-convert v0.1.0
 	github.com/test-go/testify v1.1.4
+	gorm.io/driver/sqlite v1.6.0
 	gorm.io/gorm v1.31.1
 )
 
@@ -22,5 +22,4 @@ require (
 	github.com/mitchellh/go-testing-interface v1.14.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/text v0.38.0 // indirect
-	gorm.io/driver/sqlite v1.6.0 // indirect
 )
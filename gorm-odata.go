@@ -1,6 +1,7 @@
 package gormodata
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -9,16 +10,20 @@ import (
 	"strings"
 
 	syntaxtree "github.com/bramca/go-syntax-tree"
-	"github.com/survivorbat/go-tsyncmap"
-
-	deepgorm "github.com/survivorbat/gorm-deep-filtering"
-	gormqonvert "github.com/survivorbat/gorm-query-convert"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 )
 
 type DbType int
 
+// NullSafeSetting
+// is the gorm setting that enables null-aware comparisons, when enabled negated comparisons
+// (e.g. name ne 'test', not(length(name) gt 5)) also match rows where the column is NULL
+//
+//	gormodata.BuildQuery(query, db.Set(gormodata.NullSafeSetting, true), gormodata.PostgreSQL)
+const NullSafeSetting = "gormodata:null_safe"
+
 const (
 	PostgreSQL DbType = iota
 	MySQL
@@ -27,8 +32,7 @@ const (
 )
 
 var (
-	cacheGormqonvertTranslationMap = tsyncmap.Map[string, map[string]string]{}
-	operatorTranslation            = map[string]string{
+	operatorTranslation = map[string]string{
 		"eq":         "=",
 		"ne":         "!=",
 		"lt":         "<",
@@ -52,110 +56,94 @@ var (
 		"endswith":   "!~",
 	}
 
-	gormqonvertTranslation = map[string]string{
-		"eq":         "=",
-		"ne":         "!=",
-		"lt":         "<",
-		"le":         "<=",
-		"gt":         ">",
-		"ge":         ">=",
-		"contains":   "~",
-		"startswith": "~",
-		"endswith":   "~",
-	}
-
-	gormqonvertTranslationReversed = map[string]string{
-		"eq":         "!=",
-		"ne":         "=",
-		"lt":         ">=",
-		"le":         ">",
-		"gt":         "<=",
-		"ge":         "<",
-		"contains":   "!~",
-		"startswith": "!~",
-		"endswith":   "!~",
-	}
-
 	unaryFunctionTranslation = map[DbType]map[string]string{
 		PostgreSQL: {
-			"length":           "LENGTH",
-			"indexof":          "POSITION",
-			"tolower":          "LOWER",
-			"toupper":          "UPPER",
-			"trim":             "TRIM",
-			"year":             "EXTRACT(YEAR FROM %s)",
-			"month":            "EXTRACT(MONTH FROM %s)",
-			"day":              "EXTRACT(DAY FROM %s)",
-			"hour":             "EXTRACT(HOUR FROM %s)",
-			"minute":           "EXTRACT(MINUTE FROM %s)",
-			"second":           "EXTRACT(SECOND FROM %s)",
-			"fractionalsecond": "EXTRACT(MICROSECOND FROM %s)",
-			"date":             "TO_DATE",
-			"time":             "CAST(%s::timestamp AS time)",
-			"now":              "NOW",
-			"round":            "ROUND",
-			"floor":            "FLOOR",
-			"ceiling":          "CEIL",
+			"length":         "LENGTH",
+			"indexof":        "POSITION",
+			"tolower":        "LOWER",
+			"toupper":        "UPPER",
+			"trim":           "TRIM",
+			"year":           "EXTRACT(YEAR FROM %s)",
+			"month":          "EXTRACT(MONTH FROM %s)",
+			"day":            "EXTRACT(DAY FROM %s)",
+			"hour":           "EXTRACT(HOUR FROM %s)",
+			"minute":         "EXTRACT(MINUTE FROM %s)",
+			"second":         "EXTRACT(SECOND FROM %s)",
+			"date":           "TO_DATE",
+			"time":           "CAST(%s::timestamp AS time)",
+			"round":          "ROUND",
+			"floor":          "FLOOR",
+			"ceiling":        "CEIL",
+			sqrtFunction:     "SQRT",
+			"abs":            "ABS",
+			"sign":           "SIGN",
+			negationFunction: "-(%s)",
 		},
 		MySQL: {
-			"length":           "LENGTH",
-			"indexof":          "LOCATE",
-			"tolower":          "LOWER",
-			"toupper":          "UPPER",
-			"trim":             "TRIM",
-			"year":             "YEAR",
-			"month":            "MONTH",
-			"day":              "DAY",
-			"hour":             "HOUR",
-			"minute":           "MINUTE",
-			"second":           "SECOND",
-			"fractionalsecond": "MICROSECOND",
-			"date":             "DATE",
-			"time":             "TIME",
-			"now":              "NOW",
-			"round":            "ROUND",
-			"floor":            "FLOOR",
-			"ceiling":          "CEIL",
+			"length":         "LENGTH",
+			"indexof":        "LOCATE",
+			"tolower":        "LOWER",
+			"toupper":        "UPPER",
+			"trim":           "TRIM",
+			"year":           "YEAR",
+			"month":          "MONTH",
+			"day":            "DAY",
+			"hour":           "HOUR",
+			"minute":         "MINUTE",
+			"second":         "SECOND",
+			"date":           "DATE",
+			"time":           "TIME",
+			"round":          "ROUND",
+			"floor":          "FLOOR",
+			"ceiling":        "CEIL",
+			sqrtFunction:     "SQRT",
+			"abs":            "ABS",
+			"sign":           "SIGN",
+			negationFunction: "-(%s)",
 		},
 		SQLite: {
-			"length":           "LENGTH",
-			"indexof":          "LOCATE",
-			"tolower":          "LOWER",
-			"toupper":          "UPPER",
-			"trim":             "TRIM",
-			"year":             "YEAR",
-			"month":            "MONTH",
-			"day":              "DAY",
-			"hour":             "HOUR",
-			"minute":           "MINUTE",
-			"second":           "SECOND",
-			"fractionalsecond": "MICROSECOND",
-			"date":             "DATE",
-			"time":             "TIME",
-			"now":              "NOW",
-			"round":            "ROUND",
-			"floor":            "FLOOR",
-			"ceiling":          "CEIL",
+			"length":         "LENGTH",
+			"indexof":        "LOCATE",
+			"tolower":        "LOWER",
+			"toupper":        "UPPER",
+			"trim":           "TRIM",
+			"year":           "YEAR",
+			"month":          "MONTH",
+			"day":            "DAY",
+			"hour":           "HOUR",
+			"minute":         "MINUTE",
+			"second":         "SECOND",
+			"date":           "DATE",
+			"time":           "TIME",
+			"round":          "ROUND",
+			"floor":          "FLOOR",
+			"ceiling":        "CEIL",
+			sqrtFunction:     "SQRT",
+			"abs":            "ABS",
+			"sign":           "SIGN",
+			negationFunction: "-(%s)",
 		},
 		SQLServer: {
-			"length":           "LENGTH",
-			"indexof":          "LOCATE",
-			"tolower":          "LOWER",
-			"toupper":          "UPPER",
-			"trim":             "TRIM",
-			"year":             "YEAR",
-			"month":            "MONTH",
-			"day":              "DAY",
-			"hour":             "HOUR",
-			"minute":           "MINUTE",
-			"second":           "SECOND",
-			"fractionalsecond": "MICROSECOND",
-			"date":             "DATE",
-			"time":             "TIME",
-			"now":              "NOW",
-			"round":            "ROUND",
-			"floor":            "FLOOR",
-			"ceiling":          "CEIL",
+			"length":         "LENGTH",
+			"indexof":        "LOCATE",
+			"tolower":        "LOWER",
+			"toupper":        "UPPER",
+			"trim":           "TRIM",
+			"year":           "YEAR",
+			"month":          "MONTH",
+			"day":            "DAY",
+			"hour":           "HOUR",
+			"minute":         "MINUTE",
+			"second":         "SECOND",
+			"date":           "DATE",
+			"time":           "CAST(%s AS time)",
+			"round":          "ROUND",
+			"floor":          "FLOOR",
+			"ceiling":        "CEIL",
+			sqrtFunction:     "SQRT",
+			"abs":            "ABS",
+			"sign":           "SIGN",
+			negationFunction: "-(%s)",
 		},
 	}
 
@@ -167,6 +155,14 @@ var (
 			"ge",
 			"lt",
 			"le",
+			"in",
+			"has",
+			"add",
+			"sub",
+			"mul",
+			"div",
+			"divby",
+			"mod",
 			"and",
 			"or",
 		},
@@ -175,9 +171,18 @@ var (
 			"contains",
 			"endswith",
 			"startswith",
+			"soundslike",
+			levenshteinFunction,
+			regexReplaceFunction,
+			nullIfFunction,
+			greatestFunction,
+			leastFunction,
+			powerFunction,
+			substringOfFunction,
 		},
 		UnaryFunctions: []string{
 			"not",
+			negationFunction,
 			"length",
 			"indexof",
 			"tolower",
@@ -189,13 +194,16 @@ var (
 			"hour",
 			"minute",
 			"second",
-			"fractionalsecond",
+			fractionalSecondsFunction,
 			"date",
 			"time",
 			"now",
 			"round",
 			"floor",
 			"ceiling",
+			"abs",
+			"sign",
+			sqrtFunction,
 		},
 		OpenDelimiter:             '(',
 		CloseDelimiter:            ')',
@@ -205,17 +213,40 @@ var (
 	}
 
 	odataPrecedence = map[string]int{
-		"or":  1,
-		"and": 2,
-		"eq":  3,
-		"ne":  3,
-		"gt":  3,
-		"ge":  3,
-		"lt":  3,
-		"le":  3,
+		"or":    1,
+		"and":   2,
+		"eq":    3,
+		"ne":    3,
+		"gt":    3,
+		"ge":    3,
+		"lt":    3,
+		"le":    3,
+		"in":    3,
+		"add":   4,
+		"sub":   4,
+		"mul":   5,
+		"div":   5,
+		"divby": 5,
+		"mod":   5,
+		"has":   6,
+	}
+
+	likePatterns = map[string]string{
+		"contains":   "%%%s%%",
+		"startswith": "%s%%",
+		"endswith":   "%%%s",
 	}
 
 	operandBadPattern = regexp.MustCompile(`^[^'].*(\*|;|-)+.*[^']$`)
+
+	// operandBadPatternValidation
+	// is the extra protection against SQL injection that every Builder validates
+	operandBadPatternValidation = WithBadPatternValidation(map[*regexp.Regexp][]syntaxtree.NodeType{
+		operandBadPattern: {
+			syntaxtree.LeftOperand,
+			syntaxtree.RightOperand,
+		},
+	})
 )
 
 // QueryValidation
@@ -238,17 +269,44 @@ func PrintTree(query string) (string, error) {
 // GetAST
 // to get the full abstract syntaxtree for a given query
 func GetAST(query string) (*syntaxtree.SyntaxTree, error) {
-	tree := &syntaxtree.SyntaxTree{
-		Lexer:       odataLexer,
-		Precendence: odataPrecedence,
+	return odataParser.parse(query)
+}
+
+// syntaxErrorKind
+// returns the kind of syntax error of a query that cannot be parsed, based on the tokens of the query
+//
+//	ErrUnbalancedParens: the brackets do not match
+//	ErrUnknownFunction: a bracket is opened right after a property (e.g. "concot(name,'value')")
+//	ErrUnsupportedOperator: two operands follow each other (e.g. "name qe 'value'")
+func syntaxErrorKind(tokens []syntaxtree.Token) error {
+	openBrackets := 0
+	for _, token := range tokens {
+		switch token.Type {
+		case syntaxtree.OpenDelimiter:
+			openBrackets++
+		case syntaxtree.CloseDelimiter:
+			openBrackets--
+		}
+		if openBrackets < 0 {
+			return ErrUnbalancedParens
+		}
+	}
+	if openBrackets != 0 {
+		return ErrUnbalancedParens
 	}
 
-	err := tree.BuildTree(query)
-	if err != nil {
-		return nil, err
+	for index := 1; index < len(tokens); index++ {
+		previous, current := tokens[index-1], tokens[index]
+		if previous.Type == syntaxtree.Operand && current.Type == syntaxtree.OpenDelimiter {
+			return ErrUnknownFunction
+		}
+		isOperand := current.Type == syntaxtree.Operand || current.Type == syntaxtree.StringOperand
+		if isOperand && (previous.Type == syntaxtree.Operand || previous.Type == syntaxtree.StringOperand || previous.Type == syntaxtree.CloseDelimiter) {
+			return ErrUnsupportedOperator
+		}
 	}
 
-	return tree, nil
+	return ErrInvalidSyntax
 }
 
 // WithInputModelValidation
@@ -258,7 +316,7 @@ func WithInputModelValidation(input any) QueryValidation {
 		columnNamesList := columnNames(input, db.NamingStrategy)
 
 		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
-			if currentNode.Type == syntaxtree.LeftOperand && currentNode.Parent.Value != "concat" {
+			if currentNode.Type == syntaxtree.LeftOperand && currentNode.Parent.Value != "concat" && currentNode.Parent.Value != regexReplaceArguments && !isNowArgument(currentNode) {
 				columnName := db.NamingStrategy.ColumnName("", currentNode.Value)
 				if strings.Contains(columnName, "/") {
 					splitName := strings.Split(columnName, "/")
@@ -266,7 +324,10 @@ func WithInputModelValidation(input any) QueryValidation {
 				}
 				if !slices.Contains(columnNamesList, columnName) {
 					return &InvalidQueryError{
-						Msg: fmt.Sprintf("unknown column name '%s'", columnName),
+						Msg:        fmt.Sprintf("unknown column name '%s'", columnName),
+						Err:        ErrUnknownProperty,
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
 					}
 				}
 			}
@@ -274,7 +335,7 @@ func WithInputModelValidation(input any) QueryValidation {
 			return nil
 		}
 
-		return validateQueryDepthFirstSearch(tree, validationCheck)
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
 	}
 }
 
@@ -286,13 +347,15 @@ func WithMaxTreeDepth(maxTreeDepth int) QueryValidation {
 			if depth > maxTreeDepth {
 				return &InvalidQueryError{
 					Msg: fmt.Sprintf("maximum query complexity exceeded: >%d", maxTreeDepth),
+					Err: ErrComplexityExceeded,
 				}
 			}
 
 			return nil
 		}
 
-		return validateQueryDepthFirstSearch(tree, validationCheck)
+		// Every node below the maximum depth exceeds it, so only the first one is reported
+		return validateQueryDepthFirstSearch(nil, tree, validationCheck)
 	}
 }
 
@@ -307,7 +370,10 @@ func WithMaxObjectExpansion(maxObjectExpansion int) QueryValidation {
 				splitName := strings.Split(currentNode.Value, "/")
 				if len(splitName) > maxObjectExpansion {
 					return &InvalidQueryError{
-						Msg: fmt.Sprintf("query contains value '%s' that exceeds the maximum allowed object expansion depth: >%d", currentNode.Value, maxObjectExpansion),
+						Msg:        fmt.Sprintf("query contains value '%s' that exceeds the maximum allowed object expansion depth: >%d", currentNode.Value, maxObjectExpansion),
+						Err:        ErrComplexityExceeded,
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
 					}
 				}
 			}
@@ -315,21 +381,31 @@ func WithMaxObjectExpansion(maxObjectExpansion int) QueryValidation {
 			return nil
 		}
 
-		return validateQueryDepthFirstSearch(tree, validationCheck)
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
 	}
 }
 
 // WithBadPatternValidation
 // returns a QueryValidation function that checks queries against a regexp pattern for certain node types
 //
-// that is not allowed or considered a bad pattern
+// that is not allowed or considered a bad pattern, the values of a list (e.g. name in ('a','b')) are checked one by one
 func WithBadPatternValidation(patternMap map[*regexp.Regexp][]syntaxtree.NodeType) QueryValidation {
+	// The patterns are grouped by node type once, so nodes are only matched against the patterns of their type
+	nodeTypePatterns := map[syntaxtree.NodeType][]*regexp.Regexp{}
+	for pattern, nodeTypes := range patternMap {
+		for _, nodeType := range nodeTypes {
+			nodeTypePatterns[nodeType] = append(nodeTypePatterns[nodeType], pattern)
+		}
+	}
+
 	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
 		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
-			for pattern, nodeTypes := range patternMap {
-				if slices.Contains(nodeTypes, currentNode.Type) && pattern.MatchString(currentNode.Value) {
+			for _, pattern := range nodeTypePatterns[currentNode.Type] {
+				if slices.ContainsFunc(patternValues(currentNode.Value), pattern.MatchString) {
 					return &InvalidQueryError{
-						Msg: fmt.Sprintf("node %q contains a bad pattern", currentNode.Value),
+						Msg:        fmt.Sprintf("node %q contains a bad pattern", currentNode.Value),
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
 					}
 				}
 			}
@@ -337,7 +413,7 @@ func WithBadPatternValidation(patternMap map[*regexp.Regexp][]syntaxtree.NodeTyp
 			return nil
 		}
 
-		return validateQueryDepthFirstSearch(tree, validationCheck)
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
 	}
 }
 
@@ -347,166 +423,384 @@ func WithBadPatternValidation(patternMap map[*regexp.Regexp][]syntaxtree.NodeTyp
 // You can add optional query validations from this package (see WithInputModelValidation, WithMaxObjectExpansion...)
 //
 // Or add your custom validation functions -> type QueryValidtion
+//
+// Use New to create a reusable Builder instead of building the configuration on every call
 func BuildQuery(query string, db *gorm.DB, databaseType DbType, queryValidations ...QueryValidation) (*gorm.DB, error) {
-	var err error
-	db, err = checkDbPlugins(db)
-	if err != nil {
-		return db, err
-	}
+	return New(WithDatabaseType(databaseType), WithQueryValidations(queryValidations...)).Build(query, db)
+}
 
-	tree, err := GetAST(query)
-	if err != nil {
+// BuildQueryContext
+// builds a gorm query based on an odata query string like BuildQuery, with the context on the returned gorm session,
+// the build is stopped with the error of the context when it is canceled or its deadline is exceeded
+func BuildQueryContext(ctx context.Context, query string, db *gorm.DB, databaseType DbType, queryValidations ...QueryValidation) (*gorm.DB, error) {
+	return New(WithDatabaseType(databaseType), WithQueryValidations(queryValidations...)).BuildContext(ctx, query, db)
+}
+
+// BuildQueryFor
+// builds a gorm query on the model T based on an odata query string, the properties and relation paths of the filter
+// are checked against the gorm schema of T before the query is built, so unknown properties fail here instead of when the query is executed
+//
+//	dbQuery, err := gormodata.BuildQueryFor[MyModel](queryString, db, gormodata.WithAllowedFields("name"))
+//	err = dbQuery.Find(&results).Error
+//
+// the schema is parsed once per type and cached by gorm, the returned query has the model set (db.Model(new(T)))
+func BuildQueryFor[T any](query string, db *gorm.DB, opts ...Option) (*gorm.DB, error) {
+	model := new(T)
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
 		return db, err
 	}
 
-	for _, validateQuery := range queryValidations {
-		if err := validateQuery(tree, db); err != nil {
-			return db, err
-		}
-	}
+	return New(append(opts, WithQueryValidations(schemaValidation(statement.Schema)))...).Build(query, db.Model(model))
+}
 
-	// Extra protection against SQL injection
-	err = WithBadPatternValidation(map[*regexp.Regexp][]syntaxtree.NodeType{
-		operandBadPattern: {
-			syntaxtree.LeftOperand,
-			syntaxtree.RightOperand,
-		},
-	})(tree, db)
-	if err != nil {
-		return db, err
-	}
+// buildFrame
+// is a node of the filter that is being built by buildGormQuery, with the db and the negation it is built with
+type buildFrame struct {
+	node          *syntaxtree.Node
+	db            *gorm.DB
+	opTranslation map[string]string
+	notEnabled    bool
+
+	// visits counts how often the frame of an 'and' or 'or' was on top of the stack, the query of its left child is kept in left
+	visits  int
+	cleanDB *gorm.DB
+	left    *gorm.DB
+}
 
-	columnTranslationFunc := func(s string) string {
-		return db.NamingStrategy.ColumnName("", s)
+// buildGormQuery
+// builds the filter of the tree with the root on the db,
+// the tree is walked with a stack instead of recursion, so deeply nested filters cannot exhaust the stack
+func buildGormQuery(root *syntaxtree.Node, db *gorm.DB, translation *queryTranslation, opTranslation map[string]string, notEnabled bool) (*gorm.DB, error) {
+	// Most filters fit in the buffer, so the stack is only allocated for deeply nested filters
+	var buffer [16]buildFrame
+	stack := append(buffer[:0], buildFrame{node: root, db: db, opTranslation: opTranslation, notEnabled: notEnabled})
+
+	// result is the query of the last frame that was built, it is taken over by the frame of its parent
+	var result *gorm.DB
+	for len(stack) > 0 {
+		frame := &stack[len(stack)-1]
+		node := frame.node
+
+		switch {
+		case node.Type == syntaxtree.Operator && (node.Value == "and" || node.Value == "or"):
+			// The frame is changed before a child is pushed, since pushing can move the stack
+			frame.visits++
+			switch frame.visits {
+			case 1:
+				frame.cleanDB = frame.db.Session(&gorm.Session{NewDB: true})
+				stack = append(stack, buildFrame{node: node.LeftChild, db: frame.cleanDB, opTranslation: frame.opTranslation, notEnabled: frame.notEnabled})
+			case 2:
+				frame.left = result
+				stack = append(stack, buildFrame{node: node.RightChild, db: frame.cleanDB, opTranslation: frame.opTranslation, notEnabled: frame.notEnabled})
+			default:
+				// A negated 'and' becomes an 'or' and the other way around
+				if (node.Value == "or") != frame.notEnabled {
+					result = frame.db.Where(frame.left).Or(result)
+				} else {
+					result = frame.db.Where(frame.left).Where(result)
+				}
+				stack = stack[:len(stack)-1]
+			}
+		case node.Type == syntaxtree.UnaryOperator && node.Value == "not":
+			// The negated filter takes the place of the 'not', since nothing is added to it afterwards
+			*frame = buildFrame{node: node.LeftChild, db: frame.db, opTranslation: operatorTranslationReversed, notEnabled: true}
+		default:
+			var err error
+			result, err = buildCondition(node, frame.db, translation, frame.opTranslation, frame.notEnabled)
+			if err != nil {
+				return db, err
+			}
+			stack = stack[:len(stack)-1]
+		}
 	}
 
-	db, err = buildGormQuery(tree.Root, db, databaseType, operatorTranslation, gormqonvertTranslation, columnTranslationFunc, false)
-
-	return db, err
+	return result, nil
 }
 
-func buildGormQuery(root *syntaxtree.Node, db *gorm.DB, databaseType DbType, opTranslation map[string]string, gqTranslation map[string]string, columnTranslation func(string) string, notEnabled bool) (*gorm.DB, error) {
-	cleanDB := db.Session(&gorm.Session{NewDB: true})
+// buildCondition
+// builds the comparison or function of the node on the db, other nodes than and, or and not (see buildGormQuery)
+func buildCondition(root *syntaxtree.Node, db *gorm.DB, translation *queryTranslation, opTranslation map[string]string, notEnabled bool) (*gorm.DB, error) {
 	switch root.Type {
 	case syntaxtree.Operator:
+		if err := checkRootReferences(root); err != nil {
+			return db, err
+		}
+
 		switch root.Value {
-		case "and":
-			if notEnabled {
-				db = db.Where(buildGormQuery(root.LeftChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled)).Or(buildGormQuery(root.RightChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled))
-			} else {
-				db = db.Where(buildGormQuery(root.LeftChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled)).Where(buildGormQuery(root.RightChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled))
-			}
-		case "or":
-			if notEnabled {
-				db = db.Where(buildGormQuery(root.LeftChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled)).Where(buildGormQuery(root.RightChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled))
-			} else {
-				db = db.Where(buildGormQuery(root.LeftChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled)).Or(buildGormQuery(root.RightChild, cleanDB, databaseType, opTranslation, gqTranslation, columnTranslation, notEnabled))
-			}
 		case "eq", "ne", "lt", "le", "gt", "ge":
 			// Build up left child
 			leftChild := root.LeftChild
+			if err := checkArithmetic(leftChild, translation); err != nil {
+				return db, err
+			}
 			queryLeftOperandString := ""
 			if leftChild.Type == syntaxtree.UnaryOperator {
-				queryLeftOperandString = buildUnaryFuncChain(databaseType, columnTranslation, leftChild)
+				queryLeftOperandString = buildUnaryFuncChain(translation, leftChild)
+			}
+			if isArithmeticNode(leftChild) {
+				queryLeftOperandString = buildArithmetic(translation, leftChild)
 			}
 			if leftChild.Value == "concat" {
-				queryLeftOperandString = buildConcat(databaseType, columnTranslation, leftChild)
+				queryLeftOperandString = buildConcat(translation, leftChild)
 			}
 			if leftChild.Type == syntaxtree.LeftOperand {
-				queryLeftOperandString = columnTranslation(leftChild.Value)
+				queryLeftOperandString = translation.columnName(leftChild.Value)
+			}
+			if isNumericFunctionNode(leftChild) {
+				var err error
+				if queryLeftOperandString, err = buildNumericFunction(translation, leftChild); err != nil {
+					return db, err
+				}
+			}
+			// The arguments of functions that are bound come before the right operand
+			var leftArgs []any
+			textResult := false
+			if isBoundFunction(leftChild) {
+				var err error
+				queryLeftOperandString, leftArgs, textResult, err = buildBoundFunction(translation, leftChild)
+				if err != nil {
+					return db, err
+				}
 			}
 
 			// Build up right child
 			rightChild := root.RightChild
 			queryRightOperandString := ""
-			if rightChild.Type == syntaxtree.UnaryOperator {
+			if rightChild.Type == syntaxtree.UnaryOperator && !isNowCall(rightChild) {
 				return db, &InvalidQueryError{
-					Msg: "unary operators not supported as right operand of equality operators",
+					Msg:        fmt.Sprintf("unary function '%s' cannot be the right operand of '%s', only values can", rightChild.Value, root.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(rightChild),
+					Node:       rightChild,
 				}
 			}
-			if rightChild.Value == "concat" {
+			if rightChild.Value == "concat" || isBoundFunction(rightChild) || isNumericFunctionNode(rightChild) {
 				return db, &InvalidQueryError{
-					Msg: "concat not supported as right operand of equality operators",
+					Msg:        fmt.Sprintf("function '%s' cannot be the right operand of '%s', only values can", rightChild.Value, root.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(rightChild),
+					Node:       rightChild,
+				}
+			}
+			if isArithmeticNode(rightChild) {
+				return db, &InvalidQueryError{
+					Msg:        fmt.Sprintf("arithmetic '%s' cannot be the right operand of '%s', only values can", nodeExpression(rightChild), root.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(rightChild),
+					Node:       rightChild,
+				}
+			}
+			// Nothing is smaller or larger than null, so only eq and ne can compare with it
+			if root.Value != "eq" && root.Value != "ne" && (isNullOperand(leftChild) || isNullOperand(rightChild)) {
+				return db, &InvalidQueryError{
+					Msg:        fmt.Sprintf("'%s' cannot compare with null, only 'eq' and 'ne' can", root.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(root),
+					Node:       root,
 				}
 			}
 			if rightChild.Type == syntaxtree.RightOperand {
-				queryRightOperandString = strings.ReplaceAll(rightChild.Value, "'", "")
+				queryRightOperandString = unquote(rightChild.Value)
+			}
+			// Numbers with a type suffix (e.g. 10L or 2.5M) are written without it in the filters of relations
+			if rightChild.Type == syntaxtree.RightOperand && isNumericSuffixLiteral(rightChild.Value) {
+				queryRightOperandString = numericSQL(literalValue(rightChild.Value))
+			}
+
+			// References to another entity set are compared with the result of a subquery (see WithRootEntitySets)
+			var rootReference *clause.Expr
+			if rightChild.Type == syntaxtree.RightOperand && isRootReference(rightChild.Value) {
+				if translation.isRelationPath(leftChild.Value) {
+					return db, rootReferenceError(rightChild, fmt.Sprintf("relation property '%s' cannot be compared with a reference", leftChild.Value))
+				}
+				reference, err := buildRootReference(db, translation, rightChild)
+				if err != nil {
+					return db, err
+				}
+				rootReference = &reference
 			}
 
 			// If the leftoperand contains an expansion token ('/') then it should create a map according to this format
-			// Needs gorm-deep-filtering (https://github.com/survivorbat/gorm-deep-filtering) enabled and gorm-query-qonvert (https://github.com/survivorbat/gorm-query-convert)
-			filterMap := map[string]any{}
-			currentMap := filterMap
-			if strings.Contains(leftChild.Value, "/") {
-				queryRightOperandString = strings.ReplaceAll(queryRightOperandString, "'", "")
-				fieldSplit := strings.Split(leftChild.Value, "/")
-				for i, field := range fieldSplit {
-					fieldSnakeCase := columnTranslation(field)
-					if i < len(fieldSplit)-1 {
-						currentMap[fieldSnakeCase] = map[string]any{}
-						currentMap = currentMap[fieldSnakeCase].(map[string]any)
-						continue
-					}
-					currentMap[fieldSnakeCase] = queryRightOperandString
-					if root.Value != "eq" {
-						currentMap[fieldSnakeCase] = gqTranslation[root.Value] + currentMap[fieldSnakeCase].(string)
-					}
+			// The nested map is resolved into subqueries by the nestedFilterPlugin and needs gorm-query-qonvert (https://github.com/survivorbat/gorm-query-convert)
+			if translation.isRelationPath(leftChild.Value) && isNowCall(rightChild) {
+				return db, &InvalidQueryError{
+					Msg:        fmt.Sprintf("relation property '%s' cannot be compared with now()", leftChild.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(root),
+					Node:       rightChild,
 				}
-				db = db.Where(filterMap)
+			}
+			if translation.isRelationPath(leftChild.Value) && (isBinaryLiteral(rightChild.Value) || isGeoLiteral(rightChild.Value) || isTimeOfDayLiteral(rightChild.Value)) {
+				return db, &InvalidQueryError{
+					Msg:        fmt.Sprintf("relation property '%s' cannot be compared with literal %s", leftChild.Value, rightChild.Value),
+					Err:        ErrUnsupportedOperator,
+					Expression: nodeExpression(root),
+					Node:       rightChild,
+				}
+			}
+			if translation.isRelationPath(leftChild.Value) {
+				db = whereNestedFilter(db, buildNestedFilter(leftChild.Value, root.Value, queryRightOperandString, translation), notEnabled)
 			} else {
-				queryString := fmt.Sprintf("%s %s ?", queryLeftOperandString, opTranslation[root.Value])
-				if queryRightOperandInt, err := strconv.Atoi(queryRightOperandString); err == nil {
-					db = db.Where(queryString, queryRightOperandInt)
-				} else {
-					db = db.Where(queryString, queryRightOperandString)
+				// The elements of lambdas have no column type, so only numbers that are not quoted are compared as numbers,
+				// on postgres the elements are text and are cast to compare them with numbers
+				_, element := translation.elementColumn(leftChild.Value)
+				element = element && leftChild.Type == syntaxtree.LeftOperand
+				_, isInteger := integerLiteral(queryRightOperandString)
+				// Functions that return text compare quoted numbers as text as well
+				isInteger = isInteger && !((element || textResult) && isStringLiteral(rightChild.Value))
+				if element && (isInteger || isNumericSuffixLiteral(rightChild.Value)) && translation.databaseType == PostgreSQL {
+					queryLeftOperandString = "CAST(" + queryLeftOperandString + " AS numeric)"
+				}
+				if leftChild.Type == syntaxtree.LeftOperand && rightChild.Type == syntaxtree.RightOperand && isTimeOfDayLiteral(rightChild.Value) {
+					queryLeftOperandString = timeOfDayColumn(queryLeftOperandString, translation.databaseType)
+				}
+				// Comparisons with null check whether the operand is null, since nothing equals null in SQL
+				if rightChild.Type == syntaxtree.RightOperand && rightChild.Value == "null" && (opTranslation[root.Value] == "=" || opTranslation[root.Value] == "!=") {
+					db = db.Where(nullCheck(queryLeftOperandString, opTranslation[root.Value]), leftArgs...)
+
+					break
+				}
+				comparisonSQL := func(operand string) string {
+					// Following odata, comparisons with null are false, so negated comparisons are true for null values
+					if translation.nullSafe && (opTranslation[root.Value] == "!=" || (notEnabled && root.Value != "ne")) {
+						return nullSafeComparison(translation.databaseType, operand, opTranslation[root.Value])
+					}
+
+					return operand + " " + opTranslation[root.Value] + " ?"
+				}
+				queryString := comparisonSQL(queryLeftOperandString)
+				var queryRightOperand any = queryRightOperandString
+				if queryRightOperandInt, ok := integerLiteral(queryRightOperandString); ok && isInteger {
+					queryRightOperand = queryRightOperandInt
+				}
+				// Functions that return numbers are compared with decimals as numbers, not as text
+				if value, ok := literalValue(rightChild.Value).(float64); ok && isNumericFunctionNode(leftChild) && rightChild.Type == syntaxtree.RightOperand {
+					queryRightOperand = value
 				}
+				// Numbers with a type suffix are bound as their type, decimals are cast by the database so they keep their precision
+				if numeric, ok := bindNumericLiteral(rightChild.Value, translation.databaseType); ok && rightChild.Type == syntaxtree.RightOperand {
+					queryRightOperand = numeric
+				}
+				// Binary literals are bound as bytes, so every driver sends them as bytea, VARBINARY or BLOB
+				if rightChild.Type == syntaxtree.RightOperand && isBinaryLiteral(rightChild.Value) {
+					queryRightOperand = []byte(binaryLiteral(rightChild.Value))
+				}
+				// Geo literals become a shape of the spatial extension of the database (e.g. ST_GeomFromText on PostGIS)
+				if rightChild.Type == syntaxtree.RightOperand && isGeoLiteral(rightChild.Value) {
+					queryRightOperand = bindGeoLiteral(geoLiteral(rightChild.Value), translation.databaseType)
+				}
+				// Datetimeoffset literals are compared in UTC or with their offset (see WithDateTimeOffsetMode)
+				if value, ok := dateTimeOffsetLiteral(rightChild.Value); ok && rightChild.Type == syntaxtree.RightOperand {
+					queryRightOperand = bindDateTimeOffset(value, translation.databaseType, translation.dateTimeOffsetMode)
+				}
+				// Time literals are cast to the time type of the database, so they are not compared as text
+				if rightChild.Type == syntaxtree.RightOperand && isTimeOfDayLiteral(rightChild.Value) {
+					queryRightOperand = bindTimeOfDayLiteral(timeOfDayLiteral(rightChild.Value), translation.databaseType)
+				}
+				// now() is the function of the database or the time of the build (see WithServerNow)
+				if isNowCall(rightChild) {
+					queryRightOperand = bindNow(translation)
+				}
+				// Comparisons on a plain column get the literal converted to the type of the column (e.g. uuid) once the model is known
+				switch {
+				case rootReference != nil:
+					db = db.Where(queryString, append(leftArgs, *rootReference)...)
+				case extremumProperty(leftChild) != nil && rightChild.Type == syntaxtree.RightOperand:
+					// The values compared with greatest or least are converted to the type of their properties (e.g. time columns)
+					db = db.Where(columnComparison{Column: translation.columnName(extremumProperty(leftChild).Value), SQL: queryString, Value: queryRightOperand})
+				case leftChild.Type == syntaxtree.LeftOperand:
+					comparison := columnComparison{Column: queryLeftOperandString, SQL: queryString, Value: queryRightOperand}
+					// Strings compared for equality ignore the case on case-insensitive fields (see WithCaseInsensitiveFields)
+					if !element && isStringLiteral(rightChild.Value) && (opTranslation[root.Value] == "=" || opTranslation[root.Value] == "!=") {
+						comparison.FoldedSQL = comparisonSQL("LOWER(" + queryLeftOperandString + ")")
+						if translation.isCaseInsensitive(leftChild.Value) {
+							comparison = comparison.fold()
+						}
+					}
+					db = db.Where(comparison)
+				default:
+					db = db.Where(queryString, append(leftArgs, queryRightOperand)...)
+				}
+			}
+		case inOperator:
+			var err error
+			db, err = buildInCondition(db, root, translation, notEnabled)
+			if err != nil {
+				return db, err
+			}
+		case "any", "all":
+			return buildLambda(root, db, translation, notEnabled)
+		case "has":
+			return buildHas(root, db, translation, notEnabled)
+		case "add", "sub", "mul", "div", "divby", "mod":
+			return db, &InvalidQueryError{
+				Msg:        fmt.Sprintf("arithmetic '%s' cannot be the root of a filter, compare it with a value instead", nodeExpression(root)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(root),
+				Node:       root,
+			}
+		case "soundslike":
+			return buildSoundsLike(root, db, translation, notEnabled)
+		case levenshteinFunction:
+			return db, &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' cannot be the root of a filter, compare it with a number instead (e.g. %s le 2)", root.Value, nodeExpression(root)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(root),
+				Node:       root,
+			}
+		case regexReplaceFunction:
+			return db, &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' cannot be the root of a filter, compare it with a string instead (e.g. %s eq '123')", root.Value, nodeExpression(root)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(root),
+				Node:       root,
+			}
+		case greatestFunction, leastFunction, roundFunction, powerFunction:
+			return db, &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' cannot be the root of a filter, compare it with a value instead (e.g. %s gt 5)", root.Value, nodeExpression(root)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(root),
+				Node:       root,
+			}
+		case nullIfFunction:
+			return db, &InvalidQueryError{
+				Msg:        fmt.Sprintf("function '%s' cannot be the root of a filter, compare it with a value instead (e.g. %s ne null)", root.Value, nodeExpression(root)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(root),
+				Node:       root,
 			}
 		case "contains", "startswith", "endswith":
 			// Build up left child
 			leftChild := root.LeftChild
 			queryLeftOperandString := ""
 			if leftChild.Type == syntaxtree.UnaryOperator {
-				queryLeftOperandString = buildUnaryFuncChain(databaseType, columnTranslation, leftChild)
+				queryLeftOperandString = buildUnaryFuncChain(translation, leftChild)
 			}
 			if leftChild.Value == "concat" {
-				queryLeftOperandString = buildConcat(databaseType, columnTranslation, leftChild)
+				queryLeftOperandString = buildConcat(translation, leftChild)
 			}
 			if leftChild.Type == syntaxtree.LeftOperand {
-				queryLeftOperandString = columnTranslation(leftChild.Value)
+				queryLeftOperandString = translation.columnName(leftChild.Value)
 			}
 
 			// Build up right child
-			queryRightOperandString := root.RightChild.Value
+			queryRightOperandString := unquote(root.RightChild.Value)
 			escapeContains := false
-			rightOperandTranslation := map[string]string{
-				"contains":   `%$1%`,
-				"startswith": `$1%`,
-				"endswith":   `%$1`,
-			}
 			if strings.Contains(queryRightOperandString, "%") {
 				queryRightOperandString = strings.ReplaceAll(queryRightOperandString, "%", "\\%")
 				escapeContains = true
 			}
 
-			queryRightOperandString = regexp.MustCompile(`\s*'(.*)'\s*`).ReplaceAllString(queryRightOperandString, rightOperandTranslation[root.Value])
+			// Only string literals are turned into a pattern, other operands are matched as they are
+			if isStringLiteral(root.RightChild.Value) {
+				queryRightOperandString = fmt.Sprintf(likePatterns[root.Value], queryRightOperandString)
+			}
 
 			// If the leftoperand contains an expansion token ('/') then it should create a map according to this format
-			// Needs gorm-deep-filtering (https://github.com/survivorbat/gorm-deep-filtering) enabled and gorm-query-qonvert (https://github.com/survivorbat/gorm-query-convert)
-			filterMap := map[string]any{}
-			currentMap := filterMap
-			if strings.Contains(leftChild.Value, "/") {
-				queryRightOperandString = strings.ReplaceAll(queryRightOperandString, "'", "")
-				fieldSplit := strings.Split(leftChild.Value, "/")
-				for i, field := range fieldSplit {
-					fieldSnakeCase := columnTranslation(field)
-					if i < len(fieldSplit)-1 {
-						currentMap[fieldSnakeCase] = map[string]any{}
-						currentMap = currentMap[fieldSnakeCase].(map[string]any)
-						continue
-					}
-					currentMap[fieldSnakeCase] = gqTranslation[root.Value] + queryRightOperandString
-				}
-				db = db.Where(filterMap)
+			// The nested map is resolved into subqueries by the nestedFilterPlugin and needs gorm-query-qonvert (https://github.com/survivorbat/gorm-query-convert)
+			if translation.isRelationPath(leftChild.Value) {
+				db = whereNestedFilter(db, buildNestedFilter(leftChild.Value, root.Value, queryRightOperandString, translation), notEnabled)
 			} else {
 				replacementString := "%s LIKE ?"
 				if notEnabled {
@@ -516,163 +810,314 @@ func buildGormQuery(root *syntaxtree.Node, db *gorm.DB, databaseType DbType, opT
 				if escapeContains {
 					replacementString += " ESCAPE '\\'"
 				}
-				queryString := fmt.Sprintf(replacementString, queryLeftOperandString)
-				db = db.Where(queryString, queryRightOperandString)
+				if translation.nullSafe && notEnabled {
+					replacementString = "(" + replacementString + " OR %[1]s IS NULL)"
+				}
+				// Patterns on a plain column cast the column to text when it is not text (see columnPattern)
+				if leftChild.Type == syntaxtree.LeftOperand {
+					db = db.Where(columnPattern{Column: queryLeftOperandString, SQL: replacementString, Value: queryRightOperandString, DatabaseType: translation.databaseType})
+				} else {
+					queryString := fmt.Sprintf(replacementString, queryLeftOperandString)
+					db = db.Where(queryString, queryRightOperandString)
+				}
 			}
 		}
 	case syntaxtree.UnaryOperator:
-		if root.Value != "not" {
-			return db, &InvalidQueryError{
-				Msg: "root level operators other then 'not' are not supported",
-			}
+		msg := fmt.Sprintf("unary function '%s' cannot be the root of a filter, compare it with a value instead", root.Value)
+		if notEnabled {
+			msg = fmt.Sprintf("unary function '%s' cannot be negated with 'not', compare it with a value instead", root.Value)
 		}
-		var err error
-		db, err = buildGormQuery(root.LeftChild, db, databaseType, operatorTranslationReversed, gormqonvertTranslationReversed, columnTranslation, true)
-		if err != nil {
-			return db, err
+
+		return db, &InvalidQueryError{
+			Msg:        msg,
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(root),
+			Node:       root,
 		}
 	default:
 		return db, &InvalidQueryError{
-			Msg: "unknown query type",
+			Msg:        fmt.Sprintf("operand '%s' cannot be a filter on its own, compare it with a value instead", root.Value),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(root),
+			Node:       root,
 		}
 	}
 
 	return db, nil
 }
 
-func buildConcat(databaseType DbType, columnTranslation func(string) string, root *syntaxtree.Node) string {
-	result := ""
-	if root.Value == "concat" {
-		result = fmt.Sprintf("%s || %s", buildConcat(databaseType, columnTranslation, root.LeftChild), buildConcat(databaseType, columnTranslation, root.RightChild))
+// integerLiteral
+// returns the value of an integer literal, other values are not parsed so they do not allocate a parse error
+func integerLiteral(value string) (int, bool) {
+	if value == "" || (value[0] != '-' && value[0] != '+' && (value[0] < '0' || value[0] > '9')) {
+		return 0, false
+	}
+
+	integer, err := strconv.Atoi(value)
+
+	return integer, err == nil
+}
+
+// nodeExpression
+// returns the part of the filter that the node was parsed from (e.g. "length(name) gt 5"), to point out that part in errors
+func nodeExpression(node *syntaxtree.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	expression := node.Value
+	switch {
+	case isLambdaNode(node):
+		// The range variable is not kept in the tree, so the lambda is written like Expr.String writes it
+		expression = newExpr(node).String()
+	case node.Type == syntaxtree.UnaryOperator:
+		expression = fmt.Sprintf("%s(%s)", node.Value, nodeExpression(node.LeftChild))
+	case (node.Type == syntaxtree.Operator && odataParser.isBinaryFunction(node.Value)) || isRoundNode(node):
+		expression = fmt.Sprintf("%s(%s,%s)", node.Value, nodeExpression(node.LeftChild), nodeExpression(node.RightChild))
+	case node.Type == syntaxtree.Operator && node.Value == regexReplaceArguments:
+		expression = nodeExpression(node.LeftChild) + "," + nodeExpression(node.RightChild)
+	case node.Type == syntaxtree.Operator:
+		expression = fmt.Sprintf("%s %s %s", nodeExpression(node.LeftChild), node.Value, nodeExpression(node.RightChild))
+	}
+
+	if node.IsGroup {
+		expression = "(" + expression + ")"
+	}
+
+	return expression
+}
+
+// buildNestedFilter
+// builds the nested filter map for an expanded property (e.g. metadata/tag/value) that is resolved by the nestedFilterPlugin
+//
+//	{"metadata": {"tag": {"value": "<gormqonvert prefix><value>"}}}
+//
+// the operator is never reversed, negations are applied on the whole nested filter (see whereNestedFilter)
+func buildNestedFilter(property string, operator string, value string, translation *queryTranslation) map[string]any {
+	var filter any = value
+	if operator != "eq" {
+		filter = translation.qonvert.prefixes[operator] + value
+	}
+
+	return nestedFilterMap(property, filter, translation)
+}
+
+// nestedFilterMap
+// builds the nested filter map with the filter as the value of the last field of the property path
+func nestedFilterMap(property string, filter any, translation *queryTranslation) map[string]any {
+	// The maps are built from the last field of the path to the first, so every level is created once
+	for {
+		index := strings.LastIndexByte(property, '/')
+		filterMap := map[string]any{translation.columnName(property[index+1:]): filter}
+		if index < 0 {
+			return filterMap
+		}
+		filter, property = filterMap, property[:index]
 	}
-	if root.Type == syntaxtree.UnaryOperator {
-		result = buildUnaryFuncChain(databaseType, columnTranslation, root)
+}
+
+// whereNestedFilter
+// adds the nested filter to the query, negated filters become NOT IN / NOT EXISTS subqueries
+func whereNestedFilter(db *gorm.DB, filterMap map[string]any, notEnabled bool) *gorm.DB {
+	if notEnabled {
+		return db.Not(filterMap)
 	}
 
-	if root.Type == syntaxtree.LeftOperand || root.Type == syntaxtree.RightOperand {
-		result = root.Value
-		if !strings.Contains(result, "'") {
-			result = columnTranslation(result)
+	return db.Where(filterMap)
+}
+
+// nullSafeComparison
+// builds a comparison that also matches null values of the operand, using the null-safe operators of the database if available
+func nullSafeComparison(databaseType DbType, operand string, operator string) string {
+	if operator == "!=" {
+		switch databaseType {
+		case PostgreSQL:
+			return fmt.Sprintf("%s IS DISTINCT FROM ?", operand)
+		case MySQL:
+			return fmt.Sprintf("NOT (%s <=> ?)", operand)
+		case SQLite:
+			return fmt.Sprintf("%s IS NOT ?", operand)
 		}
 	}
 
-	return result
+	return fmt.Sprintf("(%s %s ? OR %s IS NULL)", operand, operator, operand)
 }
 
-func buildUnaryFuncChain(databaseType DbType, columnTranslation func(string) string, root *syntaxtree.Node) string {
-	result := ""
-	nodesVisited := map[int]bool{}
-	for !nodesVisited[root.Id] && root.Type == syntaxtree.UnaryOperator {
-		if root.LeftChild != nil && root.LeftChild.Type == syntaxtree.UnaryOperator && !nodesVisited[root.LeftChild.Id] {
-			root = root.LeftChild
+// isNullOperand
+// returns whether the node is the null literal
+func isNullOperand(node *syntaxtree.Node) bool {
+	return (node.Type == syntaxtree.LeftOperand || node.Type == syntaxtree.RightOperand) && node.Value == "null"
+}
+
+// nullCheck
+// returns whether the operand is null for = and whether it is not null for !=
+func nullCheck(operand string, operator string) string {
+	if operator == "!=" {
+		return operand + " IS NOT NULL"
+	}
+
+	return operand + " IS NULL"
+}
+
+// isBoundFunction
+// returns whether the node calls a function whose arguments are bound instead of written in the query (see buildBoundFunction)
+func isBoundFunction(node *syntaxtree.Node) bool {
+	return node.Type == syntaxtree.Operator && (node.Value == levenshteinFunction || node.Value == regexReplaceFunction || node.Value == nullIfFunction)
+}
+
+// buildBoundFunction
+// builds a function that is compared with a value, with the values to bind for its arguments and whether it returns text
+func buildBoundFunction(translation *queryTranslation, node *syntaxtree.Node) (string, []any, bool, error) {
+	switch node.Value {
+	case levenshteinFunction:
+		query, value, err := buildLevenshtein(translation, node)
+
+		return query, []any{value}, false, err
+	case regexReplaceFunction:
+		query, values, err := buildRegexReplace(translation, node)
+
+		return query, values, true, err
+	default:
+		query, value, err := buildNullIf(translation, node)
+
+		return query, []any{value}, isStringLiteral(node.RightChild.Value), err
+	}
+}
+
+func buildConcat(translation *queryTranslation, root *syntaxtree.Node) string {
+	var result strings.Builder
+
+	// The arguments are written from left to right, the stack holds the arguments that are not written yet
+	var buffer [8]*syntaxtree.Node
+	stack := append(buffer[:0], root)
+	for written := false; len(stack) > 0; {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node.Value == "concat" && node.Type == syntaxtree.Operator {
+			stack = append(stack, node.RightChild, node.LeftChild)
+
 			continue
 		}
-		nodesVisited[root.Id] = true
-		if result == "" {
-			if strings.Contains(unaryFunctionTranslation[databaseType][root.Value], "%") {
-				result = fmt.Sprintf(unaryFunctionTranslation[databaseType][root.Value], columnTranslation(root.LeftChild.Value))
+
+		if written {
+			result.WriteString(" || ")
+		}
+		written = true
+		if node.Type == syntaxtree.UnaryOperator {
+			result.WriteString(buildUnaryFuncChain(translation, node))
+		}
+		if node.Type == syntaxtree.LeftOperand || node.Type == syntaxtree.RightOperand {
+			if strings.Contains(node.Value, "'") {
+				result.WriteString(node.Value)
 			} else {
-				result = fmt.Sprintf("%s(%s)", unaryFunctionTranslation[databaseType][root.Value], columnTranslation(root.LeftChild.Value))
+				result.WriteString(translation.columnName(node.Value))
 			}
-		} else {
-			result = fmt.Sprintf("%s(%s)", unaryFunctionTranslation[databaseType][root.Value], result)
-		}
-
-		if root.Parent != nil {
-			root = root.Parent
 		}
 	}
 
-	return result
+	return result.String()
 }
 
-func checkDbPlugins(db *gorm.DB) (*gorm.DB, error) {
-	if _, ok := db.Plugins[deepgorm.New().Name()]; !ok {
-		if err := db.Use(deepgorm.New()); err != nil {
-			return db, err
-		}
+func buildUnaryFuncChain(translation *queryTranslation, root *syntaxtree.Node) string {
+	// The functions are applied from the innermost to the outermost, so the chain is collected first
+	var buffer [8]*syntaxtree.Node
+	chain := append(buffer[:0], root)
+	for chain[len(chain)-1].LeftChild.Type == syntaxtree.UnaryOperator {
+		chain = append(chain, chain[len(chain)-1].LeftChild)
 	}
-	if _, ok := db.Plugins[gormqonvert.New(gormqonvert.CharacterConfig{}).Name()]; ok {
-		plugin := db.Plugins[gormqonvert.New(gormqonvert.CharacterConfig{}).Name()]
-		pluginConfig := reflect.ValueOf(plugin).Elem().FieldByName("config")
-		if gormqonvertTranslationMap, cacheOk := cacheGormqonvertTranslationMap.Load("gormqonvertTranslation"); !cacheOk {
-			gormqonvertTranslation["gt"] = pluginConfig.FieldByName("GreaterThanPrefix").String()
-			gormqonvertTranslation["ge"] = pluginConfig.FieldByName("GreaterOrEqualToPrefix").String()
-			gormqonvertTranslation["lt"] = pluginConfig.FieldByName("LessThanPrefix").String()
-			gormqonvertTranslation["le"] = pluginConfig.FieldByName("LessOrEqualToPrefix").String()
-			gormqonvertTranslation["ne"] = pluginConfig.FieldByName("NotEqualToPrefix").String()
-			gormqonvertTranslation["contains"] = pluginConfig.FieldByName("LikePrefix").String()
-			gormqonvertTranslation["startswith"] = pluginConfig.FieldByName("LikePrefix").String()
-			gormqonvertTranslation["endswith"] = pluginConfig.FieldByName("LikePrefix").String()
-		} else {
-			gormqonvertTranslation = gormqonvertTranslationMap
-		}
-		if gormqonvertTranslationMap, cacheOk := cacheGormqonvertTranslationMap.Load("gormqonvertTranslationReversed"); !cacheOk {
-			gormqonvertTranslationReversed["gt"] = pluginConfig.FieldByName("LessThanPrefix").String()
-			gormqonvertTranslationReversed["ge"] = pluginConfig.FieldByName("LessOrEqualToPrefix").String()
-			gormqonvertTranslationReversed["lt"] = pluginConfig.FieldByName("GreaterThanPrefix").String()
-			gormqonvertTranslationReversed["le"] = pluginConfig.FieldByName("GreaterOrEqualToPrefix").String()
-			gormqonvertTranslationReversed["ne"] = ""
-			gormqonvertTranslationReversed["contains"] = pluginConfig.FieldByName("NotLikePrefix").String()
-			gormqonvertTranslationReversed["startswith"] = pluginConfig.FieldByName("NotLikePrefix").String()
-			gormqonvertTranslationReversed["endswith"] = pluginConfig.FieldByName("NotLikePrefix").String()
-			cacheGormqonvertTranslationMap.Store("gormqonvertTranslationReversed", gormqonvertTranslationReversed)
-		} else {
-			gormqonvertTranslationReversed = gormqonvertTranslationMap
+
+	result := buildArithmetic(translation, chain[len(chain)-1].LeftChild)
+	for index := len(chain) - 1; index >= 0; index-- {
+		if isNowCall(chain[index]) {
+			result = nowSQL(translation)
+			continue
 		}
-	} else {
-		config := gormqonvert.CharacterConfig{
-			GreaterThanPrefix:      gormqonvertTranslation["gt"],
-			GreaterOrEqualToPrefix: gormqonvertTranslation["ge"],
-			LessThanPrefix:         gormqonvertTranslation["lt"],
-			LessOrEqualToPrefix:    gormqonvertTranslation["le"],
-			NotEqualToPrefix:       gormqonvertTranslation["ne"],
-			LikePrefix:             gormqonvertTranslation["contains"],
-			NotLikePrefix:          gormqonvertTranslationReversed["contains"],
+		function := unaryFunctionTranslation[translation.databaseType][chain[index].Value]
+		if chain[index].Value == fractionalSecondsFunction {
+			function = fractionalSecondsTranslation[translation.databaseType][translation.fractionalSecondsPrecision]
 		}
-		if err := db.Use(gormqonvert.New(config)); err != nil {
-			return db, err
+		if strings.Contains(function, "%") {
+			result = fmt.Sprintf(function, result)
+		} else {
+			result = function + "(" + result + ")"
 		}
-		cacheGormqonvertTranslationMap.Store("gormqonvertTranslation", gormqonvertTranslation)
-		cacheGormqonvertTranslationMap.Store("gormqonvertTranslationReversed", gormqonvertTranslationReversed)
+	}
+
+	return result
+}
+
+// checkDbPlugins
+// registers the plugins that the built queries need if they are not registered yet,
+// gormqonvert is registered with the given config or the default config if it is nil
+//
+// the plugins are only checked on the first build on a db (see pluginSetup), later builds only look up the setup of the db
+//
+// the config of gormqonvert is stored for the plugin when it is registered here, gormqonvert does not expose it,
+// so builds on dbs where it was registered outside of this package need the config of WithQonvertConfig
+func checkDbPlugins(db *gorm.DB, qonvert *qonvertTranslation) (*gorm.DB, error) {
+	// Only the first build on a db needs a new setup
+	setup, ok := pluginSetups.Load(db.Callback())
+	pluginSetupCounters.record(ok)
+	if !ok {
+		setup, _ = pluginSetups.LoadOrStore(db.Callback(), &pluginSetup{})
+	}
+	if err := setup.register(db, qonvert); err != nil {
+		return db, err
 	}
 
 	return db, nil
 }
 
-func validateQueryDepthFirstSearch(tree *syntaxtree.SyntaxTree, validationChecks ...func(depth int, currentNode *syntaxtree.Node) error) error {
+// validateQueryDepthFirstSearch
+// runs the validation checks on every node of the tree and returns the first error,
+// or all errors as QueryErrors when the db collects all errors (see WithCollectAllErrors)
+func validateQueryDepthFirstSearch(db *gorm.DB, tree *syntaxtree.SyntaxTree, validationChecks ...func(depth int, currentNode *syntaxtree.Node) error) error {
 	depth := 0
-	currentNode := tree.Root
-	nodesVisited := map[int]bool{}
+	collectAll := collectAllErrors(db)
+	queryErrors := QueryErrors{}
 
-	for !nodesVisited[currentNode.Id] {
-		for _, validationCheck := range validationChecks {
-			if err := validationCheck(depth, currentNode); err != nil {
-				return err
-			}
-		}
-		if currentNode.Type == syntaxtree.Operator || currentNode.Type == syntaxtree.UnaryOperator {
-			if currentNode.LeftChild != nil && !nodesVisited[currentNode.LeftChild.Id] {
-				currentNode = currentNode.LeftChild
-				depth += 1
-
-				continue
+	// The node the walk comes from tells whether a node is entered or left through one of its children,
+	// so no nodes have to be remembered
+	var previousNode *syntaxtree.Node
+	currentNode := tree.Root
+	for currentNode != nil {
+		hasChildren := currentNode.Type == syntaxtree.Operator || currentNode.Type == syntaxtree.UnaryOperator
+		var nextNode *syntaxtree.Node
+		switch {
+		case previousNode == nil || previousNode == currentNode.Parent:
+			for _, validationCheck := range validationChecks {
+				if err := validationCheck(depth, currentNode); err != nil {
+					if !collectAll {
+						return err
+					}
+					queryErrors = append(queryErrors, err)
+				}
 			}
-			if currentNode.RightChild != nil && !nodesVisited[currentNode.RightChild.Id] {
-				currentNode = currentNode.RightChild
-				depth += 1
-
-				continue
+			if hasChildren && currentNode.LeftChild != nil {
+				nextNode = currentNode.LeftChild
+			} else if hasChildren {
+				nextNode = currentNode.RightChild
 			}
-
+		case previousNode == currentNode.LeftChild && hasChildren:
+			nextNode = currentNode.RightChild
 		}
 
-		nodesVisited[currentNode.Id] = true
+		previousNode = currentNode
+		if nextNode != nil {
+			currentNode = nextNode
+			depth += 1
 
-		if currentNode.Parent != nil {
-			currentNode = currentNode.Parent
-			depth -= 1
+			continue
 		}
+		if currentNode == tree.Root {
+			break
+		}
+		currentNode = currentNode.Parent
+		depth -= 1
+	}
+
+	if len(queryErrors) > 0 {
+		return queryErrors
 	}
 
 	return nil
@@ -692,9 +1137,12 @@ func columnNames(input any, schemaNamer schema.Namer) []string {
 	tableName := tableName(input, schemaNamer)
 	typeOf := reflect.TypeOf(input)
 	flds := typeOf.NumField()
-	res := make([]string, flds)
+	res := make([]string, 0, flds)
 	for i := range flds {
 		fld := typeOf.Field(i)
+		if isHiddenTag(fld.Tag) {
+			continue
+		}
 		name := fld.Name
 
 		var gormName string
@@ -712,7 +1160,7 @@ func columnNames(input any, schemaNamer schema.Namer) []string {
 			gormName = schemaNamer.ColumnName(tableName, name)
 		}
 
-		res[i] = gormName
+		res = append(res, gormName)
 	}
 
 	return res
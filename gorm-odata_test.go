@@ -1,7 +1,12 @@
 package gormodata
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +16,7 @@ import (
 	"github.com/stoewer/go-strcase"
 	gormqonvert "github.com/survivorbat/gorm-query-convert"
 	"github.com/test-go/testify/assert"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
@@ -75,7 +81,7 @@ func Test_BuildQuery_CorrectQueryForDbType(t *testing.T) {
 		},
 		"SQLServer": {
 			queryString: "year(createdAt) gt 2025 and time(createdAt) lt '01:12:00'",
-			expectedSql: "SELECT * FROM `mock_time_models` WHERE YEAR(created_at) > 2025 AND TIME(created_at) < \"01:12:00\"",
+			expectedSql: "SELECT * FROM `mock_time_models` WHERE YEAR(created_at) > 2025 AND CAST(created_at AS time) < \"01:12:00\"",
 			dbType:      SQLServer,
 		},
 		"SQLite": {
@@ -106,6 +112,341 @@ func Test_BuildQuery_CorrectQueryForDbType(t *testing.T) {
 	}
 }
 
+func Test_BuildQuery_NullSafeComparisons(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+		dbType      DbType
+	}{
+		"PostgreSQL ne": {
+			queryString: "ownerRef ne 'owner-1'",
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref IS DISTINCT FROM \"owner-1\"",
+			dbType:      PostgreSQL,
+		},
+		"MySQL ne": {
+			queryString: "ownerRef ne 'owner-1'",
+			expectedSql: "SELECT * FROM `pets` WHERE NOT (owner_ref <=> \"owner-1\")",
+			dbType:      MySQL,
+		},
+		"SQLite ne": {
+			queryString: "ownerRef ne 'owner-1'",
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref IS NOT \"owner-1\"",
+			dbType:      SQLite,
+		},
+		"SQLServer ne": {
+			queryString: "ownerRef ne 'owner-1'",
+			expectedSql: "SELECT * FROM `pets` WHERE (owner_ref != \"owner-1\" OR owner_ref IS NULL)",
+			dbType:      SQLServer,
+		},
+		"not eq": {
+			queryString: "not(ownerRef eq 'owner-1')",
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref IS NOT \"owner-1\"",
+			dbType:      SQLite,
+		},
+		"not ne": {
+			queryString: "not(ownerRef ne 'owner-1')",
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref = \"owner-1\"",
+			dbType:      SQLite,
+		},
+		"not gt": {
+			queryString: "not(length(ownerRef) gt 5)",
+			expectedSql: "SELECT * FROM `pets` WHERE (LENGTH(owner_ref) <= 5 OR LENGTH(owner_ref) IS NULL)",
+			dbType:      SQLite,
+		},
+		"not startswith": {
+			queryString: "not(startswith(ownerRef,'owner'))",
+			expectedSql: "SELECT * FROM `pets` WHERE (owner_ref NOT LIKE \"owner%\" OR owner_ref IS NULL)",
+			dbType:      SQLite,
+		},
+	}
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{})
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx.Set(NullSafeSetting, true), testData.dbType)
+				return dbQuery.Find(&Pet{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NotNil(t, dbQuery)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQuery_NullSafeComparisonsResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	db.Create(&Pet{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "rex", OwnerRef: ptr("owner-1"), Owner: &Owner{OwnerKey: "owner-1"}})
+	db.Create(&Pet{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "tom", OwnerRef: ptr("owner-2"), Owner: &Owner{OwnerKey: "owner-2"}})
+	db.Create(&Pet{ID: uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"), Name: "stray"})
+
+	expectedResult := []Pet{
+		{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "tom", OwnerRef: ptr("owner-2")},
+		{ID: uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"), Name: "stray"},
+	}
+
+	// Act
+	var result []Pet
+	dbQuery, err := BuildQuery("ownerRef ne 'owner-1'", db.Set(NullSafeSetting, true), SQLite)
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedResult, result)
+}
+
+func Test_BuildQuery_UUIDLiterals(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query          string
+		expectedResult []MockModel
+	}{
+		"uppercase uuid": {
+			query: "id eq '885B50A8-F2D2-4FC2-B8E8-4DB54F5EF5B6'",
+			expectedResult: []MockModel{
+				{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test", MetadataID: ptr(uuid.MustParse("96954f52-f87c-4ec2-9af5-3e13642bdc83"))},
+			},
+		},
+		"uuid without hyphens": {
+			query: "id eq '885b50a8f2d24fc2b8e84db54f5ef5b6'",
+			expectedResult: []MockModel{
+				{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test", MetadataID: ptr(uuid.MustParse("96954f52-f87c-4ec2-9af5-3e13642bdc83"))},
+			},
+		},
+		"nested uuid": {
+			query: "metadata/id eq '96954F52-F87C-4EC2-9AF5-3E13642BDC83'",
+			expectedResult: []MockModel{
+				{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test", MetadataID: ptr(uuid.MustParse("96954f52-f87c-4ec2-9af5-3e13642bdc83"))},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			db.Create(&Metadata{ID: uuid.MustParse("96954f52-f87c-4ec2-9af5-3e13642bdc83"), Name: "prd"})
+			db.Create(&MockModel{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "test", MetadataID: ptr(uuid.MustParse("96954f52-f87c-4ec2-9af5-3e13642bdc83"))})
+			db.Create(&MockModel{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "prd"})
+
+			// Act
+			var result []MockModel
+			dbQuery, err := BuildQuery(testData.query, db, SQLite)
+			queryResult := dbQuery.Find(&result)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedResult, result)
+		})
+	}
+}
+
+func Test_BuildQuery_PatternOnNonTextColumn(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query        string
+		model        any
+		expectedSqls map[DbType]string
+	}{
+		"uuid column": {
+			query: "contains(id,'885b')",
+			model: &MockModel{},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_models` WHERE CAST(id AS text) LIKE \"%885b%\"",
+				MySQL:      "SELECT * FROM `mock_models` WHERE CAST(id AS CHAR) LIKE \"%885b%\"",
+				SQLite:     "SELECT * FROM `mock_models` WHERE CAST(id AS TEXT) LIKE \"%885b%\"",
+				SQLServer:  "SELECT * FROM `mock_models` WHERE CAST(id AS nvarchar(max)) LIKE \"%885b%\"",
+			},
+		},
+		"integer column": {
+			query: "not(startswith(id,'80'))",
+			model: &Company{},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `companies` WHERE CAST(id AS text) NOT LIKE \"80%\"",
+				MySQL:      "SELECT * FROM `companies` WHERE CAST(id AS CHAR) NOT LIKE \"80%\"",
+				SQLite:     "SELECT * FROM `companies` WHERE CAST(id AS TEXT) NOT LIKE \"80%\"",
+				SQLServer:  "SELECT * FROM `companies` WHERE CAST(id AS nvarchar(max)) NOT LIKE \"80%\"",
+			},
+		},
+		"text column": {
+			query: "endswith(name,'prd')",
+			model: &Company{},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `companies` WHERE name LIKE \"%prd\"",
+				MySQL:      "SELECT * FROM `companies` WHERE name LIKE \"%prd\"",
+				SQLite:     "SELECT * FROM `companies` WHERE name LIKE \"%prd\"",
+				SQLServer:  "SELECT * FROM `companies` WHERE name LIKE \"%prd\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					var dbQuery *gorm.DB
+					dbQuery, err = BuildQuery(testData.query, tx, dbType)
+					return dbQuery.Find(testData.model)
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_PatternOnNonTextColumnResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Company{})
+	db.Create(&Company{ID: 80, Name: "http"})
+	db.Create(&Company{ID: 8080, Name: "proxy"})
+	db.Create(&Company{ID: 443, Name: "https"})
+
+	// Act
+	var result []Company
+	dbQuery, err := BuildQuery("startswith(id,'80')", db, SQLite)
+	queryResult := dbQuery.Order("id").Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 80, result[0].ID)
+	assert.Equal(t, 8080, result[1].ID)
+}
+
+func Test_BuildQuery_InvalidTypedLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query         string
+		model         any
+		expectedError string
+	}{
+		"uuid column": {
+			query:         "id eq 'not-a-uuid'",
+			model:         &[]MockModel{},
+			expectedError: "invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+		},
+		"nested uuid column": {
+			query:         "metadata/id ne 'not-a-uuid'",
+			model:         &[]MockModel{},
+			expectedError: "invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+		},
+		"time column": {
+			query:         "createdAt gt '01/02/2025'",
+			model:         &[]MockTimeModel{},
+			expectedError: "invalid query: invalid datetime literal '01/02/2025' for column 'created_at'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &MockTimeModel{})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.query, db, SQLite)
+			queryResult := dbQuery.Find(testData.model)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.EqualError(t, queryResult.Error, testData.expectedError)
+		})
+	}
+}
+
+func Test_BuildQuery_DatetimeLiterals(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query          string
+		expectedResult []string
+	}{
+		"rfc3339": {
+			query:          "createdAt gt '2025-03-01T12:00:00Z'",
+			expectedResult: []string{"afternoon", "next day"},
+		},
+		"rfc3339 with offset": {
+			query:          "createdAt lt '2025-03-01T17:00:00+02:00'",
+			expectedResult: []string{"morning"},
+		},
+		"date only": {
+			query:          "createdAt ge '2025-03-02'",
+			expectedResult: []string{"next day"},
+		},
+		"epoch milliseconds": {
+			query:          "createdAt le 1740830400000",
+			expectedResult: []string{"morning"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockTimeModel{})
+			db.Create(&MockTimeModel{Name: "morning", CreatedAt: time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)})
+			db.Create(&MockTimeModel{Name: "afternoon", CreatedAt: time.Date(2025, 3, 1, 15, 30, 0, 0, time.UTC)})
+			db.Create(&MockTimeModel{Name: "next day", CreatedAt: time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)})
+
+			// Act
+			var result []string
+			dbQuery, err := BuildQuery(testData.query, db.Model(&MockTimeModel{}), SQLite)
+			queryResult := dbQuery.Order("created_at").Pluck("name", &result)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedResult, result)
+		})
+	}
+}
+
 func Test_BuildQuery_CustomNamingStrategy(t *testing.T) {
 	t.Parallel()
 	t.Cleanup(cleanupCache)
@@ -520,7 +861,7 @@ func Test_BuildQuery_Success(t *testing.T) {
 				},
 			},
 			queryString: "not(contains(tolower(testValue),' ') and endswith(metadata/name,'prd')) and not(name eq 'test' or startswith(name,'prd'))",
-			expectedSql: "SELECT * FROM `mock_models` WHERE (LOWER(test_value) NOT LIKE \"% %\" OR metadata_id IN (SELECT `id` FROM `metadata` WHERE name NOT LIKE \"%prd\")) AND (name != \"test\" AND name NOT LIKE \"prd%\")",
+			expectedSql: "SELECT * FROM `mock_models` WHERE (LOWER(test_value) NOT LIKE \"% %\" OR (metadata_id IS NULL OR metadata_id NOT IN (SELECT `id` FROM `metadata` WHERE name LIKE \"%prd\"))) AND (name != \"test\" AND name NOT LIKE \"prd%\")",
 			expectedResult: []MockModel{
 				{
 					ID:         uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"),
@@ -681,12 +1022,13 @@ func Test_BuildQuery_SuccessCustomPluginConfig(t *testing.T) {
 	var dbQuery *gorm.DB
 	var err error
 	var result []MockModel
+	builder := New(WithDatabaseType(SQLite), WithQonvertConfig(config))
 	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		dbQuery, err = BuildQuery(queryString, tx, SQLite)
+		dbQuery, err = builder.Build(queryString, tx)
 		return dbQuery.Find(&MockModel{})
 	})
 
-	dbQuery, err = BuildQuery(queryString, db, SQLite)
+	dbQuery, err = builder.Build(queryString, db)
 
 	queryResult := dbQuery.Find(&result)
 
@@ -698,6 +1040,82 @@ func Test_BuildQuery_SuccessCustomPluginConfig(t *testing.T) {
 	assert.Equal(t, expectedResult, result)
 }
 
+func Test_BuildQuery_UnknownPluginConfig(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.Use(gormqonvert.New(gormqonvert.CharacterConfig{NotEqualToPrefix: "/="}))
+
+	// Act
+	_, err := BuildQuery("metadata/name ne 'prd'", db, SQLite)
+
+	// Assert
+	assert.EqualError(t, err, "the config of the gormqonvert plugin that was registered outside of gormodata is unknown, use WithQonvertConfig")
+}
+
+func Test_BuildQuery_ConcurrentPluginConfigs(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	defaultDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_default"))
+	_ = defaultDB.AutoMigrate(&MockModel{}, &Metadata{})
+
+	customDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_custom"))
+	_ = customDB.AutoMigrate(&MockModel{}, &Metadata{})
+	customConfig := gormqonvert.CharacterConfig{
+		GreaterThanPrefix:      "+",
+		GreaterOrEqualToPrefix: "+=",
+		LessThanPrefix:         "-",
+		LessOrEqualToPrefix:    "-=",
+		NotEqualToPrefix:       "/=",
+		LikePrefix:             "::",
+		NotLikePrefix:          "!::",
+	}
+	_ = customDB.Use(gormqonvert.New(customConfig))
+
+	defaultBuilder := New(WithDatabaseType(SQLite))
+	customBuilder := New(WithDatabaseType(SQLite), WithQonvertConfig(customConfig))
+
+	queryString := "metadata/name ne 'prd' and metadata/name ge 'acc'"
+	expectedSql := "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE name != \"prd\") AND metadata_id IN (SELECT `id` FROM `metadata` WHERE name >= \"acc\")"
+
+	// Act
+	results := make(chan string, 20)
+	errs := make(chan error, 20)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		db, builder := defaultDB, defaultBuilder
+		if i%2 == 0 {
+			db, builder = customDB, customBuilder
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err := builder.Build(queryString, tx)
+				errs <- err
+				return dbQuery.Find(&[]MockModel{})
+			})
+			results <- sqlQuery
+		}()
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	for sqlQuery := range results {
+		assert.Equal(t, expectedSql, sqlQuery)
+	}
+}
+
 func Test_BuildQuery_ObjectExpansion(t *testing.T) {
 	t.Cleanup(cleanupCache)
 
@@ -832,7 +1250,7 @@ func Test_BuildQuery_ErrorOnBuildTree(t *testing.T) {
 		},
 		"parse error last part": {
 			query:          "concat(name,'value') qe 'namevalue'",
-			expectedErrMsg: "failed to parse query: unexpected token \"qe'namevalue'\" (StringOperand) after \"concat\" (Operator)",
+			expectedErrMsg: "failed to parse query: unexpected token \"qe\" (Operand) after \"concat\" (Operator)",
 		},
 		"parse error first part": {
 			query:          "concot(name,'value') eq 'namevalue'",
@@ -857,6 +1275,312 @@ func Test_BuildQuery_ErrorOnBuildTree(t *testing.T) {
 	}
 }
 
+func Test_BuildQuery_LargeTrees(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	comparisons := make([]string, 2501)
+	conditions := make([]string, len(comparisons))
+	for index := range comparisons {
+		comparisons[index] = fmt.Sprintf("name eq 'v%d'", index)
+		conditions[index] = fmt.Sprintf("name = \"v%d\"", index)
+	}
+	last := len(conditions) - 1
+
+	// gorm groups the left operand of every 'or' of the chain: ((v0 OR v1) OR v2) OR v3
+	orChain := conditions[0]
+	for index, condition := range conditions[1:] {
+		if index > 0 {
+			orChain = "(" + orChain + ")"
+		}
+		orChain += " OR " + condition
+	}
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"long or chain": {
+			queryString: strings.Join(comparisons, " or "),
+			expectedSql: "SELECT * FROM `mock_models` WHERE " + orChain,
+		},
+		"deeply nested groups": {
+			queryString: strings.Join(comparisons, " or (") + strings.Repeat(")", len(comparisons)-1),
+			expectedSql: "SELECT * FROM `mock_models` WHERE " + strings.Join(conditions[:last], " OR (") + " OR " + conditions[last] + strings.Repeat(")", last-1),
+		},
+		"deep function chain": {
+			queryString: "length(" + strings.Repeat("trim(", 10000) + "name" + strings.Repeat(")", 10001) + " gt 3",
+			expectedSql: "SELECT * FROM `mock_models` WHERE LENGTH(" + strings.Repeat("TRIM(", 10000) + "name" + strings.Repeat(")", 10001) + " > 3",
+		},
+		"long concat": {
+			queryString: strings.Repeat("concat(", 5000) + "name" + strings.Repeat(",'a')", 5000) + " eq 'x'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name" + strings.Repeat(" || 'a'", 5000) + " = \"x\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{})
+			tree, err := GetAST(testData.queryString)
+			assert.NoError(t, err)
+			assert.True(t, len(tree.Nodes) >= 10000)
+
+			// Act
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				tx, err = BuildQuery(testData.queryString, tx, SQLite)
+
+				return tx.Find(&[]MockModel{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQueryFor(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+		expectedErr string
+	}{
+		"column": {
+			queryString: "name eq 'test'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"relation path": {
+			queryString: "metadata/tag/value eq 'test'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = \"test\"))",
+		},
+		"unknown column": {
+			queryString: "name eq 'test' and unknown eq 'test'",
+			expectedErr: "invalid query: unknown column name 'unknown'",
+		},
+		"unknown column in function": {
+			queryString: "concat(name,unknownValue) eq 'test'",
+			expectedErr: "invalid query: unknown column name 'unknown_value'",
+		},
+		"unknown relation": {
+			queryString: "owner/name eq 'test'",
+			expectedErr: "invalid query: unknown relation 'owner' on 'mock_models'",
+		},
+		"unknown column on relation": {
+			queryString: "metadata/tag/unknown eq 'test'",
+			expectedErr: "invalid query: unknown column name 'unknown' on 'tags'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = BuildQueryFor[MockModel](testData.queryString, tx, WithDatabaseType(SQLite))
+				return dbQuery.Find(&[]map[string]any{})
+			})
+
+			// Assert
+			if testData.expectedErr != "" {
+				assert.EqualError(t, err, testData.expectedErr)
+				assert.True(t, errors.Is(err, ErrUnknownProperty))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQueryFor_EmbeddedStruct(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Company{})
+	db.Create(&Company{ID: 1, Name: "first", Address: Address{City: "Ghent"}, Contact: Contact{Email: "info@first.be"}})
+	db.Create(&Company{ID: 2, Name: "second", Address: Address{City: "Antwerp"}, Contact: Contact{Email: "info@second.be"}})
+
+	// Act
+	dbQuery, err := BuildQueryFor[Company]("address/city eq 'Ghent' and email eq 'info@first.be'", db)
+	var result []Company
+	dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Company{{ID: 1, Name: "first", Address: Address{City: "Ghent"}, Contact: Contact{Email: "info@first.be"}}}, result)
+}
+
+func Test_BuildQueryContext(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	type contextKey struct{}
+
+	cancelValidation := func(cancel context.CancelFunc) QueryValidation {
+		return func(*syntaxtree.SyntaxTree, *gorm.DB) error {
+			cancel()
+
+			return nil
+		}
+	}
+
+	tests := map[string]struct {
+		context     func() (context.Context, context.CancelFunc)
+		validations func(cancel context.CancelFunc) []QueryValidation
+		expectedErr error
+	}{
+		"active context": {
+			context: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.WithValue(context.Background(), contextKey{}, "request"))
+			},
+		},
+		"canceled before the build": {
+			context: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "request"))
+				cancel()
+
+				return ctx, cancel
+			},
+			expectedErr: context.Canceled,
+		},
+		"canceled during the validations": {
+			context: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.WithValue(context.Background(), contextKey{}, "request"))
+			},
+			validations: func(cancel context.CancelFunc) []QueryValidation {
+				return []QueryValidation{cancelValidation(cancel)}
+			},
+			expectedErr: context.Canceled,
+		},
+		"deadline exceeded": {
+			context: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.WithValue(context.Background(), contextKey{}, "request"), time.Now().Add(-time.Second))
+			},
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			ctx, cancel := testData.context()
+			t.Cleanup(cancel)
+
+			var validations []QueryValidation
+			if testData.validations != nil {
+				validations = testData.validations(cancel)
+			}
+
+			// Act
+			dbQuery, err := BuildQueryContext(ctx, "name eq 'test' and metadata/name eq 'prd'", db, SQLite, validations...)
+
+			// Assert
+			if testData.expectedErr != nil {
+				assert.True(t, errors.Is(err, testData.expectedErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "request", dbQuery.Statement.Context.Value(contextKey{}))
+			assert.NoError(t, dbQuery.Find(&[]MockModel{}).Error)
+		})
+	}
+}
+
+func Test_BuildQuery_ErrorKinds(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query            string
+		queryValidations []QueryValidation
+		expectedErr      error
+		parseError       bool
+	}{
+		"missing closing bracket": {
+			query:       "length(name",
+			expectedErr: ErrUnbalancedParens,
+			parseError:  true,
+		},
+		"missing opening bracket": {
+			query:       "concat(name,'test')) eq 'nametest'",
+			expectedErr: ErrUnbalancedParens,
+			parseError:  true,
+		},
+		"unknown function": {
+			query:       "concot(name,'value') eq 'namevalue'",
+			expectedErr: ErrUnknownFunction,
+			parseError:  true,
+		},
+		"unknown operator": {
+			query:       "concat(name,'value') qe 'namevalue'",
+			expectedErr: ErrUnsupportedOperator,
+			parseError:  true,
+		},
+		"unary function as right operand": {
+			query:       "name eq tolower(testValue)",
+			expectedErr: ErrUnsupportedOperator,
+		},
+		"unknown property": {
+			query:            "unknown eq 'test'",
+			queryValidations: []QueryValidation{WithInputModelValidation(MockModel{})},
+			expectedErr:      ErrUnknownProperty,
+		},
+		"max tree depth": {
+			query:            "name eq 'test' and (testValue eq 'value' or name eq 'other')",
+			queryValidations: []QueryValidation{WithMaxTreeDepth(1)},
+			expectedErr:      ErrComplexityExceeded,
+		},
+		"max object expansion": {
+			query:            "metadata/tag/value eq 'test'",
+			queryValidations: []QueryValidation{WithMaxObjectExpansion(2)},
+			expectedErr:      ErrComplexityExceeded,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+			// Act
+			_, err := BuildQuery(testData.query, db, SQLite, testData.queryValidations...)
+
+			// Assert
+			assert.Error(t, err)
+			assert.True(t, errors.Is(err, testData.expectedErr))
+
+			var parseError *syntaxtree.ParseError
+			assert.Equal(t, testData.parseError, errors.As(err, &parseError))
+			assert.Equal(t, testData.parseError, errors.Is(err, ErrInvalidSyntax))
+
+			var invalidQueryError *InvalidQueryError
+			assert.Equal(t, !testData.parseError, errors.As(err, &invalidQueryError))
+		})
+	}
+}
+
 // TODO: these need fixing
 func Test_BuildQuery_NoInjection(t *testing.T) {
 	t.Parallel()
@@ -963,9 +1687,9 @@ func Test_BuildQuery_NoInjection(t *testing.T) {
 		},
 		"nested quote bypass": {
 			query:               "name eq ''' OR 1=1 --'",
-			expectedSql:         "SELECT * FROM `mock_models`",
+			expectedSql:         "SELECT * FROM `mock_models` WHERE name = \"' OR 1=1 --\"",
 			expectedRowAffected: 0,
-			expectedErr:         true,
+			expectedErr:         false,
 		},
 		"double quote in value": {
 			query:               "name eq 'test\"value'",
@@ -1256,7 +1980,7 @@ func Test_BuildQueryWithValidation_Success(t *testing.T) {
 				},
 			},
 			queryString: "not(contains(tolower(testValue),' ') and endswith(metadata/name,'prd')) and not(name eq 'test' or startswith(name,'prd'))",
-			expectedSql: "SELECT * FROM `mock_models` WHERE (LOWER(test_value) NOT LIKE \"% %\" OR metadata_id IN (SELECT `id` FROM `metadata` WHERE name NOT LIKE \"%prd\")) AND (name != \"test\" AND name NOT LIKE \"prd%\")",
+			expectedSql: "SELECT * FROM `mock_models` WHERE (LOWER(test_value) NOT LIKE \"% %\" OR (metadata_id IS NULL OR metadata_id NOT IN (SELECT `id` FROM `metadata` WHERE name LIKE \"%prd\"))) AND (name != \"test\" AND name NOT LIKE \"prd%\")",
 			expectedResult: []MockModel{
 				{
 					ID:         uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"),
@@ -1310,28 +2034,44 @@ func Test_BuildQuery_ErrorOnInvalidQuery(t *testing.T) {
 	t.Cleanup(cleanupCache)
 
 	tests := map[string]struct {
-		query          string
-		expectedErrMsg string
+		query              string
+		expectedErrMsg     string
+		expectedExpression string
 	}{
 		"no function or operator": {
-			query:          "name",
-			expectedErrMsg: "invalid query: unknown query type",
+			query:              "name",
+			expectedErrMsg:     "invalid query: operand 'name' cannot be a filter on its own, compare it with a value instead",
+			expectedExpression: "name",
 		},
 		"invalid unary function as root": {
-			query:          "length(name)",
-			expectedErrMsg: "invalid query: root level operators other then 'not' are not supported",
+			query:              "length(name)",
+			expectedErrMsg:     "invalid query: unary function 'length' cannot be the root of a filter, compare it with a value instead",
+			expectedExpression: "length(name)",
 		},
 		"invalid not query": {
-			query:          "not(length(name))",
-			expectedErrMsg: "invalid query: root level operators other then 'not' are not supported",
+			query:              "not(length(name))",
+			expectedErrMsg:     "invalid query: unary function 'length' cannot be negated with 'not', compare it with a value instead",
+			expectedExpression: "length(name)",
+		},
+		"invalid unary function in and": {
+			query:              "name eq 'test' and tolower(name)",
+			expectedErrMsg:     "invalid query: unary function 'tolower' cannot be the root of a filter, compare it with a value instead",
+			expectedExpression: "tolower(name)",
+		},
+		"invalid operand in or": {
+			query:              "(name eq 'test' or testValue) and name ne 'other'",
+			expectedErrMsg:     "invalid query: operand 'testValue' cannot be a filter on its own, compare it with a value instead",
+			expectedExpression: "testValue",
 		},
 		"unsupported concat on right operand": {
-			query:          "name eq concat('test',test_value)",
-			expectedErrMsg: "invalid query: concat not supported as right operand of equality operators",
+			query:              "name eq concat('test',test_value)",
+			expectedErrMsg:     "invalid query: function 'concat' cannot be the right operand of 'eq', only values can",
+			expectedExpression: "concat('test',test_value)",
 		},
 		"unsupported unary function on right operand": {
-			query:          "name eq tolower(test_value)",
-			expectedErrMsg: "invalid query: unary operators not supported as right operand of equality operators",
+			query:              "name eq tolower(test_value)",
+			expectedErrMsg:     "invalid query: unary function 'tolower' cannot be the right operand of 'eq', only values can",
+			expectedExpression: "tolower(test_value)",
 		},
 	}
 
@@ -1349,6 +2089,11 @@ func Test_BuildQuery_ErrorOnInvalidQuery(t *testing.T) {
 			// Assert
 			assert.Error(t, err)
 			assert.Equal(t, testData.expectedErrMsg, err.Error())
+
+			var invalidQueryError *InvalidQueryError
+			assert.True(t, errors.As(err, &invalidQueryError))
+			assert.Equal(t, testData.expectedExpression, invalidQueryError.Expression)
+			assert.NotNil(t, invalidQueryError.Node)
 		})
 	}
 }
@@ -1412,5 +2157,30 @@ func Test_PrintTree_Error(t *testing.T) {
 }
 
 func cleanupCache() {
-	cacheGormqonvertTranslationMap.Clear()
+	ClearCaches()
+}
+
+// benchmarkFilters
+// are the representative filters of the benchmarks
+var benchmarkFilters = map[string]string{
+	"simple":   "name eq 'test'",
+	"logical":  "name eq 'test' and (testValue ne 'prd' or id gt 3)",
+	"function": "contains(tolower(trim(name)),'test') and length(concat(name,testValue)) gt 3",
+	"nested":   "metadata/name eq 'test' and startswith(metadata/tag/value,'prd')",
+}
+
+func Benchmark_BuildQuery(b *testing.B) {
+	for name, query := range benchmarkFilters {
+		b.Run(name, func(b *testing.B) {
+			db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = BuildQuery(query, db, SQLite)
+			}
+		})
+	}
 }
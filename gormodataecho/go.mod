@@ -0,0 +1,37 @@
+module github.com/bramca/gorm-odata-filtering/gormodataecho
+
+go 1.26.0
+
+require (
+	github.com/bramca/gorm-odata-filtering v0.0.0
+	github.com/ing-bank/gormtestutil v0.0.1
+	github.com/labstack/echo/v4 v4.16.0
+	github.com/test-go/testify v1.1.4
+	gorm.io/gorm v1.31.1
+)
+
+require (
+	github.com/bramca/go-syntax-tree v1.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/labstack/gommon v0.5.0 // indirect
+	github.com/mattn/go-colorable v0.1.15 // indirect
+	github.com/mattn/go-isatty v0.0.22 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	github.com/mitchellh/go-testing-interface v1.14.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stoewer/go-strcase v1.3.1 // indirect
+	github.com/survivorbat/go-tsyncmap v0.0.0 // indirect
+	github.com/survivorbat/gorm-query-convert v0.1.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.53.0 // indirect
+	golang.org/x/net v0.56.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	gorm.io/driver/sqlite v1.6.0 // indirect
+)
+
+replace github.com/bramca/gorm-odata-filtering => ../
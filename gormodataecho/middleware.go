@@ -0,0 +1,61 @@
+// Package gormodataecho
+// applies the OData query options of echo requests ($filter, $orderby, $top and $skip) on gorm queries
+//
+//	e.GET("/pets", func(c echo.Context) error {
+//		query := gormodataecho.FromContext(c)
+//		...
+//	}, gormodataecho.Middleware(db, &Pet{}, []string{"name", "birthYear"}))
+package gormodataecho
+
+import (
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// contextKey
+// is the key of the query of a request in the echo.Context
+const contextKey = "gormodataecho.query"
+
+// Middleware
+// builds the query on the model with the query options of the request (see gormodatahttp.Middleware) and attaches it to the echo.Context,
+// the handler gets the query with FromContext
+//
+// filters can only reference the allowed fields (see gormodata.WithAllowedFields), an empty allowlist rejects every filter,
+// requests with invalid query options are not passed to the handler, the middleware returns the error as an *echo.HTTPError (see HTTPError)
+func Middleware(db *gorm.DB, model any, allowedFields []string, opts ...gormodatahttp.Option) echo.MiddlewareFunc {
+	opts = append([]gormodatahttp.Option{gormodatahttp.WithBuilderOptions(gormodata.WithAllowedFields(allowedFields...))}, opts...)
+	queries := echo.WrapMiddleware(gormodatahttp.Middleware(db, model, opts...))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return queries(func(c echo.Context) error {
+			query, err := gormodatahttp.FromContext(c.Request().Context())
+			if err != nil {
+				return HTTPError(err)
+			}
+
+			c.Set(contextKey, query)
+
+			return next(c)
+		})
+	}
+}
+
+// FromContext
+// returns the query that the middleware attached to the echo.Context, nil if the request did not pass the middleware
+func FromContext(c echo.Context) *gorm.DB {
+	query, _ := c.Get(contextKey).(*gorm.DB)
+
+	return query
+}
+
+// HTTPError
+// converts the error of a build to an *echo.HTTPError with the status and the OData error body of gormodatahttp.NewODataError,
+// errors that are not about the query (e.g. of the database) get a generic message, so they are not exposed to clients,
+// the error is kept as internal error, so it can still be checked with errors.Is and errors.As
+func HTTPError(err error) *echo.HTTPError {
+	status, body := gormodatahttp.NewODataError(err)
+
+	return echo.NewHTTPError(status, body).SetInternal(err)
+}
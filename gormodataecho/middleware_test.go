@@ -0,0 +1,163 @@
+package gormodataecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/labstack/echo/v4"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Pet struct {
+	ID        uint
+	Name      string
+	BirthYear int
+}
+
+// sqlHandler
+// responds with the SQL of the query of the request
+func sqlHandler(c echo.Context) error {
+	return c.String(http.StatusOK, FromContext(c).ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]Pet{})
+	}))
+}
+
+func Test_Middleware(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values         url.Values
+		allowedFields  []string
+		options        []gormodatahttp.Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		"no options": {
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets`",
+		},
+		"all options": {
+			values: url.Values{
+				"$filter":  {"birthYear gt 2020 and name ne 'rex'"},
+				"$orderby": {"name desc"},
+				"$top":     {"10"},
+				"$skip":    {"20"},
+			},
+			allowedFields:  []string{"name", "birthYear"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets` WHERE birth_year > 2020 AND name != \"rex\" ORDER BY `name` DESC LIMIT 10 OFFSET 20",
+		},
+		"field not allowed": {
+			values:         url.Values{"$filter": {"birthYear gt 2020"}},
+			allowedFields:  []string{"name"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'birthYear' is not allowed\",\"target\":\"birthYear\"}}\n",
+		},
+		"empty allowlist": {
+			values:         url.Values{"$filter": {"name eq 'rex'"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'name' is not allowed\",\"target\":\"name\"}}\n",
+		},
+		"invalid syntax": {
+			values:         url.Values{"$filter": {"name eq"}},
+			allowedFields:  []string{"name"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"top exceeds maximum": {
+			values:         url.Values{"$top": {"11"}},
+			options:        []gormodatahttp.Option{gormodatahttp.WithMaxTop(10)},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidQueryOption\",\"message\":\"invalid query: $top exceeds the maximum of 10\"}}\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{})
+			e := echo.New()
+			e.GET("/pets", sqlHandler, Middleware(db, &Pet{}, testData.allowedFields, testData.options...))
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			if testData.expectedBody != "" {
+				assert.Equal(t, testData.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func Test_FromContext_WithoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/pets", nil), httptest.NewRecorder())
+
+	// Act
+	result := FromContext(c)
+
+	// Assert
+	assert.Nil(t, result)
+}
+
+func Test_HTTPError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		"invalid query": {
+			err:            &gormodata.InvalidQueryError{Msg: "field 'name' is not allowed", Err: gormodata.ErrFieldNotAllowed, Expression: "name"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'name' is not allowed\",\"target\":\"name\"}}\n",
+		},
+		"syntax error": {
+			err:            &gormodata.SyntaxError{Err: errors.New("failed to parse query"), Kind: gormodata.ErrUnbalancedParens},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnbalancedParentheses\",\"message\":\"failed to parse query\"}}\n",
+		},
+		"other error": {
+			err:            errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "{\"error\":{\"code\":\"InternalServerError\",\"message\":\"Internal Server Error\"}}\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			e := echo.New()
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/pets", nil), recorder)
+
+			// Act
+			result := HTTPError(testData.err)
+			e.DefaultHTTPErrorHandler(result, c)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, result.Code)
+			assert.True(t, errors.Is(result, testData.err))
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			assert.Equal(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Package gormodatafiber
+// applies the OData query options of fiber requests ($filter, $orderby, $top and $skip) on gorm queries,
+// with the same validation and errors as the net/http middleware of gormodatahttp
+//
+//	app.Get("/pets", gormodatafiber.Middleware(db, &Pet{}), func(c fiber.Ctx) error {
+//		query := gormodatafiber.FromContext(c)
+//		...
+//	})
+package gormodatafiber
+
+import (
+	"net/url"
+
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// contextKey
+// is the key of the query of a request in the locals of the fiber.Ctx
+type contextKey struct{}
+
+// Middleware
+// builds the query on the model with the query options of the request (see gormodatahttp.QueryBuilder) and stores it in the locals of the fiber.Ctx,
+// the handler gets the query with FromContext
+//
+// requests with invalid query options are not passed to the handler, the middleware responds with the status and the OData error body
+// of gormodatahttp.NewODataError, so errors that are not about the query (e.g. of the database) are not exposed to clients
+func Middleware(db *gorm.DB, model any, opts ...gormodatahttp.Option) fiber.Handler {
+	queries := gormodatahttp.NewQueryBuilder(db, model, opts...)
+
+	return func(c fiber.Ctx) error {
+		// Like net/http, pairs of the query string that cannot be unescaped are skipped
+		values, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+
+		query, err := queries.Build(c.Context(), values)
+		if err != nil {
+			return writeError(c, err)
+		}
+
+		fiber.Locals(c, contextKey{}, query)
+
+		return c.Next()
+	}
+}
+
+// FromContext
+// returns the query that the middleware stored in the locals of the fiber.Ctx, nil if the request did not pass the middleware
+func FromContext(c fiber.Ctx) *gorm.DB {
+	query, _ := c.Locals(contextKey{}).(*gorm.DB)
+
+	return query
+}
+
+// HTTPError
+// converts the error of a build to a *fiber.Error with the status and the message of gormodatahttp.NewODataError,
+// errors that are not about the query (e.g. of the database) get a generic message, so they are not exposed to clients
+func HTTPError(err error) *fiber.Error {
+	status, body := gormodatahttp.NewODataError(err)
+
+	return fiber.NewError(status, body.Error.Message)
+}
+
+// writeError
+// responds to the request with the error in the OData JSON format (see gormodatahttp.NewODataError)
+func writeError(c fiber.Ctx, err error) error {
+	status, body := gormodatahttp.NewODataError(err)
+
+	return c.Status(status).JSON(body)
+}
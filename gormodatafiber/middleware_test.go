@@ -0,0 +1,180 @@
+package gormodatafiber
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/gofiber/fiber/v3"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Pet struct {
+	ID        uint
+	Name      string
+	BirthYear int
+}
+
+// sqlHandler
+// responds with the SQL of the query of the request
+func sqlHandler(c fiber.Ctx) error {
+	return c.SendString(FromContext(c).ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]Pet{})
+	}))
+}
+
+func Test_Middleware(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values         url.Values
+		options        []gormodatahttp.Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		"no options": {
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets`",
+		},
+		"all options": {
+			values: url.Values{
+				"$filter":  {"birthYear gt 2020 and name ne 'rex'"},
+				"$orderby": {"name desc"},
+				"$top":     {"10"},
+				"$skip":    {"20"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets` WHERE birth_year > 2020 AND name != \"rex\" ORDER BY `name` DESC LIMIT 10 OFFSET 20",
+		},
+		"field not allowed": {
+			values:         url.Values{"$filter": {"birthYear gt 2020"}},
+			options:        []gormodatahttp.Option{gormodatahttp.WithBuilderOptions(gormodata.WithAllowedFields("name"))},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'birthYear' is not allowed\",\"target\":\"birthYear\"}}",
+		},
+		"unknown property": {
+			values:         url.Values{"$orderby": {"color"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown property 'color' in $orderby\",\"target\":\"color\"}}",
+		},
+		"invalid skip": {
+			values:         url.Values{"$skip": {"-1"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidQueryOption\",\"message\":\"invalid query: $skip must be a non-negative integer, got '-1'\"}}",
+		},
+		"top exceeds maximum": {
+			values:         url.Values{"$top": {"11"}},
+			options:        []gormodatahttp.Option{gormodatahttp.WithMaxTop(10)},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidQueryOption\",\"message\":\"invalid query: $top exceeds the maximum of 10\"}}",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{})
+			app := fiber.New()
+			app.Get("/pets", Middleware(db, &Pet{}, testData.options...), sqlHandler)
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+
+			// Act
+			response, err := app.Test(request)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedStatus, response.StatusCode)
+			body, _ := io.ReadAll(response.Body)
+			assert.Equal(t, testData.expectedBody, string(body))
+		})
+	}
+}
+
+func Test_FromContext_WithoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	app := fiber.New()
+	app.Get("/pets", func(c fiber.Ctx) error {
+		if FromContext(c) != nil {
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	// Act
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/pets", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func Test_HTTPError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err             error
+		expectedCode    int
+		expectedMessage string
+	}{
+		"invalid query": {
+			err:             &gormodata.InvalidQueryError{Msg: "field 'name' is not allowed", Err: gormodata.ErrFieldNotAllowed},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "invalid query: field 'name' is not allowed",
+		},
+		"syntax error": {
+			err:             &gormodata.SyntaxError{Err: errors.New("failed to parse query"), Kind: gormodata.ErrUnbalancedParens},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "failed to parse query",
+		},
+		"other error": {
+			err:             errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedCode:    http.StatusInternalServerError,
+			expectedMessage: "Internal Server Error",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result := HTTPError(testData.err)
+
+			// Assert
+			assert.Equal(t, testData.expectedCode, result.Code)
+			assert.Equal(t, testData.expectedMessage, result.Message)
+		})
+	}
+}
+
+func Test_WriteError_InternalError(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	app := fiber.New()
+	app.Get("/pets", func(c fiber.Ctx) error {
+		return writeError(c, errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	})
+
+	// Act
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/pets", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, "{\"error\":{\"code\":\"InternalServerError\",\"message\":\"Internal Server Error\"}}", string(body))
+}
@@ -0,0 +1,36 @@
+// Package gormodatagin
+// applies the OData query options of gin requests ($filter, $orderby, $top and $skip) on gorm queries
+//
+//	router.GET("/pets", func(c *gin.Context) {
+//		query, err := gormodatagin.BindODataQuery(c, db, &Pet{})
+//		if err != nil {
+//			return
+//		}
+//		...
+//	})
+package gormodatagin
+
+import (
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BindODataQuery
+// returns the query on the model with the query options of the request applied (see gormodatahttp.BuildQuery),
+// like gin's Bind it aborts the request when the query options are invalid, with the status and the OData error body of gormodatahttp.NewODataError,
+// so errors that are not about the query (e.g. of the database) are not exposed to clients,
+// the error is added to the errors of the context as a gin.ErrorTypeBind error
+//
+// the properties of $filter and $orderby are checked against the model, the query has the context of the request
+func BindODataQuery(c *gin.Context, db *gorm.DB, model any, opts ...gormodatahttp.Option) (*gorm.DB, error) {
+	query, err := gormodatahttp.BuildQuery(c.Request, db, model, opts...)
+	if err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		c.AbortWithStatusJSON(gormodatahttp.NewODataError(err))
+
+		return nil, err
+	}
+
+	return query, nil
+}
@@ -0,0 +1,168 @@
+package gormodatagin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/bramca/gorm-odata-filtering/gormodatahttp"
+	"github.com/gin-gonic/gin"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Pet struct {
+	ID        uint
+	Name      string
+	BirthYear int
+}
+
+func Test_BindODataQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values      url.Values
+		options     []gormodatahttp.Option
+		expectedSql string
+	}{
+		"no options": {
+			expectedSql: "SELECT * FROM `pets`",
+		},
+		"all options": {
+			values: url.Values{
+				"$filter":  {"birthYear gt 2020 and name ne 'rex'"},
+				"$orderby": {"name desc"},
+				"$top":     {"10"},
+				"$skip":    {"20"},
+			},
+			options:     []gormodatahttp.Option{gormodatahttp.WithMaxTop(10)},
+			expectedSql: "SELECT * FROM `pets` WHERE birth_year > 2020 AND name != \"rex\" ORDER BY `name` DESC LIMIT 10 OFFSET 20",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{})
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+
+			// Act
+			query, err := BindODataQuery(c, db, &Pet{}, testData.options...)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.False(t, c.IsAborted())
+			assert.Equal(t, testData.expectedSql, query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Find(&[]Pet{})
+			}))
+		})
+	}
+}
+
+func Test_BindODataQuery_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values           url.Values
+		options          []gormodatahttp.Option
+		expectedErr      error
+		expectedResponse gormodatahttp.ODataError
+	}{
+		"invalid syntax": {
+			values:      url.Values{"$filter": {"(name eq 'rex'"}},
+			expectedErr: gormodata.ErrInvalidSyntax,
+			expectedResponse: gormodatahttp.ODataError{Error: gormodatahttp.ODataErrorDetail{
+				Code:    "UnbalancedParentheses",
+				Message: "failed to parse query: expected closing bracket but got \"\"",
+			}},
+		},
+		"unknown property": {
+			values:      url.Values{"$filter": {"name eq 'rex' or color eq 'red'"}},
+			expectedErr: gormodata.ErrUnknownProperty,
+			expectedResponse: gormodatahttp.ODataError{Error: gormodatahttp.ODataErrorDetail{
+				Code:    "UnknownProperty",
+				Message: "invalid query: unknown column name 'color'",
+				Target:  "color",
+			}},
+		},
+		"field not allowed": {
+			values:      url.Values{"$filter": {"birthYear gt 2020"}},
+			options:     []gormodatahttp.Option{gormodatahttp.WithBuilderOptions(gormodata.WithAllowedFields("name"))},
+			expectedErr: gormodata.ErrFieldNotAllowed,
+			expectedResponse: gormodatahttp.ODataError{Error: gormodatahttp.ODataErrorDetail{
+				Code:    "FieldNotAllowed",
+				Message: "invalid query: field 'birthYear' is not allowed",
+				Target:  "birthYear",
+			}},
+		},
+		"invalid top": {
+			values:      url.Values{"$top": {"ten"}},
+			expectedErr: gormodatahttp.ErrInvalidQueryOption,
+			expectedResponse: gormodatahttp.ODataError{Error: gormodatahttp.ODataErrorDetail{
+				Code:    "InvalidQueryOption",
+				Message: "invalid query: $top must be a non-negative integer, got 'ten'",
+			}},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{})
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+
+			// Act
+			query, err := BindODataQuery(c, db, &Pet{}, testData.options...)
+
+			// Assert
+			assert.Nil(t, query)
+			assert.True(t, errors.Is(err, testData.expectedErr))
+			assert.True(t, c.IsAborted())
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Len(t, c.Errors.ByType(gin.ErrorTypeBind), 1)
+
+			var response gormodatahttp.ODataError
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, testData.expectedResponse, response)
+		})
+	}
+}
+
+func Test_BindODataQuery_InternalError(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{})
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	values := url.Values{"$filter": {"name eq 'rex'"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/pets?"+values.Encode(), nil).WithContext(ctx)
+
+	// Act
+	query, err := BindODataQuery(c, db, &Pet{})
+
+	// Assert
+	assert.Nil(t, query)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "{\"error\":{\"code\":\"InternalServerError\",\"message\":\"Internal Server Error\"}}", recorder.Body.String())
+}
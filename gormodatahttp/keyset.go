@@ -0,0 +1,212 @@
+package gormodatahttp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Keyset
+// pages through the results of a model in the order of $orderby with a cursor ($skiptoken) instead of $skip (see NewKeyset),
+// the next page starts right after the last row of the previous page, so pages do not get slower the further they are
+// and rows that are added or removed do not shift the pages
+type Keyset struct {
+	columns []clause.OrderByColumn
+	fields  []*schema.Field
+}
+
+// NewKeyset
+// returns the keyset paging of the model in the order of the properties, the primary key is added to the order
+// when it is not part of it, so every row has a unique position
+//
+//	keyset, err := gormodatahttp.NewKeyset(db, &Pet{}, options.OrderBy)
+//	query, err := keyset.Apply(db.Model(&Pet{}).Limit(50), options.SkipToken)
+//	...
+//	skipToken, err := keyset.SkipToken(pets[len(pets)-1])
+//
+// the columns of the order should not contain null values, rows with null values in them are skipped by the cursor
+func NewKeyset(db *gorm.DB, model any, orderBy []OrderBy) (*Keyset, error) {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return nil, err
+	}
+
+	if len(statement.Schema.PrimaryFields) == 0 {
+		return nil, fmt.Errorf("keyset paging needs a primary key, model '%s' has none", statement.Schema.Name)
+	}
+
+	columns, err := (&QueryOptions{OrderBy: orderBy}).orderColumns(db.NamingStrategy, statement.Schema, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keyset := &Keyset{}
+	for _, column := range columns {
+		field := statement.Schema.LookUpField(column.Column.Name)
+		if !keyset.contains(field) {
+			keyset.columns = append(keyset.columns, column)
+			keyset.fields = append(keyset.fields, field)
+		}
+	}
+
+	for _, field := range statement.Schema.PrimaryFields {
+		if !keyset.contains(field) {
+			keyset.columns = append(keyset.columns, clause.OrderByColumn{Column: clause.Column{Name: field.DBName}})
+			keyset.fields = append(keyset.fields, field)
+		}
+	}
+
+	return keyset, nil
+}
+
+func (k *Keyset) contains(field *schema.Field) bool {
+	for _, keysetField := range k.fields {
+		if keysetField == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply
+// orders the query and continues after the row of the skip token, the query starts at the first row when the skip token is empty
+func (k *Keyset) Apply(db *gorm.DB, skipToken string) (*gorm.DB, error) {
+	for _, column := range k.columns {
+		db = db.Order(column)
+	}
+
+	if skipToken == "" {
+		return db, nil
+	}
+
+	// The values are decoded to the types of the fields, so e.g. times are compared as times instead of strings
+	targets := make([]any, len(k.fields))
+	for i, field := range k.fields {
+		targets[i] = reflect.New(field.IndirectFieldType).Interface()
+	}
+	if err := DecodeCursor(skipToken, targets...); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(targets))
+	for i, target := range targets {
+		values[i] = reflect.ValueOf(target).Elem().Interface()
+	}
+
+	return db.Where(k.seekPredicate(db.Dialector.Name(), values)), nil
+}
+
+// seekPredicate
+// returns the condition of the rows after the values in the order of the columns,
+// as a comparison of row values if every column has the same direction and the database supports them (e.g. "(a,b) > (?,?)"),
+// otherwise as its expansion (e.g. "a > ? OR (a = ? AND b < ?)")
+func (k *Keyset) seekPredicate(dialect string, values []any) clause.Expression {
+	sameDirection := true
+	for _, column := range k.columns {
+		sameDirection = sameDirection && column.Desc == k.columns[0].Desc
+	}
+
+	if sameDirection && dialect != "sqlserver" {
+		vars := make([]any, 0, 2*len(k.columns))
+		for _, column := range k.columns {
+			vars = append(vars, column.Column)
+		}
+		vars = append(vars, values...)
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(k.columns)), ",")
+		operator := ">"
+		if k.columns[0].Desc {
+			operator = "<"
+		}
+
+		return clause.Expr{SQL: fmt.Sprintf("(%s) %s (%s)", placeholders, operator, placeholders), Vars: vars}
+	}
+
+	conditions := make([]clause.Expression, len(k.columns))
+	for i, column := range k.columns {
+		// The rows with the same values in the columns before this one and a value after it in this column
+		equal := make([]clause.Expression, 0, i+1)
+		for j := range i {
+			equal = append(equal, clause.Eq{Column: k.columns[j].Column, Value: values[j]})
+		}
+
+		if column.Desc {
+			equal = append(equal, clause.Lt{Column: column.Column, Value: values[i]})
+		} else {
+			equal = append(equal, clause.Gt{Column: column.Column, Value: values[i]})
+		}
+		conditions[i] = clause.And(equal...)
+	}
+
+	return clause.Or(conditions...)
+}
+
+// SkipToken
+// returns the skip token of the row, for the next link of a page that ends with the row
+func (k *Keyset) SkipToken(row any) (string, error) {
+	value := reflect.ValueOf(row)
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	values := make([]any, len(k.fields))
+	for i, field := range k.fields {
+		values[i], _ = field.ValueOf(context.Background(), value)
+	}
+
+	return EncodeCursor(values...)
+}
+
+// EncodeCursor
+// returns an opaque token of the values that is safe to use in urls, the values are encoded as JSON
+func EncodeCursor(values ...any) (string, error) {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// DecodeCursor
+// decodes the values of a token of EncodeCursor into the targets, which are pointers to values of the types of the values,
+// tokens that are not a valid cursor with a value for every target fail with an InvalidQueryError wrapping ErrInvalidQueryOption
+func DecodeCursor(token string, targets ...any) error {
+	invalid := func(err error) error {
+		return &gormodata.InvalidQueryError{
+			Msg:        fmt.Sprintf("invalid $skiptoken: %s", err),
+			Err:        ErrInvalidQueryOption,
+			Expression: token,
+		}
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return invalid(err)
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(decoded, &values); err != nil {
+		return invalid(err)
+	}
+	if len(values) != len(targets) {
+		return invalid(fmt.Errorf("expected %d values, got %d", len(targets), len(values)))
+	}
+
+	for i, value := range values {
+		if err := json.Unmarshal(value, targets[i]); err != nil {
+			return invalid(err)
+		}
+	}
+
+	return nil
+}
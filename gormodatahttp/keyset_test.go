@@ -0,0 +1,239 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Keyset_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		orderBy     []OrderBy
+		values      []any
+		expectedSql string
+	}{
+		"first page": {
+			orderBy:     []OrderBy{{Property: "name"}},
+			expectedSql: "SELECT * FROM `pets` ORDER BY `name`,`id` LIMIT 3",
+		},
+		"primary key only": {
+			values:      []any{7},
+			expectedSql: "SELECT * FROM `pets` WHERE (`id`) > (7) ORDER BY `id` LIMIT 3",
+		},
+		"same direction": {
+			orderBy:     []OrderBy{{Property: "birthYear", Desc: true}, {Property: "id", Desc: true}},
+			values:      []any{2020, 7},
+			expectedSql: "SELECT * FROM `pets` WHERE (`birth_year`,`id`) < (2020,7) ORDER BY `birth_year` DESC,`id` DESC LIMIT 3",
+		},
+		"mixed directions": {
+			orderBy:     []OrderBy{{Property: "birthYear", Desc: true}, {Property: "name"}},
+			values:      []any{2020, "rex", 7},
+			expectedSql: "SELECT * FROM `pets` WHERE (`birth_year` < 2020 OR (`birth_year` = 2020 AND `name` > \"rex\") OR (`birth_year` = 2020 AND `name` = \"rex\" AND `id` > 7)) ORDER BY `birth_year` DESC,`name`,`id` LIMIT 3",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			keyset, err := NewKeyset(db, &Pet{}, testData.orderBy)
+			assert.NoError(t, err)
+			skipToken := ""
+			if testData.values != nil {
+				skipToken, _ = EncodeCursor(testData.values...)
+			}
+
+			// Act
+			query, err := keyset.Apply(db.Model(&Pet{}).Limit(3), skipToken)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Find(&[]Pet{})
+			}))
+		})
+	}
+}
+
+func Test_Keyset_SeekPredicateWithoutRowValues(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	keyset, _ := NewKeyset(db, &Pet{}, []OrderBy{{Property: "name"}})
+
+	// Act
+	predicate := keyset.seekPredicate("sqlserver", []any{"rex", 7})
+
+	// Assert
+	assert.Equal(t, "SELECT * FROM `pets` WHERE (`name` > \"rex\" OR (`name` = \"rex\" AND `id` > 7))", db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(predicate).Find(&[]Pet{})
+	}))
+}
+
+func Test_Keyset_Pages(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		orderBy []OrderBy
+	}{
+		"same direction": {
+			orderBy: []OrderBy{{Property: "birthYear"}},
+		},
+		"mixed directions": {
+			orderBy: []OrderBy{{Property: "birthYear", Desc: true}, {Property: "name"}},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{}, &Owner{})
+			_ = db.Create(&[]Pet{
+				{Name: "rex", BirthYear: 2020}, {Name: "tom", BirthYear: 2021}, {Name: "max", BirthYear: 2020},
+				{Name: "bob", BirthYear: 2019}, {Name: "max", BirthYear: 2021}, {Name: "ace", BirthYear: 2020},
+				{Name: "kit", BirthYear: 2022},
+			}).Error
+			keyset, err := NewKeyset(db, &Pet{}, testData.orderBy)
+			assert.NoError(t, err)
+
+			var expected []Pet
+			ordered, _ := keyset.Apply(db.Model(&Pet{}), "")
+			_ = ordered.Find(&expected).Error
+
+			// Act
+			var result []Pet
+			skipToken := ""
+			for range len(expected) {
+				query, err := keyset.Apply(db.Model(&Pet{}).Limit(3), skipToken)
+				assert.NoError(t, err)
+
+				var page []Pet
+				assert.NoError(t, query.Find(&page).Error)
+				if len(page) == 0 {
+					break
+				}
+				result = append(result, page...)
+
+				skipToken, err = keyset.SkipToken(&page[len(page)-1])
+				assert.NoError(t, err)
+			}
+
+			// Assert
+			assert.Len(t, expected, 7)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func Test_Keyset_ApplyInvalidSkipToken(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		skipToken   string
+		expectedErr string
+	}{
+		"not base64": {
+			skipToken:   "!!",
+			expectedErr: "invalid query: invalid $skiptoken: illegal base64 data at input byte 0",
+		},
+		"wrong number of values": {
+			skipToken:   "WzEsMl0",
+			expectedErr: "invalid query: invalid $skiptoken: expected 1 values, got 2",
+		},
+		"wrong type": {
+			skipToken:   "WyJhIl0",
+			expectedErr: "invalid query: invalid $skiptoken: json: cannot unmarshal string into Go value of type uint",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			keyset, _ := NewKeyset(db, &Pet{}, nil)
+
+			// Act
+			query, err := keyset.Apply(db.Model(&Pet{}), testData.skipToken)
+
+			// Assert
+			assert.Nil(t, query)
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidQueryOption))
+		})
+	}
+}
+
+func Test_NewKeyset_Error(t *testing.T) {
+	t.Parallel()
+
+	type Event struct {
+		Name string
+	}
+
+	tests := map[string]struct {
+		model       any
+		orderBy     []OrderBy
+		expectedErr error
+	}{
+		"unknown property": {
+			model:       &Pet{},
+			orderBy:     []OrderBy{{Property: "color"}},
+			expectedErr: gormodata.ErrUnknownProperty,
+		},
+		"no primary key": {
+			model: &Event{},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			keyset, err := NewKeyset(db, testData.model, testData.orderBy)
+
+			// Assert
+			assert.Nil(t, keyset)
+			assert.Error(t, err)
+			if testData.expectedErr != nil {
+				assert.True(t, errors.Is(err, testData.expectedErr))
+			}
+		})
+	}
+}
+
+func Test_EncodeCursor(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var name string
+	var year int
+
+	// Act
+	token, err := EncodeCursor("rex", 2020)
+	decodeErr := DecodeCursor(token, &name, &year)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, "WyJyZXgiLDIwMjBd", token)
+	assert.Equal(t, "rex", name)
+	assert.Equal(t, 2020, year)
+}
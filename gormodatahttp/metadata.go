@@ -0,0 +1,36 @@
+package gormodatahttp
+
+import (
+	"net/http"
+	"strings"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+)
+
+// MetadataHandler
+// serves the CSDL document of the models (see gormodata.NewCSDL) as the $metadata of the service,
+// in XML, or in JSON for requests with $format=json or an Accept header with application/json
+//
+//	csdl, err := gormodata.NewCSDL(db, "Pets", &Pet{}, &Owner{})
+//	mux.Handle("GET /$metadata", gormodatahttp.MetadataHandler(csdl))
+func MetadataHandler(csdl *gormodata.CSDL) http.Handler {
+	// The documents do not change, so they are rendered once
+	xmlDocument, xmlErr := csdl.XML()
+	jsonDocument, jsonErr := csdl.JSON()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, document, err := "application/xml", xmlDocument, xmlErr
+		if r.URL.Query().Get("$format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			contentType, document, err = "application/json", jsonDocument, jsonErr
+		}
+		if err != nil {
+			WriteError(w, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("OData-Version", "4.0")
+		_, _ = w.Write(document)
+	})
+}
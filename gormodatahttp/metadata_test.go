@@ -0,0 +1,64 @@
+package gormodatahttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_MetadataHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target              string
+		accept              string
+		expectedContentType string
+		expectedPrefix      string
+	}{
+		"xml by default": {
+			target:              "/$metadata",
+			expectedContentType: "application/xml",
+			expectedPrefix:      "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<edmx:Edmx",
+		},
+		"json format": {
+			target:              "/$metadata?$format=json",
+			expectedContentType: "application/json",
+			expectedPrefix:      "{\n  \"$Version\": \"4.01\"",
+		},
+		"json accept header": {
+			target:              "/$metadata",
+			accept:              "application/json;odata.metadata=minimal",
+			expectedContentType: "application/json",
+			expectedPrefix:      "{\n  \"$Version\": \"4.01\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			csdl, err := gormodata.NewCSDL(db, "Pets", &Pet{})
+			assert.NoError(t, err)
+			request := httptest.NewRequest(http.MethodGet, testData.target, nil)
+			request.Header.Set("Accept", testData.accept)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			MetadataHandler(csdl).ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, testData.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, "4.0", recorder.Header().Get("OData-Version"))
+			assert.True(t, strings.HasPrefix(recorder.Body.String(), testData.expectedPrefix), recorder.Body.String())
+			assert.Contains(t, recorder.Body.String(), "Pets.Owner")
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Package gormodatahttp
+// applies the OData query options of net/http requests ($filter, $orderby, $top, $skip, $select and $expand) on gorm queries
+//
+//	mux.Handle("GET /pets", gormodatahttp.Middleware(db, &Pet{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		query, err := gormodatahttp.FromContext(r.Context())
+//		...
+//	})))
+package gormodatahttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNoQuery
+// is returned by FromContext when the request did not pass the middleware
+var ErrNoQuery = errors.New("no odata query in the context, the request did not pass the gormodatahttp middleware")
+
+// Option
+// configures the middleware
+type Option func(*config)
+
+type config struct {
+	builderOptions []gormodata.Option
+	pagingOptions  []PagingOption
+	maxTop         int
+
+	selectExpand        bool
+	selectExpandOptions []SelectExpandOption
+
+	taggedFields bool
+}
+
+// WithBuilderOptions
+// configures the builder of the filters (e.g. gormodata.WithAllowedFields or gormodata.WithMaxDepth)
+func WithBuilderOptions(opts ...gormodata.Option) Option {
+	return func(c *config) {
+		c.builderOptions = append(c.builderOptions, opts...)
+	}
+}
+
+// WithMaxTop
+// rejects requests with a $top that is greater than maxTop
+func WithMaxTop(maxTop int) Option {
+	return func(c *config) {
+		c.maxTop = maxTop
+	}
+}
+
+// WithPaging
+// enforces the page size of the queries (see ApplyPaging, WithDefaultTop, WithMaxPageSize),
+// the handler gets the page to build the next link of the response with PageFromContext
+func WithPaging(opts ...PagingOption) Option {
+	return func(c *config) {
+		c.pagingOptions = append(c.pagingOptions, opts...)
+	}
+}
+
+// WithSelectExpand
+// applies $select and $expand on the queries, so only the requested columns of the model and its relations are fetched (see ApplySelectExpand),
+// without this option $select and $expand are ignored and every column is fetched
+func WithSelectExpand(opts ...SelectExpandOption) Option {
+	return func(c *config) {
+		c.selectExpand = true
+		c.selectExpandOptions = append(c.selectExpandOptions, opts...)
+	}
+}
+
+// WithTaggedFields
+// only allows the fields that the odata tags of the model allow: $filter on filterable fields (see gormodata.WithTaggedFields),
+// $orderby on sortable fields and $expand on expandable relations (see WithExpandableTags), other fields fail with gormodata.ErrFieldNotAllowed
+//
+//	type Pet struct {
+//		Name  string `odata:"filterable,sortable"`
+//		Owner *Owner `odata:"filterable,expandable"`
+//	}
+func WithTaggedFields() Option {
+	return func(c *config) {
+		c.taggedFields = true
+	}
+}
+
+// QueryBuilder
+// builds the queries of requests on a model with a configuration that is prepared once,
+// for frameworks that don't use net/http requests (e.g. fasthttp)
+type QueryBuilder struct {
+	db            *gorm.DB
+	model         any
+	builder       *gormodata.Builder
+	pagingOptions []PagingOption
+	maxTop        int
+
+	selectExpand        bool
+	selectExpandOptions []SelectExpandOption
+
+	taggedFields bool
+}
+
+// NewQueryBuilder
+// prepares the builder of the queries on the model, the properties of $filter and $orderby are checked against the model
+func NewQueryBuilder(db *gorm.DB, model any, opts ...Option) *QueryBuilder {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// The properties of the filters are checked against the model, the options of the caller can add more checks
+	builderOptions := append([]gormodata.Option{gormodata.WithQueryValidations(gormodata.WithModelValidation(model))}, c.builderOptions...)
+	if c.taggedFields {
+		builderOptions = append(builderOptions, gormodata.WithTaggedFields(model))
+		c.selectExpandOptions = append(c.selectExpandOptions, WithExpandableTags())
+	}
+
+	return &QueryBuilder{
+		db:            db,
+		model:         model,
+		builder:       gormodata.New(builderOptions...),
+		pagingOptions: c.pagingOptions,
+		maxTop:        c.maxTop,
+
+		selectExpand:        c.selectExpand,
+		selectExpandOptions: c.selectExpandOptions,
+
+		taggedFields: c.taggedFields,
+	}
+}
+
+// Build
+// returns the query on the model with the query options applied (see ParseQueryOptions), with the context of the request
+//
+//	query, err := queries.Build(ctx, values)
+func (q *QueryBuilder) Build(ctx context.Context, values url.Values) (*gorm.DB, error) {
+	db, _, err := q.BuildPage(ctx, values)
+
+	return db, err
+}
+
+// BuildPage
+// returns the query like Build, with the page of results it selects (see ApplyPaging)
+func (q *QueryBuilder) BuildPage(ctx context.Context, values url.Values) (*gorm.DB, *Page, error) {
+	options, err := ParseQueryOptions(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if q.maxTop > 0 && options.Top != nil && *options.Top > q.maxTop {
+		return nil, nil, &gormodata.InvalidQueryError{
+			Msg: fmt.Sprintf("$top exceeds the maximum of %d", q.maxTop),
+			Err: ErrInvalidQueryOption,
+		}
+	}
+
+	db := q.db.WithContext(ctx).Model(q.model)
+	if options.Filter != "" {
+		if db, err = q.builder.Build(options.Filter, db); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if q.selectExpand {
+		if db, err = ApplySelectExpand(db, q.model, options.Select, options.Expand, q.selectExpandOptions...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(options.OrderBy) > 0 {
+		// The schema is parsed once per model and cached by gorm
+		statement := &gorm.Statement{DB: q.db}
+		if err := statement.Parse(q.model); err != nil {
+			return nil, nil, err
+		}
+
+		columns, err := options.orderColumns(q.db.NamingStrategy, statement.Schema, q.taggedFields)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, column := range columns {
+			// The columns are prefixed with the table of the model, since joined relations can have the same columns
+			if q.selectExpand {
+				column.Column.Table = clause.CurrentTable
+			}
+			db = db.Order(column)
+		}
+	}
+
+	return ApplyPaging(db, options.Top, options.Skip, q.pagingOptions...)
+}
+
+// BuildQuery
+// builds the query on the model with the query options of the request, like Middleware does, for frameworks that call it from their own handlers
+//
+//	query, err := gormodatahttp.BuildQuery(r, db, &Pet{}, gormodatahttp.WithMaxTop(100))
+func BuildQuery(r *http.Request, db *gorm.DB, model any, opts ...Option) (*gorm.DB, error) {
+	return NewQueryBuilder(db, model, opts...).Build(r.Context(), r.URL.Query())
+}
+
+// contextKey
+// is the key of the query of a request in its context
+type contextKey struct{}
+
+// contextQuery
+// is the query of a request or the reason it could not be built
+type contextQuery struct {
+	query *gorm.DB
+	page  *Page
+	err   error
+}
+
+// Middleware
+// builds the query on the model for every request with the query options of the request and stores it in the context of the request,
+// the handler gets the query, or the error of invalid query options, with FromContext
+//
+// the properties of $filter and $orderby are checked against the model, the query has the context of the request
+func Middleware(db *gorm.DB, model any, opts ...Option) func(http.Handler) http.Handler {
+	queries := NewQueryBuilder(db, model, opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query, page, err := queries.BuildPage(r.Context(), r.URL.Query())
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, &contextQuery{query: query, page: page, err: err})))
+		})
+	}
+}
+
+// FromContext
+// returns the query that the middleware built for the request, or the error of its query options
+//
+//	query, err := gormodatahttp.FromContext(r.Context())
+//	if err != nil {
+//		gormodatahttp.WriteError(w, err)
+//		return
+//	}
+func FromContext(ctx context.Context) (*gorm.DB, error) {
+	stored, ok := ctx.Value(contextKey{}).(*contextQuery)
+	if !ok {
+		return nil, ErrNoQuery
+	}
+
+	return stored.query, stored.err
+}
+
+// PageFromContext
+// returns the page of results of the query that the middleware built for the request (see ApplyPaging),
+// nil if the request did not pass the middleware or has invalid query options
+//
+//	if nextLink := gormodatahttp.PageFromContext(r.Context()).NextLink(r.URL, len(pets)); nextLink != "" {
+//		...
+//	}
+func PageFromContext(ctx context.Context) *Page {
+	stored, ok := ctx.Value(contextKey{}).(*contextQuery)
+	if !ok {
+		return nil
+	}
+
+	return stored.page
+}
+
+// Handler
+// returns a handler that calls handle with the query of the request (see Middleware),
+// requests with invalid query options get the error as response in the OData JSON format (see WriteError)
+func Handler(db *gorm.DB, model any, handle func(w http.ResponseWriter, r *http.Request, query *gorm.DB), opts ...Option) http.Handler {
+	return Middleware(db, model, opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, err := FromContext(r.Context())
+		if err != nil {
+			WriteError(w, err)
+
+			return
+		}
+
+		handle(w, r, query)
+	}))
+}
+
+// StatusCode
+// returns the http status of the error of FromContext: 400 Bad Request for invalid query options and filters,
+// 500 Internal Server Error for other errors
+func StatusCode(err error) int {
+	var invalidQueryError *gormodata.InvalidQueryError
+	var syntaxError *gormodata.SyntaxError
+	if errors.As(err, &invalidQueryError) || errors.As(err, &syntaxError) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
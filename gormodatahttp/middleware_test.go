@@ -0,0 +1,364 @@
+package gormodatahttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Pet struct {
+	ID        uint
+	Name      string
+	BirthYear int
+	Owner     *Owner
+	OwnerID   *uint
+}
+
+type Owner struct {
+	ID   uint
+	Name string
+}
+
+// sqlHandler
+// responds with the SQL of the query of the request
+func sqlHandler(w http.ResponseWriter, _ *http.Request, query *gorm.DB) {
+	_, _ = w.Write([]byte(query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]Pet{})
+	})))
+}
+
+func Test_Handler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values         url.Values
+		options        []Option
+		expectedStatus int
+		expectedBody   string
+	}{
+		"no options": {
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets`",
+		},
+		"filter": {
+			values:         url.Values{"$filter": {"name eq 'rex' and owner/name eq 'tom'"}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets` WHERE name = \"rex\" AND owner_id IN (SELECT `id` FROM `owners` WHERE `owners`.`name` = \"tom\")",
+		},
+		"all options": {
+			values: url.Values{
+				"$filter":  {"birthYear gt 2020"},
+				"$orderby": {"birthYear desc,name"},
+				"$top":     {"10"},
+				"$skip":    {"20"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `pets` WHERE birth_year > 2020 ORDER BY `birth_year` DESC,`name` LIMIT 10 OFFSET 20",
+		},
+		"builder options": {
+			values:         url.Values{"$filter": {"birthYear gt 2020"}},
+			options:        []Option{WithBuilderOptions(gormodata.WithAllowedFields("name"))},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'birthYear' is not allowed\",\"target\":\"birthYear\"}}\n",
+		},
+		"invalid filter": {
+			values:         url.Values{"$filter": {"name eq 'rex"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidSyntax\",\"message\":\"failed to parse query: unterminated string literal 'rex\"}}\n",
+		},
+		"unknown property in filter": {
+			values:         url.Values{"$filter": {"color eq 'red'"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown column name 'color'\",\"target\":\"color\"}}\n",
+		},
+		"unknown property in orderby": {
+			values:         url.Values{"$orderby": {"color"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown property 'color' in $orderby\",\"target\":\"color\"}}\n",
+		},
+		"relation property in orderby": {
+			values:         url.Values{"$orderby": {"owner/name"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidQueryOption\",\"message\":\"invalid query: $orderby cannot sort on property 'owner/name' of a relation\"}}\n",
+		},
+		"top exceeds maximum": {
+			values:         url.Values{"$top": {"101"}},
+			options:        []Option{WithMaxTop(100)},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"InvalidQueryOption\",\"message\":\"invalid query: $top exceeds the maximum of 100\"}}\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{}, &Owner{})
+			handler := Handler(db, &Pet{}, sqlHandler, testData.options...)
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			assert.Equal(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+type TaggedPet struct {
+	ID      uint
+	Name    string `odata:"filterable,sortable"`
+	Notes   string
+	Owner   *Owner `odata:"filterable,expandable"`
+	OwnerID *uint
+	Vet     *Owner `gorm:"foreignKey:VetID" odata:"filterable"`
+	VetID   *uint
+}
+
+func Test_Handler_TaggedFields(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values         url.Values
+		expectedStatus int
+		expectedBody   string
+	}{
+		"tagged fields": {
+			values: url.Values{
+				"$filter":  {"name eq 'rex'"},
+				"$orderby": {"name"},
+				"$expand":  {"owner"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT * FROM `tagged_pets` WHERE name = \"rex\" ORDER BY `tagged_pets`.`name`",
+		},
+		"field that is not filterable": {
+			values:         url.Values{"$filter": {"notes eq 'friendly'"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'notes' is not filterable\",\"target\":\"notes\"}}\n",
+		},
+		"field of a relation that is not filterable": {
+			values:         url.Values{"$filter": {"vet/name eq 'tom'"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: field 'vet/name' is not filterable\",\"target\":\"vet/name\"}}\n",
+		},
+		"field that is not sortable": {
+			values:         url.Values{"$orderby": {"notes desc"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: $orderby cannot sort on property 'notes', it is not sortable\",\"target\":\"notes\"}}\n",
+		},
+		"relation that is not expandable": {
+			values:         url.Values{"$expand": {"vet"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"FieldNotAllowed\",\"message\":\"invalid query: $expand cannot expand relation 'vet', it is not expandable\",\"target\":\"vet\"}}\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&TaggedPet{}, &Owner{})
+			handler := Handler(db, &TaggedPet{}, func(w http.ResponseWriter, _ *http.Request, query *gorm.DB) {
+				_, _ = w.Write([]byte(query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					return tx.Find(&[]TaggedPet{})
+				})))
+			}, WithSelectExpand(), WithTaggedFields())
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			assert.Equal(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+type HiddenPet struct {
+	ID        uint
+	Name      string
+	ChipCode  string `odata:"-"`
+	Breeder   *Owner `odata:"-"`
+	BreederID *uint
+}
+
+func Test_Handler_HiddenFields(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values         url.Values
+		expectedStatus int
+		expectedBody   string
+	}{
+		"visible fields": {
+			values: url.Values{
+				"$filter":  {"name eq 'rex'"},
+				"$orderby": {"name"},
+				"$select":  {"name"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SELECT `id`,`name` FROM `hidden_pets` WHERE name = \"rex\" ORDER BY `hidden_pets`.`name`",
+		},
+		"hidden field in $filter": {
+			values:         url.Values{"$filter": {"chipCode eq 'x'"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown column name 'chip_code'\",\"target\":\"chipCode\"}}\n",
+		},
+		"hidden field in $orderby": {
+			values:         url.Values{"$orderby": {"chipCode"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown property 'chipCode' in $orderby\",\"target\":\"chipCode\"}}\n",
+		},
+		"hidden field in $select": {
+			values:         url.Values{"$select": {"name,chipCode"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown property 'chipCode' in $select\",\"target\":\"chipCode\"}}\n",
+		},
+		"hidden relation in $expand": {
+			values:         url.Values{"$expand": {"breeder"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown relation 'breeder' in $expand\",\"target\":\"breeder\"}}\n",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&HiddenPet{}, &Owner{})
+			handler := Handler(db, &HiddenPet{}, func(w http.ResponseWriter, _ *http.Request, query *gorm.DB) {
+				_, _ = w.Write([]byte(query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					return tx.Find(&[]HiddenPet{})
+				})))
+			}, WithSelectExpand())
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, recorder.Code)
+			assert.Equal(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	_ = db.Create(&[]Pet{{Name: "rex", BirthYear: 2020}, {Name: "tom", BirthYear: 2021}, {Name: "max", BirthYear: 2022}}).Error
+
+	var pets []Pet
+	var err error
+	var queryContext context.Context
+	handler := Middleware(db, &Pet{})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var query *gorm.DB
+		query, err = FromContext(r.Context())
+		if err == nil {
+			queryContext = query.Statement.Context
+			err = query.Find(&pets).Error
+		}
+	}))
+	values := url.Values{"$filter": {"birthYear ge 2021"}, "$orderby": {"name"}}
+	request := httptest.NewRequest(http.MethodGet, "/pets?"+values.Encode(), nil)
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"max", "tom"}, []string{pets[0].Name, pets[1].Name})
+	assert.Equal(t, request.Context().Done(), queryContext.Done())
+}
+
+func Test_FromContext_WithoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	query, err := FromContext(context.Background())
+
+	// Assert
+	assert.Nil(t, query)
+	assert.True(t, errors.Is(err, ErrNoQuery))
+}
+
+func Test_StatusCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err            error
+		expectedStatus int
+	}{
+		"invalid query": {
+			err:            &gormodata.InvalidQueryError{Msg: "invalid", Err: gormodata.ErrFieldNotAllowed},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"syntax error": {
+			err:            &gormodata.SyntaxError{Err: errors.New("failed to parse query"), Kind: gormodata.ErrInvalidSyntax},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"collected errors": {
+			err:            gormodata.QueryErrors{&gormodata.InvalidQueryError{Msg: "invalid", Err: gormodata.ErrFieldNotAllowed}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"other error": {
+			err:            context.Canceled,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result := StatusCode(testData.err)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, result)
+		})
+	}
+}
+
+func Test_BuildQuery(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	values := url.Values{"$filter": {"name eq 'rex'"}, "$top": {"5"}}
+	request := httptest.NewRequest(http.MethodGet, "/pets?"+values.Encode(), nil)
+
+	// Act
+	query, err := BuildQuery(request, db, &Pet{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `pets` WHERE name = \"rex\" LIMIT 5", query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]Pet{})
+	}))
+}
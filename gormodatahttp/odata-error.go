@@ -0,0 +1,108 @@
+package gormodatahttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+)
+
+// ODataError
+// is the body of an error response in the OData JSON format
+//
+//	{"error": {"code": "UnknownProperty", "message": "invalid query: unknown column name 'color'", "target": "color"}}
+type ODataError struct {
+	Error ODataErrorDetail `json:"error"`
+}
+
+// ODataErrorDetail
+// is an error of an OData error response
+type ODataErrorDetail struct {
+	// Code is the kind of error (e.g. "FieldNotAllowed"), it does not change between versions, so clients can act on it
+	Code string `json:"code"`
+
+	Message string `json:"message"`
+
+	// Target is the part of the filter that is invalid (e.g. "length(name)"), empty if the error is not about a part of the filter
+	Target string `json:"target,omitempty"`
+
+	// Details are the errors of a filter with several problems (see gormodata.WithCollectAllErrors)
+	Details []ODataErrorDetail `json:"details,omitempty"`
+}
+
+// errorCodes
+// are the codes of the errors, the most specific errors are first since a syntax error wraps both its kind and ErrInvalidSyntax
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{err: ErrInvalidQueryOption, code: "InvalidQueryOption"},
+	{err: gormodata.ErrUnbalancedParens, code: "UnbalancedParentheses"},
+	{err: gormodata.ErrUnknownFunction, code: "UnknownFunction"},
+	{err: gormodata.ErrUnsupportedOperator, code: "UnsupportedOperator"},
+	{err: gormodata.ErrUnknownProperty, code: "UnknownProperty"},
+	{err: gormodata.ErrFieldNotAllowed, code: "FieldNotAllowed"},
+	{err: gormodata.ErrFunctionNotAllowed, code: "FunctionNotAllowed"},
+	{err: gormodata.ErrOperatorNotAllowed, code: "OperatorNotAllowed"},
+	{err: gormodata.ErrComplexityExceeded, code: "FilterTooComplex"},
+	{err: gormodata.ErrLiteralNotBound, code: "LiteralNotBound"},
+	{err: gormodata.ErrInvalidSyntax, code: "InvalidSyntax"},
+}
+
+// NewODataError
+// returns the http status (see StatusCode) and the OData error body of the error,
+// errors that are not about the query (e.g. of the database) get a generic message, so they are not exposed to clients
+//
+//	status, body := gormodatahttp.NewODataError(err)
+//	return c.JSON(status, body)
+func NewODataError(err error) (int, *ODataError) {
+	status := StatusCode(err)
+	if status != http.StatusBadRequest {
+		return status, &ODataError{Error: ODataErrorDetail{Code: "InternalServerError", Message: http.StatusText(status)}}
+	}
+
+	// A filter with several problems gets a generic code, the details have the code of every problem
+	var queryErrors gormodata.QueryErrors
+	if errors.As(err, &queryErrors) && len(queryErrors) > 1 {
+		detail := ODataErrorDetail{Code: "InvalidQuery", Message: err.Error()}
+		for _, queryError := range queryErrors {
+			detail.Details = append(detail.Details, newErrorDetail(queryError))
+		}
+
+		return status, &ODataError{Error: detail}
+	}
+
+	return status, &ODataError{Error: newErrorDetail(err)}
+}
+
+// newErrorDetail
+// returns the error detail of an error about the query
+func newErrorDetail(err error) ODataErrorDetail {
+	detail := ODataErrorDetail{Code: "InvalidQuery", Message: err.Error()}
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			detail.Code = errorCode.code
+
+			break
+		}
+	}
+
+	// The expression of an invalid query option is its value, the option is already in the message
+	var invalidQueryError *gormodata.InvalidQueryError
+	if errors.As(err, &invalidQueryError) && !errors.Is(err, ErrInvalidQueryOption) {
+		detail.Target = invalidQueryError.Expression
+	}
+
+	return detail
+}
+
+// WriteError
+// responds to the request with the error in the OData JSON format, with the status of StatusCode (see NewODataError)
+func WriteError(w http.ResponseWriter, err error) {
+	status, body := NewODataError(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
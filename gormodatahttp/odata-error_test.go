@@ -0,0 +1,126 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_NewODataError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err            error
+		expectedStatus int
+		expectedError  *ODataError
+	}{
+		"syntax error": {
+			err:            &gormodata.SyntaxError{Err: errors.New("failed to parse query: expected closing bracket"), Kind: gormodata.ErrUnbalancedParens},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "UnbalancedParentheses",
+				Message: "failed to parse query: expected closing bracket",
+			}},
+		},
+		"invalid query": {
+			err:            &gormodata.InvalidQueryError{Msg: "function 'length' is not allowed", Err: gormodata.ErrFunctionNotAllowed, Expression: "length(name)"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "FunctionNotAllowed",
+				Message: "invalid query: function 'length' is not allowed",
+				Target:  "length(name)",
+			}},
+		},
+		"operator not allowed": {
+			err:            &gormodata.InvalidQueryError{Msg: "operator 'or' is not allowed for role ''", Err: gormodata.ErrOperatorNotAllowed, Expression: "name eq 'a' or name eq 'b'"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "OperatorNotAllowed",
+				Message: "invalid query: operator 'or' is not allowed for role ''",
+				Target:  "name eq 'a' or name eq 'b'",
+			}},
+		},
+		"literal not bound": {
+			err:            &gormodata.InvalidQueryError{Msg: "literal '1' in 'id add 1' cannot be bound as a parameter, only the values that are compared with can", Err: gormodata.ErrLiteralNotBound, Expression: "id add 1"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "LiteralNotBound",
+				Message: "invalid query: literal '1' in 'id add 1' cannot be bound as a parameter, only the values that are compared with can",
+				Target:  "id add 1",
+			}},
+		},
+		"invalid query without sentinel": {
+			err:            &gormodata.InvalidQueryError{Msg: "'abc' is not a valid uuid", Expression: "'abc'"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "InvalidQuery",
+				Message: "invalid query: 'abc' is not a valid uuid",
+				Target:  "'abc'",
+			}},
+		},
+		"invalid query option": {
+			err:            &gormodata.InvalidQueryError{Msg: "$top must be a non-negative integer, got 'ten'", Err: ErrInvalidQueryOption, Expression: "ten"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "InvalidQueryOption",
+				Message: "invalid query: $top must be a non-negative integer, got 'ten'",
+			}},
+		},
+		"collected errors": {
+			err: gormodata.QueryErrors{
+				&gormodata.InvalidQueryError{Msg: "field 'secret' is not allowed", Err: gormodata.ErrFieldNotAllowed, Expression: "secret"},
+				&gormodata.InvalidQueryError{Msg: "unknown column name 'color'", Err: gormodata.ErrUnknownProperty, Expression: "color"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "InvalidQuery",
+				Message: "invalid query: field 'secret' is not allowed; invalid query: unknown column name 'color'",
+				Details: []ODataErrorDetail{
+					{Code: "FieldNotAllowed", Message: "invalid query: field 'secret' is not allowed", Target: "secret"},
+					{Code: "UnknownProperty", Message: "invalid query: unknown column name 'color'", Target: "color"},
+				},
+			}},
+		},
+		"other error": {
+			err:            errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ODataError{Error: ODataErrorDetail{
+				Code:    "InternalServerError",
+				Message: "Internal Server Error",
+			}},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			status, result := NewODataError(testData.err)
+
+			// Assert
+			assert.Equal(t, testData.expectedStatus, status)
+			assert.Equal(t, testData.expectedError, result)
+		})
+	}
+}
+
+func Test_WriteError(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	recorder := httptest.NewRecorder()
+	err := &gormodata.InvalidQueryError{Msg: "unknown column name 'color'", Err: gormodata.ErrUnknownProperty, Expression: "color"}
+
+	// Act
+	WriteError(recorder, err)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"error\":{\"code\":\"UnknownProperty\",\"message\":\"invalid query: unknown column name 'color'\",\"target\":\"color\"}}\n", recorder.Body.String())
+}
@@ -0,0 +1,131 @@
+package gormodatahttp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"gorm.io/gorm"
+)
+
+// PagingOption
+// configures the paging of ApplyPaging
+type PagingOption func(*pagingConfig)
+
+type pagingConfig struct {
+	defaultTop  int
+	maxPageSize int
+}
+
+// WithDefaultTop
+// limits the pages of requests without $top to top results
+func WithDefaultTop(top int) PagingOption {
+	return func(c *pagingConfig) {
+		c.defaultTop = top
+	}
+}
+
+// WithMaxPageSize
+// limits the pages to maxPageSize results, a greater $top is lowered to maxPageSize
+// and the client gets the rest of the results with the next link of the page (see Page.NextLink)
+func WithMaxPageSize(maxPageSize int) PagingOption {
+	return func(c *pagingConfig) {
+		c.maxPageSize = maxPageSize
+	}
+}
+
+// Page
+// is the page of results that ApplyPaging selected
+type Page struct {
+	// Top is the maximum number of results of the page, nil if the number of results is not limited
+	Top *int
+
+	// Skip is the number of results before the page
+	Skip int
+
+	// requestedTop is the $top of the request, nil if the request has no $top
+	requestedTop *int
+}
+
+// ApplyPaging
+// limits the query to the page of $top and $skip with the limit and offset of the dialect of the db,
+// the page size is enforced with the options (see WithDefaultTop, WithMaxPageSize)
+//
+//	query, page, err := gormodatahttp.ApplyPaging(db, options.Top, options.Skip, gormodatahttp.WithDefaultTop(50), gormodatahttp.WithMaxPageSize(100))
+//
+// the returned page has the effective page parameters, to build the next link of the response (see Page.NextLink)
+func ApplyPaging(db *gorm.DB, top, skip *int, opts ...PagingOption) (*gorm.DB, *Page, error) {
+	c := &pagingConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, option := range []struct {
+		name  string
+		value *int
+	}{{name: "$top", value: top}, {name: "$skip", value: skip}} {
+		if option.value != nil && *option.value < 0 {
+			return nil, nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("%s must be a non-negative integer, got '%d'", option.name, *option.value),
+				Err:        ErrInvalidQueryOption,
+				Expression: strconv.Itoa(*option.value),
+			}
+		}
+	}
+
+	page := &Page{requestedTop: top}
+	if skip != nil {
+		page.Skip = *skip
+	}
+
+	switch {
+	case top != nil && c.maxPageSize > 0 && *top > c.maxPageSize:
+		page.Top = &c.maxPageSize
+	case top != nil:
+		page.Top = top
+	case c.defaultTop > 0:
+		page.Top = &c.defaultTop
+	case c.maxPageSize > 0:
+		page.Top = &c.maxPageSize
+	}
+
+	if page.Top != nil {
+		db = db.Limit(*page.Top)
+	}
+	if page.Skip > 0 {
+		db = db.Offset(page.Skip)
+	}
+
+	return db, page, nil
+}
+
+// NextLink
+// returns the url of the next page (@odata.nextLink) for a page with the given number of results,
+// empty if the client got all the results it asked for: the page is not limited by the server or has fewer results than its size,
+// or if the page is nil
+//
+//	if nextLink := page.NextLink(r.URL, len(pets)); nextLink != "" {
+//		response["@odata.nextLink"] = nextLink
+//	}
+func (p *Page) NextLink(requestURL *url.URL, results int) string {
+	if p == nil {
+		return ""
+	}
+
+	serverLimited := p.Top != nil && (p.requestedTop == nil || *p.requestedTop > *p.Top)
+	if !serverLimited || results < *p.Top {
+		return ""
+	}
+
+	values := requestURL.Query()
+	values.Set("$skip", strconv.Itoa(p.Skip+*p.Top))
+	if p.requestedTop != nil {
+		values.Set("$top", strconv.Itoa(*p.requestedTop-*p.Top))
+	}
+
+	next := *requestURL
+	next.RawQuery = values.Encode()
+
+	return next.String()
+}
@@ -0,0 +1,184 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_ApplyPaging(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		top          *int
+		skip         *int
+		options      []PagingOption
+		expectedSql  string
+		expectedTop  *int
+		expectedSkip int
+	}{
+		"no paging": {
+			expectedSql: "SELECT * FROM `pets`",
+		},
+		"top and skip": {
+			top:          ptr(10),
+			skip:         ptr(20),
+			expectedSql:  "SELECT * FROM `pets` LIMIT 10 OFFSET 20",
+			expectedTop:  ptr(10),
+			expectedSkip: 20,
+		},
+		"top of zero": {
+			top:         ptr(0),
+			options:     []PagingOption{WithDefaultTop(50)},
+			expectedSql: "SELECT * FROM `pets` LIMIT 0",
+			expectedTop: ptr(0),
+		},
+		"default top": {
+			skip:         ptr(5),
+			options:      []PagingOption{WithDefaultTop(50), WithMaxPageSize(100)},
+			expectedSql:  "SELECT * FROM `pets` LIMIT 50 OFFSET 5",
+			expectedTop:  ptr(50),
+			expectedSkip: 5,
+		},
+		"top below the maximum": {
+			top:         ptr(80),
+			options:     []PagingOption{WithDefaultTop(50), WithMaxPageSize(100)},
+			expectedSql: "SELECT * FROM `pets` LIMIT 80",
+			expectedTop: ptr(80),
+		},
+		"top above the maximum": {
+			top:         ptr(500),
+			options:     []PagingOption{WithDefaultTop(50), WithMaxPageSize(100)},
+			expectedSql: "SELECT * FROM `pets` LIMIT 100",
+			expectedTop: ptr(100),
+		},
+		"maximum without default": {
+			options:     []PagingOption{WithMaxPageSize(100)},
+			expectedSql: "SELECT * FROM `pets` LIMIT 100",
+			expectedTop: ptr(100),
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			query, page, err := ApplyPaging(db.Model(&Pet{}), testData.top, testData.skip, testData.options...)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Find(&[]Pet{})
+			}))
+			assert.Equal(t, testData.expectedTop, page.Top)
+			assert.Equal(t, testData.expectedSkip, page.Skip)
+		})
+	}
+}
+
+func Test_ApplyPaging_Negative(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+	// Act
+	query, page, err := ApplyPaging(db, ptr(-1), ptr(-2))
+
+	// Assert
+	assert.Nil(t, query)
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "invalid query: $top must be a non-negative integer, got '-1'")
+	assert.True(t, errors.Is(err, ErrInvalidQueryOption))
+}
+
+func Test_Page_NextLink(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		top              *int
+		skip             *int
+		results          int
+		expectedNextLink string
+	}{
+		"default page is full": {
+			skip:             ptr(10),
+			results:          50,
+			expectedNextLink: "/pets?%24filter=name+eq+%27rex%27&%24skip=60",
+		},
+		"default page is not full": {
+			results: 49,
+		},
+		"lowered top": {
+			top:              ptr(250),
+			results:          100,
+			expectedNextLink: "/pets?%24filter=name+eq+%27rex%27&%24skip=100&%24top=150",
+		},
+		"top of the client": {
+			top:     ptr(80),
+			results: 80,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_, page, _ := ApplyPaging(db, testData.top, testData.skip, WithDefaultTop(50), WithMaxPageSize(100))
+			requestURL, _ := url.Parse("/pets?$filter=name eq 'rex'")
+
+			// Act
+			result := page.NextLink(requestURL, testData.results)
+
+			// Assert
+			assert.Equal(t, testData.expectedNextLink, result)
+		})
+	}
+}
+
+func Test_Middleware_WithPaging(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	_ = db.Create(&[]Pet{{Name: "rex"}, {Name: "tom"}, {Name: "max"}}).Error
+
+	var pets []Pet
+	var nextLink string
+	handler := Middleware(db, &Pet{}, WithPaging(WithMaxPageSize(2)))(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		query, _ := FromContext(r.Context())
+		_ = query.Find(&pets).Error
+		nextLink = PageFromContext(r.Context()).NextLink(r.URL, len(pets))
+	}))
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/pets?$orderby=name", nil))
+
+	// Assert
+	assert.Len(t, pets, 2)
+	assert.Equal(t, "/pets?%24orderby=name&%24skip=2", nextLink)
+}
+
+func Test_PageFromContext_WithoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	result := PageFromContext(httptest.NewRequest(http.MethodGet, "/pets", nil).Context())
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Empty(t, result.NextLink(&url.URL{Path: "/pets"}, 10))
+}
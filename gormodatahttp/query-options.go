@@ -0,0 +1,152 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidQueryOption
+// is wrapped by the errors of query options other than $filter that are invalid (e.g. $top=abc or $orderby=name up)
+var ErrInvalidQueryOption = errors.New("invalid query option")
+
+// QueryOptions
+// are the OData query options of a request (see ParseQueryOptions)
+type QueryOptions struct {
+	// Filter is the $filter of the request, empty if the request has no filter
+	Filter string
+
+	// OrderBy are the properties of $orderby in the order they are sorted on
+	OrderBy []OrderBy
+
+	// Top is the $top of the request, nil if the request has no $top
+	Top *int
+
+	// Skip is the $skip of the request, nil if the request has no $skip
+	Skip *int
+
+	// SkipToken is the $skiptoken of the request, the cursor of keyset paging (see Keyset), empty if the request has no $skiptoken
+	SkipToken string
+
+	// Select are the properties of $select, nil if the request has no $select (see ApplySelectExpand)
+	Select []string
+
+	// Expand are the relations of $expand with their nested query options (see ApplySelectExpand)
+	Expand []Expand
+}
+
+// OrderBy
+// is a property of $orderby (e.g. "name desc")
+type OrderBy struct {
+	Property string
+	Desc     bool
+}
+
+// ParseQueryOptions
+// reads $filter, $orderby, $top, $skip, $skiptoken, $select and $expand from the query parameters of a request, other parameters are ignored
+//
+//	options, err := gormodatahttp.ParseQueryOptions(r.URL.Query())
+//
+// the filter is only read here, it is parsed and validated when the query is built
+func ParseQueryOptions(values url.Values) (*QueryOptions, error) {
+	options := &QueryOptions{
+		Filter:    strings.TrimSpace(values.Get("$filter")),
+		SkipToken: values.Get("$skiptoken"),
+	}
+
+	if orderBy := values.Get("$orderby"); orderBy != "" {
+		for item := range strings.SplitSeq(orderBy, ",") {
+			fields := strings.Fields(item)
+			if len(fields) == 0 || len(fields) > 2 || (len(fields) == 2 && fields[1] != "asc" && fields[1] != "desc") {
+				return nil, &gormodata.InvalidQueryError{
+					Msg:        fmt.Sprintf("$orderby item '%s' must be a property followed by an optional 'asc' or 'desc'", strings.TrimSpace(item)),
+					Err:        ErrInvalidQueryOption,
+					Expression: orderBy,
+				}
+			}
+			options.OrderBy = append(options.OrderBy, OrderBy{Property: fields[0], Desc: len(fields) == 2 && fields[1] == "desc"})
+		}
+	}
+
+	var err error
+	if options.Top, err = nonNegativeInteger(values, "$top"); err != nil {
+		return nil, err
+	}
+	if options.Skip, err = nonNegativeInteger(values, "$skip"); err != nil {
+		return nil, err
+	}
+	if values.Has("$select") {
+		if options.Select, err = parseSelect(values.Get("$select")); err != nil {
+			return nil, err
+		}
+	}
+	if expand := values.Get("$expand"); expand != "" {
+		if options.Expand, err = parseExpand(expand); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+// nonNegativeInteger
+// returns the value of the query option as an integer, nil if the option is not given
+func nonNegativeInteger(values url.Values, option string) (*int, error) {
+	if !values.Has(option) {
+		return nil, nil
+	}
+
+	value := values.Get(option)
+	integer, err := strconv.Atoi(value)
+	if err != nil || integer < 0 {
+		return nil, &gormodata.InvalidQueryError{
+			Msg:        fmt.Sprintf("%s must be a non-negative integer, got '%s'", option, value),
+			Err:        ErrInvalidQueryOption,
+			Expression: value,
+		}
+	}
+
+	return &integer, nil
+}
+
+// orderColumns
+// returns the columns of $orderby, the properties are checked against the schema of the model
+// and only sortable fields are allowed when the fields are tagged (see WithTaggedFields)
+func (o *QueryOptions) orderColumns(namer schema.Namer, modelSchema *schema.Schema, tagged bool) ([]clause.OrderByColumn, error) {
+	columns := make([]clause.OrderByColumn, 0, len(o.OrderBy))
+	for _, orderBy := range o.OrderBy {
+		if strings.Contains(orderBy.Property, "/") {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$orderby cannot sort on property '%s' of a relation", orderBy.Property),
+				Err:        ErrInvalidQueryOption,
+				Expression: orderBy.Property,
+			}
+		}
+
+		field := modelSchema.LookUpField(namer.ColumnName("", orderBy.Property))
+		if field == nil || field.DBName == "" || gormodata.IsHiddenField(field) {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("unknown property '%s' in $orderby", orderBy.Property),
+				Err:        gormodata.ErrUnknownProperty,
+				Expression: orderBy.Property,
+			}
+		}
+		if tagged && !gormodata.HasODataTag(field, gormodata.SortableTag) {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$orderby cannot sort on property '%s', it is not sortable", orderBy.Property),
+				Err:        gormodata.ErrFieldNotAllowed,
+				Expression: orderBy.Property,
+			}
+		}
+
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: field.DBName}, Desc: orderBy.Desc})
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,115 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/test-go/testify/assert"
+)
+
+func ptr[T any](in T) *T {
+	return &in
+}
+
+func Test_ParseQueryOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query           string
+		expectedOptions *QueryOptions
+	}{
+		"no options": {
+			query:           "other=value",
+			expectedOptions: &QueryOptions{},
+		},
+		"all options": {
+			query: url.Values{
+				"$filter":    {" name eq 'rex' "},
+				"$orderby":   {"birthYear desc, name asc,id"},
+				"$top":       {"10"},
+				"$skip":      {"0"},
+				"$skiptoken": {"WzEwXQ"},
+				"$select":    {"name, birthYear"},
+				"$expand":    {"owner($select=name)"},
+			}.Encode(),
+			expectedOptions: &QueryOptions{
+				Filter:    "name eq 'rex'",
+				OrderBy:   []OrderBy{{Property: "birthYear", Desc: true}, {Property: "name"}, {Property: "id"}},
+				Top:       ptr(10),
+				Skip:      ptr(0),
+				SkipToken: "WzEwXQ",
+				Select:    []string{"name", "birthYear"},
+				Expand:    []Expand{{Property: "owner", Select: []string{"name"}}},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			values, err := url.ParseQuery(testData.query)
+			assert.NoError(t, err)
+
+			// Act
+			result, err := ParseQueryOptions(values)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedOptions, result)
+		})
+	}
+}
+
+func Test_ParseQueryOptions_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values      url.Values
+		expectedErr string
+	}{
+		"top is not an integer": {
+			values:      url.Values{"$top": {"ten"}},
+			expectedErr: "invalid query: $top must be a non-negative integer, got 'ten'",
+		},
+		"negative skip": {
+			values:      url.Values{"$skip": {"-1"}},
+			expectedErr: "invalid query: $skip must be a non-negative integer, got '-1'",
+		},
+		"unknown direction": {
+			values:      url.Values{"$orderby": {"name up"}},
+			expectedErr: "invalid query: $orderby item 'name up' must be a property followed by an optional 'asc' or 'desc'",
+		},
+		"empty item": {
+			values:      url.Values{"$orderby": {"name,,id"}},
+			expectedErr: "invalid query: $orderby item '' must be a property followed by an optional 'asc' or 'desc'",
+		},
+		"select of a relation property": {
+			values:      url.Values{"$select": {"owner/name"}},
+			expectedErr: "invalid query: $select item 'owner/name' must be a property",
+		},
+		"unbalanced expand": {
+			values:      url.Values{"$expand": {"owner($select=name"}},
+			expectedErr: "invalid query: unbalanced brackets in 'owner($select=name'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := ParseQueryOptions(testData.values)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidQueryOption))
+			var invalidQueryError *gormodata.InvalidQueryError
+			assert.True(t, errors.As(err, &invalidQueryError))
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package gormodatahttp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Expand
+// is a relation of $expand with its nested query options (e.g. "owner($select=name;$expand=address)")
+type Expand struct {
+	Property string
+
+	// Select are the properties of $select of the relation, nil if every property is selected
+	Select []string
+
+	// Expand are the relations of the relation that are expanded as well
+	Expand []Expand
+}
+
+// SelectExpandOption
+// configures how ApplySelectExpand loads the expanded relations
+type SelectExpandOption func(*selectExpandConfig)
+
+type selectExpandConfig struct {
+	joins bool
+
+	// expandableTags only allows the relations that are tagged as expandable
+	expandableTags bool
+}
+
+// WithJoinedExpands
+// loads the expanded relations to a single record (belongs to and has one) of the model with a LEFT JOIN instead of a second query,
+// relations with nested expands and relations to many records are still preloaded with a query of their own
+//
+// the columns of filters are not prefixed with the table, so a filter on a column that the joined tables have as well (e.g. id) is ambiguous,
+// only use joins when the filterable properties are not in the tables of the relations (see gormodata.WithAllowedFields)
+func WithJoinedExpands() SelectExpandOption {
+	return func(c *selectExpandConfig) {
+		c.joins = true
+	}
+}
+
+// WithExpandableTags
+// only expands the relations that are tagged as expandable in the model (odata:"expandable"), also the relations of nested expands,
+// other relations fail with gormodata.ErrFieldNotAllowed
+func WithExpandableTags() SelectExpandOption {
+	return func(c *selectExpandConfig) {
+		c.expandableTags = true
+	}
+}
+
+// ApplySelectExpand
+// selects the columns of $select and loads the relations of $expand with only their selected columns, like a smaller struct does in gorm,
+// so the database only returns the requested columns
+//
+//	query, err := gormodatahttp.ApplySelectExpand(db.Model(&Pet{}), &Pet{}, options.Select, options.Expand)
+//	err = query.Find(&pets).Error
+//
+// the primary keys and the foreign keys of the relations are always selected, since gorm needs them to load the relations,
+// the other fields of the results have their zero values
+func ApplySelectExpand(db *gorm.DB, model any, selects []string, expands []Expand, opts ...SelectExpandOption) (*gorm.DB, error) {
+	c := &selectExpandConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// The schema is parsed once per model and cached by gorm
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return nil, err
+	}
+
+	if c.expandableTags {
+		if err := checkExpandable(db.NamingStrategy, statement.Schema, expands); err != nil {
+			return nil, err
+		}
+	}
+
+	columns, err := selectColumns(db.NamingStrategy, statement.Schema, selects, expands)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := false
+	for _, expand := range expands {
+		relation, err := expandRelation(db.NamingStrategy, statement.Schema, expand.Property)
+		if err != nil {
+			return nil, err
+		}
+
+		relationColumns, err := relationSelectColumns(db.NamingStrategy, relation, expand)
+		if err != nil {
+			return nil, err
+		}
+
+		single := relation.Type == schema.BelongsTo || relation.Type == schema.HasOne
+		if c.joins && single && len(expand.Expand) == 0 {
+			joined = true
+			db = db.Joins(relation.Name, db.Session(&gorm.Session{NewDB: true}).Select(relationColumns))
+
+			continue
+		}
+
+		if db, err = preload(db, relation, relation.Name, relationColumns, expand.Expand); err != nil {
+			return nil, err
+		}
+	}
+
+	if columns == nil {
+		return db, nil
+	}
+
+	// The columns of the model are prefixed with its table, since the joined tables can have the same columns
+	if joined {
+		for i, column := range columns {
+			columns[i] = db.Statement.Quote(clause.Column{Table: statement.Schema.Table, Name: column})
+		}
+	}
+
+	return db.Select(columns), nil
+}
+
+// preload
+// preloads the relation with only the columns, and the expanded relations of the relation below it
+func preload(db *gorm.DB, relation *schema.Relationship, path string, columns []string, expands []Expand) (*gorm.DB, error) {
+	db = db.Preload(path, func(tx *gorm.DB) *gorm.DB {
+		if columns == nil {
+			return tx
+		}
+
+		return tx.Select(columns)
+	})
+
+	for _, expand := range expands {
+		nested, err := expandRelation(db.NamingStrategy, relation.FieldSchema, expand.Property)
+		if err != nil {
+			return nil, err
+		}
+
+		nestedColumns, err := relationSelectColumns(db.NamingStrategy, nested, expand)
+		if err != nil {
+			return nil, err
+		}
+
+		if db, err = preload(db, nested, path+"."+nested.Name, nestedColumns, expand.Expand); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// selectColumns
+// returns the columns of the properties of $select with the keys that are needed to load the relations,
+// nil if every column is selected
+func selectColumns(namer schema.Namer, modelSchema *schema.Schema, selects []string, expands []Expand) ([]string, error) {
+	if selects == nil || slices.Contains(selects, "*") {
+		return nil, nil
+	}
+
+	columns := []string{}
+	add := func(column string) {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	for _, field := range modelSchema.PrimaryFields {
+		add(field.DBName)
+	}
+
+	for _, property := range selects {
+		field := modelSchema.LookUpField(namer.ColumnName("", property))
+		if field == nil || field.DBName == "" || gormodata.IsHiddenField(field) {
+			if _, err := expandRelation(namer, modelSchema, property); err == nil {
+				return nil, &gormodata.InvalidQueryError{
+					Msg:        fmt.Sprintf("$select cannot select relation '%s', use $expand", property),
+					Err:        ErrInvalidQueryOption,
+					Expression: property,
+				}
+			}
+
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("unknown property '%s' in $select", property),
+				Err:        gormodata.ErrUnknownProperty,
+				Expression: property,
+			}
+		}
+		add(field.DBName)
+	}
+
+	// The foreign keys of the model are needed to load the relations it belongs to
+	for _, expand := range expands {
+		relation, err := expandRelation(namer, modelSchema, expand.Property)
+		if err != nil {
+			return nil, err
+		}
+		for _, reference := range relation.References {
+			if reference.OwnPrimaryKey || reference.ForeignKey.Schema != modelSchema {
+				continue
+			}
+			add(reference.ForeignKey.DBName)
+		}
+	}
+
+	return columns, nil
+}
+
+// relationSelectColumns
+// returns the columns of the relation like selectColumns, with the foreign keys of the relation in its own table (has one and has many),
+// nil if every column is selected
+func relationSelectColumns(namer schema.Namer, relation *schema.Relationship, expand Expand) ([]string, error) {
+	columns, err := selectColumns(namer, relation.FieldSchema, expand.Select, expand.Expand)
+	if err != nil || columns == nil {
+		return columns, err
+	}
+
+	for _, reference := range relation.References {
+		if reference.ForeignKey.Schema == relation.FieldSchema && !slices.Contains(columns, reference.ForeignKey.DBName) {
+			columns = append(columns, reference.ForeignKey.DBName)
+		}
+	}
+
+	return columns, nil
+}
+
+// expandRelation
+// returns the relation of the property of $expand, hidden relations are unknown (see gormodata.IsHiddenField)
+func expandRelation(namer schema.Namer, modelSchema *schema.Schema, property string) (*schema.Relationship, error) {
+	for name, relation := range modelSchema.Relationships.Relations {
+		if namer.ColumnName("", name) == namer.ColumnName("", property) && !gormodata.IsHiddenField(relation.Field) {
+			return relation, nil
+		}
+	}
+
+	return nil, &gormodata.InvalidQueryError{
+		Msg:        fmt.Sprintf("unknown relation '%s' in $expand", property),
+		Err:        gormodata.ErrUnknownProperty,
+		Expression: property,
+	}
+}
+
+// checkExpandable
+// returns an error for the first relation of the expands or their nested expands that is not tagged as expandable
+func checkExpandable(namer schema.Namer, modelSchema *schema.Schema, expands []Expand) error {
+	for _, expand := range expands {
+		relation, err := expandRelation(namer, modelSchema, expand.Property)
+		if err != nil {
+			return err
+		}
+		if !gormodata.HasODataTag(relation.Field, gormodata.ExpandableTag) {
+			return &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$expand cannot expand relation '%s', it is not expandable", expand.Property),
+				Err:        gormodata.ErrFieldNotAllowed,
+				Expression: expand.Property,
+			}
+		}
+		if err := checkExpandable(namer, relation.FieldSchema, expand.Expand); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseExpand
+// parses the relations of $expand with their nested $select and $expand, separated by ';' (e.g. "owner($select=name;$expand=address),toys")
+func parseExpand(expand string) ([]Expand, error) {
+	items, err := splitTopLevel(expand, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	expands := make([]Expand, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		property, options, nested := strings.Cut(item, "(")
+		property = strings.TrimSpace(property)
+		if property == "" || strings.ContainsAny(property, " /") {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$expand item '%s' must be a relation followed by optional query options in brackets", item),
+				Err:        ErrInvalidQueryOption,
+				Expression: expand,
+			}
+		}
+
+		result := Expand{Property: property}
+		if nested && !strings.HasSuffix(options, ")") {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$expand item '%s' must end with the closing bracket of its query options", item),
+				Err:        ErrInvalidQueryOption,
+				Expression: expand,
+			}
+		}
+		if nested {
+			if result.Select, result.Expand, err = parseExpandOptions(strings.TrimSuffix(options, ")")); err != nil {
+				return nil, err
+			}
+		}
+		expands = append(expands, result)
+	}
+
+	return expands, nil
+}
+
+// parseExpandOptions
+// parses the query options of a relation of $expand, only $select and $expand are supported
+func parseExpandOptions(options string) ([]string, []Expand, error) {
+	items, err := splitTopLevel(options, ';')
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var selects []string
+	var expands []Expand
+	for _, item := range items {
+		name, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch name {
+		case "$select":
+			if selects, err = parseSelect(value); err != nil {
+				return nil, nil, err
+			}
+		case "$expand":
+			if expands, err = parseExpand(value); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("query option '%s' is not supported in $expand", name),
+				Err:        ErrInvalidQueryOption,
+				Expression: options,
+			}
+		}
+	}
+
+	return selects, expands, nil
+}
+
+// parseSelect
+// parses the properties of $select, "*" selects every property
+func parseSelect(selectOption string) ([]string, error) {
+	properties := strings.Split(selectOption, ",")
+	for i, property := range properties {
+		properties[i] = strings.TrimSpace(property)
+		if properties[i] == "" || strings.ContainsAny(properties[i], " /()") {
+			return nil, &gormodata.InvalidQueryError{
+				Msg:        fmt.Sprintf("$select item '%s' must be a property", properties[i]),
+				Err:        ErrInvalidQueryOption,
+				Expression: selectOption,
+			}
+		}
+	}
+
+	return properties, nil
+}
+
+// splitTopLevel
+// splits the value on the separator outside of brackets
+func splitTopLevel(value string, separator rune) ([]string, error) {
+	var items []string
+	depth, start := 0, 0
+	for i, character := range value {
+		switch character {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case separator:
+			if depth == 0 {
+				items = append(items, value[start:i])
+				start = i + 1
+			}
+		}
+
+		if depth < 0 {
+			break
+		}
+	}
+
+	if depth != 0 {
+		return nil, &gormodata.InvalidQueryError{
+			Msg:        fmt.Sprintf("unbalanced brackets in '%s'", value),
+			Err:        ErrInvalidQueryOption,
+			Expression: value,
+		}
+	}
+
+	return append(items, value[start:]), nil
+}
@@ -0,0 +1,288 @@
+package gormodatahttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gormodata "github.com/bramca/gorm-odata-filtering"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Shelter struct {
+	ID   uint
+	Name string
+	City string
+	Pets []ShelterPet
+}
+
+type ShelterPet struct {
+	ID        uint
+	Name      string
+	BirthYear int
+	ShelterID uint
+	Owner     *Owner
+	OwnerID   *uint
+}
+
+func Test_ApplySelectExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selects     []string
+		expands     []Expand
+		options     []SelectExpandOption
+		expectedSQL string
+	}{
+		"nothing": {
+			expectedSQL: "SELECT * FROM `pets`",
+		},
+		"select": {
+			selects:     []string{"name"},
+			expectedSQL: "SELECT `id`,`name` FROM `pets`",
+		},
+		"select all": {
+			selects:     []string{"*"},
+			expectedSQL: "SELECT * FROM `pets`",
+		},
+		"select with expand": {
+			selects:     []string{"name"},
+			expands:     []Expand{{Property: "owner", Select: []string{"name"}}},
+			expectedSQL: "SELECT `id`,`name`,`owner_id` FROM `pets`",
+		},
+		"joined expand": {
+			selects: []string{"name"},
+			expands: []Expand{{Property: "owner", Select: []string{"name"}}},
+			options: []SelectExpandOption{WithJoinedExpands()},
+			expectedSQL: "SELECT `pets`.`id`,`pets`.`name`,`pets`.`owner_id`,`Owner`.`id` AS `Owner__id`,`Owner`.`name` AS `Owner__name` " +
+				"FROM `pets` LEFT JOIN `owners` `Owner` ON `pets`.`owner_id` = `Owner`.`id`",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			query, err := ApplySelectExpand(db.Model(&Pet{}), &Pet{}, testData.selects, testData.expands, testData.options...)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSQL, query.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Find(&[]Pet{})
+			}))
+		})
+	}
+}
+
+func Test_ApplySelectExpand_Preload(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Shelter{}, &ShelterPet{}, &Owner{})
+	owner := &Owner{Name: "tom"}
+	assert.NoError(t, db.Create(owner).Error)
+	assert.NoError(t, db.Create(&Shelter{Name: "paws", City: "ghent", Pets: []ShelterPet{
+		{Name: "rex", BirthYear: 2020, OwnerID: &owner.ID},
+		{Name: "bob", BirthYear: 2021},
+	}}).Error)
+
+	expands, err := parseExpand("pets($select=name;$expand=owner($select=name))")
+	assert.NoError(t, err)
+
+	// Act
+	query, err := ApplySelectExpand(db.Model(&Shelter{}), &Shelter{}, []string{"name"}, expands)
+
+	// Assert
+	assert.NoError(t, err)
+	var shelters []Shelter
+	assert.NoError(t, query.Find(&shelters).Error)
+	assert.Equal(t, []Shelter{{
+		ID:   1,
+		Name: "paws",
+		Pets: []ShelterPet{
+			{ID: 1, Name: "rex", ShelterID: 1, OwnerID: &owner.ID, Owner: &Owner{ID: owner.ID, Name: "tom"}},
+			{ID: 2, Name: "bob", ShelterID: 1},
+		},
+	}}, shelters)
+}
+
+func Test_ApplySelectExpand_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selects     []string
+		expands     []Expand
+		expectedErr error
+		expectedMsg string
+	}{
+		"unknown property": {
+			selects:     []string{"color"},
+			expectedErr: gormodata.ErrUnknownProperty,
+			expectedMsg: "invalid query: unknown property 'color' in $select",
+		},
+		"relation in select": {
+			selects:     []string{"owner"},
+			expectedErr: ErrInvalidQueryOption,
+			expectedMsg: "invalid query: $select cannot select relation 'owner', use $expand",
+		},
+		"unknown relation": {
+			expands:     []Expand{{Property: "toys"}},
+			expectedErr: gormodata.ErrUnknownProperty,
+			expectedMsg: "invalid query: unknown relation 'toys' in $expand",
+		},
+		"unknown property of relation": {
+			expands:     []Expand{{Property: "owner", Select: []string{"age"}}},
+			expectedErr: gormodata.ErrUnknownProperty,
+			expectedMsg: "invalid query: unknown property 'age' in $select",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			query, err := ApplySelectExpand(db.Model(&Pet{}), &Pet{}, testData.selects, testData.expands)
+
+			// Assert
+			assert.Nil(t, query)
+			assert.EqualError(t, err, testData.expectedMsg)
+			assert.True(t, errors.Is(err, testData.expectedErr))
+		})
+	}
+}
+
+func Test_parseExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		expand         string
+		expectedExpand []Expand
+	}{
+		"relation": {
+			expand:         "owner",
+			expectedExpand: []Expand{{Property: "owner"}},
+		},
+		"relations": {
+			expand:         "owner, toys",
+			expectedExpand: []Expand{{Property: "owner"}, {Property: "toys"}},
+		},
+		"nested options": {
+			expand: "pets($select=name,birthYear;$expand=owner($select=name)),owner",
+			expectedExpand: []Expand{
+				{Property: "pets", Select: []string{"name", "birthYear"}, Expand: []Expand{{Property: "owner", Select: []string{"name"}}}},
+				{Property: "owner"},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := parseExpand(testData.expand)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedExpand, result)
+		})
+	}
+}
+
+func Test_parseExpand_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		expand      string
+		expectedErr string
+	}{
+		"empty item": {
+			expand:      "owner,,toys",
+			expectedErr: "invalid query: $expand item '' must be a relation followed by optional query options in brackets",
+		},
+		"unsupported option": {
+			expand:      "owner($top=1)",
+			expectedErr: "invalid query: query option '$top' is not supported in $expand",
+		},
+		"text after options": {
+			expand:      "owner($select=name)id",
+			expectedErr: "invalid query: $expand item 'owner($select=name)id' must end with the closing bracket of its query options",
+		},
+		"unbalanced brackets": {
+			expand:      "owner($select=name))",
+			expectedErr: "invalid query: unbalanced brackets in 'owner($select=name))'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := parseExpand(testData.expand)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrInvalidQueryOption))
+		})
+	}
+}
+
+func Test_Handler_SelectExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		values       url.Values
+		options      []Option
+		expectedBody string
+	}{
+		"ignored without option": {
+			values:       url.Values{"$select": {"name"}, "$expand": {"owner"}},
+			expectedBody: "SELECT * FROM `pets`",
+		},
+		"select and expand": {
+			values:       url.Values{"$select": {"name"}, "$expand": {"owner"}, "$orderby": {"name"}},
+			options:      []Option{WithSelectExpand()},
+			expectedBody: "SELECT `id`,`name`,`owner_id` FROM `pets` ORDER BY `pets`.`name`",
+		},
+		"joined expand": {
+			values:  url.Values{"$select": {"name"}, "$expand": {"owner($select=name)"}, "$orderby": {"id"}},
+			options: []Option{WithSelectExpand(WithJoinedExpands())},
+			expectedBody: "SELECT `pets`.`id`,`pets`.`name`,`pets`.`owner_id`,`Owner`.`id` AS `Owner__id`,`Owner`.`name` AS `Owner__name` " +
+				"FROM `pets` LEFT JOIN `owners` `Owner` ON `pets`.`owner_id` = `Owner`.`id` ORDER BY `pets`.`id`",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			handler := Handler(db, &Pet{}, sqlHandler, testData.options...)
+			request := httptest.NewRequest(http.MethodGet, "/pets?"+testData.values.Encode(), nil)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			handler.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
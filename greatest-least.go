@@ -0,0 +1,81 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+const (
+	greatestFunction = "greatest"
+	leastFunction    = "least"
+)
+
+// extremumTranslation
+// returns the largest or smallest of two operands for every database type, SQLite has no GREATEST or LEAST but its MAX and MIN
+// take several arguments, SQL Server before 2022 has neither so the operands are aggregated from a table of values
+//
+// nulls are ignored on PostgreSQL and SQL Server, MySQL and SQLite return null when one of the operands is null
+var extremumTranslation = map[DbType]map[string]string{
+	PostgreSQL: {
+		greatestFunction: "GREATEST(%s, %s)",
+		leastFunction:    "LEAST(%s, %s)",
+	},
+	MySQL: {
+		greatestFunction: "GREATEST(%s, %s)",
+		leastFunction:    "LEAST(%s, %s)",
+	},
+	SQLite: {
+		greatestFunction: "MAX(%s, %s)",
+		leastFunction:    "MIN(%s, %s)",
+	},
+	SQLServer: {
+		greatestFunction: "(SELECT MAX(extremum) FROM (VALUES (%s), (%s)) AS extremums(extremum))",
+		leastFunction:    "(SELECT MIN(extremum) FROM (VALUES (%s), (%s)) AS extremums(extremum))",
+	},
+}
+
+// isExtremumNode
+// returns whether the node is a call of greatest or least
+func isExtremumNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && (node.Value == greatestFunction || node.Value == leastFunction)
+}
+
+// buildExtremum
+// builds the largest or smallest of the operands of greatest or least, which are properties, numbers or other numeric expressions
+//
+//	greatest(updatedAt,createdAt) gt '2025-01-01'  ->  GREATEST(updated_at, created_at) > '2025-01-01 00:00:00'
+//
+// the value it is compared with is converted to the type of its first property (see extremumProperty)
+func buildExtremum(translation *queryTranslation, node *syntaxtree.Node) (string, error) {
+	operands := [2]string{}
+	for index, operand := range []*syntaxtree.Node{node.LeftChild, node.RightChild} {
+		var err error
+		if operands[index], err = buildNumericOperand(translation, node, operand); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf(extremumTranslation[translation.databaseType][node.Value], operands[0], operands[1]), nil
+}
+
+// extremumProperty
+// returns the first property of the operands of greatest or least (e.g. updatedAt of greatest(updatedAt,createdAt)),
+// whose column type the value of the comparison is converted to (see convertLiteral), nil if the node is not greatest or least
+// or none of its operands is a property
+func extremumProperty(node *syntaxtree.Node) *syntaxtree.Node {
+	if !isExtremumNode(node) {
+		return nil
+	}
+
+	for _, operand := range []*syntaxtree.Node{node.LeftChild, node.RightChild} {
+		if property := extremumProperty(operand); property != nil {
+			return property
+		}
+		if (operand.Type == syntaxtree.LeftOperand || operand.Type == syntaxtree.RightOperand) && isPropertyNode(operand) {
+			return operand
+		}
+	}
+
+	return nil
+}
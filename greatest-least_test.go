@@ -0,0 +1,260 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Match struct {
+	ID        int
+	HomeScore int
+	AwayScore int
+}
+
+func Test_Builder_GreatestLeast(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"greatest": {
+			queryString: "greatest(homeScore,awayScore) gt 3",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `matches` WHERE GREATEST(home_score, away_score) > 3",
+				MySQL:      "SELECT * FROM `matches` WHERE GREATEST(home_score, away_score) > 3",
+				SQLite:     "SELECT * FROM `matches` WHERE MAX(home_score, away_score) > 3",
+				SQLServer:  "SELECT * FROM `matches` WHERE (SELECT MAX(extremum) FROM (VALUES (home_score), (away_score)) AS extremums(extremum)) > 3",
+			},
+		},
+		"least": {
+			queryString: "least(homeScore,2) eq 0",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `matches` WHERE LEAST(home_score, 2) = 0",
+				MySQL:      "SELECT * FROM `matches` WHERE LEAST(home_score, 2) = 0",
+				SQLite:     "SELECT * FROM `matches` WHERE MIN(home_score, 2) = 0",
+				SQLServer:  "SELECT * FROM `matches` WHERE (SELECT MIN(extremum) FROM (VALUES (home_score), (2)) AS extremums(extremum)) = 0",
+			},
+		},
+		"nested": {
+			queryString: "not(greatest(least(homeScore,awayScore),homeScore sub awayScore) le 1)",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `matches` WHERE GREATEST(LEAST(home_score, away_score), (home_score - away_score)) > 1",
+				MySQL:      "SELECT * FROM `matches` WHERE GREATEST(LEAST(home_score, away_score), (home_score - away_score)) > 1",
+				SQLite:     "SELECT * FROM `matches` WHERE MAX(MIN(home_score, away_score), (home_score - away_score)) > 1",
+				SQLServer:  "SELECT * FROM `matches` WHERE (SELECT MAX(extremum) FROM (VALUES ((SELECT MIN(extremum) FROM (VALUES (home_score), (away_score)) AS extremums(extremum))), ((home_score - away_score))) AS extremums(extremum)) > 1",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Match{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_GreatestLeastResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedIDs []int
+	}{
+		"greatest": {
+			queryString: "greatest(homeScore,awayScore) ge 3",
+			expectedIDs: []int{1, 2},
+		},
+		"least": {
+			queryString: "least(homeScore,awayScore) eq 0",
+			expectedIDs: []int{2, 3},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Match{})
+			db.Create(&Match{ID: 1, HomeScore: 3, AwayScore: 1})
+			db.Create(&Match{ID: 2, HomeScore: 0, AwayScore: 4})
+			db.Create(&Match{ID: 3, HomeScore: 0, AwayScore: 0})
+
+			// Act
+			dbQuery, err := New(WithDatabaseType(SQLite)).Build(testData.queryString, db)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var ids []int
+			assert.NoError(t, dbQuery.Model(&Match{}).Order("id").Pluck("id", &ids).Error)
+			assert.Equal(t, testData.expectedIDs, ids)
+		})
+	}
+}
+
+func Test_Builder_GreatestLeastErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		options     []Option
+		expectedErr string
+		expectedIs  error
+	}{
+		"root of the filter": {
+			queryString: "greatest(homeScore,awayScore)",
+			expectedErr: "invalid query: function 'greatest' cannot be the root of a filter, compare it with a value instead (e.g. greatest(homeScore,awayScore) gt 5)",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"right operand": {
+			queryString: "homeScore eq least(homeScore,awayScore)",
+			expectedErr: "invalid query: function 'least' cannot be the right operand of 'eq', only values can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"string literal": {
+			queryString: "greatest(homeScore,'3') gt 1",
+			expectedErr: "invalid query: operand '3' of 'greatest' must be a number, a property of the model or a function",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"relation path": {
+			queryString: "least(homeScore,team/score) lt 1",
+			expectedErr: "invalid query: operand 'team/score' of 'least' must be a number, a property of the model or a function",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"field not allowed": {
+			queryString: "greatest(homeScore,awayScore) gt 1",
+			options:     []Option{WithAllowedFields("homeScore")},
+			expectedErr: "invalid query: field 'awayScore' is not allowed",
+			expectedIs:  ErrFieldNotAllowed,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(PostgreSQL)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Match{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+type Ticket struct {
+	ID        int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func Test_Builder_GreatestLeastTimeColumns(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+		expectedIDs []int
+	}{
+		"greatest with a date": {
+			queryString: "greatest(updatedAt,createdAt) gt '2025-01-01'",
+			expectedSql: "SELECT * FROM `tickets` WHERE MAX(updated_at, created_at) > \"2025-01-01 00:00:00\"",
+			expectedIDs: []int{2, 3},
+		},
+		"least with a timestamp": {
+			queryString: "least(updatedAt,createdAt) lt '2025-01-01T12:00:00Z'",
+			expectedSql: "SELECT * FROM `tickets` WHERE MIN(updated_at, created_at) < \"2025-01-01 12:00:00\"",
+			expectedIDs: []int{1, 2},
+		},
+		"nested with epoch milliseconds": {
+			queryString: "greatest(least(updatedAt,createdAt),createdAt) ge 1735732800000",
+			expectedSql: "SELECT * FROM `tickets` WHERE MAX(MIN(updated_at, created_at), created_at) >= \"2025-01-01 12:00:00\"",
+			expectedIDs: []int{3},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Ticket{})
+			db.Create(&Ticket{ID: 1, CreatedAt: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)})
+			db.Create(&Ticket{ID: 2, CreatedAt: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)})
+			db.Create(&Ticket{ID: 3, CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)})
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&[]Ticket{})
+			})
+
+			dbQuery, _ := builder.Build(testData.queryString, db)
+			var ids []int
+			queryResult := dbQuery.Model(&Ticket{}).Order("id").Pluck("id", &ids)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+			assert.Equal(t, testData.expectedIDs, ids)
+		})
+	}
+}
+
+func Test_Builder_GreatestLeastInvalidTime(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Ticket{})
+
+	// Act
+	dbQuery, err := New(WithDatabaseType(SQLite)).Build("greatest(updatedAt,createdAt) gt 'tomorrow'", db)
+	if err == nil {
+		err = dbQuery.Find(&[]Ticket{}).Error
+	}
+
+	// Assert
+	assert.EqualError(t, err, "invalid query: invalid datetime literal 'tomorrow' for column 'updated_at'")
+}
@@ -0,0 +1,143 @@
+package gormodata
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// enumTypePattern
+// matches the qualified names of enum types (e.g. Ns.Permission) that start enum literals
+var enumTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)+$`)
+
+// enumLiteralPattern
+// matches the enum literals of a filter: <enum type>'<member>,<member>' (e.g. Ns.Permission'Read,Write')
+var enumLiteralPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+)'(.*)'$`)
+
+// EnumLiteral
+// is the value of an enum literal of a filter as it is written (e.g. Ns.Permission'Read,Write'), the right operand of 'has'
+type EnumLiteral string
+
+// WithEnum
+// registers the members of a flag enum type (e.g. "Ns.Permission": {"Read": 1, "Write": 2, "Delete": 4}),
+// so filters can check the flags of integer columns with 'has'
+//
+//	builder := gormodata.New(gormodata.WithEnum("Ns.Permission", map[string]int64{"Read": 1, "Write": 2, "Delete": 4}))
+//	dbQuery, err := builder.Build("permissions has Ns.Permission'Read,Write'", db.Model(&User{}))
+//
+// the members of an enum literal are combined into one flag, the column has it when all of its bits are set: (permissions & 3) = 3
+func WithEnum(name string, members map[string]int64) Option {
+	return func(b *Builder) {
+		if b.enums == nil {
+			b.enums = map[string]map[string]int64{}
+		}
+		b.enums[name] = maps.Clone(members)
+	}
+}
+
+// enumLiteralEnd
+// returns the index after the enum literal that starts with the word (e.g. Ns.Permission'Read,Write'),
+// the members are a string literal that directly follows the enum type
+func (c *parserConfig) enumLiteralEnd(query string, word string, end int) int {
+	if end >= len(query) || query[end] != c.lexer.StringDelimiter || !enumTypePattern.MatchString(word) {
+		return end
+	}
+
+	literalEnd, terminated := c.stringLiteralEnd(query, end)
+	if !terminated {
+		return end
+	}
+
+	return literalEnd
+}
+
+// isEnumLiteral
+// returns whether the value of a node is an enum literal
+func isEnumLiteral(value string) bool {
+	return enumLiteralPattern.MatchString(value)
+}
+
+// buildHas
+// builds the flag check of 'has' on an integer column, the column has the flag when all of its bits are set
+//
+//	permissions has Ns.Permission'Read,Write'  ->  (permissions & 3) = 3
+func buildHas(root *syntaxtree.Node, db *gorm.DB, translation *queryTranslation, notEnabled bool) (*gorm.DB, error) {
+	leftChild, rightChild := root.LeftChild, root.RightChild
+	if leftChild.Type != syntaxtree.LeftOperand || !isPropertyNode(leftChild) || translation.isRelationPath(leftChild.Value) {
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'has' expects a column of the model as its left operand, got '%s'", nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(root),
+			Node:       leftChild,
+		}
+	}
+
+	flag, err := translation.enumFlag(rightChild)
+	if err != nil {
+		return db, err
+	}
+
+	column := translation.columnName(leftChild.Value)
+	queryString := fmt.Sprintf("(%s & ?) = ?", column)
+	if notEnabled {
+		queryString = fmt.Sprintf("(%s & ?) <> ?", column)
+		// Following odata, comparisons with null are false, so negated comparisons are true for null values
+		if translation.nullSafe {
+			queryString = fmt.Sprintf("((%[1]s & ?) <> ? OR %[1]s IS NULL)", column)
+		}
+	}
+
+	return db.Where(queryString, flag, flag), nil
+}
+
+// enumFlag
+// returns the flag of the enum literal of the node, the members of the literal combined with a bitwise or,
+// members are the names of members of the registered enum type (see WithEnum) or integers
+func (t *queryTranslation) enumFlag(node *syntaxtree.Node) (int64, error) {
+	match := enumLiteralPattern.FindStringSubmatch(node.Value)
+	if node.Type != syntaxtree.RightOperand || match == nil {
+		return 0, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'has' expects an enum literal as its right operand (e.g. Namespace.Type'Member'), got '%s'", nodeExpression(node)),
+			Err:        ErrInvalidSyntax,
+			Expression: nodeExpression(node.Parent),
+			Node:       node,
+		}
+	}
+	enumType, literal := match[1], strings.ReplaceAll(match[2], "''", "'")
+
+	members, ok := t.enums[enumType]
+	if !ok {
+		return 0, &InvalidQueryError{
+			Msg:        fmt.Sprintf("unknown enum type '%s'", enumType),
+			Err:        ErrUnknownProperty,
+			Expression: node.Value,
+			Node:       node,
+		}
+	}
+
+	var flag int64
+	for _, member := range strings.Split(literal, ",") {
+		member = strings.TrimSpace(member)
+		value, ok := members[member]
+		if !ok {
+			number, err := strconv.ParseInt(member, 10, 64)
+			if err != nil {
+				return 0, &InvalidQueryError{
+					Msg:        fmt.Sprintf("unknown member '%s' of enum type '%s'", member, enumType),
+					Err:        ErrUnknownProperty,
+					Expression: node.Value,
+					Node:       node,
+				}
+			}
+			value = number
+		}
+		flag |= value
+	}
+
+	return flag, nil
+}
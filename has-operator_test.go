@@ -0,0 +1,222 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Account struct {
+	ID          uuid.UUID
+	Name        string
+	Permissions int
+}
+
+// permissionEnum
+// is the flag enum type of the permissions of the accounts
+func permissionEnum() Option {
+	return WithEnum("Ns.Permission", map[string]int64{"Read": 1, "Write": 2, "Delete": 4})
+}
+
+func Test_Builder_Has(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		dbType      DbType
+		expectedSql string
+	}{
+		"single flag": {
+			queryString: "permissions has Ns.Permission'Write'",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `accounts` WHERE (permissions & 2) = 2",
+		},
+		"combined flags": {
+			queryString: "permissions has Ns.Permission'Read,Write'",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `accounts` WHERE (permissions & 3) = 3",
+		},
+		"integer members": {
+			queryString: "permissions has Ns.Permission'Read, 4'",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `accounts` WHERE (permissions & 5) = 5",
+		},
+		"negated": {
+			queryString: "not(permissions has Ns.Permission'Delete')",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `accounts` WHERE (permissions & 4) <> 4",
+		},
+		"combined with other conditions": {
+			queryString: "name eq 'admin' or permissions has Ns.Permission'Read,Write,Delete'",
+			dbType:      PostgreSQL,
+			expectedSql: "SELECT * FROM `accounts` WHERE name = \"admin\" OR (permissions & 7) = 7",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(testData.dbType), permissionEnum())
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = builder.Build(testData.queryString, tx.Model(&Account{}))
+				return dbQuery.Find(&Account{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_HasResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"single flag": {
+			queryString:   "permissions has Ns.Permission'Write'",
+			expectedNames: []string{"editor", "owner"},
+		},
+		"combined flags": {
+			queryString:   "permissions has Ns.Permission'Read,Delete'",
+			expectedNames: []string{"owner"},
+		},
+		"negated": {
+			queryString:   "not(permissions has Ns.Permission'Read,Write')",
+			expectedNames: []string{"reader"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Account{})
+			db.Create(&Account{ID: uuid.New(), Name: "reader", Permissions: 1})
+			db.Create(&Account{ID: uuid.New(), Name: "editor", Permissions: 3})
+			db.Create(&Account{ID: uuid.New(), Name: "owner", Permissions: 7})
+
+			builder := New(WithDatabaseType(SQLite), permissionEnum())
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db.Model(&Account{}))
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Account
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, account := range result {
+				names = append(names, account.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_Builder_HasErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"unknown enum type": {
+			queryString: "permissions has Ns.Role'Admin'",
+			expectedErr: "invalid query: unknown enum type 'Ns.Role'",
+			expectedIs:  ErrUnknownProperty,
+		},
+		"unknown member": {
+			queryString: "permissions has Ns.Permission'Read,Share'",
+			expectedErr: "invalid query: unknown member 'Share' of enum type 'Ns.Permission'",
+			expectedIs:  ErrUnknownProperty,
+		},
+		"no enum literal": {
+			queryString: "permissions has 3",
+			expectedErr: "invalid query: 'has' expects an enum literal as its right operand (e.g. Namespace.Type'Member'), got '3'",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation property": {
+			queryString: "metadata/flags has Ns.Permission'Read'",
+			expectedErr: "invalid query: 'has' expects a column of the model as its left operand, got 'metadata/flags'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), permissionEnum())
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Account{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_Has(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("permissions has Ns.Permission'Read,Write' and name eq 'admin'")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Expr{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+		{Kind: ComparisonExpr, Op: "has", Args: []*Expr{exprProperty("permissions"), exprLiteral(EnumLiteral("Ns.Permission'Read,Write'"))}},
+		{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("name"), exprLiteral("admin")}},
+	}}, expr)
+	assert.Equal(t, "permissions has Ns.Permission'Read,Write' and name eq 'admin'", expr.String())
+
+	data, err := json.Marshal(expr)
+	assert.NoError(t, err)
+
+	var decoded Expr
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, expr, &decoded)
+}
+
+func Test_Builder_CapabilitiesHas(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	builder := New(permissionEnum())
+
+	// Act
+	result, err := builder.Capabilities(&Account{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, result.Operators, "has")
+}
@@ -0,0 +1,165 @@
+package gormodata
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// hiddenSetting
+// is the value of the odata tag that hides a field from clients, like json:"-" hides it from JSON
+const hiddenSetting = "-"
+
+// IsHiddenField
+// returns whether the field is hidden with the odata tag, or is a field of an embedded struct that is hidden:
+//
+//	type User struct {
+//		Name         string
+//		PasswordHash string `odata:"-"`
+//	}
+//
+// hidden fields are unknown properties to filters, $select, $orderby and $expand, and are left out of the metadata (see NewCSDL)
+func IsHiddenField(field *schema.Field) bool {
+	if field == nil {
+		return false
+	}
+	if isHiddenTag(field.Tag) {
+		return true
+	}
+	if field.Schema == nil || len(field.BindNames) < 2 {
+		return false
+	}
+
+	// The fields of embedded structs have the tags of their own struct field, the embedded struct can hide all of them
+	structType := field.Schema.ModelType
+	for _, bindName := range field.BindNames[:len(field.BindNames)-1] {
+		structField, ok := structType.FieldByName(bindName)
+		if !ok {
+			return false
+		}
+		if isHiddenTag(structField.Tag) {
+			return true
+		}
+		structType = structField.Type
+		for structType.Kind() == reflect.Pointer {
+			structType = structType.Elem()
+		}
+	}
+
+	return false
+}
+
+// isHiddenTag
+// returns whether the odata tag hides the field
+func isHiddenTag(tag reflect.StructTag) bool {
+	return strings.TrimSpace(tag.Get(odataTag)) == hiddenSetting
+}
+
+// validateHiddenFields
+// rejects the properties that are hidden on the model of the db (see IsHiddenField) as unknown properties,
+// also when the filter is not validated against the model, so hidden fields can never be filtered on
+func validateHiddenFields(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+	if db.Statement.Model == nil {
+		return nil
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(db.Statement.Model); err != nil || !hasHiddenFields(statement.Schema, map[*schema.Schema]bool{}) {
+		return nil
+	}
+
+	validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+		if !isPropertyNode(currentNode) || isRootReference(currentNode.Value) {
+			return nil
+		}
+
+		path := strings.Split(propertyPath(db.NamingStrategy, currentNode.Value), "/")
+		if !isHiddenPath(db.NamingStrategy, statement.Schema, path) {
+			return nil
+		}
+
+		return &InvalidQueryError{
+			Msg:        unknownPropertyPath(db.NamingStrategy, statement.Schema, path, false),
+			Err:        ErrUnknownProperty,
+			Expression: nodeExpression(currentNode),
+			Node:       currentNode,
+		}
+	}
+
+	return validateQueryDepthFirstSearch(db, tree, validationCheck)
+}
+
+// hasHiddenFields
+// returns whether the schema or the schemas of its relations have hidden fields, the filters of models without any are not walked
+func hasHiddenFields(modelSchema *schema.Schema, visited map[*schema.Schema]bool) bool {
+	if modelSchema == nil || visited[modelSchema] {
+		return false
+	}
+	visited[modelSchema] = true
+
+	for _, field := range modelSchema.Fields {
+		if IsHiddenField(field) {
+			return true
+		}
+	}
+	for _, relationship := range modelSchema.Relationships.Relations {
+		if hasHiddenFields(relationship.FieldSchema, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isHiddenPath
+// returns whether the property path (column names) goes through a hidden relation or ends in a hidden field,
+// the keys of the elements of JSON columns are not fields, so only the column can be hidden
+func isHiddenPath(namer schema.Namer, modelSchema *schema.Schema, path []string) bool {
+	for i := range path {
+		if len(path)-i > 1 {
+			if field := findEmbeddedField(namer, modelSchema, path[i:]); field != nil {
+				return IsHiddenField(field)
+			}
+		}
+
+		if relationship := findRelationship(namer, modelSchema, path[i]); relationship != nil {
+			if IsHiddenField(relationship.Field) {
+				return true
+			}
+			modelSchema = relationship.FieldSchema
+
+			continue
+		}
+
+		return IsHiddenField(modelSchema.LookUpField(path[i]))
+	}
+
+	return false
+}
+
+// appendHiddenFieldErrors
+// appends the errors of the hidden fields to the query errors, except for the properties the validations already reported as unknown
+// (e.g. with WithModelValidation)
+func appendHiddenFieldErrors(queryErrors QueryErrors, err error) QueryErrors {
+	reported := map[*syntaxtree.Node]bool{}
+	for _, queryError := range queryErrors {
+		var invalidQueryError *InvalidQueryError
+		if errors.As(queryError, &invalidQueryError) && errors.Is(queryError, ErrUnknownProperty) {
+			reported[invalidQueryError.Node] = true
+		}
+	}
+
+	for _, hiddenFieldError := range appendQueryErrors(nil, err) {
+		var invalidQueryError *InvalidQueryError
+		if errors.As(hiddenFieldError, &invalidQueryError) && reported[invalidQueryError.Node] {
+			continue
+		}
+		queryErrors = append(queryErrors, hiddenFieldError)
+	}
+
+	return queryErrors
+}
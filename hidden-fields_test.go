@@ -0,0 +1,173 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type HiddenAudit struct {
+	CreatedBy string
+	UpdatedBy string
+}
+
+type HiddenUser struct {
+	ID           uint
+	Name         string
+	PasswordHash string      `odata:"-"`
+	Audit        HiddenAudit `gorm:"embedded;embeddedPrefix:audit_" odata:"-"`
+	Manager      *HiddenUser `odata:"-"`
+	ManagerID    *uint
+	Team         *HiddenTeam
+	TeamID       *uint
+}
+
+type HiddenTeam struct {
+	ID     uint
+	Name   string
+	Budget int `odata:"-"`
+}
+
+func Test_Builder_HiddenFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"visible field": {
+			queryString: "name eq 'alice'",
+			expectedSql: "SELECT * FROM `hidden_users` WHERE name = \"alice\"",
+		},
+		"visible field of a relation": {
+			queryString: "team/name eq 'core'",
+			expectedSql: "SELECT * FROM `hidden_users` WHERE team_id IN (SELECT `id` FROM `hidden_teams` WHERE `hidden_teams`.`name` = \"core\")",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(testData.queryString, tx.Model(&HiddenUser{}))
+				return dbQuery.Find(&HiddenUser{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_HiddenFieldsRejected(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		options       []Option
+		expectedError string
+	}{
+		"hidden field": {
+			queryString:   "passwordHash eq 'x'",
+			expectedError: "invalid query: unknown column name 'password_hash'",
+		},
+		"hidden field in a function": {
+			queryString:   "name eq 'alice' and length(passwordHash) gt 3",
+			expectedError: "invalid query: unknown column name 'password_hash'",
+		},
+		"field of a hidden embedded struct": {
+			queryString:   "audit/createdBy eq 'admin'",
+			expectedError: "invalid query: unknown relation 'audit' on 'hidden_users'",
+		},
+		"hidden relation": {
+			queryString:   "manager/name eq 'bob'",
+			expectedError: "invalid query: unknown relation 'manager' on 'hidden_users'",
+		},
+		"hidden field of a relation": {
+			queryString:   "team/budget gt 100",
+			expectedError: "invalid query: unknown column name 'budget' on 'hidden_teams'",
+		},
+		"hidden field with model validation": {
+			queryString:   "passwordHash eq 'x'",
+			options:       []Option{WithQueryValidations(WithModelValidation(&HiddenUser{}))},
+			expectedError: "invalid query: unknown column name 'password_hash'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&HiddenUser{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrUnknownProperty))
+		})
+	}
+}
+
+func Test_Builder_HiddenFieldsCollectAllErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithCollectAllErrors(), WithQueryValidations(WithModelValidation(&HiddenUser{})))
+
+	// Act
+	_, err := builder.Build("passwordHash eq 'x' or manager/name eq 'bob'", db.Model(&HiddenUser{}))
+
+	// Assert
+	var queryErrors QueryErrors
+	assert.True(t, errors.As(err, &queryErrors))
+	assert.Len(t, queryErrors, 2)
+	assert.True(t, errors.Is(err, ErrUnknownProperty))
+}
+
+func Test_IsHiddenField(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	statement := &gorm.Statement{DB: db}
+	_ = statement.Parse(&HiddenUser{})
+
+	// Act
+	hidden := map[string]bool{}
+	for _, field := range statement.Schema.Fields {
+		hidden[field.Name] = IsHiddenField(field)
+	}
+
+	// Assert
+	assert.Equal(t, map[string]bool{
+		"ID":           false,
+		"Name":         false,
+		"PasswordHash": true,
+		"CreatedBy":    true,
+		"UpdatedBy":    true,
+		"Manager":      true,
+		"ManagerID":    false,
+		"Team":         false,
+		"TeamID":       false,
+	}, hidden)
+}
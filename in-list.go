@@ -0,0 +1,160 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// inOperator
+// compares a property with a list of values (e.g. name in ('a','b',3)), the list is one operand (see listLiteralEnd)
+const inOperator = "in"
+
+// defaultInListChunkSize
+// is the maximum number of values of an IN list if WithInListChunkSize is not given, the limit of Oracle
+const defaultInListChunkSize = 1000
+
+// WithInListChunkSize
+// splits the lists of 'in' with more than chunkSize values (e.g. name in ('a','b',...)) into IN lists of at most chunkSize values joined by OR,
+// since some databases and drivers reject long IN lists, the default is 1000 values
+//
+//	builder := gormodata.New(gormodata.WithInListChunkSize(500))
+//
+// a negated list becomes NOT IN lists joined by AND, which matches the same rows as the NOT IN of the whole list
+func WithInListChunkSize(chunkSize int) Option {
+	return func(b *Builder) {
+		b.inListChunkSize = chunkSize
+	}
+}
+
+// chunkSize
+// returns the maximum number of values of an IN list of the translation
+func (t *queryTranslation) chunkSize() int {
+	if t.inListChunkSize <= 0 {
+		return defaultInListChunkSize
+	}
+
+	return t.inListChunkSize
+}
+
+// buildInCondition
+// builds the 'in' of the root as IN lists of the values, the lists of expanded properties become a nested filter per list
+func buildInCondition(db *gorm.DB, root *syntaxtree.Node, translation *queryTranslation, notEnabled bool) (*gorm.DB, error) {
+	leftChild, rightChild := root.LeftChild, root.RightChild
+	if rightChild.Type != syntaxtree.RightOperand || !isListLiteral(rightChild.Value) {
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' needs a list of values, e.g. name in ('a','b')", nodeExpression(root)),
+			Err:        ErrInvalidSyntax,
+			Expression: nodeExpression(root),
+			Node:       root,
+		}
+	}
+	elements := listLiteralElements(rightChild.Value)
+	if len(elements) == 0 || slices.Contains(elements, "") {
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("list %s has empty values", rightChild.Value),
+			Err:        ErrInvalidSyntax,
+			Expression: nodeExpression(rightChild),
+			Node:       rightChild,
+		}
+	}
+
+	relation := translation.isRelationPath(leftChild.Value)
+	values := make([]any, len(elements))
+	for index, element := range elements {
+		values[index] = unquote(element)
+		if integer, ok := integerLiteral(element); ok {
+			values[index] = integer
+		}
+		// Numbers with a type suffix are bound as their type, and written without it in the filters of relations
+		if numeric, ok := bindNumericLiteral(element, translation.databaseType); ok && !relation {
+			values[index] = numeric
+		} else if isNumericSuffixLiteral(element) {
+			values[index] = numericSQL(literalValue(element))
+		}
+	}
+
+	operandString := ""
+	switch {
+	case leftChild.Type == syntaxtree.UnaryOperator:
+		operandString = buildUnaryFuncChain(translation, leftChild)
+	case leftChild.Value == "concat":
+		operandString = buildConcat(translation, leftChild)
+	case leftChild.Type == syntaxtree.LeftOperand:
+		operandString = translation.columnName(leftChild.Value)
+	default:
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' cannot be the left operand of 'in', only properties and functions can", nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(leftChild),
+			Node:       leftChild,
+		}
+	}
+
+	queryString := operandString + " IN (?)"
+	if notEnabled {
+		queryString = operandString + " NOT IN (?)"
+		// Following odata, comparisons with null are false, so negated lists are true for null values
+		if translation.nullSafe {
+			queryString = fmt.Sprintf("(%s OR %s IS NULL)", queryString, operandString)
+		}
+	}
+
+	// The lists are joined by OR, or by AND when negated, in a group of their own
+	cleanDB := db.Session(&gorm.Session{NewDB: true})
+	lists := cleanDB
+	for index, chunk := range slices.Collect(slices.Chunk(values, translation.chunkSize())) {
+		var list *gorm.DB
+		switch {
+		case relation:
+			list = whereNestedFilter(cleanDB, nestedFilterMap(leftChild.Value, chunk, translation), notEnabled)
+		case leftChild.Type == syntaxtree.LeftOperand:
+			// The values are converted to the type of the column (e.g. uuid) once the model is known
+			list = cleanDB.Where(columnComparison{Column: operandString, SQL: queryString, Value: chunk})
+		default:
+			list = cleanDB.Where(queryString, chunk)
+		}
+
+		if index == 0 || notEnabled {
+			lists = lists.Where(list)
+		} else {
+			lists = lists.Or(list)
+		}
+	}
+
+	return db.Where(lists), nil
+}
+
+// isListLiteral
+// returns whether the value of a node is the list of values of 'in' (e.g. ('a','b',3))
+func isListLiteral(value string) bool {
+	return len(value) >= 2 && value[0] == odataLexer.OpenDelimiter && value[len(value)-1] == odataLexer.CloseDelimiter
+}
+
+// listLiteralElements
+// returns the values of a list literal as they are written in the filter, without the whitespace around them,
+// commas inside string literals are part of the value
+func listLiteralElements(literal string) []string {
+	content := literal[1 : len(literal)-1]
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+
+	elements := []string{}
+	start, quoted := 0, false
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == odataLexer.StringDelimiter:
+			// An escaped quote ('') closes and opens the literal again
+			quoted = !quoted
+		case content[i] == odataLexer.BinaryFunctionOpSeparator && !quoted:
+			elements = append(elements, strings.TrimSpace(content[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(elements, strings.TrimSpace(content[start:]))
+}
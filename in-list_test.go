@@ -0,0 +1,219 @@
+package gormodata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_InList(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"list": {
+			queryString: "name in ('a','b', 'c')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name IN (\"a\",\"b\",\"c\")",
+		},
+		"numbers": {
+			queryString: "testValue in (1,2)",
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value IN (1,2)",
+		},
+		"escaped values": {
+			queryString: "name in ('it''s','a,b')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name IN (\"it's\",\"a,b\")",
+		},
+		"chunked": {
+			queryString: "name in ('a','b','c','d','e')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name IN (\"a\",\"b\",\"c\") OR name IN (\"d\",\"e\")",
+		},
+		"chunked in and": {
+			queryString: "testValue eq 'x' and name in ('a','b','c','d','e') and testValue ne 'y'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE (test_value = \"x\" AND (name IN (\"a\",\"b\",\"c\") OR name IN (\"d\",\"e\"))) AND test_value != \"y\"",
+		},
+		"negated chunked in or": {
+			queryString: "testValue eq 'x' or not(name in ('a','b','c','d'))",
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"x\" OR (name NOT IN (\"a\",\"b\",\"c\") AND name NOT IN (\"d\"))",
+		},
+		"negated": {
+			queryString: "not(name in ('a','b','c','d','e'))",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name NOT IN (\"a\",\"b\",\"c\") AND name NOT IN (\"d\",\"e\")",
+		},
+		"function": {
+			queryString: "tolower(name) in ('a','b','c')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE LOWER(name) IN (\"a\",\"b\",\"c\")",
+		},
+		"combined": {
+			queryString: "name in ('a','b') and testValue eq 'c' or name in ('d')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE (name IN (\"a\",\"b\") AND test_value = \"c\") OR name IN (\"d\")",
+		},
+		"expanded property": {
+			queryString: "metadata/name in ('a','b','c','d')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` IN (\"a\",\"b\",\"c\")) OR metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"d\")",
+		},
+		"expanded property in and": {
+			queryString: "testValue eq 'x' and metadata/name in ('a','b','c','d')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"x\" AND (metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` IN (\"a\",\"b\",\"c\")) OR metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"d\"))",
+		},
+		"negated expanded property": {
+			queryString: "not(metadata/name in ('a','b','c','d'))",
+			expectedSql: "SELECT * FROM `mock_models` WHERE (metadata_id IS NULL OR metadata_id NOT IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` IN (\"a\",\"b\",\"c\"))) AND (metadata_id IS NULL OR metadata_id NOT IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"d\"))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+			builder := New(WithDatabaseType(SQLite), WithInListChunkSize(3))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&[]MockModel{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_InListError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+		expectedKind  error
+	}{
+		"no list": {
+			queryString:   "name in 'a'",
+			expectedError: "invalid query: 'name in 'a'' needs a list of values, e.g. name in ('a','b')",
+			expectedKind:  ErrInvalidSyntax,
+		},
+		"empty list": {
+			queryString:   "name in ()",
+			expectedError: "invalid query: list () has empty values",
+			expectedKind:  ErrInvalidSyntax,
+		},
+		"empty value": {
+			queryString:   "name in ('a',,'b')",
+			expectedError: "invalid query: list ('a',,'b') has empty values",
+			expectedKind:  ErrInvalidSyntax,
+		},
+		"function in list": {
+			queryString:   "name in (tolower('a'))",
+			expectedError: "failed to parse query: list (tolower( can only contain values",
+			expectedKind:  ErrInvalidSyntax,
+		},
+		"unterminated list": {
+			queryString:   "name in ('a','b'",
+			expectedError: "failed to parse query: unterminated list ('a','b'",
+			expectedKind:  ErrInvalidSyntax,
+		},
+		"bad pattern in list": {
+			queryString:   "testValue in (1,2;drop)",
+			expectedError: "invalid query: node \"(1,2;drop)\" contains a bad pattern",
+		},
+		"invalid uuid in list": {
+			queryString:   "id in ('5f4f2a3c-6b6e-4b8a-9d1e-2f3a4b5c6d7e','abc')",
+			expectedError: "invalid query: invalid uuid literal 'abc' for column 'id'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&MockModel{}), SQLite)
+			if err == nil {
+				err = dbQuery.Find(&[]MockModel{}).Error
+			}
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			if testData.expectedKind != nil {
+				assert.True(t, errors.Is(err, testData.expectedKind))
+			}
+		})
+	}
+}
+
+func Test_Builder_InListLargeList(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+
+	records := []MockModel{{ID: uuid.New(), Name: "name-0"}, {ID: uuid.New(), Name: "name-2499"}, {ID: uuid.New(), Name: "other"}}
+	db.CreateInBatches(records, len(records))
+
+	values := make([]string, 0, 2500)
+	for i := range 2500 {
+		values = append(values, fmt.Sprintf("'name-%d'", i))
+	}
+	queryString := fmt.Sprintf("name in (%s)", strings.Join(values, ","))
+
+	// Act
+	dbQuery, err := New(WithDatabaseType(SQLite)).Build(queryString, db)
+	statement := dbQuery.Session(&gorm.Session{DryRun: true}).Find(&[]MockModel{}).Statement
+
+	var result []MockModel
+	queryResult := dbQuery.Order("name").Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, 3, strings.Count(statement.SQL.String(), " IN ("))
+	assert.Len(t, statement.Vars, 2500)
+	assert.Equal(t, records[:2], result)
+}
+
+func Test_Builder_InListUUIDs(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+
+	records := []MockModel{{ID: uuid.New(), Name: "a"}, {ID: uuid.New(), Name: "b"}, {ID: uuid.New(), Name: "c"}}
+	db.CreateInBatches(records, len(records))
+
+	queryString := fmt.Sprintf("id in ('%s','%s')", records[0].ID, records[2].ID)
+
+	// Act
+	dbQuery, err := BuildQuery(queryString, db, SQLite)
+
+	var result []MockModel
+	queryResult := dbQuery.Order("name").Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, []MockModel{records[0], records[2]}, result)
+}
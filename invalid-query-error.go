@@ -1,9 +1,103 @@
 package gormodata
 
+import (
+	"errors"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// Errors that can be checked with errors.Is, to tell invalid filters from other errors (e.g. to respond with 400 Bad Request)
+var (
+	// ErrInvalidSyntax is wrapped by the errors of filters that cannot be parsed
+	ErrInvalidSyntax = errors.New("invalid syntax")
+
+	// ErrUnbalancedParens is wrapped by the errors of filters with an opening bracket without a closing bracket or the other way around
+	ErrUnbalancedParens = errors.New("unbalanced parentheses")
+
+	// ErrUnknownFunction is wrapped by the errors of filters that call a function that does not exist
+	ErrUnknownFunction = errors.New("unknown function")
+
+	// ErrUnsupportedOperator is wrapped by the errors of filters with an operator that does not exist or is not supported in that position
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+
+	// ErrUnknownProperty is wrapped by the errors of filters on a property or relation that does not exist on the model
+	ErrUnknownProperty = errors.New("unknown property")
+
+	// ErrFieldNotAllowed is wrapped by the errors of filters on a field that is not allowed (see WithAllowedFields, WithDeniedFields, WithDeniedRelations, WithPolicy)
+	ErrFieldNotAllowed = errors.New("field not allowed")
+
+	// ErrFunctionNotAllowed is wrapped by the errors of filters that use a disabled function (see WithDisabledFunctions, WithPolicy)
+	ErrFunctionNotAllowed = errors.New("function not allowed")
+
+	// ErrOperatorNotAllowed is wrapped by the errors of filters that use an operator that is not allowed (see WithPolicy)
+	ErrOperatorNotAllowed = errors.New("operator not allowed")
+
+	// ErrComplexityExceeded is wrapped by the errors of filters that are too complex (see WithMaxLength, WithMaxTokens, WithMaxDepth)
+	ErrComplexityExceeded = errors.New("filter too complex")
+
+	// ErrLiteralNotBound is wrapped by the errors of filters with a literal that would be written into the SQL instead of bound as a parameter (see WithBindParametersOnly)
+	ErrLiteralNotBound = errors.New("literal not bound")
+)
+
+// InvalidQueryError
+// is returned for filters that can be parsed, but cannot be built into a query
 type InvalidQueryError struct {
+	// Msg describes what is invalid and why (e.g. "unary function 'length' cannot be the root of a filter")
 	Msg string
+
+	// Err is the error that caused the query to be invalid, it can be checked with errors.Is (e.g. ErrFieldNotAllowed)
+	Err error
+
+	// Expression is the part of the filter that is invalid (e.g. "length(name)"), empty if the error is not about a part of the filter
+	Expression string
+
+	// Node is the node of the syntax tree that is invalid, nil if the error is not about a node
+	Node *syntaxtree.Node
 }
 
 func (i *InvalidQueryError) Error() string {
 	return "invalid query: " + i.Msg
 }
+
+func (i *InvalidQueryError) Unwrap() error {
+	return i.Err
+}
+
+// SyntaxError
+// is returned for filters that cannot be parsed, it renders as the error of the parser
+// and wraps both the parser error and the kind of syntax error (ErrInvalidSyntax and e.g. ErrUnbalancedParens)
+type SyntaxError struct {
+	// Err is the error of the parser (*syntaxtree.ParseError)
+	Err error
+
+	// Kind is the most specific kind of syntax error (e.g. ErrUnbalancedParens, ErrUnknownFunction), ErrInvalidSyntax if unknown
+	Kind error
+}
+
+func (s *SyntaxError) Error() string {
+	return s.Err.Error()
+}
+
+func (s *SyntaxError) Unwrap() []error {
+	return []error{s.Err, s.Kind, ErrInvalidSyntax}
+}
+
+// QueryErrors
+// are all problems of a filter, returned by a Builder that collects all errors (see WithCollectAllErrors)
+//
+// each error can be checked on its own, errors.Is and errors.As also match any of the errors
+type QueryErrors []error
+
+func (q QueryErrors) Error() string {
+	messages := make([]string, len(q))
+	for i, err := range q {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (q QueryErrors) Unwrap() []error {
+	return q
+}
@@ -0,0 +1,602 @@
+package gormodata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// lambdaOperators
+// are the operators of lambda expressions on collections (e.g. addresses/any(a: a/city eq 'Ghent'))
+var lambdaOperators = []string{"any", "all"}
+
+// itPrefix
+// starts the properties of the model that is filtered inside of a lambda (e.g. $it/name),
+// it is only needed when the property would otherwise be an element of a lambda (see resolve)
+const itPrefix = "$it/"
+
+// jsonKeyPattern
+// matches the keys of the JSON objects that lambda expressions can filter on and the range variables of lambdas
+var jsonKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// lambdaScope
+// is a lambda expression that is being tokenized or built, with the lambda expressions it is nested in
+type lambdaScope struct {
+	// variable is the range variable of the lambda (e.g. "a"), only known while tokenizing
+	variable string
+
+	// collection is the property path of the collection the lambda ranges over (e.g. "addresses")
+	collection string
+
+	// depth is the bracket depth of the condition of the lambda while tokenizing
+	depth int
+
+	// alias is the alias of the elements of the collection in the subquery while building (e.g. "lambda_1")
+	alias string
+
+	// schema is the related model of lambdas on relations while building, nil for JSON arrays
+	schema *schema.Schema
+
+	outer *lambdaScope
+}
+
+// lambdaToken
+// returns the tokens of the start of a lambda expression on a collection (e.g. "addresses/any(a:") and the index after its range variable,
+// ok is false if the word is not the start of a lambda expression
+//
+//	addresses/any(a: a/city eq 'Ghent')  ->  any ( addresses , a/city eq 'Ghent' )
+//
+// the lambda is parsed as a binary function of the collection and the condition, the scope of the range variable is returned
+// to replace the range variable in the properties of the condition by the collection (a/city -> addresses/city, see resolve)
+func (c *parserConfig) lambdaToken(query string, word string, end int, depth int, scope *lambdaScope) ([]syntaxtree.Token, int, *lambdaScope, bool, error) {
+	slash := strings.LastIndexByte(word, '/')
+	if slash <= 0 || !isLambdaOperator(word[slash+1:]) {
+		return nil, 0, nil, false, nil
+	}
+
+	open := end
+	for open < len(query) && isWhitespace(query[open]) {
+		open++
+	}
+	if open >= len(query) || query[open] != c.lexer.OpenDelimiter {
+		return nil, 0, nil, false, nil
+	}
+
+	operator := word[slash+1:]
+	collection := scope.resolve(word[:slash])
+
+	// The range variable is everything up to the colon after the opening bracket
+	colon := strings.IndexByte(query[open+1:], ':')
+	variable := ""
+	if colon >= 0 {
+		variable = strings.TrimSpace(query[open+1 : open+1+colon])
+	}
+	if variable == "" || strings.ContainsAny(variable, "/() ,'\t\n\r") {
+		return nil, 0, nil, false, &syntaxtree.ParseError{
+			Msg: fmt.Sprintf("expected a range variable in lambda %s, e.g. %s(x: x eq 'value')", word, word),
+		}
+	}
+	if !jsonKeyPattern.MatchString(variable) {
+		return nil, 0, nil, false, &syntaxtree.ParseError{
+			Msg: fmt.Sprintf("invalid range variable '%s' in lambda %s, expected an identifier", variable, word),
+		}
+	}
+
+	tokens := []syntaxtree.Token{
+		{Value: operator, Type: syntaxtree.BinaryFunc},
+		{Value: "(", Type: syntaxtree.OpenDelimiter},
+		{Value: collection, Type: syntaxtree.Operand},
+		{Value: ",", Type: syntaxtree.BinaryFuncSeparator},
+	}
+
+	return tokens, open + colon + 2, &lambdaScope{variable: variable, collection: collection, depth: depth + 1, outer: scope}, true, nil
+}
+
+// resolve
+// replaces the range variable at the start of the property by the collection of its lambda, the innermost lambda first,
+// so the range variables of nested lambdas shadow the ones of the lambdas they are nested in and the properties of the model
+//
+// the other properties are properties of the model (optionally written as $it/name), they are prefixed with $it/
+// when they would otherwise be an element of a lambda, e.g. the model's own collection in a lambda on that collection:
+//
+//	children/any(c: children/any(d: d/name eq c/name))  ->  any(children, any($it/children, $it/children/name eq children/name))
+func (s *lambdaScope) resolve(property string) string {
+	if scope := s.variableScope(property); scope != nil {
+		_, rest, nested := strings.Cut(property, "/")
+		if !nested {
+			return scope.collection
+		}
+
+		return scope.collection + "/" + rest
+	}
+
+	property = strings.TrimPrefix(property, itPrefix)
+	if s.element(property) != nil {
+		return itPrefix + property
+	}
+
+	return property
+}
+
+// variableScope
+// returns the lambda whose range variable the property starts with (e.g. o/sku), the innermost lambda first, nil if it does not
+func (s *lambdaScope) variableScope(property string) *lambdaScope {
+	variable, _, _ := strings.Cut(property, "/")
+	for scope := s; scope != nil; scope = scope.outer {
+		if scope.variable == variable {
+			return scope
+		}
+	}
+
+	return nil
+}
+
+// modelProperty
+// returns the property of the model without the $it/ prefix of properties of the model inside of lambdas (see resolve)
+func modelProperty(property string) string {
+	return strings.TrimPrefix(property, itPrefix)
+}
+
+// withVariable
+// returns the scope of a lambda on the collection nested in this scope, with the range variable the lambda is written with:
+// the first letter of the collection (e.g. addresses/any(a: ...)), numbered when an outer lambda already uses it
+// or when it is the first segment of a property of the model in the condition (reserved), so it does not shadow them
+func (s *lambdaScope) withVariable(collection string, reserved map[string]bool) *lambdaScope {
+	name := collection[strings.LastIndexByte(collection, '/')+1:]
+	base := "x"
+	if name != "" && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		base = strings.ToLower(name[:1])
+	}
+
+	variable := base
+	for index := 2; reserved[variable] || s.resolve(variable) != variable; index++ {
+		variable = base + strconv.Itoa(index)
+	}
+
+	return &lambdaScope{variable: variable, collection: collection, outer: s}
+}
+
+// unresolve
+// writes the property of an element of a lambda with its range variable (e.g. addresses/city -> a/city), the innermost lambda first
+func (s *lambdaScope) unresolve(property string) string {
+	scope := s.element(property)
+	if scope == nil {
+		return property
+	}
+
+	return scope.variable + strings.TrimPrefix(property, scope.collection)
+}
+
+// isLambdaOperator
+// returns whether the operator is the operator of a lambda expression (any or all)
+func isLambdaOperator(operator string) bool {
+	return operator == "any" || operator == "all"
+}
+
+// isLambdaNode
+// returns whether the node is a lambda expression, its left child is the collection and its right child the condition
+func isLambdaNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && isLambdaOperator(node.Value) &&
+		node.LeftChild != nil && node.LeftChild.Type == syntaxtree.LeftOperand
+}
+
+// inLambdaElement
+// returns whether the property of the node is (a property of) an element of a lambda the node is in,
+// the properties of the elements are not columns of the model but keys of the JSON elements of the collection
+func inLambdaElement(node *syntaxtree.Node) bool {
+	for child, parent := node, node.Parent; parent != nil; child, parent = parent, parent.Parent {
+		if isLambdaNode(parent) && parent.RightChild == child && isElementProperty(parent.LeftChild.Value, node.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isElementProperty
+// returns whether the property is the element of the collection (primitive collections) or a key of it
+func isElementProperty(collection string, property string) bool {
+	return property == collection || strings.HasPrefix(property, collection+"/")
+}
+
+// element
+// returns the lambda of the innermost scope whose collection the property is an element of, nil if it is not
+func (s *lambdaScope) element(property string) *lambdaScope {
+	for scope := s; scope != nil; scope = scope.outer {
+		if isElementProperty(scope.collection, property) {
+			return scope
+		}
+	}
+
+	return nil
+}
+
+// buildLambda
+// builds the lambda expression of the node as an EXISTS subquery on the elements of the collection,
+// 'all' is built as the absence of elements that don't match the condition
+//
+//	any: EXISTS (SELECT 1 FROM <elements of the collection> WHERE condition)
+//	all: NOT EXISTS (SELECT 1 FROM <elements of the collection> WHERE NOT (condition))
+//
+// the collection is a relation of the model (or of the element of the lambda it is nested in), or a JSON array column:
+//
+//	relations:   EXISTS (SELECT 1 FROM <related table> <alias> WHERE <alias>.<foreign key> = <owner>.<primary key> AND condition)
+//	JSON arrays: EXISTS (SELECT 1 FROM <elements of the column> AS <alias> WHERE condition)
+//
+// every lambda has its own alias (lambda_1, lambda_2, ...), so nested lambdas are correlated with the lambda they are nested in
+func buildLambda(root *syntaxtree.Node, db *gorm.DB, translation *queryTranslation, notEnabled bool) (*gorm.DB, error) {
+	depth := 1
+	for scope := translation.lambda; scope != nil; scope = scope.outer {
+		depth++
+	}
+
+	scope := &lambdaScope{
+		collection: root.LeftChild.Value,
+		alias:      "lambda_" + strconv.Itoa(depth),
+		outer:      translation.lambda,
+	}
+	source, err := translation.lambdaSource(db, scope)
+	if err != nil {
+		return db, err
+	}
+
+	conditionTranslation := *translation
+	conditionTranslation.lambda = scope
+	if translation.lambda == nil {
+		conditionTranslation.table = translation.modelTable(db)
+	}
+	if err := checkLambdaCondition(root, &conditionTranslation); err != nil {
+		return db, err
+	}
+
+	condition, err := buildGormQuery(root.RightChild, db.Session(&gorm.Session{NewDB: true}), &conditionTranslation, operatorTranslation, false)
+	if err != nil {
+		return db, err
+	}
+
+	exists, negation := "EXISTS", ""
+	if root.Value == "all" {
+		negation = "NOT "
+	}
+	if (root.Value == "all") != notEnabled {
+		exists = "NOT EXISTS"
+	}
+
+	conditions := append(source.conditions, clause.Expr{
+		SQL:  negation + "(?)",
+		Vars: []any{clause.AndConditions{Exprs: whereConditions(condition)}},
+	})
+
+	vars := make([]any, 0, len(conditions)+1)
+	vars = append(vars, source.from)
+	for _, condition := range conditions {
+		vars = append(vars, condition)
+	}
+
+	return db.Where(clause.Expr{
+		SQL:  exists + " (SELECT 1 FROM ? WHERE " + strings.Repeat("? AND ", len(conditions)-1) + "?)",
+		Vars: vars,
+	}), nil
+}
+
+// lambdaSource
+// is the table of the elements of a lambda with the conditions that correlate them with their owner
+type lambdaSource struct {
+	from       clause.Expression
+	conditions []clause.Expression
+}
+
+// lambdaSource
+// returns the elements of the collection of the lambda, the schema of the scope is set for relations
+func (t *queryTranslation) lambdaSource(db *gorm.DB, scope *lambdaScope) (*lambdaSource, error) {
+	// The collection belongs to the model, or to the element of the lambda it is nested in
+	ownerSchema, ownerTable, path := (*schema.Schema)(nil), clause.CurrentTable, modelProperty(scope.collection)
+	if owner := scope.outer.element(scope.collection); owner != nil {
+		ownerSchema, ownerTable, path = owner.schema, owner.alias, strings.TrimPrefix(scope.collection, owner.collection+"/")
+	} else {
+		ownerSchema = t.modelSchema(db)
+	}
+
+	if ownerSchema != nil && !strings.Contains(path, "/") {
+		if relationship := findRelationship(t.namer, ownerSchema, t.namer.ColumnName("", path)); relationship != nil {
+			scope.schema = relationship.FieldSchema
+
+			return relationLambdaSource(relationship, ownerTable, scope.alias)
+		}
+	}
+
+	array, err := t.jsonArray(scope.outer, scope.collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lambdaSource{from: clause.Expr{SQL: jsonElements(t.databaseType, array, scope.alias)}}, nil
+}
+
+// modelSchema
+// returns the schema of the model of the db the filter is built on, nil if the db has no model
+func (t *queryTranslation) modelSchema(db *gorm.DB) *schema.Schema {
+	if t.model == nil {
+		return nil
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(t.model); err != nil {
+		return nil
+	}
+
+	return statement.Schema
+}
+
+// modelTable
+// returns the table of the db the filter is built on: the table set with db.Table or the table of its model, empty if neither is known
+func (t *queryTranslation) modelTable(db *gorm.DB) string {
+	if db.Statement.Table != "" {
+		return db.Statement.Table
+	}
+	if modelSchema := t.modelSchema(db); modelSchema != nil {
+		return modelSchema.Table
+	}
+
+	return ""
+}
+
+// relationLambdaSource
+// returns the related records of the owner table as the elements of a lambda, many to many relations are joined with their join table
+//
+//	has one/many: <related table> <alias> WHERE <alias>.<foreign key> = <owner>.<primary key>
+//	belongs to:   <related table> <alias> WHERE <alias>.<primary key> = <owner>.<foreign key>
+//	many to many: <related table> <alias> INNER JOIN <join table> <alias>_join ON <alias>_join.<join key> = <alias>.<primary key>
+//	              WHERE <alias>_join.<join key> = <owner>.<primary key>
+func relationLambdaSource(relationship *schema.Relationship, ownerTable string, alias string) (*lambdaSource, error) {
+	keys, err := newRelationKeys(relationship, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &lambdaSource{
+		from:       clause.Expr{SQL: "?", Vars: []any{clause.Table{Name: relationship.FieldSchema.Table, Alias: alias}}},
+		conditions: keys.conditions,
+	}
+	if relationship.Type != schema.Many2Many {
+		source.conditions = append(source.conditions, correlation(alias, keys.related, ownerTable, keys.owner)...)
+
+		return source, nil
+	}
+
+	joinAlias := alias + "_join"
+	joins := correlation(joinAlias, keys.joinRelated, alias, keys.related)
+	source.from = clause.Expr{
+		SQL:  "? INNER JOIN ? ON ?",
+		Vars: []any{clause.Table{Name: relationship.FieldSchema.Table, Alias: alias}, clause.Table{Name: relationship.JoinTable.Table, Alias: joinAlias}, clause.AndConditions{Exprs: joins}},
+	}
+	source.conditions = append(source.conditions, correlation(joinAlias, keys.joinOwner, ownerTable, keys.owner)...)
+
+	return source, nil
+}
+
+// correlation
+// returns the conditions that link the columns of a table to the columns of another table
+func correlation(table string, columns []string, otherTable string, otherColumns []string) []clause.Expression {
+	conditions := make([]clause.Expression, len(columns))
+	for i := range columns {
+		conditions[i] = clause.Expr{
+			SQL:  "? = ?",
+			Vars: []any{clause.Column{Table: table, Name: columns[i]}, clause.Column{Table: otherTable, Name: otherColumns[i]}},
+		}
+	}
+
+	return conditions
+}
+
+// checkLambdaCondition
+// checks the properties of the condition of the lambda: the keys of JSON elements must be identifiers,
+// the properties of related records must be columns of the related model and relations cannot be filtered on inside of the condition,
+// the conditions of nested lambdas are checked when they are built
+func checkLambdaCondition(root *syntaxtree.Node, translation *queryTranslation) error {
+	stack := []*syntaxtree.Node{root.RightChild}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil {
+			continue
+		}
+		if isLambdaNode(node) {
+			continue
+		}
+		stack = append(stack, node.LeftChild, node.RightChild)
+
+		if !isPropertyNode(node) || !strings.Contains(modelProperty(node.Value), "/") {
+			continue
+		}
+
+		scope := translation.lambda.element(node.Value)
+		if scope == nil {
+			return &InvalidQueryError{
+				Msg:        fmt.Sprintf("relation property '%s' cannot be filtered on inside of the lambda on '%s'", node.Value, root.LeftChild.Value),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(node),
+				Node:       node,
+			}
+		}
+		if scope.schema == nil {
+			if _, err := elementKeys(scope.collection, node.Value); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if _, err := translation.relatedField(scope, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relatedField
+// returns the field of the related model of a relation lambda that the property of the node refers to
+func (t *queryTranslation) relatedField(scope *lambdaScope, node *syntaxtree.Node) (*schema.Field, error) {
+	property := strings.TrimPrefix(node.Value, scope.collection)
+	if property == "" || strings.Contains(property[1:], "/") {
+		return nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' is not a property of the elements of '%s', only their columns can be filtered on", node.Value, scope.collection),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	field := scope.schema.LookUpField(t.namer.ColumnName("", property[1:]))
+	if field == nil || field.DBName == "" || IsHiddenField(field) {
+		return nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("unknown column name '%s' on '%s'", property[1:], scope.schema.Table),
+			Err:        ErrUnknownProperty,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	return field, nil
+}
+
+// isRelationPath
+// returns whether the property is a property of a related model (e.g. metadata/name), not of an element of a lambda
+func (t *queryTranslation) isRelationPath(property string) bool {
+	return strings.Contains(modelProperty(property), "/") && t.lambda.element(property) == nil
+}
+
+// jsonArray
+// returns the SQL of the JSON array of the collection: the column of the model, the column of the related record
+// or the key of the JSON element of the lambda it is nested in
+func (t *queryTranslation) jsonArray(scope *lambdaScope, collection string) (string, error) {
+	owner := scope.element(collection)
+	if owner == nil {
+		return t.columnName(collection), nil
+	}
+	if owner.schema != nil {
+		field, err := t.relatedField(owner, &syntaxtree.Node{Value: collection})
+		if err != nil {
+			return "", err
+		}
+
+		return owner.alias + "." + field.DBName, nil
+	}
+
+	keys, err := elementKeys(owner.collection, collection)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonElementValue(t.databaseType, owner.alias, keys, true), nil
+}
+
+// elementColumn
+// returns the SQL of an element of a lambda: the column of the related record, or the value of the JSON element as text,
+// ok is false if the property is not an element of a lambda,
+// the properties are checked before the condition of the lambda is built (see checkLambdaCondition)
+func (t *queryTranslation) elementColumn(property string) (string, bool) {
+	scope := t.lambda.element(property)
+	if scope == nil {
+		return "", false
+	}
+	if scope.schema != nil {
+		field, err := t.relatedField(scope, &syntaxtree.Node{Value: property})
+		if err != nil {
+			return property, true
+		}
+
+		return scope.alias + "." + field.DBName, true
+	}
+
+	keys, _ := elementKeys(scope.collection, property)
+
+	return jsonElementValue(t.databaseType, scope.alias, keys, false), true
+}
+
+// elementKeys
+// returns the keys of the property in the JSON element of the collection (e.g. addresses/city -> [city]),
+// keys are written as they are in the filter and must be identifiers
+func elementKeys(collection string, property string) ([]string, error) {
+	if property == collection {
+		return nil, nil
+	}
+
+	keys := strings.Split(strings.TrimPrefix(property, collection+"/"), "/")
+	for _, key := range keys {
+		if !jsonKeyPattern.MatchString(key) {
+			return nil, &InvalidQueryError{
+				Msg:        fmt.Sprintf("invalid key '%s' of the elements of '%s'", key, collection),
+				Err:        ErrInvalidSyntax,
+				Expression: property,
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// jsonElements
+// returns the SQL of the table of the elements of the JSON array for the database type, with the element in the column 'value'
+func jsonElements(databaseType DbType, array string, alias string) string {
+	switch databaseType {
+	case PostgreSQL:
+		return fmt.Sprintf("jsonb_array_elements(CAST(%s AS jsonb)) AS %s(value)", array, alias)
+	case MySQL:
+		return fmt.Sprintf("JSON_TABLE(%s, '$[*]' COLUMNS (value JSON PATH '$')) AS %s", array, alias)
+	case SQLServer:
+		return fmt.Sprintf("OPENJSON(%s) AS %s", array, alias)
+	default:
+		return fmt.Sprintf("json_each(%s) AS %s", array, alias)
+	}
+}
+
+// jsonElementValue
+// returns the SQL of the key path of the element as text, or as JSON to range over a nested array
+func jsonElementValue(databaseType DbType, alias string, keys []string, asJSON bool) string {
+	value := alias + ".value"
+
+	switch databaseType {
+	case PostgreSQL:
+		operator := "#>>"
+		if asJSON {
+			operator = "#>"
+		}
+
+		return fmt.Sprintf("%s %s '{%s}'", value, operator, strings.Join(keys, ","))
+	case MySQL:
+		path := jsonPath(keys)
+		if asJSON {
+			return fmt.Sprintf("JSON_EXTRACT(%s, '%s')", value, path)
+		}
+
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '%s'))", value, path)
+	case SQLServer:
+		if len(keys) == 0 {
+			return value
+		}
+		if asJSON {
+			return fmt.Sprintf("JSON_QUERY(%s, '%s')", value, jsonPath(keys))
+		}
+
+		return fmt.Sprintf("JSON_VALUE(%s, '%s')", value, jsonPath(keys))
+	default:
+		if len(keys) == 0 {
+			return value
+		}
+
+		return fmt.Sprintf("json_extract(%s, '%s')", value, jsonPath(keys))
+	}
+}
+
+// jsonPath
+// returns the JSON path of the keys (e.g. $.address.city)
+func jsonPath(keys []string) string {
+	return strings.Join(append([]string{"$"}, keys...), ".")
+}
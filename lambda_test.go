@@ -0,0 +1,623 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type ShipmentAddress struct {
+	City   string   `json:"city"`
+	Zip    int      `json:"zip"`
+	Phones []string `json:"phones"`
+}
+
+type Shipment struct {
+	ID        uuid.UUID
+	Name      string
+	Addresses []ShipmentAddress `gorm:"serializer:json"`
+	Tags      []string          `gorm:"serializer:json"`
+}
+
+type Customer struct {
+	ID     int
+	Name   string
+	Orders []Order
+}
+
+type Order struct {
+	ID         int
+	CustomerID int
+	Total      int
+	Items      []OrderItem
+	Tags       []string `gorm:"serializer:json"`
+}
+
+type OrderItem struct {
+	ID      int
+	OrderID int
+	Sku     string
+}
+
+func Test_BuildQuery_Lambda(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		dbType      DbType
+		expectedSql string
+	}{
+		"any on sqlite": {
+			queryString: "addresses/any(a: a/city eq 'Ghent')",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM json_each(addresses) AS lambda_1 WHERE (json_extract(lambda_1.value, '$.city') = \"Ghent\"))",
+		},
+		"any on postgres": {
+			queryString: "addresses/any(a: a/city eq 'Ghent')",
+			dbType:      PostgreSQL,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(CAST(addresses AS jsonb)) AS lambda_1(value) WHERE (lambda_1.value #>> '{city}' = \"Ghent\"))",
+		},
+		"any on mysql": {
+			queryString: "addresses/any(a: a/city eq 'Ghent')",
+			dbType:      MySQL,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM JSON_TABLE(addresses, '$[*]' COLUMNS (value JSON PATH '$')) AS lambda_1 WHERE (JSON_UNQUOTE(JSON_EXTRACT(lambda_1.value, '$.city')) = \"Ghent\"))",
+		},
+		"any on sqlserver": {
+			queryString: "addresses/any(a: a/city eq 'Ghent')",
+			dbType:      SQLServer,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM OPENJSON(addresses) AS lambda_1 WHERE (JSON_VALUE(lambda_1.value, '$.city') = \"Ghent\"))",
+		},
+		"all on primitive collection": {
+			queryString: "tags/all(t: t ne 'archived')",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `shipments` WHERE NOT EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE NOT (lambda_1.value != \"archived\"))",
+		},
+		"primitive element on postgres": {
+			queryString: "tags/any(t: t eq 'urgent')",
+			dbType:      PostgreSQL,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(CAST(tags AS jsonb)) AS lambda_1(value) WHERE (lambda_1.value #>> '{}' = \"urgent\"))",
+		},
+		"numbers on postgres": {
+			queryString: "addresses/any(a: a/zip ge 9000)",
+			dbType:      PostgreSQL,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(CAST(addresses AS jsonb)) AS lambda_1(value) WHERE (CAST(lambda_1.value #>> '{zip}' AS numeric) >= 9000))",
+		},
+		"negated any": {
+			queryString: "not(tags/any(t: t eq 'urgent'))",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `shipments` WHERE NOT EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE (lambda_1.value = \"urgent\"))",
+		},
+		"functions and logical operators": {
+			queryString: "name eq 'first' and addresses/any(a: startswith(tolower(a/city), 'gh') or a/zip eq 9000)",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `shipments` WHERE name = \"first\" AND EXISTS (SELECT 1 FROM json_each(addresses) AS lambda_1 WHERE ((LOWER(json_extract(lambda_1.value, '$.city')) LIKE \"gh%\" OR json_extract(lambda_1.value, '$.zip') = 9000)))",
+		},
+		"nested lambda": {
+			queryString: "addresses/any(a: a/phones/any(p: p eq '0471'))",
+			dbType:      SQLite,
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM json_each(addresses) AS lambda_1 WHERE (EXISTS (SELECT 1 FROM json_each(json_extract(lambda_1.value, '$.phones')) AS lambda_2 WHERE (lambda_2.value = \"0471\"))))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Shipment{})
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx, testData.dbType)
+				return dbQuery.Find(&Shipment{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQuery_LambdaResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"any": {
+			queryString:   "addresses/any(a: a/city eq 'Ghent')",
+			expectedNames: []string{"first", "second"},
+		},
+		"any number": {
+			queryString:   "addresses/any(a: a/zip lt 2000)",
+			expectedNames: []string{"second"},
+		},
+		"all": {
+			queryString:   "tags/all(t: t eq 'urgent' or t eq 'fragile')",
+			expectedNames: []string{"first", "third"},
+		},
+		"not all": {
+			queryString:   "not(tags/all(t: t eq 'urgent'))",
+			expectedNames: []string{"first", "second"},
+		},
+		"nested": {
+			queryString:   "addresses/any(a: a/phones/any(p: p eq '0471') and a/city eq 'Ghent')",
+			expectedNames: []string{"first"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Shipment{})
+			db.Create(&Shipment{ID: uuid.New(), Name: "first", Addresses: []ShipmentAddress{{City: "Ghent", Zip: 9000, Phones: []string{"0471"}}}, Tags: []string{"urgent", "fragile"}})
+			db.Create(&Shipment{ID: uuid.New(), Name: "second", Addresses: []ShipmentAddress{{City: "Brussels", Zip: 1000}, {City: "Ghent", Zip: 9000}}, Tags: []string{"archived"}})
+			db.Create(&Shipment{ID: uuid.New(), Name: "third", Addresses: []ShipmentAddress{}, Tags: []string{"urgent"}})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db, SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Shipment
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, shipment := range result {
+				names = append(names, shipment.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQuery_LambdaErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"missing range variable": {
+			queryString: "addresses/any(city eq 'Ghent')",
+			expectedErr: "failed to parse query: expected a range variable in lambda addresses/any, e.g. addresses/any(x: x eq 'value')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"invalid range variable": {
+			queryString: "tags/any($it: $it eq 'urgent')",
+			expectedErr: "failed to parse query: invalid range variable '$it' in lambda tags/any, expected an identifier",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"range variable as value": {
+			queryString: "addresses/any(a: a/city eq a/street)",
+			expectedErr: "failed to parse query: 'a/street' refers to the range variable 'a' of the lambda on addresses, only literals can be the value of a comparison",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"outer range variable as value in nested lambda": {
+			queryString: "orders/any(o: o/lines/any(l: l/sku eq o/sku))",
+			expectedErr: "failed to parse query: 'o/sku' refers to the range variable 'o' of the lambda on orders, only literals can be the value of a comparison",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation in lambda": {
+			queryString: "addresses/any(a: metadata/name eq 'Ghent')",
+			expectedErr: "invalid query: relation property 'metadata/name' cannot be filtered on inside of the lambda on 'addresses'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"invalid element key": {
+			queryString: "addresses/any(a: a/1st eq '9000')",
+			expectedErr: "invalid query: invalid key '1st' of the elements of 'addresses'",
+			expectedIs:  ErrInvalidSyntax,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db, SQLite)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_BuildQueryFor_Lambda(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+	}{
+		"element keys are not checked against the schema": {
+			queryString: "addresses/any(a: a/city eq 'Ghent') and tags/all(t: t ne 'archived')",
+		},
+		"unknown collection": {
+			queryString: "stops/any(s: s/city eq 'Ghent')",
+			expectedErr: "invalid query: unknown column name 'stops'",
+		},
+		"properties of the model in the lambda": {
+			queryString: "tags/any(t: t eq 'urgent' and nickname eq 'x')",
+			expectedErr: "invalid query: unknown column name 'nickname'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQueryFor[Shipment](testData.queryString, db, WithDatabaseType(SQLite))
+
+			// Assert
+			if testData.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, ErrUnknownProperty))
+		})
+	}
+}
+
+func Test_Parse_Lambda(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedExpr   *Expr
+		expectedString string
+	}{
+		"any": {
+			queryString: "addresses/any(x: x/city eq 'Ghent')",
+			expectedExpr: &Expr{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+				exprProperty("addresses"),
+				{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("addresses/city"), exprLiteral("Ghent")}},
+			}},
+			expectedString: "addresses/any(a:a/city eq 'Ghent')",
+		},
+		"nested with the same variable": {
+			queryString: "addresses/all(a: a/phones/any(a: a ne '0471'))",
+			expectedExpr: &Expr{Kind: LambdaExpr, Op: "all", Args: []*Expr{
+				exprProperty("addresses"),
+				{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+					exprProperty("addresses/phones"),
+					{Kind: ComparisonExpr, Op: "ne", Args: []*Expr{exprProperty("addresses/phones"), exprLiteral("0471")}},
+				}},
+			}},
+			expectedString: "addresses/all(a:a/phones/any(p:p ne '0471'))",
+		},
+		"numbered variable": {
+			queryString: "products/any(x: x/parts/any(y: y/price gt 5 and x/name eq 'a'))",
+			expectedExpr: &Expr{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+				exprProperty("products"),
+				{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+					exprProperty("products/parts"),
+					{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+						{Kind: ComparisonExpr, Op: "gt", Args: []*Expr{exprProperty("products/parts/price"), exprLiteral(int64(5))}},
+						{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("products/name"), exprLiteral("a")}},
+					}},
+				}},
+			}},
+			expectedString: "products/any(p:p/parts/any(p2:p2/price gt 5 and p/name eq 'a'))",
+		},
+		"collection of the model in a lambda on it": {
+			queryString: "children/any(c: children/any(d: d/name eq 'leaf') and $it/name eq 'root')",
+			expectedExpr: &Expr{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+				exprProperty("children"),
+				{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+					{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+						exprProperty("$it/children"),
+						{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("$it/children/name"), exprLiteral("leaf")}},
+					}},
+					{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("name"), exprLiteral("root")}},
+				}},
+			}},
+			expectedString: "children/any(c:$it/children/any(c2:c2/name eq 'leaf') and name eq 'root')",
+		},
+		"variable that would shadow a property": {
+			queryString: "tags/any(x: x eq 'a' and t eq 'b')",
+			expectedExpr: &Expr{Kind: LambdaExpr, Op: "any", Args: []*Expr{
+				exprProperty("tags"),
+				{Kind: LogicalExpr, Op: "and", Args: []*Expr{
+					{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("tags"), exprLiteral("a")}},
+					{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("t"), exprLiteral("b")}},
+				}},
+			}},
+			expectedString: "tags/any(t2:t2 eq 'a' and t eq 'b')",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			expr, err := Parse(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedExpr, expr)
+			assert.Equal(t, testData.expectedString, expr.String())
+
+			reparsedExpr, err := Parse(expr.String())
+			assert.NoError(t, err)
+			assert.Equal(t, expr, reparsedExpr)
+		})
+	}
+}
+
+func Test_BuildQuery_RelationLambda(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		model       any
+		expectedSql string
+	}{
+		"has many": {
+			queryString: "orders/any(o: o/total gt 20)",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND (lambda_1.total > 20))",
+		},
+		"all": {
+			queryString: "orders/all(o: o/total lt 20)",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE NOT EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND NOT (lambda_1.total < 20))",
+		},
+		"many to many": {
+			queryString: "labels/any(l: l/name eq 'go')",
+			model:       &Article{},
+			expectedSql: "SELECT * FROM `articles` WHERE EXISTS (SELECT 1 FROM `labels` `lambda_1` INNER JOIN `article_labels` `lambda_1_join` ON `lambda_1_join`.`label_id` = `lambda_1`.`id` WHERE `lambda_1_join`.`article_id` = `articles`.`id` AND (lambda_1.name = \"go\"))",
+		},
+		"nested relations": {
+			queryString: "orders/any(o: o/items/any(i: i/sku eq 'X'))",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND (EXISTS (SELECT 1 FROM `order_items` `lambda_2` WHERE `lambda_2`.`order_id` = `lambda_1`.`id` AND (lambda_2.sku = \"X\"))))",
+		},
+		"outer variable in nested lambda": {
+			queryString: "orders/any(o: o/items/any(i: i/sku eq 'Y' and o/total eq 50))",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND (EXISTS (SELECT 1 FROM `order_items` `lambda_2` WHERE `lambda_2`.`order_id` = `lambda_1`.`id` AND ((lambda_2.sku = \"Y\" AND lambda_1.total = 50)))))",
+		},
+		"self reference": {
+			queryString: "children/any(c: c/children/any(g: g/name eq 'leaf'))",
+			model:       &Category{},
+			expectedSql: "SELECT * FROM `categories` WHERE EXISTS (SELECT 1 FROM `categories` `lambda_1` WHERE `lambda_1`.`parent_id` = `categories`.`id` AND (EXISTS (SELECT 1 FROM `categories` `lambda_2` WHERE `lambda_2`.`parent_id` = `lambda_1`.`id` AND (lambda_2.name = \"leaf\"))))",
+		},
+		"json collection of related rows": {
+			queryString: "orders/any(o: o/tags/any(t: t eq 'gift'))",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND (EXISTS (SELECT 1 FROM json_each(lambda_1.tags) AS lambda_2 WHERE (lambda_2.value = \"gift\"))))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx.Model(testData.model), SQLite)
+				return dbQuery.Find(testData.model)
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQuery_RelationLambdaResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"any": {
+			queryString:   "orders/any(o: o/total gt 20)",
+			expectedNames: []string{"second"},
+		},
+		"all": {
+			queryString:   "orders/all(o: o/total lt 20)",
+			expectedNames: []string{"first", "third"},
+		},
+		"nested": {
+			queryString:   "orders/any(o: o/items/any(i: i/sku eq 'X'))",
+			expectedNames: []string{"first"},
+		},
+		"outer variable in nested lambda": {
+			queryString:   "orders/any(o: o/items/any(i: i/sku eq 'Y' and o/total eq 10))",
+			expectedNames: []string{},
+		},
+		"json collection of related rows": {
+			queryString:   "orders/any(o: o/tags/any(t: t eq 'gift'))",
+			expectedNames: []string{"first"},
+		},
+		"property of the model": {
+			queryString:   "orders/any(o: o/total gt 5 and name eq 'second')",
+			expectedNames: []string{"second"},
+		},
+		"shadowed range variable": {
+			queryString:   "orders/any(o: o/total eq 50 and o/items/any(o: o/sku eq 'Y'))",
+			expectedNames: []string{"second"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Customer{}, &Order{}, &OrderItem{})
+			db.Create(&Customer{ID: 1, Name: "first", Orders: []Order{{ID: 1, Total: 10, Tags: []string{"gift"}, Items: []OrderItem{{ID: 1, Sku: "X"}}}}})
+			db.Create(&Customer{ID: 2, Name: "second", Orders: []Order{{ID: 2, Total: 50, Items: []OrderItem{{ID: 2, Sku: "Y"}}}}})
+			db.Create(&Customer{ID: 3, Name: "third"})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&Customer{}), SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Customer
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, customer := range result {
+				names = append(names, customer.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQueryFor_RelationLambda(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"relations are checked against the schema": {
+			queryString: "orders/any(o: o/items/any(i: i/sku eq 'X') and o/tags/any(t: t eq 'gift'))",
+		},
+		"unknown column of the related rows": {
+			queryString: "orders/any(o: o/weight gt 5)",
+			expectedErr: "invalid query: unknown column name 'weight' on 'orders'",
+			expectedIs:  ErrUnknownProperty,
+		},
+		"relation of the related rows": {
+			queryString: "orders/any(o: o/items/sku eq 'X')",
+			expectedErr: "invalid query: 'orders/items/sku' is not a property of the elements of 'orders', only their columns can be filtered on",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQueryFor[Customer](testData.queryString, db, WithDatabaseType(SQLite))
+
+			// Assert
+			if testData.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_BuildQuery_LambdaScoping(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		model       any
+		expectedSql string
+	}{
+		"range variable referenced multiple times": {
+			queryString: "tags/any(t: t eq 'urgent' or t eq 'fragile')",
+			model:       &Shipment{},
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE ((lambda_1.value = \"urgent\" OR lambda_1.value = \"fragile\")))",
+		},
+		"properties of the model are qualified": {
+			queryString: "orders/any(o: o/total gt 5 and name eq 'first')",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND ((lambda_1.total > 5 AND customers.name = \"first\")))",
+		},
+		"properties of the model in a lambda on a JSON array": {
+			queryString: "tags/any(t: t eq 'urgent' and name eq 'first')",
+			model:       &Shipment{},
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE ((lambda_1.value = \"urgent\" AND shipments.name = \"first\")))",
+		},
+		"range variable shadows a property": {
+			queryString: "tags/any(name: name eq 'urgent') and name eq 'first'",
+			model:       &Shipment{},
+			expectedSql: "SELECT * FROM `shipments` WHERE EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE (lambda_1.value = \"urgent\")) AND name = \"first\"",
+		},
+		"range variable shadows an outer range variable": {
+			queryString: "orders/any(o: o/items/any(o: o/sku eq 'X'))",
+			model:       &Customer{},
+			expectedSql: "SELECT * FROM `customers` WHERE EXISTS (SELECT 1 FROM `orders` `lambda_1` WHERE `lambda_1`.`customer_id` = `customers`.`id` AND (EXISTS (SELECT 1 FROM `order_items` `lambda_2` WHERE `lambda_2`.`order_id` = `lambda_1`.`id` AND (lambda_2.sku = \"X\"))))",
+		},
+		"collection of the model in a lambda on it": {
+			queryString: "children/any(c: children/any(d: d/name eq 'leaf'))",
+			model:       &Category{},
+			expectedSql: "SELECT * FROM `categories` WHERE EXISTS (SELECT 1 FROM `categories` `lambda_1` WHERE `lambda_1`.`parent_id` = `categories`.`id` AND (EXISTS (SELECT 1 FROM `categories` `lambda_2` WHERE `lambda_2`.`parent_id` = `categories`.`id` AND (lambda_2.name = \"leaf\"))))",
+		},
+		"property of the model with $it": {
+			queryString: "children/any(c: c/name eq 'leaf' and $it/name eq 'root')",
+			model:       &Category{},
+			expectedSql: "SELECT * FROM `categories` WHERE EXISTS (SELECT 1 FROM `categories` `lambda_1` WHERE `lambda_1`.`parent_id` = `categories`.`id` AND ((lambda_1.name = \"leaf\" AND categories.name = \"root\")))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx.Model(testData.model), SQLite)
+				return dbQuery.Find(testData.model)
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
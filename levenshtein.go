@@ -0,0 +1,62 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// levenshteinFunction
+// is the extension function that returns the edit distance between a column and a string (see WithExtensionFunctions)
+const levenshteinFunction = "levenshtein"
+
+// levenshteinTranslation
+// is the edit distance between a column and a string for the database types that have one, %s is the column:
+// the levenshtein of the fuzzystrmatch extension on PostgreSQL, the other databases have no built-in function
+var levenshteinTranslation = map[DbType]string{
+	PostgreSQL: "LEVENSHTEIN(%s, ?)",
+}
+
+// buildLevenshtein
+// builds the edit distance of the extension function levenshtein, which is compared with a number,
+// the string is returned as the value to bind
+//
+//	levenshtein(lastName,'smith') le 2  ->  LEVENSHTEIN(last_name, 'smith') <= 2
+func buildLevenshtein(translation *queryTranslation, node *syntaxtree.Node) (string, any, error) {
+	queryFormat, ok := levenshteinTranslation[translation.databaseType]
+	if !ok {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("function '%s' is only supported on PostgreSQL with the fuzzystrmatch extension", node.Value),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	leftChild, rightChild := node.LeftChild, node.RightChild
+	column := ""
+	switch {
+	case leftChild.Type == syntaxtree.UnaryOperator:
+		column = buildUnaryFuncChain(translation, leftChild)
+	case leftChild.Type == syntaxtree.LeftOperand && isPropertyNode(leftChild) && !translation.isRelationPath(leftChild.Value):
+		column = translation.columnName(leftChild.Value)
+	default:
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a column of the model as its first argument, got '%s'", node.Value, nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       leftChild,
+		}
+	}
+
+	if !isStringLiteral(rightChild.Value) {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a string literal as its second argument, got '%s'", node.Value, nodeExpression(rightChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       rightChild,
+		}
+	}
+
+	return fmt.Sprintf(queryFormat, column), unquote(rightChild.Value), nil
+}
@@ -0,0 +1,134 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_Levenshtein(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"column": {
+			queryString: "levenshtein(name,'smith') le 2",
+			expectedSql: "SELECT * FROM `members` WHERE LEVENSHTEIN(name, \"smith\") <= 2",
+		},
+		"function": {
+			queryString: "levenshtein(tolower(name),'smith') lt 3",
+			expectedSql: "SELECT * FROM `members` WHERE LEVENSHTEIN(LOWER(name), \"smith\") < 3",
+		},
+		"negated": {
+			queryString: "not(levenshtein(name,'smith') le 2) and id gt 1",
+			expectedSql: "SELECT * FROM `members` WHERE LEVENSHTEIN(name, \"smith\") > 2 AND id > 1",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(PostgreSQL), WithExtensionFunctions("levenshtein"))
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&Member{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_LevenshteinErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		databaseType DbType
+		options      []Option
+		expectedErr  string
+		expectedIs   error
+	}{
+		"not enabled": {
+			queryString:  "levenshtein(name,'smith') le 2",
+			databaseType: PostgreSQL,
+			expectedErr:  "invalid query: function 'levenshtein' is an extension function that is not enabled, enable it with WithExtensionFunctions",
+			expectedIs:   ErrFunctionNotAllowed,
+		},
+		"mysql": {
+			queryString:  "levenshtein(name,'smith') le 2",
+			databaseType: MySQL,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: function 'levenshtein' is only supported on PostgreSQL with the fuzzystrmatch extension",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"sqlite": {
+			queryString:  "levenshtein(name,'smith') le 2",
+			databaseType: SQLite,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: function 'levenshtein' is only supported on PostgreSQL with the fuzzystrmatch extension",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"sqlserver": {
+			queryString:  "levenshtein(name,'smith') le 2",
+			databaseType: SQLServer,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: function 'levenshtein' is only supported on PostgreSQL with the fuzzystrmatch extension",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"root of the filter": {
+			queryString:  "levenshtein(name,'smith')",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: function 'levenshtein' cannot be the root of a filter, compare it with a number instead (e.g. levenshtein(name,'smith') le 2)",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"right operand": {
+			queryString:  "id le levenshtein(name,'smith')",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: function 'levenshtein' cannot be the right operand of 'le', only values can",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"not a string literal": {
+			queryString:  "levenshtein(name,email) le 2",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("levenshtein")},
+			expectedErr:  "invalid query: 'levenshtein' expects a string literal as its second argument, got 'email'",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(testData.databaseType)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Member{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// tokenize
+// splits a query into the tokens of the syntax in a single pass
+//
+//	name eq 'it''s' and contains(tolower(name),'x')
+//
+// string literals keep their quotes, quotes inside of them are escaped by doubling them (see the example above)
+// and any other character is part of the literal (e.g. " and ", "(" or ","),
+// operators and functions are only recognized outside of string literals and as whole words,
+// functions only when they are followed by an opening bracket
+//
+// the list of values of 'in' (e.g. name in ('a','b',3)) is one operand, brackets included (see listLiteralEnd)
+//
+// lambda expressions (e.g. addresses/any(a: a/city eq 'Ghent')) are rewritten to a binary function of the collection and the condition,
+// with the range variable in the properties of the condition replaced by the collection (see lambdaToken and resolve)
+//
+// a unary minus without brackets (e.g. -price or -length(name)) gets the brackets of a function call around its operand,
+// so it binds tighter than any binary operator
+//
+// now() gets an empty operand, since the parser expects an operand in every call of a unary function
+//
+// the pattern and the replacement of regexreplace(field,'pattern','replacement') become the arguments of the inner binary function regexReplaceArguments,
+// since the parser only knows functions with up to two arguments
+//
+// round with a precision (e.g. round(price,2)) becomes a binary function, round without one stays a unary function
+//
+// an unterminated string literal returns the tokens up to and including the literal with an error
+func (c *parserConfig) tokenize(query string) ([]syntaxtree.Token, error) {
+	tokens := make([]syntaxtree.Token, 0, len(query)/4+1)
+
+	// depth is the bracket depth, scope the innermost lambda whose condition is being tokenized,
+	// negations are the depths of the unary minuses whose operand is not closed yet
+	depth := 0
+	var scope *lambdaScope
+	var negations []int
+	var regexReplaces []*regexReplaceCall
+	var rounds []roundCall
+	closeNegations := func() {
+		for len(negations) > 0 && negations[len(negations)-1] == depth {
+			tokens = append(tokens, syntaxtree.Token{Value: ")", Type: syntaxtree.CloseDelimiter})
+			negations = negations[:len(negations)-1]
+		}
+	}
+
+	for i := 0; i < len(query); {
+		switch char := query[i]; {
+		case isWhitespace(char):
+			i++
+		case char == c.lexer.OpenDelimiter && len(tokens) > 0 && tokens[len(tokens)-1].Type == syntaxtree.BinaryOp && tokens[len(tokens)-1].Value == inOperator:
+			end, err := c.listLiteralEnd(query, i)
+			tokens = append(tokens, syntaxtree.Token{Value: query[i:end], Type: syntaxtree.Operand})
+			if err != nil {
+				return tokens, err
+			}
+			i = end
+		case char == c.lexer.OpenDelimiter:
+			tokens = append(tokens, syntaxtree.Token{Value: "(", Type: syntaxtree.OpenDelimiter})
+			depth++
+			i++
+			// now() has no arguments, it gets an empty operand since every unary function has one
+			if isNow, ok := c.opensNowCall(query, tokens, i); isNow && !ok {
+				return tokens, &syntaxtree.ParseError{Msg: "function now has no arguments, expected now()"}
+			} else if isNow {
+				tokens = append(tokens, syntaxtree.Token{Value: "", Type: syntaxtree.Operand})
+			}
+			if len(tokens) > 1 && tokens[len(tokens)-2].Type == syntaxtree.BinaryFunc && tokens[len(tokens)-2].Value == regexReplaceFunction {
+				regexReplaces = append(regexReplaces, &regexReplaceCall{depth: depth, args: 1})
+			}
+			if len(tokens) > 1 && tokens[len(tokens)-2].Type == syntaxtree.UnaryFunc && tokens[len(tokens)-2].Value == roundFunction {
+				rounds = append(rounds, roundCall{depth: depth, token: len(tokens) - 2})
+			}
+		case char == c.lexer.CloseDelimiter:
+			// The call of regexreplace closes the inner function of its pattern and replacement as well
+			if call := lastRegexReplace(regexReplaces); call != nil && call.argumentsDepth() == depth {
+				if call.args != 3 {
+					return tokens, regexReplaceArgumentsError(call.args)
+				}
+				if call.depth < depth {
+					tokens = append(tokens, syntaxtree.Token{Value: ")", Type: syntaxtree.CloseDelimiter})
+					depth--
+				}
+				regexReplaces = regexReplaces[:len(regexReplaces)-1]
+			}
+			if len(rounds) > 0 && rounds[len(rounds)-1].depth == depth {
+				rounds = rounds[:len(rounds)-1]
+			}
+			tokens = append(tokens, syntaxtree.Token{Value: ")", Type: syntaxtree.CloseDelimiter})
+			depth--
+			for scope != nil && scope.depth > depth {
+				scope = scope.outer
+			}
+			closeNegations()
+			i++
+		case char == c.lexer.BinaryFunctionOpSeparator:
+			tokens = append(tokens, syntaxtree.Token{Value: ",", Type: syntaxtree.BinaryFuncSeparator})
+			i++
+			if len(rounds) > 0 && rounds[len(rounds)-1].depth == depth {
+				tokens[rounds[len(rounds)-1].token].Type = syntaxtree.BinaryFunc
+			}
+			if call := lastRegexReplace(regexReplaces); call != nil && call.argumentsDepth() == depth {
+				call.args++
+				if call.args == 2 {
+					tokens = append(tokens,
+						syntaxtree.Token{Value: regexReplaceArguments, Type: syntaxtree.BinaryFunc},
+						syntaxtree.Token{Value: "(", Type: syntaxtree.OpenDelimiter},
+					)
+					depth++
+				}
+			}
+		case char == c.lexer.StringDelimiter:
+			end, terminated := c.stringLiteralEnd(query, i)
+			tokens = append(tokens, syntaxtree.Token{Value: query[i:end], Type: syntaxtree.StringOperand})
+			if !terminated {
+				return tokens, &syntaxtree.ParseError{Msg: fmt.Sprintf("unterminated string literal %s", query[i:end])}
+			}
+			closeNegations()
+			i = end
+		default:
+			end := i + 1
+			for end < len(query) && !c.isWordEnd(query[end]) {
+				end++
+			}
+			if isNegationPrefix(query[i:end]) && c.wordType(query, query[i:end], end) != syntaxtree.UnaryFunc {
+				tokens = append(tokens,
+					syntaxtree.Token{Value: negationFunction, Type: syntaxtree.UnaryFunc},
+					syntaxtree.Token{Value: "(", Type: syntaxtree.OpenDelimiter},
+				)
+				negations = append(negations, depth)
+				i++
+
+				continue
+			}
+
+			end = c.rootReferenceEnd(query, query[i:end], end)
+			end = c.enumLiteralEnd(query, query[i:end], end)
+			end, err := c.typedLiteralEnd(query, query[i:end], end)
+			if err != nil {
+				return tokens, err
+			}
+			lambdaTokens, next, lambda, ok, err := c.lambdaToken(query, query[i:end], end, depth, scope)
+			if err != nil {
+				return tokens, err
+			}
+			if ok {
+				tokens = append(tokens, lambdaTokens...)
+				depth, scope, i = lambda.depth, lambda, next
+				continue
+			}
+
+			// The values of comparisons are literals, so they are not resolved
+			word, wordType := query[i:end], c.wordType(query, query[i:end], end)
+			if dateTimeOffsetPattern.MatchString(word) && !isDateTimeOffsetLiteral(word) {
+				return tokens, &syntaxtree.ParseError{Msg: fmt.Sprintf("invalid datetimeoffset literal %s, expected a valid timestamp with an offset (e.g. 2025-01-01T10:00:00+02:00)", word)}
+			}
+			if wordType == syntaxtree.Operand && !isComparisonValue(tokens) {
+				word = scope.resolve(word)
+			}
+			// A range variable would be bound as text, so the elements of lambdas cannot be compared with each other
+			if lambda := scope.variableScope(word); lambda != nil && wordType == syntaxtree.Operand && isComparisonValue(tokens) {
+				return tokens, &syntaxtree.ParseError{
+					Msg: fmt.Sprintf("'%s' refers to the range variable '%s' of the lambda on %s, only literals can be the value of a comparison", word, lambda.variable, lambda.collection),
+				}
+			}
+			tokens = append(tokens, syntaxtree.Token{Value: word, Type: wordType})
+			if wordType == syntaxtree.Operand {
+				closeNegations()
+			}
+			i = end
+		}
+	}
+
+	// An unclosed call of regexreplace closes its inner function, so the parser reports the missing bracket of regexreplace
+	if call := lastRegexReplace(regexReplaces); call != nil && call.argumentsDepth() == depth {
+		if call.args != 3 {
+			return tokens, regexReplaceArgumentsError(call.args)
+		}
+		tokens = append(tokens, syntaxtree.Token{Value: ")", Type: syntaxtree.CloseDelimiter})
+	}
+
+	return tokens, nil
+}
+
+// opensNowCall
+// returns whether the bracket before the index opens a call of now() and whether the call has no arguments
+func (c *parserConfig) opensNowCall(query string, tokens []syntaxtree.Token, index int) (bool, bool) {
+	if len(tokens) < 2 || tokens[len(tokens)-2].Type != syntaxtree.UnaryFunc || tokens[len(tokens)-2].Value != nowFunction {
+		return false, false
+	}
+	for index < len(query) && isWhitespace(query[index]) {
+		index++
+	}
+
+	return true, index < len(query) && query[index] == c.lexer.CloseDelimiter
+}
+
+// lastRegexReplace
+// returns the innermost call of regexreplace that is being tokenized, or nil
+func lastRegexReplace(calls []*regexReplaceCall) *regexReplaceCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return calls[len(calls)-1]
+}
+
+// isComparisonValue
+// returns whether the next operand is the value of a comparison, the operand right after a comparison operator or 'has'
+func isComparisonValue(tokens []syntaxtree.Token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	previous := tokens[len(tokens)-1]
+
+	return previous.Type == syntaxtree.BinaryOp && (slices.Contains(comparisonOperators, previous.Value) || previous.Value == "has")
+}
+
+// stringLiteralEnd
+// returns the index after the string literal that starts at the given index and whether the literal is terminated
+func (c *parserConfig) stringLiteralEnd(query string, start int) (int, bool) {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != c.lexer.StringDelimiter {
+			continue
+		}
+		// A doubled quote is an escaped quote inside the literal
+		if i+1 < len(query) && query[i+1] == c.lexer.StringDelimiter {
+			i++
+			continue
+		}
+
+		return i + 1, true
+	}
+
+	return len(query), false
+}
+
+// listLiteralEnd
+// returns the index after the list literal that starts at the bracket at the given index,
+// the list can only contain values, so a bracket outside of a string literal ends it or is an error
+func (c *parserConfig) listLiteralEnd(query string, start int) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case c.lexer.StringDelimiter:
+			end, terminated := c.stringLiteralEnd(query, i)
+			if !terminated {
+				return len(query), &syntaxtree.ParseError{Msg: fmt.Sprintf("unterminated string literal %s", query[i:end])}
+			}
+			i = end - 1
+		case c.lexer.CloseDelimiter:
+			return i + 1, nil
+		case c.lexer.OpenDelimiter:
+			return i, &syntaxtree.ParseError{Msg: fmt.Sprintf("list %s can only contain values", query[start:i+1])}
+		}
+	}
+
+	return len(query), &syntaxtree.ParseError{Msg: fmt.Sprintf("unterminated list %s", query[start:])}
+}
+
+// wordType
+// returns the token type of a word that ends at the given index of the query
+func (c *parserConfig) wordType(query string, word string, end int) syntaxtree.TokenType {
+	keywordType, ok := c.keywords[word]
+	if !ok {
+		return syntaxtree.Operand
+	}
+	if keywordType == syntaxtree.BinaryOp {
+		return keywordType
+	}
+
+	// Functions are only functions when they are called, otherwise they are a property with the same name
+	for end < len(query) && isWhitespace(query[end]) {
+		end++
+	}
+	if end < len(query) && query[end] == c.lexer.OpenDelimiter {
+		return keywordType
+	}
+
+	return syntaxtree.Operand
+}
+
+func isWhitespace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+func (c *parserConfig) isWordEnd(char byte) bool {
+	return isWhitespace(char) ||
+		char == c.lexer.OpenDelimiter ||
+		char == c.lexer.CloseDelimiter ||
+		char == c.lexer.BinaryFunctionOpSeparator ||
+		char == c.lexer.StringDelimiter
+}
+
+// isStringLiteral
+// returns whether the value of a node is a quoted string literal
+func isStringLiteral(value string) bool {
+	return len(value) >= 2 && value[0] == odataLexer.StringDelimiter && value[len(value)-1] == odataLexer.StringDelimiter
+}
+
+// unquote
+// returns the value of a string literal without its quotes and with escaped quotes (”) unescaped,
+// values that are not quoted are returned as they are
+func unquote(value string) string {
+	if !isStringLiteral(value) {
+		return value
+	}
+
+	return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+}
+
+// quote
+// returns the value as a string literal, quotes in the value are escaped as ”
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
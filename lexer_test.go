@@ -0,0 +1,227 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Tokenize_SameAsLibraryLexer(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query string
+	}{
+		"comparison": {
+			query: "name eq 'test'",
+		},
+		"logical operators": {
+			query: "name eq 'test' and (testValue ne 'prd' or id gt 3)",
+		},
+		"functions": {
+			query: "contains(tolower(name),'test') and not(startswith(concat(name,testValue),'a'))",
+		},
+		"property paths and dates": {
+			query: "metadata/name eq 'test' and created le 2025-01-01T00:00:00Z",
+		},
+		"literal with spaces": {
+			query: "name eq 'a  b'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := odataParser.tokenize(testData.query)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, odataLexer.Tokenize(testData.query).Tokens, result)
+		})
+	}
+}
+
+func Test_Tokenize_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query          string
+		expectedTokens []syntaxtree.Token
+	}{
+		"operators inside of a literal": {
+			query: "name eq 'a and b or (c, d)'",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "'a and b or (c, d)'", Type: syntaxtree.StringOperand},
+			},
+		},
+		"list of values": {
+			query: "name in ('a', 'b)', 3) and id gt 3",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "in", Type: syntaxtree.BinaryOp},
+				{Value: "('a', 'b)', 3)", Type: syntaxtree.Operand},
+				{Value: "and", Type: syntaxtree.BinaryOp},
+				{Value: "id", Type: syntaxtree.Operand},
+				{Value: "gt", Type: syntaxtree.BinaryOp},
+				{Value: "3", Type: syntaxtree.Operand},
+			},
+		},
+		"escaped quote": {
+			query: "name eq 'it''s'",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "'it''s'", Type: syntaxtree.StringOperand},
+			},
+		},
+		"empty literal without space": {
+			query: "name eq''",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "''", Type: syntaxtree.StringOperand},
+			},
+		},
+		"operator between brackets": {
+			query: "(name eq 'a')and(id eq 1)",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "(", Type: syntaxtree.OpenDelimiter},
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "'a'", Type: syntaxtree.StringOperand},
+				{Value: ")", Type: syntaxtree.CloseDelimiter},
+				{Value: "and", Type: syntaxtree.BinaryOp},
+				{Value: "(", Type: syntaxtree.OpenDelimiter},
+				{Value: "id", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "1", Type: syntaxtree.Operand},
+				{Value: ")", Type: syntaxtree.CloseDelimiter},
+			},
+		},
+		"whitespace separates operands": {
+			query: "name\tEQ\n'test'",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: "EQ", Type: syntaxtree.Operand},
+				{Value: "'test'", Type: syntaxtree.StringOperand},
+			},
+		},
+		"function name as property": {
+			query: "length eq 3 and length (name) gt 1",
+			expectedTokens: []syntaxtree.Token{
+				{Value: "length", Type: syntaxtree.Operand},
+				{Value: "eq", Type: syntaxtree.BinaryOp},
+				{Value: "3", Type: syntaxtree.Operand},
+				{Value: "and", Type: syntaxtree.BinaryOp},
+				{Value: "length", Type: syntaxtree.UnaryFunc},
+				{Value: "(", Type: syntaxtree.OpenDelimiter},
+				{Value: "name", Type: syntaxtree.Operand},
+				{Value: ")", Type: syntaxtree.CloseDelimiter},
+				{Value: "gt", Type: syntaxtree.BinaryOp},
+				{Value: "1", Type: syntaxtree.Operand},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := odataParser.tokenize(testData.query)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedTokens, result)
+		})
+	}
+}
+
+func Test_Tokenize_UnterminatedLiteral(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	result, err := odataParser.tokenize("name eq 'it''s")
+
+	// Assert
+	assert.EqualError(t, err, "failed to parse query: unterminated string literal 'it''s")
+	assert.Equal(t, syntaxtree.Token{Value: "'it''s", Type: syntaxtree.StringOperand}, result[len(result)-1])
+
+	_, err = GetAST("name eq 'it''s")
+	assert.True(t, errors.Is(err, ErrInvalidSyntax))
+}
+
+func Test_BuildQuery_QuotedLiterals(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query       string
+		expectedSql string
+	}{
+		"escaped quote": {
+			query:       "name eq 'it''s'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"it's\"",
+		},
+		"operators inside of a literal": {
+			query:       "name eq 'a and b' or name eq 'c or d'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"a and b\" OR name = \"c or d\"",
+		},
+		"escaped quote in contains": {
+			query:       "contains(name,'it''s, (really)')",
+			expectedSql: "SELECT * FROM `mock_models` WHERE name LIKE \"%it's, (really)%\"",
+		},
+		"escaped quote in nested filter": {
+			query:       "metadata/name eq 'it''s'",
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"it's\")",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.query, tx, SQLite)
+				return dbQuery.Find(&MockModel{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Benchmark_Tokenize(b *testing.B) {
+	query := "contains(tolower(name),'a and b') and (metadata/name eq 'it''s' or length(testValue) gt 3) and not(id eq 1)"
+
+	b.Run("library lexer", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = odataLexer.Tokenize(query)
+		}
+	})
+
+	b.Run("tokenize", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = odataParser.tokenize(query)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// WithMaxListSize
+// rejects filters with more than maxSize values in the list of a property, the values of 'in' (e.g. id in (1,2,...))
+// and of 'eq' comparisons of the same property joined by 'or' (e.g. id eq 1 or id eq 2 or ...),
+// so clients cannot send lists of thousands of values, the filters fail with ErrComplexityExceeded
+//
+//	builder := gormodata.New(gormodata.WithMaxListSize(100))
+//
+// the values of the lists of 'in' and 'eq' of the same property joined by 'or' are counted together
+func WithMaxListSize(maxSize int) Option {
+	return func(b *Builder) {
+		b.maxListSize = maxSize
+		b.queryValidations = append(b.queryValidations, listSizeValidation(maxSize))
+	}
+}
+
+// listSizeValidation
+// returns a QueryValidation that checks the number of values of the lists in the filter (see WithMaxListSize),
+// it does not use the db, so it can check filters without one (e.g. in ToDeepFilterMap)
+func listSizeValidation(maxSize int) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			// Every 'or' of a chain is walked, the values are only counted at the first one
+			if (!isOrNode(currentNode) && !isInListNode(currentNode)) || isOrNode(currentNode.Parent) {
+				return nil
+			}
+
+			sizes := map[string]int{}
+			stack := []*syntaxtree.Node{currentNode}
+			for len(stack) > 0 {
+				node := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				values := 0
+				switch {
+				case isOrNode(node):
+					stack = append(stack, node.LeftChild, node.RightChild)
+				case isInListNode(node):
+					values = len(listLiteralElements(node.RightChild.Value))
+				case node.Type == syntaxtree.Operator && node.Value == "eq" && isPropertyNode(node.LeftChild):
+					values = 1
+				}
+				if values == 0 {
+					continue
+				}
+
+				// The lists of functions (e.g. tolower(name) in ('a','b')) are counted by their expression
+				list := nodeExpression(node.LeftChild)
+				if isPropertyNode(node.LeftChild) {
+					list = modelProperty(node.LeftChild.Value)
+				}
+				if sizes[list] += values; sizes[list] > maxSize {
+					return &InvalidQueryError{
+						Msg:        fmt.Sprintf("list of values of '%s' exceeds the maximum of %d values", nodeExpression(node.LeftChild), maxSize),
+						Err:        ErrComplexityExceeded,
+						Expression: nodeExpression(currentNode),
+						Node:       currentNode,
+					}
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
+
+// isOrNode
+// returns whether the node joins two conditions with 'or'
+func isOrNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && node.Value == "or"
+}
+
+// isInListNode
+// returns whether the node compares with the list of values of 'in' (e.g. id in (1,2))
+func isInListNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && node.Value == inOperator &&
+		node.RightChild != nil && node.RightChild.Type == syntaxtree.RightOperand && isListLiteral(node.RightChild.Value)
+}
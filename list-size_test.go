@@ -0,0 +1,135 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_MaxListSize(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"list at the maximum": {
+			queryString: "id eq 1 or id eq 2 or id eq 3",
+			expectedSql: "SELECT * FROM `members` WHERE (id = 1 OR id = 2) OR id = 3",
+		},
+		"lists of several properties": {
+			queryString: "id eq 1 or id eq 2 or name eq 'a' or name eq 'b' or name eq 'c'",
+			expectedSql: "SELECT * FROM `members` WHERE (((id = 1 OR id = 2) OR name = \"a\") OR name = \"b\") OR name = \"c\"",
+		},
+		"lists joined by and": {
+			queryString: "(id eq 1 or id eq 2 or id eq 3) and (id eq 4 or id eq 5 or id eq 6)",
+			expectedSql: "SELECT * FROM `members` WHERE ((id = 1 OR id = 2) OR id = 3) AND ((id = 4 OR id = 5) OR id = 6)",
+		},
+		"in list at the maximum": {
+			queryString: "id in (1,2,3)",
+			expectedSql: "SELECT * FROM `members` WHERE id IN (1,2,3)",
+		},
+		"in lists joined by and": {
+			queryString: "id in (1,2,3) and name in ('a','b') and name eq 'c'",
+			expectedSql: "SELECT * FROM `members` WHERE (id IN (1,2,3) AND name IN (\"a\",\"b\")) AND name = \"c\"",
+		},
+		"other comparisons": {
+			queryString: "id gt 1 or id gt 2 or id gt 3 or id gt 4",
+			expectedSql: "SELECT * FROM `members` WHERE ((id > 1 OR id > 2) OR id > 3) OR id > 4",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithMaxListSize(3))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&Member{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_MaxListSizeExceeded(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"list": {
+			queryString:   "id eq 1 or id eq 2 or id eq 3 or id eq 4",
+			expectedError: "invalid query: list of values of 'id' exceeds the maximum of 3 values",
+		},
+		"list in groups": {
+			queryString:   "name eq 'x' and (id eq 1 or (id eq 2 or id eq 3) or name eq 'a' or id eq 4)",
+			expectedError: "invalid query: list of values of 'id' exceeds the maximum of 3 values",
+		},
+		"in list": {
+			queryString:   "id in (1,2,3,4)",
+			expectedError: "invalid query: list of values of 'id' exceeds the maximum of 3 values",
+		},
+		"in list and comparisons joined by or": {
+			queryString:   "id in (1,2) or name eq 'a' or id eq 3 or id eq 4",
+			expectedError: "invalid query: list of values of 'id' exceeds the maximum of 3 values",
+		},
+		"in list of a function": {
+			queryString:   "tolower(name) in ('a','b','c','d')",
+			expectedError: "invalid query: list of values of 'tolower(name)' exceeds the maximum of 3 values",
+		},
+		"list under not": {
+			queryString:   "not(name eq 'a' or name eq 'b' or name eq 'c' or name eq 'd')",
+			expectedError: "invalid query: list of values of 'name' exceeds the maximum of 3 values",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithMaxListSize(3))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrComplexityExceeded))
+		})
+	}
+}
+
+func Test_Builder_MaxListSizeDeepFilterMap(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	builder := New(WithMaxListSize(2))
+
+	// Act
+	filterMap, err := builder.ToDeepFilterMap("name eq 'a' or name eq 'b' or name eq 'c'")
+
+	// Assert
+	assert.Nil(t, filterMap)
+	assert.EqualError(t, err, "invalid query: list of values of 'name' exceeds the maximum of 2 values")
+	assert.True(t, errors.Is(err, ErrComplexityExceeded))
+}
@@ -0,0 +1,58 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+const (
+	// powerFunction is the extension function that raises a number to a power (see WithExtensionFunctions)
+	powerFunction = "power"
+
+	// sqrtFunction is the extension function that returns the square root of a number (see WithExtensionFunctions)
+	sqrtFunction = "sqrt"
+)
+
+// isPowerNode
+// returns whether the node is a call of power
+func isPowerNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && node.Value == powerFunction
+}
+
+// isNumericFunctionNode
+// returns whether the node calls a numeric function with two arguments (greatest, least, round with a precision or power)
+func isNumericFunctionNode(node *syntaxtree.Node) bool {
+	return isExtremumNode(node) || isRoundNode(node) || isPowerNode(node)
+}
+
+// buildNumericFunction
+// builds a numeric function with two arguments (see isNumericFunctionNode)
+func buildNumericFunction(translation *queryTranslation, node *syntaxtree.Node) (string, error) {
+	switch {
+	case isRoundNode(node):
+		return buildRound(translation, node)
+	case isPowerNode(node):
+		return buildPower(translation, node)
+	default:
+		return buildExtremum(translation, node)
+	}
+}
+
+// buildPower
+// builds the base of power raised to its exponent, both are properties, numbers or other numeric expressions,
+// every database calls it POWER (SQLite only has it when it is compiled with SQLITE_ENABLE_MATH_FUNCTIONS)
+//
+//	sqrt(power(x,2) add power(y,2)) lt 10  ->  SQRT((POWER(x, 2) + POWER(y, 2))) < 10
+func buildPower(translation *queryTranslation, node *syntaxtree.Node) (string, error) {
+	base, err := buildNumericOperand(translation, node, node.LeftChild)
+	if err != nil {
+		return "", err
+	}
+	exponent, err := buildNumericOperand(translation, node, node.RightChild)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("POWER(%s, %s)", base, exponent), nil
+}
@@ -0,0 +1,189 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Point struct {
+	ID int
+	X  float64
+	Y  float64
+}
+
+func Test_Builder_MathFunctions(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"power": {
+			queryString: "power(x,2) gt 4",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `points` WHERE POWER(x, 2) > 4",
+				MySQL:      "SELECT * FROM `points` WHERE POWER(x, 2) > 4",
+				SQLite:     "SELECT * FROM `points` WHERE POWER(x, 2) > 4",
+				SQLServer:  "SELECT * FROM `points` WHERE POWER(x, 2) > 4",
+			},
+		},
+		"sqrt": {
+			queryString: "sqrt(y) le 1.5",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `points` WHERE SQRT(y) <= \"1.5\"",
+				MySQL:      "SELECT * FROM `points` WHERE SQRT(y) <= \"1.5\"",
+				SQLite:     "SELECT * FROM `points` WHERE SQRT(y) <= \"1.5\"",
+				SQLServer:  "SELECT * FROM `points` WHERE SQRT(y) <= \"1.5\"",
+			},
+		},
+		"distance": {
+			queryString: "sqrt(power(x sub 1,2) add power(y,2)) lt 10",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `points` WHERE SQRT((POWER((x - 1), 2) + POWER(y, 2))) < 10",
+				MySQL:      "SELECT * FROM `points` WHERE SQRT((POWER((x - 1), 2) + POWER(y, 2))) < 10",
+				SQLite:     "SELECT * FROM `points` WHERE SQRT((POWER((x - 1), 2) + POWER(y, 2))) < 10",
+				SQLServer:  "SELECT * FROM `points` WHERE SQRT((POWER((x - 1), 2) + POWER(y, 2))) < 10",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType), WithExtensionFunctions("power", "sqrt"))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Point{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_AbsSign(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+		expectedIDs []int
+	}{
+		"abs": {
+			queryString: "abs(x) gt 5",
+			expectedSql: "SELECT * FROM `points` WHERE ABS(x) > 5",
+			expectedIDs: []int{2, 3},
+		},
+		"sign": {
+			queryString: "sign(y) eq -1",
+			expectedSql: "SELECT * FROM `points` WHERE SIGN(y) = -1",
+			expectedIDs: []int{2},
+		},
+		"arithmetic": {
+			queryString: "abs(x sub y) le 1 or sign(x mul y) eq 0",
+			expectedSql: "SELECT * FROM `points` WHERE ABS((x - y)) <= 1 OR SIGN((x * y)) = 0",
+			expectedIDs: []int{1, 3},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Point{})
+			db.Create(&Point{ID: 1, X: 3, Y: 4})
+			db.Create(&Point{ID: 2, X: -6, Y: -8})
+			db.Create(&Point{ID: 3, X: 7, Y: 0})
+
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, _ := builder.Build(testData.queryString, tx)
+				return dbQuery.Find(&Point{})
+			})
+			dbQuery, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+
+			var ids []int
+			assert.NoError(t, dbQuery.Model(&Point{}).Order("id").Pluck("id", &ids).Error)
+			assert.Equal(t, testData.expectedIDs, ids)
+		})
+	}
+}
+
+func Test_Builder_MathFunctionsErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		options     []Option
+		expectedErr string
+		expectedIs  error
+	}{
+		"power not enabled": {
+			queryString: "power(x,2) gt 4",
+			options:     []Option{WithExtensionFunctions("sqrt")},
+			expectedErr: "invalid query: function 'power' is an extension function that is not enabled, enable it with WithExtensionFunctions",
+			expectedIs:  ErrFunctionNotAllowed,
+		},
+		"sqrt not enabled": {
+			queryString: "x gt 1 and sqrt(x add y) gt 4",
+			options:     []Option{WithExtensionFunctions("power")},
+			expectedErr: "invalid query: function 'sqrt' is an extension function that is not enabled, enable it with WithExtensionFunctions",
+			expectedIs:  ErrFunctionNotAllowed,
+		},
+		"string exponent": {
+			queryString: "sqrt(power(x,'2')) gt 4",
+			options:     []Option{WithExtensionFunctions("power", "sqrt")},
+			expectedErr: "invalid query: operand '2' of 'power' must be a number, a property of the model or a function",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"root of the filter": {
+			queryString: "power(x,2)",
+			options:     []Option{WithExtensionFunctions("power")},
+			expectedErr: "invalid query: function 'power' cannot be the root of a filter, compare it with a value instead (e.g. power(x,2) gt 5)",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(PostgreSQL)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Point{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package gormodata
+
+import (
+	"time"
+)
+
+// Metrics
+// receives the measurements of a Builder (see WithMetrics), e.g. to export them as Prometheus counters and histograms
+type Metrics interface {
+	// ObserveParseDuration is called with the time it took to parse a filter string, also when it came from the tree cache
+	ObserveParseDuration(duration time.Duration)
+
+	// ObserveBuildDuration is called with the time it took to build a filter into a query (including the parse),
+	// also when the filter was rejected
+	ObserveBuildDuration(duration time.Duration)
+
+	// IncTreeCacheLookup is called with every lookup of a filter in the tree cache of the builder (see WithTreeCache)
+	IncTreeCacheLookup(hit bool)
+
+	// IncRejectedQuery is called with the error of every filter that could not be built,
+	// the cause can be checked with errors.Is (e.g. ErrFieldNotAllowed)
+	IncRejectedQuery(err error)
+}
+
+// WithMetrics
+// reports the parse and build durations, the tree cache lookups and the rejected filters of the builder to the metrics
+//
+//	builder := gormodata.New(gormodata.WithTreeCache(cache), gormodata.WithMetrics(prometheusMetrics))
+func WithMetrics(metrics Metrics) Option {
+	return func(b *Builder) {
+		b.metrics = metrics
+	}
+}
+
+// noopMetrics
+// are the default Metrics of a Builder, they discard all measurements
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveParseDuration(time.Duration) {}
+
+func (noopMetrics) ObserveBuildDuration(time.Duration) {}
+
+func (noopMetrics) IncTreeCacheLookup(bool) {}
+
+func (noopMetrics) IncRejectedQuery(error) {}
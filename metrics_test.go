@@ -0,0 +1,144 @@
+package gormodata
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+type recordingMetrics struct {
+	mutex            sync.Mutex
+	parseDurations   []time.Duration
+	buildDurations   []time.Duration
+	treeCacheLookups []bool
+	rejectedQueries  []error
+}
+
+func (m *recordingMetrics) ObserveParseDuration(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.parseDurations = append(m.parseDurations, duration)
+}
+
+func (m *recordingMetrics) ObserveBuildDuration(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.buildDurations = append(m.buildDurations, duration)
+}
+
+func (m *recordingMetrics) IncTreeCacheLookup(hit bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.treeCacheLookups = append(m.treeCacheLookups, hit)
+}
+
+func (m *recordingMetrics) IncRejectedQuery(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rejectedQueries = append(m.rejectedQueries, err)
+}
+
+func Test_WithMetrics(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryStrings             []string
+		treeCache                bool
+		expectedParses           int
+		expectedBuilds           int
+		expectedTreeCacheLookups []bool
+		expectedRejectedErrs     []error
+	}{
+		"built filters": {
+			queryStrings:   []string{"name eq 'a'", "name eq 'b'"},
+			expectedParses: 2,
+			expectedBuilds: 2,
+		},
+		"tree cache": {
+			queryStrings:             []string{"name eq 'a'", "name eq 'a'", "name eq 'b'"},
+			treeCache:                true,
+			expectedParses:           3,
+			expectedBuilds:           3,
+			expectedTreeCacheLookups: []bool{false, true, false},
+		},
+		"rejected filters": {
+			queryStrings:         []string{"testValue eq 'a'", "name eq", "name eq 'a'"},
+			expectedParses:       3,
+			expectedBuilds:       3,
+			expectedRejectedErrs: []error{ErrFieldNotAllowed, ErrInvalidSyntax},
+		},
+		"rejected before parsing": {
+			queryStrings:         []string{"name eq 'a' and name eq 'b' and name eq 'c'"},
+			expectedBuilds:       1,
+			expectedRejectedErrs: []error{ErrComplexityExceeded},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			metrics := &recordingMetrics{}
+
+			options := []Option{WithDatabaseType(SQLite), WithAllowedFields("name"), WithMaxLength(40), WithMetrics(metrics)}
+			if testData.treeCache {
+				options = append(options, WithTreeCache(NewTreeCache(10)))
+			}
+			builder := New(options...)
+
+			// Act
+			for _, queryString := range testData.queryStrings {
+				_, _ = builder.Build(queryString, db)
+			}
+
+			// Assert
+			assert.Len(t, metrics.parseDurations, testData.expectedParses)
+			assert.Len(t, metrics.buildDurations, testData.expectedBuilds)
+			assert.Equal(t, testData.expectedTreeCacheLookups, metrics.treeCacheLookups)
+			assert.Len(t, metrics.rejectedQueries, len(testData.expectedRejectedErrs))
+			for i, expectedErr := range testData.expectedRejectedErrs {
+				assert.True(t, errors.Is(metrics.rejectedQueries[i], expectedErr))
+			}
+		})
+	}
+}
+
+func Test_WithMetrics_Prepare(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	metrics := &recordingMetrics{}
+	builder := New(WithDatabaseType(SQLite), WithMaxLength(5), WithMetrics(metrics))
+
+	// Act
+	_, err := builder.Prepare("name eq 'test'")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, []error{err}, metrics.rejectedQueries)
+}
+
+func Test_WithMetrics_Capabilities(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	metrics := &recordingMetrics{}
+	builder := New(WithMetrics(metrics))
+
+	// Act
+	_, err := builder.Capabilities(&MockModel{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, metrics.parseDurations)
+	assert.Empty(t, metrics.buildDurations)
+}
@@ -0,0 +1,507 @@
+package gormodata
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const nestedFilterPluginName = "gormodata:nested"
+
+// SubQueryMode
+// defines how filters on relations (e.g. metadata/name eq 'test') are translated into subqueries
+type SubQueryMode int
+
+const (
+	// SubQueryAuto uses IN subqueries for belongs-to and many-to-many relations
+	// and correlated EXISTS subqueries for has-one and has-many relations
+	SubQueryAuto SubQueryMode = iota
+	// SubQueryIn uses IN subqueries for all relations
+	SubQueryIn
+	// SubQueryExists uses correlated EXISTS subqueries for all relations
+	SubQueryExists
+)
+
+// NestedFilterConfig
+// can be given to NewNestedFilterPlugin to tweak how nested filters are translated
+type NestedFilterConfig struct {
+	SubQueryMode SubQueryMode
+
+	// MaxCyclicHops is the maximum number of hops of a nested filter path back to a model that is already on the path,
+	// e.g. parent/parent/name eq 'root' on a self-referencing model has 2 cyclic hops (defaults to 5)
+	MaxCyclicHops int
+}
+
+const defaultMaxCyclicHops = 5
+
+// NewNestedFilterPlugin
+// creates the plugin that resolves the nested filters of expanded properties
+//
+// BuildQuery registers it with the default config if it is not registered yet,
+// register it yourself (db.Use(...)) before calling BuildQuery to use a custom config
+func NewNestedFilterPlugin(config NestedFilterConfig) gorm.Plugin {
+	return &nestedFilterPlugin{config: config}
+}
+
+// nestedFilterPlugin
+// resolves nested filter maps (e.g. {"metadata": {"name": "test"}}) into subqueries
+//
+// the subqueries are based on the relationships of the gorm schema of the queried model,
+// so custom primary keys and references are respected
+//
+// nested maps that point to an embedded struct are flattened into its columns instead
+type nestedFilterPlugin struct {
+	config NestedFilterConfig
+}
+
+func (n *nestedFilterPlugin) Name() string {
+	return nestedFilterPluginName
+}
+
+func (n *nestedFilterPlugin) Initialize(db *gorm.DB) error {
+	// Run before all other callbacks so the flattened embedded filters can still be converted by gormqonvert
+	return db.Callback().Query().Before("*").Register(nestedFilterPluginName+":query", n.queryCallback)
+}
+
+func (n *nestedFilterPlugin) queryCallback(db *gorm.DB) {
+	where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return
+	}
+
+	if err := n.resolveNestedFilters(db, db.Statement.Schema, where.Exprs); err != nil {
+		_ = db.AddError(err)
+
+		return
+	}
+
+	if err := convertColumnLiterals(db.Statement.Schema, where.Exprs); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+func (n *nestedFilterPlugin) resolveNestedFilters(db *gorm.DB, modelSchema *schema.Schema, exprs []clause.Expression) error {
+	for index, expr := range exprs {
+		switch expr := expr.(type) {
+		case clause.AndConditions:
+			if err := n.resolveNestedFilters(db, modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case clause.OrConditions:
+			if err := n.resolveNestedFilters(db, modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case clause.NotConditions:
+			// A negated nested filter is replaced as a whole, so relations can become NOT IN / NOT EXISTS subqueries
+			if len(expr.Exprs) == 1 {
+				if eq, ok := expr.Exprs[0].(clause.Eq); ok {
+					if filter, ok := eq.Value.(map[string]any); ok {
+						resolved, err := n.resolveNestedFilter(db, modelSchema, eq, filter, true)
+						if err != nil {
+							return err
+						}
+						exprs[index] = resolved
+
+						continue
+					}
+				}
+			}
+			if err := n.resolveNestedFilters(db, modelSchema, expr.Exprs); err != nil {
+				return err
+			}
+		case clause.Eq:
+			filter, ok := expr.Value.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			resolved, err := n.resolveNestedFilter(db, modelSchema, expr, filter, false)
+			if err != nil {
+				return err
+			}
+			exprs[index] = resolved
+		}
+	}
+
+	return nil
+}
+
+func (n *nestedFilterPlugin) resolveNestedFilter(db *gorm.DB, modelSchema *schema.Schema, eq clause.Eq, filter map[string]any, negate bool) (clause.Expression, error) {
+	column, _ := eq.Column.(clause.Column)
+	if modelSchema == nil {
+		return nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("cannot expand '%s' without a model, use db.Model(...)", column.Name),
+		}
+	}
+
+	if embedded := flattenEmbeddedFilter(db.NamingStrategy, modelSchema, []string{column.Name}, filter); embedded != nil {
+		if !negate {
+			return embedded, nil
+		}
+
+		// gormqonvert does not look inside NOT conditions, so convert the operator prefixes before negating
+		return clause.Not(convertOperatorPrefixes(db, embedded)), nil
+	}
+
+	relationship := findRelationship(db.NamingStrategy, modelSchema, column.Name)
+	if relationship == nil {
+		return nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("unknown relation '%s' on '%s'", column.Name, modelSchema.Table),
+			Err: ErrUnknownProperty,
+		}
+	}
+
+	if err := n.checkCyclicHops(db.NamingStrategy, []*schema.Schema{modelSchema}, nil, map[string]any{column.Name: filter}, 0); err != nil {
+		return nil, err
+	}
+
+	return n.relationSubQuery(db, relationship, filter, negate)
+}
+
+// checkCyclicHops
+// returns an error if a path of the nested filter goes back to a model on the path more than MaxCyclicHops times,
+// e.g. parent/parent/parent on a self-referencing model or pets/owner/pets on a has-many relation
+func (n *nestedFilterPlugin) checkCyclicHops(namer schema.Namer, schemas []*schema.Schema, path []string, filter map[string]any, hops int) error {
+	maxHops := n.config.MaxCyclicHops
+	if maxHops <= 0 {
+		maxHops = defaultMaxCyclicHops
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(filter)) {
+		nestedFilter, ok := filter[key].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		relationship := findRelationship(namer, schemas[len(schemas)-1], key)
+		if relationship == nil {
+			continue
+		}
+
+		keyPath := append(slices.Clone(path), key)
+
+		keyHops := hops
+		if slices.ContainsFunc(schemas, func(visited *schema.Schema) bool {
+			return visited.ModelType == relationship.FieldSchema.ModelType
+		}) {
+			keyHops++
+		}
+		if keyHops > maxHops {
+			return &InvalidQueryError{
+				Msg: fmt.Sprintf("path '%s' exceeds the maximum of %d cyclic hops", strings.Join(keyPath, "/"), maxHops),
+				Err: ErrComplexityExceeded,
+			}
+		}
+
+		if err := n.checkCyclicHops(namer, append(slices.Clone(schemas), relationship.FieldSchema), keyPath, nestedFilter, keyHops); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convertOperatorPrefixes
+// applies the registered gormqonvert callback on the given expression
+func convertOperatorPrefixes(db *gorm.DB, expr clause.Expression) clause.Expression {
+	convert := db.Callback().Query().Get("gormQonvert:query")
+	if convert == nil {
+		return expr
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true}).Where(expr)
+	convert(tx)
+
+	return tx.Statement.Clauses["WHERE"].Expression
+}
+
+func findRelationship(namer schema.Namer, modelSchema *schema.Schema, name string) *schema.Relationship {
+	for _, relationName := range slices.Sorted(maps.Keys(modelSchema.Relationships.Relations)) {
+		if namer.ColumnName("", relationName) == name {
+			return modelSchema.Relationships.Relations[relationName]
+		}
+	}
+
+	return nil
+}
+
+// flattenEmbeddedFilter
+// turns a nested filter on an embedded struct (e.g. {"address": {"city": "Ghent"}}) into conditions on the flattened columns,
+// returns nil if the path does not point to an embedded struct of the schema
+func flattenEmbeddedFilter(namer schema.Namer, modelSchema *schema.Schema, path []string, filter map[string]any) clause.Expression {
+	conditions := []clause.Expression{}
+	for _, key := range slices.Sorted(maps.Keys(filter)) {
+		fieldPath := append(slices.Clone(path), key)
+		if nestedFilter, ok := filter[key].(map[string]any); ok {
+			nested := flattenEmbeddedFilter(namer, modelSchema, fieldPath, nestedFilter)
+			if nested == nil {
+				return nil
+			}
+			conditions = append(conditions, nested)
+
+			continue
+		}
+
+		field := findEmbeddedField(namer, modelSchema, fieldPath)
+		if field == nil {
+			return nil
+		}
+		conditions = append(conditions, clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  filter[key],
+		})
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+
+	return clause.And(conditions...)
+}
+
+func findEmbeddedField(namer schema.Namer, modelSchema *schema.Schema, path []string) *schema.Field {
+	for _, field := range modelSchema.Fields {
+		if len(field.BindNames) != len(path) || field.DBName == "" {
+			continue
+		}
+
+		matches := true
+		for i, bindName := range field.BindNames {
+			if namer.ColumnName("", bindName) != path[i] {
+				matches = false
+
+				break
+			}
+		}
+		if matches {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// relationKeys
+// holds the columns that link the owner of a relationship to its related records
+type relationKeys struct {
+	// columns of the owner table and the matching columns of the related table
+	owner, related []string
+
+	// columns of the join table that point to the owner and the related records (many to many only)
+	joinOwner, joinRelated []string
+
+	// extra conditions on the related table, used for the owner type of polymorphic relations
+	conditions []clause.Expression
+}
+
+func newRelationKeys(relationship *schema.Relationship, relatedTable string) (*relationKeys, error) {
+	keys := &relationKeys{}
+	for _, reference := range relationship.References {
+		if reference.PrimaryKey == nil {
+			// Polymorphic relations (gorm:"polymorphic:Owner") also need to match the owner type
+			if reference.PrimaryValue != "" {
+				keys.conditions = append(keys.conditions, clause.Eq{
+					Column: clause.Column{Table: relatedTable, Name: reference.ForeignKey.DBName},
+					Value:  reference.PrimaryValue,
+				})
+			}
+
+			continue
+		}
+
+		switch relationship.Type {
+		case schema.BelongsTo:
+			keys.owner = append(keys.owner, reference.ForeignKey.DBName)
+			keys.related = append(keys.related, reference.PrimaryKey.DBName)
+		case schema.HasOne, schema.HasMany:
+			keys.owner = append(keys.owner, reference.PrimaryKey.DBName)
+			keys.related = append(keys.related, reference.ForeignKey.DBName)
+		case schema.Many2Many:
+			if reference.OwnPrimaryKey {
+				keys.owner = append(keys.owner, reference.PrimaryKey.DBName)
+				keys.joinOwner = append(keys.joinOwner, reference.ForeignKey.DBName)
+			} else {
+				keys.related = append(keys.related, reference.PrimaryKey.DBName)
+				keys.joinRelated = append(keys.joinRelated, reference.ForeignKey.DBName)
+			}
+		}
+	}
+
+	if len(keys.owner) == 0 || len(keys.related) == 0 {
+		return nil, &InvalidQueryError{
+			Msg: fmt.Sprintf("relation '%s' has no usable references", relationship.Name),
+		}
+	}
+
+	return keys, nil
+}
+
+// relationSubQuery
+// builds the subquery expression that filters the owner of the relationship on its related records
+//
+// IN subqueries:
+//
+//	belongs to:   <foreign key> IN (SELECT <referenced key> FROM <related table> WHERE filter)
+//	has one/many: <primary key> IN (SELECT <foreign key> FROM <related table> WHERE filter)
+//	many to many: <primary key> IN (SELECT <join key> FROM <join table> WHERE <join key> IN (SELECT <primary key> FROM <related table> WHERE filter))
+//
+// EXISTS subqueries:
+//
+//	belongs to:   EXISTS (SELECT 1 FROM <related table> WHERE <related table>.<referenced key> = <owner table>.<foreign key> AND filter)
+//	has one/many: EXISTS (SELECT 1 FROM <related table> WHERE <related table>.<foreign key> = <owner table>.<primary key> AND filter)
+//	many to many: EXISTS (SELECT 1 FROM <join table> WHERE <join table>.<join key> = <owner table>.<primary key> AND EXISTS (SELECT 1 FROM <related table> WHERE <related table>.<primary key> = <join table>.<join key> AND filter))
+//
+// polymorphic relations also match the owner type (<related table>.<owner type> = <owner value>) in the subquery
+//
+// the conditions of a subquery are always added in the same order, so the generated SQL is deterministic:
+//
+//  1. the owner type of polymorphic relations
+//  2. the correlation with the outer table (EXISTS only), in the order of the relation references
+//  3. the filter, in alphabetical order of its keys (nested filters resolve recursively in the same order)
+//
+// negated filters (e.g. not(metadata/name eq 'test')) keep the filter in the subquery and negate the subquery itself,
+// so owners without related records match as well:
+//
+//	EXISTS:       NOT EXISTS (...)
+//	belongs to:   <foreign key> IS NULL OR <foreign key> NOT IN (...)
+//	has one/many: <primary key> NOT IN (SELECT <foreign key> FROM <related table> WHERE <foreign key> IS NOT NULL AND filter)
+//	many to many: <primary key> NOT IN (...)
+func (n *nestedFilterPlugin) relationSubQuery(db *gorm.DB, relationship *schema.Relationship, filter map[string]any, negate bool) (clause.Expression, error) {
+	useExists := n.config.SubQueryMode == SubQueryExists ||
+		(n.config.SubQueryMode == SubQueryAuto && (relationship.Type == schema.HasOne || relationship.Type == schema.HasMany))
+
+	// The outer table is aliased if it is a subquery of a self-referencing relation
+	ownerTable := relationship.Schema.Table
+	if db.Statement.Table != "" {
+		ownerTable = db.Statement.Table
+	}
+
+	// Correlated subqueries on the same table (e.g. children/name eq 'leaf') need an alias to refer to the outer table
+	relatedTable := relationship.FieldSchema.Table
+	if useExists && relatedTable == ownerTable {
+		relatedTable = ownerTable + "_" + db.NamingStrategy.ColumnName("", relationship.Name)
+	}
+
+	keys, err := newRelationKeys(relationship, relatedTable)
+	if err != nil {
+		return nil, err
+	}
+
+	qonvert, err := qonvertTranslationOf(db)
+	if err != nil {
+		return nil, err
+	}
+	filter, err = convertFilterLiterals(relationship.FieldSchema, qonvert, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// The subqueries resolve their own nested filters, with the same gormqonvert config
+	cleanDB := db.Session(&gorm.Session{NewDB: true}).Set(qonvertTranslationSetting, qonvert).Session(&gorm.Session{})
+	relatedQuery := cleanDB.Model(reflect.New(relationship.FieldSchema.ModelType).Interface())
+	if relatedTable != relationship.FieldSchema.Table {
+		relatedQuery = relatedQuery.Table("? AS ?", clause.Table{Name: relationship.FieldSchema.Table}, clause.Table{Name: relatedTable})
+		relatedQuery.Statement.Table = relatedTable
+	}
+	for _, condition := range keys.conditions {
+		relatedQuery = relatedQuery.Where(condition)
+	}
+
+	existsOperator := "EXISTS (?)"
+	inOperator := "%s IN (?)"
+	if negate {
+		existsOperator = "NOT EXISTS (?)"
+		inOperator = "%s NOT IN (?)"
+	}
+
+	if !useExists {
+		// NOT IN never matches if the subquery returns NULL values
+		if negate && (relationship.Type == schema.HasOne || relationship.Type == schema.HasMany) {
+			for _, column := range keys.related {
+				relatedQuery = relatedQuery.Where(fmt.Sprintf("%s IS NOT NULL", column))
+			}
+		}
+
+		subQuery := whereSorted(relatedQuery, filter).Select(keys.related)
+		if relationship.Type == schema.Many2Many {
+			subQuery = cleanDB.Table(relationship.JoinTable.Table).
+				Select(keys.joinOwner).
+				Where(fmt.Sprintf("%s IN (?)", columnList(keys.joinRelated)), subQuery)
+		}
+
+		inQuery := fmt.Sprintf(inOperator, columnList(keys.owner))
+		// Owners without a related record have NULL foreign keys, which never match NOT IN
+		if negate && relationship.Type == schema.BelongsTo {
+			nullChecks := make([]string, 0, len(keys.owner)+1)
+			for _, column := range keys.owner {
+				nullChecks = append(nullChecks, fmt.Sprintf("%s IS NULL", column))
+			}
+			inQuery = strings.Join(append(nullChecks, inQuery), " OR ")
+		}
+
+		return clause.Expr{
+			SQL:  inQuery,
+			Vars: []any{subQuery},
+		}, nil
+	}
+
+	if relationship.Type == schema.Many2Many {
+		relatedQuery = correlate(relatedQuery.Select("1"), relatedTable, keys.related, relationship.JoinTable.Table, keys.joinRelated)
+		joinQuery := correlate(cleanDB.Table(relationship.JoinTable.Table).Select("1"), relationship.JoinTable.Table, keys.joinOwner, ownerTable, keys.owner)
+
+		return clause.Expr{
+			SQL:  existsOperator,
+			Vars: []any{joinQuery.Where("EXISTS (?)", whereSorted(relatedQuery, filter))},
+		}, nil
+	}
+
+	existsQuery := correlate(relatedQuery.Select("1"), relatedTable, keys.related, ownerTable, keys.owner)
+
+	return clause.Expr{
+		SQL:  existsOperator,
+		Vars: []any{whereSorted(existsQuery, filter)},
+	}, nil
+}
+
+// whereSorted
+// adds the filter to the query one key at a time, in alphabetical order of the keys
+func whereSorted(query *gorm.DB, filter map[string]any) *gorm.DB {
+	for _, key := range slices.Sorted(maps.Keys(filter)) {
+		if comparison, ok := filter[key].(columnComparison); ok {
+			query = query.Where(comparison)
+
+			continue
+		}
+
+		query = query.Where(map[string]any{key: filter[key]})
+	}
+
+	return query
+}
+
+// correlate
+// adds the conditions that link the columns of the subquery table to the columns of the outer table
+func correlate(query *gorm.DB, table string, columns []string, outerTable string, outerColumns []string) *gorm.DB {
+	for i := range columns {
+		query = query.Where("? = ?",
+			clause.Column{Table: table, Name: columns[i]},
+			clause.Column{Table: outerTable, Name: outerColumns[i]},
+		)
+	}
+
+	return query
+}
+
+func columnList(columns []string) string {
+	if len(columns) == 1 {
+		return columns[0]
+	}
+
+	return "(" + strings.Join(columns, ", ") + ")"
+}
@@ -0,0 +1,560 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Mocks
+type Owner struct {
+	OwnerKey string `gorm:"primaryKey"`
+	Name     string
+}
+
+type Pet struct {
+	ID       uuid.UUID
+	Name     string
+	OwnerRef *string
+	Owner    *Owner `gorm:"foreignKey:OwnerRef;references:OwnerKey"`
+}
+
+func Test_BuildQuery_NestedFilterCustomPrimaryKey(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	records := []*Pet{
+		{
+			ID:       uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"),
+			Name:     "rex",
+			OwnerRef: ptr("owner-1"),
+			Owner: &Owner{
+				OwnerKey: "owner-1",
+				Name:     "alice",
+			},
+		},
+		{
+			ID:       uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"),
+			Name:     "tom",
+			OwnerRef: ptr("owner-2"),
+			Owner: &Owner{
+				OwnerKey: "owner-2",
+				Name:     "bob",
+			},
+		},
+	}
+	expectedResult := []Pet{
+		{
+			ID:       uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"),
+			Name:     "tom",
+			OwnerRef: ptr("owner-2"),
+		},
+	}
+	expectedSql := "SELECT * FROM `pets` WHERE owner_ref IN (SELECT `owner_key` FROM `owners` WHERE `owners`.`name` = \"bob\")"
+	queryString := "owner/name eq 'bob'"
+
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	db.CreateInBatches(records, len(records))
+
+	// Act
+	var dbQuery *gorm.DB
+	var err error
+	var result []Pet
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, err = BuildQuery(queryString, tx, SQLite)
+		return dbQuery.Find(&Pet{})
+	})
+	dbQuery, err = BuildQuery(queryString, db, SQLite)
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedSql, sqlQuery)
+	assert.Equal(t, expectedResult, result)
+}
+
+func Test_BuildQuery_NestedFilterUnknownRelation(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+
+	// Act
+	dbQuery, err := BuildQuery("name/value eq 'bob'", db, SQLite)
+	queryResult := dbQuery.Find(&[]Pet{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.EqualError(t, queryResult.Error, "invalid query: unknown relation 'name' on 'pets'")
+}
+
+type Basket struct {
+	ID    uuid.UUID
+	Name  string
+	Items []Item
+}
+
+type Item struct {
+	ID       uuid.UUID
+	Name     string
+	BasketID uuid.UUID
+}
+
+func Test_BuildQuery_NestedFilterHasManyExists(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	records := []*Basket{
+		{
+			ID:   uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"),
+			Name: "fruit",
+			Items: []Item{
+				{ID: uuid.MustParse("1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b"), Name: "apple"},
+				{ID: uuid.MustParse("6afa4aef-a646-415b-ae2d-1ab7fc554c08"), Name: "pear"},
+			},
+		},
+		{
+			ID:   uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"),
+			Name: "vegetables",
+			Items: []Item{
+				{ID: uuid.MustParse("200c2712-cafc-4f00-b6e1-0ff89871f1cd"), Name: "carrot"},
+			},
+		},
+		{
+			ID:   uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"),
+			Name: "empty",
+		},
+	}
+	expectedResult := []Basket{
+		{
+			ID:   uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"),
+			Name: "fruit",
+		},
+	}
+	expectedSql := "SELECT * FROM `baskets` WHERE EXISTS (SELECT 1 FROM `items` WHERE `items`.`basket_id` = `baskets`.`id` AND name LIKE \"p%\")"
+	queryString := "startswith(items/name,'p')"
+
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Basket{}, &Item{})
+	db.CreateInBatches(records, len(records))
+
+	// Act
+	var dbQuery *gorm.DB
+	var err error
+	var result []Basket
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, err = BuildQuery(queryString, tx, SQLite)
+		return dbQuery.Find(&Basket{})
+	})
+	dbQuery, err = BuildQuery(queryString, db, SQLite)
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedSql, sqlQuery)
+	assert.Equal(t, expectedResult, result)
+}
+
+type Dog struct {
+	ID   int
+	Name string
+	Toys []Toy `gorm:"polymorphic:Owner"`
+}
+
+type Cat struct {
+	ID   int
+	Name string
+	Toys []Toy `gorm:"polymorphic:Owner"`
+}
+
+type Toy struct {
+	ID        int
+	Name      string
+	OwnerID   int
+	OwnerType string
+}
+
+func Test_BuildQuery_NestedFilterPolymorphic(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Dog{}, &Cat{}, &Toy{})
+	db.Create(&Dog{ID: 1, Name: "rex", Toys: []Toy{{ID: 1, Name: "bone"}}})
+	db.Create(&Dog{ID: 2, Name: "max", Toys: []Toy{{ID: 2, Name: "ball"}}})
+	db.Create(&Cat{ID: 1, Name: "tom", Toys: []Toy{{ID: 3, Name: "ball"}}})
+
+	expectedResult := []Dog{
+		{ID: 2, Name: "max"},
+	}
+	expectedSql := "SELECT * FROM `dogs` WHERE EXISTS (SELECT 1 FROM `toys` WHERE `toys`.`owner_type` = \"dogs\" AND `toys`.`owner_id` = `dogs`.`id` AND `toys`.`name` = \"ball\")"
+	queryString := "toys/name eq 'ball'"
+
+	// Act
+	var dbQuery *gorm.DB
+	var err error
+	var result []Dog
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, err = BuildQuery(queryString, tx, SQLite)
+		return dbQuery.Find(&Dog{})
+	})
+	dbQuery, err = BuildQuery(queryString, db, SQLite)
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedSql, sqlQuery)
+	assert.Equal(t, expectedResult, result)
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type Contact struct {
+	Email string
+}
+
+type Company struct {
+	ID      int
+	Name    string
+	Address Address `gorm:"embedded;embeddedPrefix:address_"`
+	Contact
+}
+
+func Test_BuildQuery_NestedFilterEmbeddedStruct(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Company{})
+	db.Create(&Company{ID: 1, Name: "first", Address: Address{Street: "Veldstraat", City: "Ghent"}, Contact: Contact{Email: "info@first.be"}})
+	db.Create(&Company{ID: 2, Name: "second", Address: Address{Street: "Meir", City: "Antwerp"}, Contact: Contact{Email: "info@second.be"}})
+	db.Create(&Company{ID: 3, Name: "third", Address: Address{Street: "Korenmarkt", City: "Ghent"}, Contact: Contact{Email: "sales@third.be"}})
+
+	expectedResult := []Company{
+		{ID: 1, Name: "first", Address: Address{Street: "Veldstraat", City: "Ghent"}, Contact: Contact{Email: "info@first.be"}},
+	}
+	expectedSql := "SELECT * FROM `companies` WHERE `companies`.`address_city` = \"Ghent\" AND email LIKE \"info%\""
+	queryString := "address/city eq 'Ghent' and startswith(contact/email,'info')"
+
+	// Act
+	var dbQuery *gorm.DB
+	var err error
+	var result []Company
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, err = BuildQuery(queryString, tx, SQLite, WithInputModelValidation(Company{}))
+		return dbQuery.Find(&Company{})
+	})
+	dbQuery, err = BuildQuery(queryString, db, SQLite, WithInputModelValidation(Company{}))
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedSql, sqlQuery)
+	assert.Equal(t, expectedResult, result)
+}
+
+type Article struct {
+	ID     int
+	Title  string
+	Labels []Label `gorm:"many2many:article_labels"`
+}
+
+type Label struct {
+	ID   int
+	Name string
+}
+
+func Test_BuildQuery_NestedFilterSubQueryMode(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		config      NestedFilterConfig
+		queryString string
+		model       any
+		expectedSql string
+	}{
+		"auto belongs to": {
+			config:      NestedFilterConfig{},
+			queryString: "metadata/name eq 'test'",
+			model:       &MockModel{},
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"test\")",
+		},
+		"exists belongs to": {
+			config:      NestedFilterConfig{SubQueryMode: SubQueryExists},
+			queryString: "metadata/name eq 'test'",
+			model:       &MockModel{},
+			expectedSql: "SELECT * FROM `mock_models` WHERE EXISTS (SELECT 1 FROM `metadata` WHERE `metadata`.`id` = `mock_models`.`metadata_id` AND `metadata`.`name` = \"test\")",
+		},
+		"in has many": {
+			config:      NestedFilterConfig{SubQueryMode: SubQueryIn},
+			queryString: "items/name eq 'apple'",
+			model:       &Basket{},
+			expectedSql: "SELECT * FROM `baskets` WHERE id IN (SELECT `basket_id` FROM `items` WHERE `items`.`name` = \"apple\")",
+		},
+		"auto many to many": {
+			config:      NestedFilterConfig{},
+			queryString: "labels/name eq 'go'",
+			model:       &Article{},
+			expectedSql: "SELECT * FROM `articles` WHERE id IN (SELECT article_id FROM `article_labels` WHERE label_id IN (SELECT `id` FROM `labels` WHERE `labels`.`name` = \"go\"))",
+		},
+		"exists many to many": {
+			config:      NestedFilterConfig{SubQueryMode: SubQueryExists},
+			queryString: "labels/name eq 'go'",
+			model:       &Article{},
+			expectedSql: "SELECT * FROM `articles` WHERE EXISTS (SELECT 1 FROM `article_labels` WHERE `article_labels`.`article_id` = `articles`.`id` AND EXISTS (SELECT 1 FROM `labels` WHERE `labels`.`id` = `article_labels`.`label_id` AND `labels`.`name` = \"go\"))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Basket{}, &Item{}, &Article{}, &Label{})
+			_ = db.Use(NewNestedFilterPlugin(testData.config))
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx, SQLite)
+				return dbQuery.Find(testData.model)
+			})
+			dbQuery, err = BuildQuery(testData.queryString, db, SQLite)
+			queryResult := dbQuery.Find(testData.model)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQuery_NestedFilterNegation(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		model       any
+		expectedSql string
+	}{
+		"belongs to eq": {
+			queryString: "not(owner/name eq 'alice')",
+			model:       &[]Pet{},
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref IS NULL OR owner_ref NOT IN (SELECT `owner_key` FROM `owners` WHERE `owners`.`name` = \"alice\")",
+		},
+		"belongs to ne": {
+			queryString: "not(owner/name ne 'alice')",
+			model:       &[]Pet{},
+			expectedSql: "SELECT * FROM `pets` WHERE owner_ref IS NULL OR owner_ref NOT IN (SELECT `owner_key` FROM `owners` WHERE name != \"alice\")",
+		},
+		"has many": {
+			queryString: "not(startswith(items/name,'p'))",
+			model:       &[]Basket{},
+			expectedSql: "SELECT * FROM `baskets` WHERE NOT EXISTS (SELECT 1 FROM `items` WHERE `items`.`basket_id` = `baskets`.`id` AND name LIKE \"p%\")",
+		},
+		"embedded": {
+			queryString: "not(startswith(contact/email,'info'))",
+			model:       &[]Company{},
+			expectedSql: "SELECT * FROM `companies` WHERE NOT email LIKE \"info%\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Pet{}, &Owner{}, &Basket{}, &Item{}, &Company{})
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx, SQLite)
+				return dbQuery.Find(testData.model)
+			})
+			dbQuery, err = BuildQuery(testData.queryString, db, SQLite)
+			queryResult := dbQuery.Find(testData.model)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_BuildQuery_NestedFilterNegationResult(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Pet{}, &Owner{})
+	db.Create(&Pet{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "rex", OwnerRef: ptr("owner-1"), Owner: &Owner{OwnerKey: "owner-1", Name: "alice"}})
+	db.Create(&Pet{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "tom", OwnerRef: ptr("owner-2"), Owner: &Owner{OwnerKey: "owner-2", Name: "bob"}})
+	db.Create(&Pet{ID: uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"), Name: "stray"})
+
+	expectedResult := []Pet{
+		{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "tom", OwnerRef: ptr("owner-2")},
+		{ID: uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"), Name: "stray"},
+	}
+
+	// Act
+	var result []Pet
+	dbQuery, err := BuildQuery("not(owner/name eq 'alice')", db, SQLite)
+	queryResult := dbQuery.Find(&result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, queryResult.Error)
+	assert.Equal(t, expectedResult, result)
+}
+
+func Test_NestedFilterPlugin_DeterministicOrder(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+	_ = db.Use(NewNestedFilterPlugin(NestedFilterConfig{}))
+	filter := map[string]any{
+		"metadata": map[string]any{
+			"tag":  map[string]any{"value": "test-value"},
+			"name": "test-metadata",
+			"id":   "1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b",
+		},
+	}
+	expectedSql := "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`id` = \"1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b\" AND `metadata`.`name` = \"test-metadata\" AND tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = \"test-value\"))"
+
+	for range 20 {
+		// Act
+		sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Where(filter).Find(&MockModel{})
+		})
+
+		// Assert
+		assert.Equal(t, expectedSql, sqlQuery)
+	}
+}
+
+type Category struct {
+	ID       uuid.UUID
+	Name     string
+	ParentID *uuid.UUID
+	Parent   *Category
+	Children []Category `gorm:"foreignKey:ParentID"`
+}
+
+func Test_BuildQuery_NestedFilterSelfReference(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedSql    string
+		expectedResult []string
+	}{
+		"belongs to": {
+			queryString:    "parent/parent/name eq 'root'",
+			expectedSql:    "SELECT `name` FROM `categories` WHERE parent_id IN (SELECT `id` FROM `categories` WHERE parent_id IN (SELECT `id` FROM `categories` WHERE `categories`.`name` = \"root\"))",
+			expectedResult: []string{"leaf"},
+		},
+		"has many": {
+			queryString:    "children/children/name eq 'leaf'",
+			expectedSql:    "SELECT `name` FROM `categories` WHERE EXISTS (SELECT 1 FROM `categories` AS `categories_children` WHERE `categories_children`.`parent_id` = `categories`.`id` AND EXISTS (SELECT 1 FROM `categories` WHERE `categories`.`parent_id` = `categories_children`.`id` AND `categories`.`name` = \"leaf\"))",
+			expectedResult: []string{"root"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			rootID := uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6")
+			branchID := uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a")
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Category{})
+			db.Create(&Category{ID: rootID, Name: "root"})
+			db.Create(&Category{ID: branchID, Name: "branch", ParentID: &rootID})
+			db.Create(&Category{ID: uuid.MustParse("87e8ed33-512d-4482-b639-e0830a19b653"), Name: "leaf", ParentID: &branchID})
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			var result []string
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = BuildQuery(testData.queryString, tx.Model(&Category{}), SQLite)
+				return dbQuery.Pluck("name", &result)
+			})
+			dbQuery, err = BuildQuery(testData.queryString, db.Model(&Category{}), SQLite)
+			queryResult := dbQuery.Pluck("name", &result)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, queryResult.Error)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+			assert.Equal(t, testData.expectedResult, result)
+		})
+	}
+}
+
+func Test_BuildQuery_NestedFilterMaxCyclicHops(t *testing.T) {
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		config        NestedFilterConfig
+		queryString   string
+		expectedError string
+	}{
+		"within limit": {
+			config:      NestedFilterConfig{MaxCyclicHops: 2},
+			queryString: "parent/parent/name eq 'root'",
+		},
+		"self reference exceeds limit": {
+			config:        NestedFilterConfig{MaxCyclicHops: 2},
+			queryString:   "parent/parent/parent/name eq 'root'",
+			expectedError: "invalid query: path 'parent/parent/parent' exceeds the maximum of 2 cyclic hops",
+		},
+		"mixed self references exceed limit": {
+			config:        NestedFilterConfig{MaxCyclicHops: 1},
+			queryString:   "children/parent/children/name eq 'leaf'",
+			expectedError: "invalid query: path 'children/parent' exceeds the maximum of 1 cyclic hops",
+		},
+		"default limit": {
+			queryString:   "parent/parent/parent/parent/parent/parent/name eq 'root'",
+			expectedError: "invalid query: path 'parent/parent/parent/parent/parent/parent' exceeds the maximum of 5 cyclic hops",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Category{})
+			_ = db.Use(NewNestedFilterPlugin(testData.config))
+
+			// Act
+			var result []Category
+			dbQuery, err := BuildQuery(testData.queryString, db, SQLite)
+			queryResult := dbQuery.Find(&result)
+
+			// Assert
+			assert.NoError(t, err)
+			if testData.expectedError == "" {
+				assert.NoError(t, queryResult.Error)
+			} else {
+				assert.EqualError(t, queryResult.Error, testData.expectedError)
+			}
+		})
+	}
+}
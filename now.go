@@ -0,0 +1,94 @@
+package gormodata
+
+import (
+	"time"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm/clause"
+)
+
+// nowFunction
+// is the function of a filter that returns the current timestamp, it has no arguments (e.g. createdAt lt now())
+const nowFunction = "now"
+
+// nowTranslation
+// translates now() for every database type when the database evaluates it
+var nowTranslation = map[DbType]string{
+	PostgreSQL: "NOW()",
+	MySQL:      "NOW()",
+	SQLite:     "CURRENT_TIMESTAMP",
+	SQLServer:  "SYSDATETIMEOFFSET()",
+}
+
+// WithServerNow
+// evaluates now() in go when the filter is built instead of calling the function of the database,
+// the time is bound like a datetimeoffset literal in UTC, so every database compares with the same time
+//
+//	builder := gormodata.New(gormodata.WithServerNow(func() time.Time { return fixedTime }))
+//	dbQuery, err := builder.Build("expiresAt lt now()", db.Model(&Session{}))
+//
+// the clock is called once for every build, a nil clock uses time.Now
+func WithServerNow(clock func() time.Time) Option {
+	return func(b *Builder) {
+		if clock == nil {
+			clock = time.Now
+		}
+		b.clock = clock
+	}
+}
+
+// isNowCall
+// returns whether the node is a call of now(), its argument is the empty operand the lexer adds (see tokenize)
+func isNowCall(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.UnaryOperator && node.Value == nowFunction &&
+		node.LeftChild != nil && isNowArgument(node.LeftChild)
+}
+
+// isNowArgument
+// returns whether the node is the empty argument of now(), which is not a property
+func isNowArgument(node *syntaxtree.Node) bool {
+	return node.Value == "" && node.Parent != nil && node.Parent.Type == syntaxtree.UnaryOperator && node.Parent.Value == nowFunction
+}
+
+// containsNow
+// returns whether the filter calls now()
+func containsNow(tree *syntaxtree.SyntaxTree) bool {
+	for _, node := range tree.Nodes {
+		if isNowCall(node) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindNow
+// returns the bind parameter of now() when it is compared with a column
+func bindNow(translation *queryTranslation) any {
+	if translation.now == nil {
+		return clause.Expr{SQL: nowTranslation[translation.databaseType]}
+	}
+
+	return bindDateTimeOffset(*translation.now, translation.databaseType, DateTimeOffsetUTC)
+}
+
+// nowSQL
+// returns now() as the argument of a function (e.g. year(now())), the time of the build is written
+// as a timestamp of the database since functions are written without bind parameters
+func nowSQL(translation *queryTranslation) string {
+	if translation.now == nil {
+		return nowTranslation[translation.databaseType]
+	}
+
+	value := translation.now.UTC()
+	switch translation.databaseType {
+	case PostgreSQL:
+		return "CAST('" + value.Format(time.RFC3339Nano) + "' AS timestamptz)"
+	case SQLServer:
+		return "CAST('" + value.Format(time.RFC3339Nano) + "' AS datetimeoffset)"
+	case MySQL:
+		return "CAST('" + value.Format("2006-01-02 15:04:05.999999") + "' AS DATETIME(6))"
+	default:
+		return "'" + value.Format("2006-01-02 15:04:05.999999999-07:00") + "'"
+	}
+}
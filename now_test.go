@@ -0,0 +1,253 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_Now(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	fixedTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := map[string]struct {
+		queryString  string
+		options      []Option
+		expectedSqls map[DbType]string
+	}{
+		"database now": {
+			queryString: "createdAt lt now()",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE created_at < NOW()",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE created_at < NOW()",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE created_at < CURRENT_TIMESTAMP",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE created_at < SYSDATETIMEOFFSET()",
+			},
+		},
+		"database now in function": {
+			queryString: "year(now()) gt 2024",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE EXTRACT(YEAR FROM NOW()) > 2024",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE YEAR(NOW()) > 2024",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE YEAR(CURRENT_TIMESTAMP) > 2024",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE YEAR(SYSDATETIMEOFFSET()) > 2024",
+			},
+		},
+		"server now": {
+			queryString: "createdAt lt now()",
+			options:     []Option{WithServerNow(func() time.Time { return fixedTime })},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE created_at < CAST(\"2025-01-01T08:00:00Z\" AS timestamptz)",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE created_at < CAST(\"2025-01-01 08:00:00\" AS DATETIME(6))",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE created_at < \"2025-01-01 08:00:00\"",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE created_at < CAST(\"2025-01-01T08:00:00Z\" AS datetimeoffset)",
+			},
+		},
+		"server now in function": {
+			queryString: "year(now()) gt 2024",
+			options:     []Option{WithServerNow(func() time.Time { return fixedTime })},
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `mock_time_models` WHERE EXTRACT(YEAR FROM CAST('2025-01-01T08:00:00Z' AS timestamptz)) > 2024",
+				MySQL:      "SELECT * FROM `mock_time_models` WHERE YEAR(CAST('2025-01-01 08:00:00' AS DATETIME(6))) > 2024",
+				SQLite:     "SELECT * FROM `mock_time_models` WHERE YEAR('2025-01-01 08:00:00+00:00') > 2024",
+				SQLServer:  "SELECT * FROM `mock_time_models` WHERE YEAR(CAST('2025-01-01T08:00:00Z' AS datetimeoffset)) > 2024",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(append([]Option{WithDatabaseType(dbType)}, testData.options...)...)
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx.Model(&MockTimeModel{}))
+					return dbQuery.Find(&MockTimeModel{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_NowResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		options       []Option
+		expectedNames []string
+	}{
+		"database now": {
+			queryString:   "createdAt lt now()",
+			expectedNames: []string{"past"},
+		},
+		"server now": {
+			queryString:   "createdAt lt now()",
+			options:       []Option{WithServerNow(func() time.Time { return time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC) })},
+			expectedNames: []string{"future", "past"},
+		},
+		"default clock": {
+			queryString:   "createdAt gt now()",
+			options:       []Option{WithServerNow(nil)},
+			expectedNames: []string{"future"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockTimeModel{})
+			db.Create(&MockTimeModel{Name: "past", CreatedAt: time.Now().UTC().Add(-time.Hour)})
+			db.Create(&MockTimeModel{Name: "future", CreatedAt: time.Now().UTC().AddDate(1, 0, 0)})
+
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, db.Model(&MockTimeModel{}))
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []MockTimeModel
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, model := range result {
+				names = append(names, model.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_Builder_NowErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"arguments": {
+			queryString: "now(createdAt) gt 2025",
+			expectedErr: "failed to parse query: function now has no arguments, expected now()",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation property": {
+			queryString: "metadata/createdAt lt now()",
+			expectedErr: "invalid query: relation property 'metadata/createdAt' cannot be compared with now()",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := New(WithDatabaseType(PostgreSQL)).Build(testData.queryString, db.Model(&MockTimeModel{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_PreparedFilter_ServerNow(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+	calls := 0
+	builder := New(WithDatabaseType(PostgreSQL), WithServerNow(func() time.Time {
+		calls++
+		return time.Date(2025, 1, calls, 0, 0, 0, 0, time.UTC)
+	}))
+	filter, err := builder.Prepare("createdAt lt now()")
+	assert.NoError(t, err)
+
+	// Act
+	sqlQueries := []string{}
+	for range 2 {
+		sqlQueries = append(sqlQueries, db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			dbQuery, _ := filter.Apply(tx.Model(&MockTimeModel{}))
+			return dbQuery.Find(&MockTimeModel{})
+		}))
+	}
+
+	// Assert
+	assert.Equal(t, []string{
+		"SELECT * FROM `mock_time_models` WHERE created_at < CAST(\"2025-01-01T00:00:00Z\" AS timestamptz)",
+		"SELECT * FROM `mock_time_models` WHERE created_at < CAST(\"2025-01-02T00:00:00Z\" AS timestamptz)",
+	}, sqlQueries)
+}
+
+func Test_Parse_Now(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("createdAt lt now( ) and year(now()) gt 2024")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "createdAt lt now() and year(now()) gt 2024", expr.String())
+	assert.Equal(t, Field("createdAt").Lt(Func("now")).And(Func("year", Func("now")).Gt(2024)).String(), expr.String())
+
+	data, err := json.Marshal(expr)
+	assert.NoError(t, err)
+
+	var decoded Expr
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, expr, &decoded)
+}
+
+func Test_Builder_BuildExprNow(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(PostgreSQL))
+
+	// Act
+	var err error
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var dbQuery *gorm.DB
+		dbQuery, err = builder.BuildExpr(Field("createdAt").Ge(Func("now")), tx.Model(&MockTimeModel{}))
+		return dbQuery.Find(&MockTimeModel{})
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `mock_time_models` WHERE created_at >= NOW()", sqlQuery)
+}
@@ -0,0 +1,46 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// nullIfFunction
+// is the function that returns null when a column equals a value and the column otherwise, to filter out sentinel values
+const nullIfFunction = "nullif"
+
+// buildNullIf
+// builds the function nullif, which is compared with a value or null, the value is returned as the value to bind
+//
+//	nullif(status,'unknown') ne null  ->  NULLIF(status, 'unknown') IS NOT NULL
+func buildNullIf(translation *queryTranslation, node *syntaxtree.Node) (string, any, error) {
+	leftChild, rightChild := node.LeftChild, node.RightChild
+	column := ""
+	switch {
+	case leftChild.Type == syntaxtree.UnaryOperator:
+		column = buildUnaryFuncChain(translation, leftChild)
+	case leftChild.Type == syntaxtree.LeftOperand && isPropertyNode(leftChild) && !translation.isRelationPath(leftChild.Value):
+		column = translation.columnName(leftChild.Value)
+	default:
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a column of the model as its first argument, got '%s'", node.Value, nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       leftChild,
+		}
+	}
+
+	// Words that are not literals are properties, which are not validated as the second argument
+	value := literalValue(rightChild.Value)
+	if _, isWord := value.(string); rightChild.Type != syntaxtree.RightOperand || (isWord && !isStringLiteral(rightChild.Value)) {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a literal as its second argument, got '%s'", node.Value, nodeExpression(rightChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       rightChild,
+		}
+	}
+
+	return "NULLIF(" + column + ", ?)", value, nil
+}
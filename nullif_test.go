@@ -0,0 +1,175 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_NullIf(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"null literal": {
+			queryString: "email eq null",
+			expectedSql: "SELECT * FROM `members` WHERE email IS NULL",
+		},
+		"not null literal": {
+			queryString: "email ne null",
+			expectedSql: "SELECT * FROM `members` WHERE email IS NOT NULL",
+		},
+		"negated null literal": {
+			queryString: "not(length(email) eq null)",
+			expectedSql: "SELECT * FROM `members` WHERE LENGTH(email) IS NOT NULL",
+		},
+		"nullif with null": {
+			queryString: "nullif(email,'unknown') ne null",
+			expectedSql: "SELECT * FROM `members` WHERE NULLIF(email, \"unknown\") IS NOT NULL",
+		},
+		"nullif with a value": {
+			queryString: "nullif(tolower(name),'n/a') eq '123' and nullif(id,0) gt 5",
+			expectedSql: "SELECT * FROM `members` WHERE NULLIF(LOWER(name), \"n/a\") = \"123\" AND NULLIF(id, 0) > 5",
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Member{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSql, sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_NullIfResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"sentinel values": {
+			queryString:   "nullif(email,'unknown') ne null",
+			expectedNames: []string{"Alice"},
+		},
+		"sentinel values or null": {
+			queryString:   "nullif(email,'unknown') eq null",
+			expectedNames: []string{"Bob", "Carol"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Member{})
+			db.Create(&Member{ID: 1, Name: "Alice", Email: "alice@example.be"})
+			db.Create(&Member{ID: 2, Name: "Bob", Email: "unknown"})
+			db.Exec("INSERT INTO members (id, name, email) VALUES (3, 'Carol', NULL)")
+
+			// Act
+			dbQuery, err := New(WithDatabaseType(SQLite)).Build(testData.queryString, db)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var names []string
+			assert.NoError(t, dbQuery.Model(&Member{}).Order("name").Pluck("name", &names).Error)
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_Builder_NullIfErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"root of the filter": {
+			queryString: "nullif(email,'unknown')",
+			expectedErr: "invalid query: function 'nullif' cannot be the root of a filter, compare it with a value instead (e.g. nullif(email,'unknown') ne null)",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"right operand": {
+			queryString: "name eq nullif(email,'unknown')",
+			expectedErr: "invalid query: function 'nullif' cannot be the right operand of 'eq', only values can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"property as second argument": {
+			queryString: "nullif(email,name) ne null",
+			expectedErr: "invalid query: 'nullif' expects a literal as its second argument, got 'name'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"greater than null": {
+			queryString: "name gt null",
+			expectedErr: "invalid query: 'gt' cannot compare with null, only 'eq' and 'ne' can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"negated less than null": {
+			queryString: "not(name le null)",
+			expectedErr: "invalid query: 'le' cannot compare with null, only 'eq' and 'ne' can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"nullif less than null": {
+			queryString: "nullif(email,'unknown') lt null",
+			expectedErr: "invalid query: 'lt' cannot compare with null, only 'eq' and 'ne' can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"relation property greater than or equal to null": {
+			queryString: "metadata/name ge null",
+			expectedErr: "invalid query: 'ge' cannot compare with null, only 'eq' and 'ne' can",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"relation path": {
+			queryString: "nullif(metadata/name,'x') ne null",
+			expectedErr: "invalid query: 'nullif' expects a column of the model as its first argument, got 'metadata/name'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := New(WithDatabaseType(PostgreSQL)).Build(testData.queryString, db.Model(&Member{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
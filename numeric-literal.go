@@ -0,0 +1,125 @@
+package gormodata
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// numericLiteralPattern
+// matches the numbers of a filter with a type suffix: L (Edm.Int64), M (Edm.Decimal), F (Edm.Single) or D (Edm.Double),
+// e.g. 10L, 2.5M, 1.0F or 3d
+var numericLiteralPattern = regexp.MustCompile(`^([+-]?[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?)([LlMmFfDd])$`)
+
+// DecimalLiteral
+// is the value of a decimal literal of a filter without its suffix (e.g. 2.50 of 2.50M),
+// it is kept as written so it is compared with decimal columns without losing precision
+type DecimalLiteral string
+
+// MarshalJSON
+// encodes the decimal as a json number
+func (d DecimalLiteral) MarshalJSON() ([]byte, error) {
+	return []byte(decimalDigits(d)), nil
+}
+
+// isNumericSuffixLiteral
+// returns whether the value of a node is a number with a type suffix
+func isNumericSuffixLiteral(value string) bool {
+	return numericLiteralPattern.MatchString(value)
+}
+
+// numericSuffixValue
+// returns the go value of a number with a type suffix: int64 for L, DecimalLiteral for M, float32 for F and float64 for D,
+// numbers that do not fit their type are not numeric literals
+func numericSuffixValue(literal string) (any, bool) {
+	match := numericLiteralPattern.FindStringSubmatch(literal)
+	if match == nil {
+		return nil, false
+	}
+	number := match[1]
+
+	switch strings.ToUpper(match[2]) {
+	case "L":
+		value, err := strconv.ParseInt(number, 10, 64)
+		return value, err == nil
+	case "M":
+		return DecimalLiteral(number), true
+	case "F":
+		value, err := strconv.ParseFloat(number, 32)
+		return float32(value), err == nil
+	default:
+		value, err := strconv.ParseFloat(number, 64)
+		return value, err == nil
+	}
+}
+
+// numericSQL
+// returns the number as it is written in sql, decimals are written without an exponent so the database reads them as exact numbers
+func numericSQL(value any) string {
+	switch value := value.(type) {
+	case DecimalLiteral:
+		return decimalDigits(value)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	default:
+		return literalString(value)
+	}
+}
+
+// decimalDigits
+// returns the decimal without a sign prefix or an exponent (e.g. +2.5e2 -> 250), with the digits after the point that it was written with
+func decimalDigits(value DecimalLiteral) string {
+	mantissa, exponent, _ := strings.Cut(strings.ToLower(string(value)), "e")
+
+	scale := 0
+	if _, fraction, ok := strings.Cut(mantissa, "."); ok {
+		scale = len(fraction)
+	}
+	shift, _ := strconv.Atoi(exponent)
+	scale = max(scale-shift, 0)
+
+	rational, _ := new(big.Rat).SetString(string(value))
+
+	return rational.FloatString(scale)
+}
+
+// bindNumericLiteral
+// returns the bind parameter of a number with a type suffix,
+// decimals are bound as text and cast to an exact decimal by the database, so they are not rounded through a float:
+//
+//	price eq 19.99M  ->  price = CAST('19.99' AS DECIMAL(4,2))
+func bindNumericLiteral(literal string, databaseType DbType) (any, bool) {
+	value, ok := numericSuffixValue(literal)
+	if !ok {
+		return nil, false
+	}
+
+	decimal, ok := value.(DecimalLiteral)
+	if !ok {
+		return value, true
+	}
+
+	digits := decimalDigits(decimal)
+	return clause.Expr{SQL: "CAST(? AS " + decimalType(databaseType, digits) + ")", Vars: []any{digits}}, true
+}
+
+// decimalType
+// returns the sql type that holds the digits of the decimal exactly,
+// SQLite has no decimal type, its NUMERIC affinity stores decimals as integers or floats
+func decimalType(databaseType DbType, digits string) string {
+	switch databaseType {
+	case PostgreSQL:
+		return "numeric"
+	case SQLite:
+		return "NUMERIC"
+	}
+
+	integer, fraction, _ := strings.Cut(strings.TrimLeft(digits, "+-"), ".")
+	precision := max(len(strings.TrimLeft(integer, "0"))+len(fraction), len(fraction), 1)
+
+	return fmt.Sprintf("DECIMAL(%d,%d)", precision, len(fraction))
+}
@@ -0,0 +1,234 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Invoice struct {
+	ID       uuid.UUID
+	Number   string
+	Amount   float64
+	Sequence int64
+}
+
+func Test_BuildQuery_NumericSuffix(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"int64": {
+			queryString: "sequence eq 9007199254740993L",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE sequence = 9007199254740993",
+				MySQL:      "SELECT * FROM `invoices` WHERE sequence = 9007199254740993",
+				SQLite:     "SELECT * FROM `invoices` WHERE sequence = 9007199254740993",
+				SQLServer:  "SELECT * FROM `invoices` WHERE sequence = 9007199254740993",
+			},
+		},
+		"decimal": {
+			queryString: "amount eq 19.99M",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE amount = CAST(\"19.99\" AS numeric)",
+				MySQL:      "SELECT * FROM `invoices` WHERE amount = CAST(\"19.99\" AS DECIMAL(4,2))",
+				SQLite:     "SELECT * FROM `invoices` WHERE amount = CAST(\"19.99\" AS NUMERIC)",
+				SQLServer:  "SELECT * FROM `invoices` WHERE amount = CAST(\"19.99\" AS DECIMAL(4,2))",
+			},
+		},
+		"negative decimal with an exponent": {
+			queryString: "amount gt -1.5e1m",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE amount > CAST(\"-15\" AS numeric)",
+				MySQL:      "SELECT * FROM `invoices` WHERE amount > CAST(\"-15\" AS DECIMAL(2,0))",
+				SQLite:     "SELECT * FROM `invoices` WHERE amount > CAST(\"-15\" AS NUMERIC)",
+				SQLServer:  "SELECT * FROM `invoices` WHERE amount > CAST(\"-15\" AS DECIMAL(2,0))",
+			},
+		},
+		"decimal below one": {
+			queryString: "amount lt 0.005M",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE amount < CAST(\"0.005\" AS numeric)",
+				MySQL:      "SELECT * FROM `invoices` WHERE amount < CAST(\"0.005\" AS DECIMAL(3,3))",
+				SQLite:     "SELECT * FROM `invoices` WHERE amount < CAST(\"0.005\" AS NUMERIC)",
+				SQLServer:  "SELECT * FROM `invoices` WHERE amount < CAST(\"0.005\" AS DECIMAL(3,3))",
+			},
+		},
+		"single and double": {
+			queryString: "amount ge 1.5F and amount le 3d",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE amount >= 1.5 AND amount <= 3",
+				MySQL:      "SELECT * FROM `invoices` WHERE amount >= 1.5 AND amount <= 3",
+				SQLite:     "SELECT * FROM `invoices` WHERE amount >= 1.5 AND amount <= 3",
+				SQLServer:  "SELECT * FROM `invoices` WHERE amount >= 1.5 AND amount <= 3",
+			},
+		},
+		"arithmetic": {
+			queryString: "amount mul 1.21M sub 2L gt -5M",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE ((amount * 1.21) - 2) > CAST(\"-5\" AS numeric)",
+				MySQL:      "SELECT * FROM `invoices` WHERE ((amount * 1.21) - 2) > CAST(\"-5\" AS DECIMAL(1,0))",
+				SQLite:     "SELECT * FROM `invoices` WHERE ((amount * 1.21) - 2) > CAST(\"-5\" AS NUMERIC)",
+				SQLServer:  "SELECT * FROM `invoices` WHERE ((amount * 1.21) - 2) > CAST(\"-5\" AS DECIMAL(1,0))",
+			},
+		},
+		"suffix inside a string literal": {
+			queryString: "number eq '10L'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE number = \"10L\"",
+				MySQL:      "SELECT * FROM `invoices` WHERE number = \"10L\"",
+				SQLite:     "SELECT * FROM `invoices` WHERE number = \"10L\"",
+				SQLServer:  "SELECT * FROM `invoices` WHERE number = \"10L\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = BuildQuery(testData.queryString, tx.Model(&Invoice{}), dbType)
+					return dbQuery.Find(&Invoice{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_NumericSuffixResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString     string
+		expectedNumbers []string
+	}{
+		"int64": {
+			queryString:     "sequence eq 9007199254740993L",
+			expectedNumbers: []string{"INV-2"},
+		},
+		"decimal": {
+			queryString:     "amount eq 19.99M",
+			expectedNumbers: []string{"INV-1"},
+		},
+		"negative decimal": {
+			queryString:     "amount gt -0.5M",
+			expectedNumbers: []string{"INV-1", "INV-2"},
+		},
+		"double": {
+			queryString:     "amount lt 20d",
+			expectedNumbers: []string{"INV-1", "INV-2"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Invoice{})
+			db.Create(&Invoice{ID: uuid.New(), Number: "INV-1", Amount: 19.99, Sequence: 1})
+			db.Create(&Invoice{ID: uuid.New(), Number: "INV-2", Amount: 0.1, Sequence: 9007199254740993})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&Invoice{}), SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Invoice
+			assert.NoError(t, dbQuery.Order("number").Find(&result).Error)
+
+			numbers := []string{}
+			for _, invoice := range result {
+				numbers = append(numbers, invoice.Number)
+			}
+			assert.Equal(t, testData.expectedNumbers, numbers)
+		})
+	}
+}
+
+func Test_Parse_NumericSuffix(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString    string
+		expectedValue  any
+		expectedString string
+		expectedJSON   string
+	}{
+		"int64": {
+			queryString:    "sequence eq 10L",
+			expectedValue:  int64(10),
+			expectedString: "sequence eq 10",
+			expectedJSON:   `10`,
+		},
+		"decimal": {
+			queryString:    "sequence eq 2.50M",
+			expectedValue:  DecimalLiteral("2.50"),
+			expectedString: "sequence eq 2.50M",
+			expectedJSON:   `2.50`,
+		},
+		"negative decimal": {
+			queryString:    "sequence eq -2.5m",
+			expectedValue:  DecimalLiteral("-2.5"),
+			expectedString: "sequence eq -2.5M",
+			expectedJSON:   `-2.5`,
+		},
+		"single": {
+			queryString:    "sequence eq 1.5f",
+			expectedValue:  float32(1.5),
+			expectedString: "sequence eq 1.5F",
+			expectedJSON:   `1.5`,
+		},
+		"double": {
+			queryString:    "sequence eq 2.5D",
+			expectedValue:  float64(2.5),
+			expectedString: "sequence eq 2.5",
+			expectedJSON:   `2.5`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			expr, err := Parse(testData.queryString)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, &Expr{Kind: ComparisonExpr, Op: "eq", Args: []*Expr{exprProperty("sequence"), exprLiteral(testData.expectedValue)}}, expr)
+			assert.Equal(t, testData.expectedString, expr.String())
+
+			reparsedExpr, err := Parse(expr.String())
+			assert.NoError(t, err)
+			assert.Equal(t, expr, reparsedExpr)
+
+			data, err := json.Marshal(expr.Args[1])
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"kind":"literal","value":`+testData.expectedJSON+`}`, string(data))
+		})
+	}
+}
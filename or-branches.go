@@ -0,0 +1,87 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// WithMaxOrBranches
+// rejects filters with more than maxBranches conditions joined by 'or', since wide 'or's keep the database from using its indexes,
+// the filters fail with ErrComplexityExceeded and the number of branches they have
+//
+//	builder := gormodata.New(gormodata.WithMaxOrBranches(10))
+//
+// the branches are counted like the filter is built: a negated 'and' is an 'or' (e.g. not(a and b) is not a or not b),
+// every 'or' adds a branch to the conditions it joins, so "a or b or (c and (d or e))" has 5 branches
+func WithMaxOrBranches(maxBranches int) Option {
+	return func(b *Builder) {
+		b.maxOrBranches = maxBranches
+		b.queryValidations = append(b.queryValidations, orBranchesValidation(maxBranches))
+	}
+}
+
+// orBranchesValidation
+// returns a QueryValidation that checks the number of conditions joined by 'or' in the filter (see WithMaxOrBranches)
+func orBranchesValidation(maxBranches int) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		if branches := orBranches(tree.Root); branches > maxBranches {
+			return &InvalidQueryError{
+				Msg: fmt.Sprintf("filter has %d 'or' branches, which exceeds the maximum of %d", branches, maxBranches),
+				Err: ErrComplexityExceeded,
+			}
+		}
+
+		return nil
+	}
+}
+
+// orBranches
+// returns the number of conditions joined by 'or' in the filter of the root, the 'or's of a chain (e.g. a or b or c) are
+// one list of branches, the negation is pushed down like buildGormQuery does, so a negated 'and' counts as an 'or'
+// and 'not(a and b) or c' is one chain of 3 branches
+func orBranches(root *syntaxtree.Node) int {
+	type branch struct {
+		node    *syntaxtree.Node
+		negated bool
+
+		// inOr is whether the parent of the node is an 'or', so the node is a branch of its chain
+		inOr bool
+	}
+
+	branches := 0
+	stack := []branch{{node: root}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := current.node
+		if node == nil {
+			continue
+		}
+
+		switch {
+		case node.Type == syntaxtree.UnaryOperator && node.Value == "not":
+			// The negated conditions take the place of the 'not', so a negated 'and' in an 'or' is part of its chain
+			stack = append(stack, branch{node: node.LeftChild, negated: !current.negated, inOr: current.inOr})
+		case node.Type == syntaxtree.Operator && (node.Value == "and" || node.Value == "or"):
+			or := (node.Value == "or") != current.negated
+			if or {
+				// A chain starts with two branches, every next 'or' of the chain adds one
+				branches++
+				if !current.inOr {
+					branches++
+				}
+			}
+			stack = append(stack,
+				branch{node: node.LeftChild, negated: current.negated, inOr: or},
+				branch{node: node.RightChild, negated: current.negated, inOr: or},
+			)
+		case isLambdaNode(node):
+			// The condition of a lambda is built in a subquery of its own, the negation is applied to the subquery
+			stack = append(stack, branch{node: node.RightChild})
+		}
+	}
+
+	return branches
+}
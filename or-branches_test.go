@@ -0,0 +1,67 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Builder_MaxOrBranches(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"no or": {
+			queryString: "name eq 'a' and testValue eq 'b' and not(name eq 'c')",
+		},
+		"branches at the maximum": {
+			queryString: "name eq 'a' or name eq 'b' or (testValue eq 'c' and (name eq 'd' or name eq 'e'))",
+		},
+		"lambda": {
+			queryString: "metadata/any(m: m/name eq 'a' or m/name eq 'b') or name eq 'c' or name eq 'd'",
+		},
+		"too many branches": {
+			queryString:   "name eq 'a' or name eq 'b' or name eq 'c' or (testValue eq 'd' and (name eq 'e' or name eq 'f'))",
+			expectedError: "invalid query: filter has 6 'or' branches, which exceeds the maximum of 5",
+		},
+		"negated and": {
+			queryString:   "not(name eq 'a' and name eq 'b' and name eq 'c') or name eq 'd' or (name eq 'e' or name eq 'f')",
+			expectedError: "invalid query: filter has 6 'or' branches, which exceeds the maximum of 5",
+		},
+		"double negation": {
+			queryString:   "not(not(name eq 'a' or name eq 'b' or name eq 'c')) or not(name eq 'd' and name eq 'e' and name eq 'f')",
+			expectedError: "invalid query: filter has 6 'or' branches, which exceeds the maximum of 5",
+		},
+		"branches in lambdas": {
+			queryString:   "metadata/any(m: m/name eq 'a' or m/name eq 'b' or m/name eq 'c') and (name eq 'd' or name eq 'e' or name eq 'f')",
+			expectedError: "invalid query: filter has 6 'or' branches, which exceeds the maximum of 5",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithMaxOrBranches(5))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrComplexityExceeded))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package gormodata
+
+import (
+	"maps"
+	"slices"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// odataParser
+// is the parser configuration of the odata syntax that is shared by all queries
+var odataParser = newParserConfig(odataLexer, odataPrecedence)
+
+// parserConfig
+// holds the immutable configuration of a syntax (operators, functions, delimiters and precedence),
+// it is created once and shared by all parses, so only the tokens and the nodes of a query are allocated per parse
+type parserConfig struct {
+	lexer      *syntaxtree.Lexer
+	precedence map[string]int
+
+	// keywords maps the operators and functions of the lexer to their token type
+	keywords map[string]syntaxtree.TokenType
+
+	// minPrecedence is the precedence the parser starts with, lower than the precedence of any operator
+	minPrecedence int
+
+	parser syntaxtree.PrattParser
+}
+
+// newParserConfig
+// creates the parser configuration of the syntax of the lexer and the operator precedence
+func newParserConfig(lexer *syntaxtree.Lexer, precedence map[string]int) *parserConfig {
+	keywords := map[string]syntaxtree.TokenType{}
+	for _, op := range lexer.BinaryOperators {
+		keywords[op] = syntaxtree.BinaryOp
+	}
+	for _, function := range lexer.BinaryFunctions {
+		keywords[function] = syntaxtree.BinaryFunc
+	}
+	for _, function := range lexer.UnaryFunctions {
+		keywords[function] = syntaxtree.UnaryFunc
+	}
+
+	minPrecedence := 0
+	if len(precedence) > 0 {
+		minPrecedence = slices.Min(slices.Collect(maps.Values(precedence)))
+	}
+
+	return &parserConfig{
+		lexer:         lexer,
+		precedence:    precedence,
+		keywords:      keywords,
+		minPrecedence: minPrecedence - 1,
+		parser:        syntaxtree.PrattParser{Precedence: precedence},
+	}
+}
+
+// parse
+// parses the query into a syntax tree, errors are returned as *SyntaxError
+func (c *parserConfig) parse(query string) (*syntaxtree.SyntaxTree, error) {
+	tokens, err := c.tokenize(query)
+	if err != nil {
+		return nil, &SyntaxError{
+			Err:  err,
+			Kind: ErrInvalidSyntax,
+		}
+	}
+
+	// The parser only moves the start of the stream, so the tokens can still be inspected after an error
+	root, nodes, err := c.parser.Parse(&syntaxtree.TokenStream{Tokens: tokens}, c.minPrecedence, nil)
+	if err != nil {
+		return nil, &SyntaxError{
+			Err:  err,
+			Kind: syntaxErrorKind(tokens),
+		}
+	}
+
+	return c.newTree(root, nodes), nil
+}
+
+// newTree
+// returns a syntax tree with the root and nodes that refers to the shared configuration
+func (c *parserConfig) newTree(root *syntaxtree.Node, nodes []*syntaxtree.Node) *syntaxtree.SyntaxTree {
+	return &syntaxtree.SyntaxTree{
+		Root:        root,
+		Nodes:       nodes,
+		Lexer:       c.lexer,
+		Precendence: c.precedence,
+	}
+}
+
+// isBinaryFunction
+// returns whether the name is a function with two arguments (e.g. contains)
+func (c *parserConfig) isBinaryFunction(name string) bool {
+	return c.keywords[name] == syntaxtree.BinaryFunc
+}
+
+// isUnaryFunction
+// returns whether the name is a function with one argument (e.g. length), including not
+func (c *parserConfig) isUnaryFunction(name string) bool {
+	return c.keywords[name] == syntaxtree.UnaryFunc
+}
@@ -0,0 +1,94 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"github.com/test-go/testify/assert"
+)
+
+func Test_NewParserConfig(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	lexer := &syntaxtree.Lexer{
+		BinaryOperators: []string{"+", "*"},
+		BinaryFunctions: []string{"max"},
+		UnaryFunctions:  []string{"abs"},
+	}
+	precedence := map[string]int{"+": 2, "*": 3}
+
+	// Act
+	result := newParserConfig(lexer, precedence)
+
+	// Assert
+	assert.Equal(t, 1, result.minPrecedence)
+	assert.Equal(t, map[string]syntaxtree.TokenType{
+		"+":   syntaxtree.BinaryOp,
+		"*":   syntaxtree.BinaryOp,
+		"max": syntaxtree.BinaryFunc,
+		"abs": syntaxtree.UnaryFunc,
+	}, result.keywords)
+	assert.True(t, result.isBinaryFunction("max"))
+	assert.False(t, result.isBinaryFunction("abs"))
+	assert.True(t, result.isUnaryFunction("abs"))
+	assert.False(t, result.isUnaryFunction("+"))
+}
+
+func Test_ParserConfig_ParseSharesConfiguration(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	first, firstErr := odataParser.parse("name eq 'test'")
+	second, secondErr := odataParser.parse("length(name) gt 3 and contains(name,'a')")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.True(t, first.Lexer == odataLexer)
+	assert.True(t, second.Lexer == odataLexer)
+	assert.Equal(t, odataPrecedence, first.Precendence)
+	assert.False(t, first.Root == second.Root)
+}
+
+func Test_ParserConfig_ParseError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query        string
+		expectedKind error
+	}{
+		"unbalanced brackets": {
+			query:        "(name eq 'test'",
+			expectedKind: ErrUnbalancedParens,
+		},
+		"unknown function": {
+			query:        "concot(name,'test') eq 'nametest'",
+			expectedKind: ErrUnknownFunction,
+		},
+		"unknown operator": {
+			query:        "name qe 'test'",
+			expectedKind: ErrUnsupportedOperator,
+		},
+		"unterminated literal": {
+			query:        "name eq 'test",
+			expectedKind: ErrInvalidSyntax,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := odataParser.parse(testData.query)
+
+			// Assert
+			assert.Nil(t, result)
+			var syntaxError *SyntaxError
+			assert.True(t, errors.As(err, &syntaxError))
+			assert.Equal(t, testData.expectedKind, syntaxError.Kind)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package gormodata
+
+import (
+	"errors"
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// droppedConditionsSetting
+// is the gorm setting the warnings of the conditions that were dropped from a partial filter are kept in (see WithPartialFilters)
+const droppedConditionsSetting = "gormodata:dropped_conditions"
+
+// WithPartialFilters
+// drops the conditions of a filter on properties that do not exist or are not allowed (see WithAllowedFields, WithDeniedFields, WithPolicy)
+// instead of rejecting the filter, the other conditions are applied,
+// e.g. for dashboards that send the same filter to endpoints of different models
+//
+//	builder := gormodata.New(gormodata.WithPartialFilters())
+//	dbQuery, warnings, err := builder.BuildWithWarnings("name eq 'rex' and color eq 'brown'", db.Model(&Pet{}))
+//	// warnings: dropped condition color eq 'brown', unknown column name 'color'
+//
+// properties are only checked against the model when it is set on the db (db.Model(...)), the dropped conditions are
+// returned as warnings by BuildWithWarnings, a filter of which every condition is dropped does not filter at all
+func WithPartialFilters() Option {
+	return func(b *Builder) {
+		b.partialFilters = true
+	}
+}
+
+// dropInvalidConditions
+// removes the conditions of the tree that fail the validations of the builder or the model on the db with ErrUnknownProperty
+// or ErrFieldNotAllowed, an 'and' or 'or' with one condition left is replaced by that condition and a 'not' without a condition is removed,
+// the root of the tree is nil when every condition is dropped
+func (b *Builder) dropInvalidConditions(tree *syntaxtree.SyntaxTree, db *gorm.DB) []Warning {
+	validations := b.queryValidations
+	if db.Statement.Model != nil {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(db.Statement.Model); err == nil {
+			validations = append([]QueryValidation{schemaValidation(statement.Schema)}, validations...)
+		}
+	}
+
+	warnings := []Warning{}
+	var dropConditions func(node *syntaxtree.Node) *syntaxtree.Node
+	dropConditions = func(node *syntaxtree.Node) *syntaxtree.Node {
+		switch {
+		case node.Type == syntaxtree.Operator && (node.Value == "and" || node.Value == "or"):
+			node.LeftChild, node.RightChild = dropConditions(node.LeftChild), dropConditions(node.RightChild)
+			switch {
+			case node.LeftChild == nil:
+				return node.RightChild
+			case node.RightChild == nil:
+				return node.LeftChild
+			}
+		case node.Type == syntaxtree.UnaryOperator && node.Value == "not":
+			if node.LeftChild = dropConditions(node.LeftChild); node.LeftChild == nil {
+				return nil
+			}
+		default:
+			if msg := conditionError(odataParser.newTree(node, nil), db, validations); msg != "" {
+				warnings = append(warnings, Warning{
+					Msg:        fmt.Sprintf("dropped condition %s, %s", nodeExpression(node), msg),
+					Expression: nodeExpression(node),
+				})
+
+				return nil
+			}
+		}
+
+		return node
+	}
+
+	tree.Root = dropConditions(tree.Root)
+	if tree.Root == nil {
+		tree.Nodes = nil
+
+		return warnings
+	}
+	tree.Root.Parent = nil
+
+	// The children that took the place of their parent refer to their new parent,
+	// and only the nodes that are left in the tree are kept
+	kept := map[*syntaxtree.Node]bool{}
+	stack := []*syntaxtree.Node{tree.Root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		kept[node] = true
+		for _, child := range []*syntaxtree.Node{node.LeftChild, node.RightChild} {
+			if child != nil {
+				child.Parent = node
+				stack = append(stack, child)
+			}
+		}
+	}
+	nodes := tree.Nodes[:0]
+	for _, node := range tree.Nodes {
+		if kept[node] {
+			nodes = append(nodes, node)
+		}
+	}
+	tree.Nodes = nodes
+
+	return warnings
+}
+
+// conditionError
+// returns why the condition cannot be part of a partial filter, or an empty string if it can,
+// only unknown properties and properties that are not allowed drop a condition, other errors are returned when the filter is built
+func conditionError(condition *syntaxtree.SyntaxTree, db *gorm.DB, validations []QueryValidation) string {
+	for _, validateQuery := range validations {
+		err := validateQuery(condition, db)
+		if !errors.Is(err, ErrUnknownProperty) && !errors.Is(err, ErrFieldNotAllowed) {
+			continue
+		}
+
+		// All errors are collected when the builder collects all errors, the first one that drops the condition is returned
+		var queryErrors QueryErrors
+		if errors.As(err, &queryErrors) {
+			for _, queryError := range queryErrors {
+				if errors.Is(queryError, ErrUnknownProperty) || errors.Is(queryError, ErrFieldNotAllowed) {
+					err = queryError
+
+					break
+				}
+			}
+		}
+
+		var invalidQueryError *InvalidQueryError
+		if errors.As(err, &invalidQueryError) {
+			return invalidQueryError.Msg
+		}
+
+		return err.Error()
+	}
+
+	return ""
+}
+
+// droppedConditions
+// returns the warnings of the conditions that were dropped from the partial filter of the db (see WithPartialFilters)
+func droppedConditions(db *gorm.DB) []Warning {
+	dropped, _ := db.Get(droppedConditionsSetting)
+	warnings, _ := dropped.([]Warning)
+
+	return warnings
+}
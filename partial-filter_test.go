@@ -0,0 +1,134 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_PartialFilters(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString      string
+		options          []Option
+		expectedSql      string
+		expectedWarnings []Warning
+	}{
+		"valid filter": {
+			queryString:      "name eq 'Alice' and id gt 5",
+			expectedSql:      "SELECT * FROM `members` WHERE name = \"Alice\" AND id > 5",
+			expectedWarnings: []Warning{},
+		},
+		"unknown property": {
+			queryString: "name eq 'Alice' and color eq 'brown'",
+			expectedSql: "SELECT * FROM `members` WHERE name = \"Alice\"",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition color eq 'brown', unknown column name 'color'", Expression: "color eq 'brown'"},
+			},
+		},
+		"unknown property in or": {
+			queryString: "(color eq 'brown' or id gt 5) and contains(name,'li')",
+			expectedSql: "SELECT * FROM `members` WHERE id > 5 AND name LIKE \"%li%\"",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition color eq 'brown', unknown column name 'color'", Expression: "color eq 'brown'"},
+			},
+		},
+		"unknown property in not": {
+			queryString: "not(length(color) gt 3) and name eq 'Alice'",
+			expectedSql: "SELECT * FROM `members` WHERE name = \"Alice\"",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition length(color) gt 3, unknown column name 'color'", Expression: "length(color) gt 3"},
+			},
+		},
+		"denied field": {
+			queryString: "email eq 'alice@example.com' or name eq 'Alice'",
+			options:     []Option{WithDeniedFields("email")},
+			expectedSql: "SELECT * FROM `members` WHERE name = \"Alice\"",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition email eq 'alice@example.com', field 'email' is not allowed", Expression: "email eq 'alice@example.com'"},
+			},
+		},
+		"field that is not allowed": {
+			queryString: "email eq 'alice@example.com' and name eq 'Alice' and id eq 1",
+			options:     []Option{WithAllowedFields("name"), WithCollectAllErrors()},
+			expectedSql: "SELECT * FROM `members` WHERE name = \"Alice\"",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition email eq 'alice@example.com', field 'email' is not allowed", Expression: "email eq 'alice@example.com'"},
+				{Msg: "dropped condition id eq 1, field 'id' is not allowed", Expression: "id eq 1"},
+			},
+		},
+		"every condition dropped": {
+			queryString: "color eq 'brown' or size gt 3",
+			expectedSql: "SELECT * FROM `members`",
+			expectedWarnings: []Warning{
+				{Msg: "dropped condition color eq 'brown', unknown column name 'color'", Expression: "color eq 'brown'"},
+				{Msg: "dropped condition size gt 3, unknown column name 'size'", Expression: "size gt 3"},
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite), WithPartialFilters()}, testData.options...)...)
+
+			// Act
+			var warnings []Warning
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, warnings, err = builder.BuildWithWarnings(testData.queryString, tx.Model(&Member{}))
+				return dbQuery.Find(&Member{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+			assert.Equal(t, testData.expectedWarnings, warnings)
+		})
+	}
+}
+
+func Test_Builder_PartialFiltersScope(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Member{})
+	db.Create(&[]Member{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}})
+	builder := New(WithDatabaseType(SQLite), WithPartialFilters())
+
+	// Act
+	var members []Member
+	err := db.Model(&Member{}).Scopes(builder.Scope("color eq 'brown' or size gt 3")).Find(&members).Error
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+}
+
+func Test_Builder_PartialFiltersInvalid(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithPartialFilters())
+
+	// Act
+	_, warnings, err := builder.BuildWithWarnings("color eq 'brown' or length(name) eq concat(name,'x')", db.Model(&Member{}))
+
+	// Assert
+	assert.Nil(t, warnings)
+	assert.EqualError(t, err, "invalid query: function 'concat' cannot be the right operand of 'eq', only values can")
+	assert.True(t, errors.Is(err, ErrUnsupportedOperator))
+}
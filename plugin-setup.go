@@ -0,0 +1,64 @@
+package gormodata
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/survivorbat/go-tsyncmap"
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"gorm.io/gorm"
+)
+
+// pluginSetups
+// holds the plugin setup of every db by its callbacks (db.Callback()),
+// which are shared by all sessions of the db unlike its *gorm.Config that is copied by every session
+var pluginSetups = tsyncmap.Map[any, *pluginSetup]{}
+
+// pluginSetup
+// registers the plugins of one db once, concurrent builds on a new db wait until the plugins are registered
+type pluginSetup struct {
+	mutex sync.Mutex
+	done  atomic.Bool
+}
+
+// register
+// registers the nested filter plugin, gormqonvert and the statement timeout plugin on the db if they are not registered yet,
+// a failed registration is tried again on the next build
+func (p *pluginSetup) register(db *gorm.DB, qonvert *qonvertTranslation) error {
+	if p.done.Load() {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.done.Load() {
+		return nil
+	}
+
+	if _, ok := db.Plugins[nestedFilterPluginName]; !ok {
+		if err := db.Use(NewNestedFilterPlugin(NestedFilterConfig{})); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := db.Plugins[gormqonvertPluginName]; !ok {
+		if qonvert == nil {
+			qonvert = defaultQonvertTranslation
+		}
+		if err := db.Use(gormqonvert.New(qonvert.config)); err != nil {
+			return err
+		}
+		qonvertTranslations.Store(db.Plugins[gormqonvertPluginName], qonvert)
+	}
+
+	if _, ok := db.Plugins[statementTimeoutPluginName]; !ok {
+		if err := db.Use(&statementTimeoutPlugin{}); err != nil {
+			return err
+		}
+	}
+
+	p.done.Store(true)
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// PolicyWildcard
+// allows every property and relation in RolePolicy.Properties, or every operator and function in RolePolicy.Operators
+const PolicyWildcard = "*"
+
+// Policy
+// are the properties, relations and operators that the filters of every role can use (see WithPolicy),
+// so the same endpoint can offer richer filters to admins than to anonymous users
+//
+//	policy := gormodata.Policy{
+//		Role: func(db *gorm.DB) string { return RoleFromContext(db.Statement.Context) },
+//		Roles: map[string]gormodata.RolePolicy{
+//			"admin":     {Properties: []string{gormodata.PolicyWildcard}, Operators: []string{gormodata.PolicyWildcard}},
+//			"anonymous": {Properties: []string{"name"}, Relations: []string{"owner"}, Operators: []string{"eq", "and"}},
+//		},
+//	}
+type Policy struct {
+	// Role returns the role of the filter that is built on the db, e.g. from the context of the request (see Builder.BuildContext),
+	// the role is "" if Role is nil
+	Role func(db *gorm.DB) string
+
+	// Roles are the policies of the roles, the filters of a role without a policy cannot use any property or operator
+	Roles map[string]RolePolicy
+}
+
+// RolePolicy
+// is what the filters of a role can use, names are property names like in filters (e.g. "birthYear" or "owner/name")
+type RolePolicy struct {
+	// Properties are the properties the filters can use, e.g. "name" or "owner/name"
+	Properties []string
+
+	// Relations are the relations the filters can use every property of, e.g. "owner" allows "owner/name" and "owner/address/city"
+	Relations []string
+
+	// Operators are the operators and functions the filters can use, e.g. "eq", "and", "not" or "contains"
+	Operators []string
+}
+
+// WithPolicy
+// checks the filters against the policy of the role of the db, properties and relations that are not in it
+// fail with ErrFieldNotAllowed, operators with ErrOperatorNotAllowed and functions with ErrFunctionNotAllowed
+//
+// the policy is checked after the field aliases are resolved, so it uses the properties of the model
+func WithPolicy(policy Policy) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, policy.validation())
+	}
+}
+
+// validation
+// returns the query validation of the policy
+func (p Policy) validation() QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		role := ""
+		if p.Role != nil {
+			role = p.Role(db)
+		}
+		rolePolicy := p.Roles[role]
+
+		allProperties := slices.Contains(rolePolicy.Properties, PolicyWildcard)
+		properties := make(map[string]bool, len(rolePolicy.Properties))
+		for _, property := range rolePolicy.Properties {
+			properties[propertyPath(db.NamingStrategy, property)] = true
+		}
+		relations := make([]string, len(rolePolicy.Relations))
+		for i, relation := range rolePolicy.Relations {
+			relations[i] = propertyPath(db.NamingStrategy, relation) + "/"
+		}
+
+		allOperators := slices.Contains(rolePolicy.Operators, PolicyWildcard)
+		operators := make(map[string]bool, len(rolePolicy.Operators))
+		for _, operator := range rolePolicy.Operators {
+			operators[strings.ToLower(operator)] = true
+		}
+
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			switch {
+			case isPropertyNode(currentNode):
+				path := propertyPath(db.NamingStrategy, currentNode.Value)
+				if allProperties || properties[path] || slices.ContainsFunc(relations, func(relation string) bool {
+					return strings.HasPrefix(path, relation)
+				}) {
+					return nil
+				}
+
+				return &InvalidQueryError{
+					Msg:        fmt.Sprintf("field '%s' is not allowed for role '%s'", currentNode.Value, role),
+					Err:        ErrFieldNotAllowed,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			case currentNode.Type == syntaxtree.Operator || currentNode.Type == syntaxtree.UnaryOperator:
+				operator := strings.ToLower(currentNode.Value)
+				if allOperators || operators[operator] {
+					return nil
+				}
+
+				kind, err := "function", ErrFunctionNotAllowed
+				if operator == "not" || operator == negationFunction || slices.Contains(odataLexer.BinaryOperators, operator) || isLambdaOperator(operator) {
+					kind, err = "operator", ErrOperatorNotAllowed
+				}
+
+				return &InvalidQueryError{
+					Msg:        fmt.Sprintf("%s '%s' is not allowed for role '%s'", kind, currentNode.Value, role),
+					Err:        err,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
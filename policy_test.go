@@ -0,0 +1,125 @@
+package gormodata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type roleContextKey struct{}
+
+func Test_WithPolicy(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	policy := Policy{
+		Role: func(db *gorm.DB) string {
+			role, _ := db.Statement.Context.Value(roleContextKey{}).(string)
+
+			return role
+		},
+		Roles: map[string]RolePolicy{
+			"admin": {Properties: []string{PolicyWildcard}, Operators: []string{PolicyWildcard}},
+			"user": {
+				Properties: []string{"name", "testValue"},
+				Relations:  []string{"metadata/tag"},
+				Operators:  []string{"eq", "and", "not", "contains"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		role          string
+		queryString   string
+		expectedError string
+		expectedErr   error
+	}{
+		"admin": {
+			role:        "admin",
+			queryString: "length(tolower(id)) gt 2 or metadata/name eq 'prd'",
+		},
+		"allowed properties and operators": {
+			role:        "user",
+			queryString: "name eq 'test' and not(contains(testValue,'x'))",
+		},
+		"property of an allowed relation": {
+			role:        "user",
+			queryString: "metadata/tag/value eq 'test'",
+		},
+		"property not allowed": {
+			role:          "user",
+			queryString:   "name eq 'test' and testValues eq 'x'",
+			expectedError: "invalid query: field 'testValues' is not allowed for role 'user'",
+			expectedErr:   ErrFieldNotAllowed,
+		},
+		"property of a relation that is not allowed": {
+			role:          "user",
+			queryString:   "metadata/name eq 'prd'",
+			expectedError: "invalid query: field 'metadata/name' is not allowed for role 'user'",
+			expectedErr:   ErrFieldNotAllowed,
+		},
+		"operator not allowed": {
+			role:          "user",
+			queryString:   "name eq 'a' or name eq 'b'",
+			expectedError: "invalid query: operator 'or' is not allowed for role 'user'",
+			expectedErr:   ErrOperatorNotAllowed,
+		},
+		"function not allowed": {
+			role:          "user",
+			queryString:   "length(name) eq 2",
+			expectedError: "invalid query: function 'length' is not allowed for role 'user'",
+			expectedErr:   ErrFunctionNotAllowed,
+		},
+		"role without a policy": {
+			role:          "anonymous",
+			queryString:   "name eq 'test'",
+			expectedError: "invalid query: operator 'eq' is not allowed for role 'anonymous'",
+			expectedErr:   ErrOperatorNotAllowed,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithPolicy(policy))
+			ctx := context.WithValue(context.Background(), roleContextKey{}, testData.role)
+
+			// Act
+			_, err := builder.BuildContext(ctx, testData.queryString, db)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, testData.expectedErr))
+		})
+	}
+}
+
+func Test_WithPolicy_WithoutRole(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithPolicy(Policy{
+		Roles: map[string]RolePolicy{"": {Properties: []string{"name"}, Operators: []string{"eq"}}},
+	}))
+
+	// Act
+	_, allowedErr := builder.Build("name eq 'test'", db)
+	_, deniedErr := builder.Build("testValue eq 'test'", db)
+
+	// Assert
+	assert.NoError(t, allowedErr)
+	assert.EqualError(t, deniedErr, "invalid query: field 'testValue' is not allowed for role ''")
+}
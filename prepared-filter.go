@@ -0,0 +1,173 @@
+package gormodata
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"github.com/survivorbat/go-tsyncmap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreparedFilter
+// is a filter that is parsed once and can be applied on many queries (see Builder.Prepare),
+// e.g. fixed filters of a service like tenant scoping or status filters
+//
+// the filter is validated and translated on the first Apply on a db (and model),
+// later applies on that db run the query validations again (e.g. for the role of WithPolicy) and reuse the translated conditions,
+// it is safe for concurrent use
+type PreparedFilter struct {
+	builder *Builder
+	query   string
+	tree    *syntaxtree.SyntaxTree
+
+	// conditions holds the translated conditions of the filter for every db and model it was applied on
+	conditions tsyncmap.Map[preparedFilterKey, []clause.Expression]
+
+	// resolvedTree is the tree with the field aliases resolved, it is stored on the first translation
+	resolvedTree atomic.Pointer[syntaxtree.SyntaxTree]
+}
+
+// preparedFilterKey
+// identifies everything the translated conditions of a PreparedFilter depend on
+type preparedFilterKey struct {
+	// callbacks are the callbacks of the db (db.Callback()), which are shared by all its sessions
+	callbacks any
+
+	model        reflect.Type
+	databaseType DbType
+	nullSafe     bool
+}
+
+// Prepare
+// parses the filter once, so it can be applied on many queries without parsing and translating it again
+//
+//	activeFilter, err := builder.Prepare("status eq 'active' and deletedAt eq null")
+//	dbQuery, err := activeFilter.Apply(db.Model(&MyModel{}))
+//
+// the size and function checks are done here (see WithMaxLength, WithMaxTokens, WithDisabledFunctions, WithMaxDepth),
+// the query validations are run on every Apply
+func (b *Builder) Prepare(query string) (*PreparedFilter, error) {
+	tree, queryErrors, err := b.parseChecked(query)
+	if err == nil && len(queryErrors) > 0 {
+		err = queryErrors
+	}
+
+	if err != nil {
+		var collectedErrors QueryErrors
+		if b.collectAllErrors && !errors.As(err, &collectedErrors) {
+			err = QueryErrors{err}
+		}
+
+		b.metrics.IncRejectedQuery(err)
+		b.logger.Debug("odata filter rejected", "query", query, "error", err)
+
+		return nil, err
+	}
+
+	return &PreparedFilter{
+		builder: b,
+		query:   query,
+		tree:    tree,
+	}, nil
+}
+
+// String
+// returns the filter the PreparedFilter was prepared with
+func (p *PreparedFilter) String() string {
+	return p.query
+}
+
+// Invalidate
+// removes the translated conditions of all dbs, the filter is validated and translated again on the next Apply on a db,
+// e.g. when the naming strategy or the plugins of a db were changed
+func (p *PreparedFilter) Invalidate() {
+	p.conditions.Clear()
+}
+
+// Apply
+// adds the conditions of the filter to the db, like Build does with the filter string
+func (p *PreparedFilter) Apply(db *gorm.DB) (*gorm.DB, error) {
+	result, _, err := p.builder.buildLogged(db, "query", p.query, p.apply)
+
+	return result, err
+}
+
+func (p *PreparedFilter) apply(db *gorm.DB) (*gorm.DB, *syntaxtree.SyntaxTree, error) {
+	translation, db, err := p.builder.prepareDB(db)
+	if err != nil {
+		return db, nil, err
+	}
+
+	if err := contextError(db); err != nil {
+		return db, nil, err
+	}
+
+	key := preparedFilterKey{
+		callbacks:    db.Callback(),
+		model:        reflect.TypeOf(db.Statement.Model),
+		databaseType: translation.databaseType,
+		nullSafe:     translation.nullSafe,
+	}
+	// now() evaluated by the builder is part of the conditions, so they are translated on every apply (see WithServerNow),
+	// and so are partial filters with query validations of the options (see hasUserQueryValidations), which can drop other conditions for every db (e.g. for the role of WithPolicy)
+	cacheable := (p.builder.clock == nil || !containsNow(p.tree)) && (!p.builder.partialFilters || !p.builder.hasUserQueryValidations)
+	if conditions, ok := p.conditions.Load(key); ok && cacheable {
+		// The query validations can depend on the db (e.g. the role of WithPolicy), so they are run on every apply
+		tree := p.resolvedTree.Load()
+		queryErrors, err := p.builder.runQueryValidations(tree, db, nil)
+		if err == nil && len(queryErrors) > 0 {
+			err = queryErrors
+		}
+		if err != nil {
+			return db, nil, err
+		}
+
+		return db.Clauses(clause.Where{Exprs: cloneConditions(conditions)}), tree, nil
+	}
+
+	existingConditions := len(whereConditions(db))
+	result, tree, err := p.builder.buildSyntaxTree(cloneTree(p.tree), db, translation, nil)
+	if err != nil {
+		return result, nil, err
+	}
+
+	// The conditions are copied before the query runs, since the callbacks replace the nested filters in place
+	p.resolvedTree.CompareAndSwap(nil, tree)
+	if cacheable {
+		p.conditions.Store(key, cloneConditions(whereConditions(result)[existingConditions:]))
+	}
+
+	return result, tree, nil
+}
+
+// whereConditions
+// returns the conditions of the where clause of the db
+func whereConditions(db *gorm.DB) []clause.Expression {
+	where, _ := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+
+	return where.Exprs
+}
+
+// cloneConditions
+// returns a copy of the conditions with copies of all nested condition lists,
+// so the callbacks that replace conditions in place (e.g. nested filters, gormqonvert) do not change the original
+func cloneConditions(conditions []clause.Expression) []clause.Expression {
+	clones := make([]clause.Expression, len(conditions))
+	for index, condition := range conditions {
+		switch condition := condition.(type) {
+		case clause.AndConditions:
+			clones[index] = clause.AndConditions{Exprs: cloneConditions(condition.Exprs)}
+		case clause.OrConditions:
+			clones[index] = clause.OrConditions{Exprs: cloneConditions(condition.Exprs)}
+		case clause.NotConditions:
+			clones[index] = clause.NotConditions{Exprs: cloneConditions(condition.Exprs)}
+		default:
+			clones[index] = condition
+		}
+	}
+
+	return clones
+}
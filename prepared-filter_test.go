@@ -0,0 +1,352 @@
+package gormodata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func Test_PreparedFilter_Apply(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		statement   func(tx *gorm.DB) *gorm.DB
+		expectedSql string
+	}{
+		"comparison": {
+			queryString: "name eq 'test'",
+			statement: func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&MockModel{})
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"or with functions": {
+			queryString: "contains(name,'test') or length(testValue) gt 3",
+			statement: func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&MockModel{})
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name LIKE \"%test%\" OR LENGTH(test_value) > 3",
+		},
+		"negated relation filter": {
+			queryString: "not(metadata/name eq 'prd') and id eq '885B50A8-F2D2-4FC2-B8E8-4DB54F5EF5B6'",
+			statement: func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&MockModel{})
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE (metadata_id IS NULL OR metadata_id NOT IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")) AND id = \"885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6\"",
+		},
+		"existing conditions": {
+			queryString: "name eq 'test' and metadata/name eq 'prd'",
+			statement: func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&MockModel{}).Where("test_value = ?", "tenant")
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"tenant\" AND name = \"test\" AND metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+			builder := New(WithDatabaseType(SQLite))
+			prepared, err := builder.Prepare(testData.queryString)
+			assert.NoError(t, err)
+
+			// Act
+			sqlQueries := make([]string, 3)
+			for i := range sqlQueries {
+				sqlQueries[i] = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err := prepared.Apply(testData.statement(tx))
+					assert.NoError(t, err)
+					return dbQuery.Find(&[]MockModel{})
+				})
+			}
+			builtSql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, _ := builder.Build(testData.queryString, testData.statement(tx))
+				return dbQuery.Find(&[]MockModel{})
+			})
+
+			// Assert
+			for _, sqlQuery := range sqlQueries {
+				assert.Equal(t, testData.expectedSql, sqlQuery)
+			}
+			assert.Equal(t, builtSql, sqlQueries[0])
+		})
+	}
+}
+
+func Test_PreparedFilter_TranslatedOncePerDB(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	var validations atomic.Int32
+	countValidations := func(*syntaxtree.SyntaxTree, *gorm.DB) error {
+		validations.Add(1)
+		return nil
+	}
+
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	otherDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_other"))
+	_ = otherDB.AutoMigrate(&MockModel{}, &Metadata{})
+
+	prepared, err := New(WithDatabaseType(SQLite), WithQueryValidations(countValidations)).Prepare("name eq 'test'")
+	assert.NoError(t, err)
+
+	// Act
+	for range 3 {
+		_, _ = prepared.Apply(db.Model(&MockModel{}))
+	}
+	_, _ = prepared.Apply(db.Model(&Metadata{}))
+	_, _ = prepared.Apply(otherDB.Model(&MockModel{}))
+	_, _ = prepared.Apply(db.Model(&MockModel{}).Set(NullSafeSetting, true))
+
+	// Assert
+	translations := 0
+	prepared.conditions.Range(func(preparedFilterKey, []clause.Expression) bool {
+		translations++
+		return true
+	})
+	assert.Equal(t, 4, translations)
+	// The query validations can depend on the db, so they run on every apply
+	assert.Equal(t, int32(6), validations.Load())
+}
+
+func Test_PreparedFilter_PartialFiltersReused(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		options              []Option
+		expectedTranslations int
+	}{
+		"without query validations": {
+			expectedTranslations: 1,
+		},
+		"with query validations": {
+			options:              []Option{WithAllowedFields("name")},
+			expectedTranslations: 0,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Member{})
+			db.Create(&[]Member{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}})
+			builder := New(append([]Option{WithDatabaseType(SQLite), WithPartialFilters()}, testData.options...)...)
+			prepared, err := builder.Prepare("name eq 'Alice' and color eq 'brown'")
+			assert.NoError(t, err)
+
+			// Act
+			results := [][]Member{}
+			for range 3 {
+				var members []Member
+				dbQuery, err := prepared.Apply(db.Model(&Member{}))
+				assert.NoError(t, err)
+				assert.NoError(t, dbQuery.Find(&members).Error)
+				results = append(results, members)
+			}
+
+			// Assert
+			translations := 0
+			prepared.conditions.Range(func(preparedFilterKey, []clause.Expression) bool {
+				translations++
+				return true
+			})
+			assert.Equal(t, testData.expectedTranslations, translations)
+			for _, members := range results {
+				assert.Equal(t, []Member{{ID: 1, Name: "Alice"}}, members)
+			}
+		})
+	}
+}
+
+func Test_PreparedFilter_ApplyPolicyPerRole(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	builder := New(WithDatabaseType(SQLite), WithPolicy(Policy{
+		Role: func(db *gorm.DB) string {
+			role, _ := db.Statement.Context.Value(roleContextKey{}).(string)
+
+			return role
+		},
+		Roles: map[string]RolePolicy{
+			"admin": {Properties: []string{PolicyWildcard}, Operators: []string{PolicyWildcard}},
+			"user":  {Properties: []string{"name"}, Operators: []string{"eq"}},
+		},
+	}))
+	prepared, err := builder.Prepare("testValue eq 'secret'")
+	assert.NoError(t, err)
+
+	adminDB := db.WithContext(context.WithValue(context.Background(), roleContextKey{}, "admin"))
+	userDB := db.WithContext(context.WithValue(context.Background(), roleContextKey{}, "user"))
+
+	// Act
+	_, adminErr := prepared.Apply(adminDB.Model(&MockModel{}))
+	_, userErr := prepared.Apply(userDB.Model(&MockModel{}))
+
+	// Assert
+	assert.NoError(t, adminErr)
+	assert.EqualError(t, userErr, "invalid query: field 'testValue' is not allowed for role 'user'")
+	assert.True(t, errors.Is(userErr, ErrFieldNotAllowed))
+}
+
+func Test_PreparedFilter_Invalidate(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	var validations atomic.Int32
+	countValidations := func(*syntaxtree.SyntaxTree, *gorm.DB) error {
+		validations.Add(1)
+		return nil
+	}
+
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+
+	prepared, err := New(WithDatabaseType(SQLite), WithQueryValidations(countValidations)).Prepare("name eq 'test'")
+	assert.NoError(t, err)
+	_, _ = prepared.Apply(db.Model(&MockModel{}))
+
+	// Act
+	prepared.Invalidate()
+	result, err := prepared.Apply(db.Model(&MockModel{}))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, result.Find(&[]MockModel{}).Error)
+	assert.Equal(t, int32(2), validations.Load())
+}
+
+func Test_PreparedFilter_Concurrent(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	metadataID := uuid.MustParse("1ea3cf2f-5c1f-47c6-b0c3-78f0cee2007b")
+	db.Create(&MockModel{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "first", MetadataID: &metadataID, Metadata: &Metadata{ID: metadataID, Name: "prd"}})
+	db.Create(&MockModel{ID: uuid.MustParse("d8c9b566-f711-4113-8a86-a07fa470e43a"), Name: "second"})
+
+	prepared, err := New(WithDatabaseType(SQLite)).Prepare("metadata/name eq 'prd' or id eq 'D8C9B566-F711-4113-8A86-A07FA470E43A'")
+	assert.NoError(t, err)
+
+	// Act
+	results := make(chan []MockModel, 20)
+	errs := make(chan error, 20)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var result []MockModel
+			dbQuery, err := prepared.Apply(db)
+			if err == nil {
+				err = dbQuery.Find(&result).Error
+			}
+			errs <- err
+			results <- result
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(results)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	for result := range results {
+		assert.Len(t, result, 2)
+	}
+}
+
+func Test_Builder_PrepareError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		opts          []Option
+		queryString   string
+		expectedError error
+	}{
+		"invalid syntax": {
+			queryString:   "name eq 'test' and",
+			expectedError: ErrInvalidSyntax,
+		},
+		"disabled function": {
+			opts:          []Option{WithDisabledFunctions("length")},
+			queryString:   "length(name) gt 3",
+			expectedError: ErrFunctionNotAllowed,
+		},
+		"too long": {
+			opts:          []Option{WithMaxLength(10)},
+			queryString:   "name eq 'test'",
+			expectedError: ErrComplexityExceeded,
+		},
+		"collected disabled functions": {
+			opts:          []Option{WithDisabledFunctions("length", "trim"), WithCollectAllErrors()},
+			queryString:   "length(name) gt 3 and trim(name) eq 'test'",
+			expectedError: ErrFunctionNotAllowed,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			builder := New(testData.opts...)
+
+			// Act
+			prepared, err := builder.Prepare(testData.queryString)
+
+			// Assert
+			assert.Nil(t, prepared)
+			assert.True(t, errors.Is(err, testData.expectedError))
+		})
+	}
+}
+
+func Test_PreparedFilter_ApplyError(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	prepared, err := New(WithDatabaseType(SQLite), WithAllowedFields("name")).Prepare("testValue eq 'test'")
+	assert.NoError(t, err)
+
+	// Act
+	_, firstErr := prepared.Apply(db)
+	_, secondErr := prepared.Apply(db)
+
+	// Assert
+	assert.True(t, errors.Is(firstErr, ErrFieldNotAllowed))
+	assert.True(t, errors.Is(secondErr, ErrFieldNotAllowed))
+}
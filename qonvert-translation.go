@@ -0,0 +1,208 @@
+package gormodata
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/survivorbat/go-tsyncmap"
+	gormqonvert "github.com/survivorbat/gorm-query-convert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// gormqonvertPluginName
+// is the name gormqonvert registers its plugin with
+var gormqonvertPluginName = gormqonvert.New(gormqonvert.CharacterConfig{}).Name()
+
+// defaultQonvertConfig
+// is the gormqonvert config that is registered on dbs without gormqonvert
+var defaultQonvertConfig = gormqonvert.CharacterConfig{
+	GreaterThanPrefix:      ">",
+	GreaterOrEqualToPrefix: ">=",
+	LessThanPrefix:         "<",
+	LessOrEqualToPrefix:    "<=",
+	NotEqualToPrefix:       "!=",
+	LikePrefix:             "~",
+	NotLikePrefix:          "!~",
+}
+
+// defaultQonvertTranslation
+// is the translation of the default gormqonvert config
+var defaultQonvertTranslation = newQonvertTranslation(defaultQonvertConfig)
+
+// qonvertTranslations
+// holds the translation of the config of every gormqonvert plugin that was registered by this package (see checkDbPlugins),
+// gormqonvert does not expose its config, so the configs of plugins registered outside of this package are unknown, the configs of builders are never stored
+var qonvertTranslations = tsyncmap.Map[gorm.Plugin, *qonvertTranslation]{}
+
+// qonvertTranslationSetting
+// is the gorm setting that holds the translation of the gormqonvert config of the Builder that built the query (see WithQonvertConfig),
+// so the nested filters it built are converted with the same prefixes
+const qonvertTranslationSetting = "gormodata:qonvert_translation"
+
+// errUnknownQonvertConfig
+// is returned when gormqonvert was registered on the db outside of this package and the config is not given with WithQonvertConfig
+var errUnknownQonvertConfig = errors.New("the config of the gormqonvert plugin that was registered outside of gormodata is unknown, use WithQonvertConfig")
+
+// queryTranslation
+// holds everything that is needed to translate a filter into a gorm query,
+// it is created for every build so builds on dbs with different configs do not share any state
+type queryTranslation struct {
+	databaseType DbType
+
+	// namer is the naming strategy of the db that translates properties into column names
+	namer schema.Namer
+
+	// columnNames remembers the column names of the builder, nil if every property is translated by the namer,
+	// columnKey identifies the db and the model of the build in it
+	columnNames *columnNameCache
+	columnKey   columnNameKey
+
+	qonvert *qonvertTranslation
+
+	// nullSafe enables the null-aware comparisons (see NullSafeSetting)
+	nullSafe bool
+
+	// inListChunkSize is the maximum number of values of an IN list (see WithInListChunkSize), 0 for the default
+	inListChunkSize int
+
+	// model is the model of the db the filter is built on, to find the relations of lambdas, nil if the db has no model
+	model any
+
+	// lambda is the innermost lambda whose condition is being built, nil outside of lambdas
+	lambda *lambdaScope
+
+	// table is the table of the model that its properties are qualified with inside of lambdas, empty if it is not known
+	table string
+
+	// rootEntitySets are the models of the entity sets that filters can refer to with $root (see WithRootEntitySets)
+	rootEntitySets map[string]any
+
+	// enums are the members of the flag enum types that filters can check with 'has' (see WithEnum)
+	enums map[string]map[string]int64
+
+	// dateTimeOffsetMode sets how datetimeoffset literals are compared (see WithDateTimeOffsetMode)
+	dateTimeOffsetMode DateTimeOffsetMode
+
+	// fractionalSecondsPrecision sets the unit of fractionalsecond (see WithFractionalSecondsPrecision)
+	fractionalSecondsPrecision FractionalSecondsPrecision
+
+	// caseInsensitiveFields are the fields whose equality comparisons ignore the case (see WithCaseInsensitiveFields)
+	caseInsensitiveFields []string
+
+	// now is the time now() is evaluated to when the filter is built (see WithServerNow), nil if the database evaluates it
+	now *time.Time
+}
+
+// newQueryTranslation
+// creates the query translation for a build on the db
+func newQueryTranslation(db *gorm.DB, databaseType DbType, qonvert *qonvertTranslation, columnNames *columnNameCache) *queryTranslation {
+	nullSafe, _ := db.Get(NullSafeSetting)
+	nullSafeEnabled, _ := nullSafe.(bool)
+
+	return &queryTranslation{
+		databaseType: databaseType,
+		namer:        db.NamingStrategy,
+		model:        db.Statement.Model,
+		columnNames:  columnNames,
+		columnKey: columnNameKey{
+			callbacks: db.Callback(),
+			model:     reflect.TypeOf(db.Statement.Model),
+		},
+		qonvert:  qonvert,
+		nullSafe: nullSafeEnabled,
+	}
+}
+
+// columnName
+// translates a property into a column name with the naming strategy of the db,
+// or into the value of the element of a lambda it is filtered on in (see buildLambda),
+// inside of lambdas the columns of the model are qualified with its table so the tables of the lambdas do not shadow them
+func (t *queryTranslation) columnName(property string) string {
+	if column, ok := t.elementColumn(property); ok {
+		return column
+	}
+
+	property = modelProperty(property)
+	var column string
+	if t.columnNames == nil {
+		column = t.namer.ColumnName("", property)
+	} else {
+		key := t.columnKey
+		key.property = property
+		column = t.columnNames.columnName(key, t.namer)
+	}
+	if t.lambda != nil && t.table != "" {
+		return t.table + "." + column
+	}
+
+	return column
+}
+
+// qonvertTranslation
+// holds the gormqonvert prefixes of the operators of nested filters (e.g. {"name": "!=test"}) for one gormqonvert config
+type qonvertTranslation struct {
+	config gormqonvert.CharacterConfig
+
+	prefixes map[string]string
+}
+
+// newQonvertTranslation
+// maps the operators on the prefixes of the gormqonvert config
+func newQonvertTranslation(config gormqonvert.CharacterConfig) *qonvertTranslation {
+	return &qonvertTranslation{
+		config: config,
+		prefixes: map[string]string{
+			"gt":         config.GreaterThanPrefix,
+			"ge":         config.GreaterOrEqualToPrefix,
+			"lt":         config.LessThanPrefix,
+			"le":         config.LessOrEqualToPrefix,
+			"ne":         config.NotEqualToPrefix,
+			"contains":   config.LikePrefix,
+			"startswith": config.LikePrefix,
+			"endswith":   config.LikePrefix,
+		},
+	}
+}
+
+// qonvertTranslationOf
+// returns the translation of the gormqonvert config of the Builder that built the query on the db (see WithQonvertConfig),
+// or of the registered gormqonvert plugin if the Builder has no config
+func qonvertTranslationOf(db *gorm.DB) (*qonvertTranslation, error) {
+	if setting, ok := db.Get(qonvertTranslationSetting); ok {
+		if translation, ok := setting.(*qonvertTranslation); ok {
+			return translation, nil
+		}
+	}
+
+	return registeredQonvertTranslation(db)
+}
+
+// applyQonvertConfig
+// passes the gormqonvert config of the builder on to the nested filters of the built query (see WithQonvertConfig)
+func (b *Builder) applyQonvertConfig(db *gorm.DB) *gorm.DB {
+	if b.qonvert == nil {
+		return db
+	}
+
+	return db.Set(qonvertTranslationSetting, b.qonvert)
+}
+
+// registeredQonvertTranslation
+// returns the translation of the gormqonvert config of the db, the default translation if gormqonvert is not registered,
+// or errUnknownQonvertConfig if it was registered outside of this package
+func registeredQonvertTranslation(db *gorm.DB) (*qonvertTranslation, error) {
+	plugin, ok := db.Plugins[gormqonvertPluginName]
+	if !ok {
+		return defaultQonvertTranslation, nil
+	}
+
+	translation, ok := qonvertTranslations.Load(plugin)
+	qonvertTranslationCounters.record(ok)
+	if !ok {
+		return nil, errUnknownQonvertConfig
+	}
+
+	return translation, nil
+}
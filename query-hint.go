@@ -0,0 +1,148 @@
+package gormodata
+
+import (
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueryHint
+// is a dialect-specific hint that is added to the query a filter is built on (see WithQueryHints),
+// create it with IndexHint, OptimizerHint or QueryOption
+type QueryHint struct {
+	// clause is the name of the clause the hint is added to
+	clause string
+
+	// afterName adds the hint right after the name of the clause (e.g. SELECT /*+ ... */) instead of after the clause
+	afterName bool
+
+	sql string
+}
+
+// IndexHint
+// adds the hint after the table of the query, e.g. the index hints of MySQL or the table hints of SQL Server
+//
+//	gormodata.IndexHint("USE INDEX (idx_pets_name)")  // SELECT * FROM `pets` USE INDEX (idx_pets_name) WHERE ...
+//	gormodata.IndexHint("WITH (INDEX(idx_pets_name))") // SELECT * FROM "pets" WITH (INDEX(idx_pets_name)) WHERE ...
+func IndexHint(hint string) QueryHint {
+	return QueryHint{clause: "FROM", sql: hint}
+}
+
+// OptimizerHint
+// adds the hint as an optimizer hint comment after SELECT, e.g. for MySQL, Oracle or pg_hint_plan on PostgreSQL
+//
+//	gormodata.OptimizerHint("MAX_EXECUTION_TIME(1000)") // SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `pets` WHERE ...
+func OptimizerHint(hint string) QueryHint {
+	return QueryHint{clause: "SELECT", afterName: true, sql: "/*+ " + hint + " */"}
+}
+
+// QueryOption
+// adds the option at the end of the query, e.g. the query hints of SQL Server
+//
+//	gormodata.QueryOption("OPTION (RECOMPILE)") // SELECT * FROM "pets" WHERE ... OPTION (RECOMPILE)
+func QueryOption(option string) QueryHint {
+	return QueryHint{clause: "FOR", sql: option}
+}
+
+func (h QueryHint) Build(builder clause.Builder) {
+	_, _ = builder.WriteString(h.sql)
+}
+
+// ModifyStatement
+// adds the hint to its clause of the statement, next to the hints that are already there
+func (h QueryHint) ModifyStatement(stmt *gorm.Statement) {
+	hintClause := stmt.Clauses[h.clause]
+	if h.afterName {
+		hintClause.AfterNameExpression = appendHint(hintClause.AfterNameExpression, h)
+	} else {
+		hintClause.AfterExpression = appendHint(hintClause.AfterExpression, h)
+	}
+
+	// The last clause (FOR) is only written when it locks, so it needs its own builder to write the options without locking
+	if h.clause == "FOR" && hintClause.Builder == nil {
+		hintClause.Builder = buildQueryOptions
+	}
+
+	stmt.Clauses[h.clause] = hintClause
+}
+
+// hints
+// are the hints of a clause, separated by spaces
+type hints []clause.Expression
+
+func (h hints) Build(builder clause.Builder) {
+	for index, hint := range h {
+		if index > 0 {
+			_ = builder.WriteByte(' ')
+		}
+		hint.Build(builder)
+	}
+}
+
+// appendHint
+// adds the hint to the expression of the clause, an expression that is not a hint (e.g. of gorm.io/hints) is kept in front of it
+func appendHint(expression clause.Expression, hint clause.Expression) clause.Expression {
+	switch expression := expression.(type) {
+	case nil:
+		return hints{hint}
+	case hints:
+		return append(expression[:len(expression):len(expression)], hint)
+	default:
+		return hints{expression, hint}
+	}
+}
+
+// buildQueryOptions
+// builds the FOR clause with the query options after it, or only the query options when the query does not lock
+func buildQueryOptions(forClause clause.Clause, builder clause.Builder) {
+	if forClause.Expression != nil {
+		forClause.Builder = nil
+		forClause.Build(builder)
+
+		return
+	}
+
+	if forClause.AfterExpression != nil {
+		forClause.AfterExpression.Build(builder)
+	}
+}
+
+// queryHintRule
+// holds the hints that are added to queries built from filters the predicate matches (see WithQueryHints)
+type queryHintRule struct {
+	match func(filter *Expr) bool
+	hints []clause.Expression
+}
+
+// WithQueryHints
+// adds the hints to queries that are built from filters the predicate matches, so DBAs can tune the queries of hot endpoints,
+// a nil predicate matches every filter
+//
+//	gormodata.WithQueryHints(func(filter *gormodata.Expr) bool {
+//		return slices.Contains(filter.Properties(), "name")
+//	}, gormodata.IndexHint("USE INDEX (idx_pets_name)"))
+//
+// the hints are gorm clause expressions, so next to QueryHint the hints of gorm.io/hints can be used as well,
+// the predicate gets the filter after the field aliases are resolved (see WithFieldAliases)
+func WithQueryHints(match func(filter *Expr) bool, hints ...clause.Expression) Option {
+	return func(b *Builder) {
+		b.queryHints = append(b.queryHints, queryHintRule{match: match, hints: hints})
+	}
+}
+
+// applyQueryHints
+// adds the hints of the rules that match the filter of the tree to the db
+func (b *Builder) applyQueryHints(db *gorm.DB, tree *syntaxtree.SyntaxTree) *gorm.DB {
+	if len(b.queryHints) == 0 || tree == nil || tree.Root == nil {
+		return db
+	}
+
+	filter := newExpr(tree.Root)
+	for _, rule := range b.queryHints {
+		if rule.match == nil || rule.match(filter) {
+			db = db.Clauses(rule.hints...)
+		}
+	}
+
+	return db
+}
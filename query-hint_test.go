@@ -0,0 +1,154 @@
+package gormodata
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func Test_Builder_WithQueryHints(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	filtersOnName := func(filter *Expr) bool {
+		return slices.Contains(filter.Properties(), "name")
+	}
+
+	tests := map[string]struct {
+		queryString string
+		options     []Option
+		expectedSql string
+	}{
+		"index hint": {
+			queryString: "name eq 'test'",
+			options:     []Option{WithQueryHints(filtersOnName, IndexHint("USE INDEX (idx_name)"))},
+			expectedSql: "SELECT * FROM `mock_models` USE INDEX (idx_name) WHERE name = \"test\"",
+		},
+		"optimizer hint": {
+			queryString: "name eq 'test'",
+			options:     []Option{WithQueryHints(nil, OptimizerHint("MAX_EXECUTION_TIME(1000)"))},
+			expectedSql: "SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"query option": {
+			queryString: "name eq 'test'",
+			options:     []Option{WithQueryHints(nil, QueryOption("OPTION (RECOMPILE)"))},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\" OPTION (RECOMPILE)",
+		},
+		"hints of several rules": {
+			queryString: "name eq 'test'",
+			options: []Option{
+				WithQueryHints(filtersOnName, IndexHint("USE INDEX (idx_name)")),
+				WithQueryHints(nil, IndexHint("IGNORE INDEX (idx_test_value)"), QueryOption("OPTION (RECOMPILE)")),
+			},
+			expectedSql: "SELECT * FROM `mock_models` USE INDEX (idx_name) IGNORE INDEX (idx_test_value) WHERE name = \"test\" OPTION (RECOMPILE)",
+		},
+		"predicate does not match": {
+			queryString: "testValue eq 'test'",
+			options:     []Option{WithQueryHints(filtersOnName, IndexHint("USE INDEX (idx_name)"))},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"test\"",
+		},
+		"predicate gets resolved aliases": {
+			queryString: "title eq 'test'",
+			options: []Option{
+				WithFieldAliases(map[string]string{"title": "name"}),
+				WithQueryHints(filtersOnName, IndexHint("USE INDEX (idx_name)")),
+			},
+			expectedSql: "SELECT * FROM `mock_models` USE INDEX (idx_name) WHERE name = \"test\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{})
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				tx, err = builder.Build(testData.queryString, tx)
+
+				return tx.Find(&MockModel{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_WithQueryHints_ScopeAndPreparedFilter(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	builder := New(WithDatabaseType(SQLite), WithQueryHints(nil, IndexHint("USE INDEX (idx_name)")))
+	preparedFilter, err := builder.Prepare("name eq 'test'")
+	assert.NoError(t, err)
+	expectedSql := "SELECT * FROM `mock_models` USE INDEX (idx_name) WHERE name = \"test\""
+
+	// Act
+	scopeSql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(builder.Scope("name eq 'test'")).Find(&MockModel{})
+	})
+	preparedSqls := []string{}
+	for range 2 {
+		preparedSqls = append(preparedSqls, db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			tx, err = preparedFilter.Apply(tx.Model(&MockModel{}))
+
+			return tx.Find(&MockModel{})
+		}))
+	}
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSql, scopeSql)
+	assert.Equal(t, []string{expectedSql, expectedSql}, preparedSqls)
+}
+
+func Test_QueryOption_Locking(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		locking     bool
+		expectedSql string
+	}{
+		"without locking": {
+			expectedSql: "OPTION (RECOMPILE)",
+		},
+		"with locking": {
+			locking:     true,
+			expectedSql: "FOR UPDATE OPTION (RECOMPILE)",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			statement := &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+			QueryOption("OPTION (RECOMPILE)").ModifyStatement(statement)
+			if testData.locking {
+				statement.AddClause(clause.Locking{Strength: clause.LockingStrengthUpdate})
+			}
+
+			// Act
+			statement.Clauses["FOR"].Build(statement)
+
+			// Assert
+			assert.Equal(t, testData.expectedSql, statement.SQL.String())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// regexReplaceFunction
+// is the extension function that replaces the matches of a regular expression in a column (see WithExtensionFunctions)
+const regexReplaceFunction = "regexreplace"
+
+// regexReplaceArguments
+// is the inner binary function that holds the pattern and the replacement of regexreplace, since functions have at most two arguments,
+// it is added by the lexer (see tokenize) and can not be written in a filter
+const regexReplaceArguments = "regexreplace:arguments"
+
+// regexReplaceTranslation
+// replaces every match of a pattern in a column for the database types that have regular expressions built in, %s is the column,
+// SQLite and SQL Server have no REGEXP_REPLACE
+var regexReplaceTranslation = map[DbType]string{
+	PostgreSQL: "REGEXP_REPLACE(%s, ?, ?, 'g')",
+	MySQL:      "REGEXP_REPLACE(%s, ?, ?)",
+}
+
+// regexReplaceCall
+// is a call of regexreplace that is being tokenized, the pattern and the replacement get the brackets of the inner function regexReplaceArguments
+type regexReplaceCall struct {
+	// depth is the bracket depth of the arguments of the call
+	depth int
+
+	// args is the number of arguments that were tokenized so far
+	args int
+}
+
+// argumentsDepth
+// returns the bracket depth of the next argument of the call, the pattern and the replacement are inside the inner function
+func (c *regexReplaceCall) argumentsDepth() int {
+	if c.args > 1 {
+		return c.depth + 1
+	}
+
+	return c.depth
+}
+
+// regexReplaceArgumentsError
+// returns the error of a call of regexreplace that does not have a field, a pattern and a replacement
+func regexReplaceArgumentsError(args int) error {
+	return &syntaxtree.ParseError{Msg: fmt.Sprintf("function regexreplace expects 3 arguments, got %d, expected regexreplace(field,'pattern','replacement')", args)}
+}
+
+// buildRegexReplace
+// builds the replacement of the extension function regexreplace, which is compared with a value,
+// the pattern and the replacement are returned as the values to bind
+//
+//	regexreplace(phone,'[^0-9]','') eq '123'  ->  REGEXP_REPLACE(phone, '[^0-9]', '', 'g') = '123'
+func buildRegexReplace(translation *queryTranslation, node *syntaxtree.Node) (string, []any, error) {
+	queryFormat, ok := regexReplaceTranslation[translation.databaseType]
+	if !ok {
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("function '%s' is only supported on PostgreSQL and MySQL", node.Value),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node,
+		}
+	}
+
+	leftChild, arguments := node.LeftChild, node.RightChild
+	column := ""
+	switch {
+	case leftChild.Type == syntaxtree.UnaryOperator:
+		column = buildUnaryFuncChain(translation, leftChild)
+	case leftChild.Type == syntaxtree.LeftOperand && isPropertyNode(leftChild) && !translation.isRelationPath(leftChild.Value):
+		column = translation.columnName(leftChild.Value)
+	default:
+		return "", nil, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a column of the model as its first argument, got '%s'", node.Value, nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       leftChild,
+		}
+	}
+
+	args := make([]any, 0, 2)
+	for _, argument := range []*syntaxtree.Node{arguments.LeftChild, arguments.RightChild} {
+		if !isStringLiteral(argument.Value) {
+			return "", nil, &InvalidQueryError{
+				Msg:        fmt.Sprintf("'%s' expects string literals as its pattern and replacement, got '%s'", node.Value, nodeExpression(argument)),
+				Err:        ErrUnsupportedOperator,
+				Expression: nodeExpression(node),
+				Node:       argument,
+			}
+		}
+		args = append(args, unquote(argument.Value))
+	}
+
+	return fmt.Sprintf(queryFormat, column), args, nil
+}
+
+// regexReplaceArgumentsNode
+// returns the inner node with the pattern and the replacement of regexreplace, like the parser gives it (see tokenize)
+func regexReplaceArgumentsNode(tree *syntaxtree.SyntaxTree, parent *syntaxtree.Node, args []*Expr) (*syntaxtree.Node, error) {
+	node := &syntaxtree.Node{
+		Id:     len(tree.Nodes),
+		Parent: parent,
+		Value:  regexReplaceArguments,
+		Type:   syntaxtree.Operator,
+	}
+	tree.Nodes = append(tree.Nodes, node)
+
+	var err error
+	if node.LeftChild, err = args[0].node(tree, node, syntaxtree.LeftOperand); err != nil {
+		return nil, err
+	}
+	if node.RightChild, err = args[1].node(tree, node, syntaxtree.RightOperand); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
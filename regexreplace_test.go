@@ -0,0 +1,185 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_RegexReplace(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"column": {
+			queryString: "regexreplace(name,'[^0-9]','') eq '123'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE REGEXP_REPLACE(name, \"[^0-9]\", \"\", 'g') = \"123\"",
+				MySQL:      "SELECT * FROM `members` WHERE REGEXP_REPLACE(name, \"[^0-9]\", \"\") = \"123\"",
+			},
+		},
+		"function": {
+			queryString: "regexreplace(tolower(name),'[a-z]+','x') ne 'x'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE REGEXP_REPLACE(LOWER(name), \"[a-z]+\", \"x\", 'g') != \"x\"",
+				MySQL:      "SELECT * FROM `members` WHERE REGEXP_REPLACE(LOWER(name), \"[a-z]+\", \"x\") != \"x\"",
+			},
+		},
+		"inside of a condition": {
+			queryString: "id gt 1 and (regexreplace(email , '[.]' , '') eq 'a@b' or name eq 'x')",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE id > 1 AND (REGEXP_REPLACE(email, \"[.]\", \"\", 'g') = \"a@b\" OR name = \"x\")",
+				MySQL:      "SELECT * FROM `members` WHERE id > 1 AND (REGEXP_REPLACE(email, \"[.]\", \"\") = \"a@b\" OR name = \"x\")",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType), WithExtensionFunctions("regexreplace"))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Member{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_RegexReplaceErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		databaseType DbType
+		options      []Option
+		expectedErr  string
+		expectedIs   error
+	}{
+		"not enabled": {
+			queryString:  "regexreplace(name,'[^0-9]','') eq '123'",
+			databaseType: PostgreSQL,
+			expectedErr:  "invalid query: function 'regexreplace' is an extension function that is not enabled, enable it with WithExtensionFunctions",
+			expectedIs:   ErrFunctionNotAllowed,
+		},
+		"sqlite": {
+			queryString:  "regexreplace(name,'[^0-9]','') eq '123'",
+			databaseType: SQLite,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "invalid query: function 'regexreplace' is only supported on PostgreSQL and MySQL",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"sqlserver": {
+			queryString:  "regexreplace(name,'[^0-9]','') eq '123'",
+			databaseType: SQLServer,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "invalid query: function 'regexreplace' is only supported on PostgreSQL and MySQL",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"two arguments": {
+			queryString:  "regexreplace(name,'[^0-9]') eq '123'",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "failed to parse query: function regexreplace expects 3 arguments, got 2, expected regexreplace(field,'pattern','replacement')",
+			expectedIs:   ErrInvalidSyntax,
+		},
+		"four arguments": {
+			queryString:  "regexreplace(name,'[^0-9]','','x') eq '123'",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "failed to parse query: function regexreplace expects 3 arguments, got 4, expected regexreplace(field,'pattern','replacement')",
+			expectedIs:   ErrInvalidSyntax,
+		},
+		"root of the filter": {
+			queryString:  "regexreplace(name,'[^0-9]','')",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "invalid query: function 'regexreplace' cannot be the root of a filter, compare it with a string instead (e.g. regexreplace(name,'[^0-9]','') eq '123')",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"right operand": {
+			queryString:  "name eq regexreplace(email,'[^0-9]','')",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "invalid query: function 'regexreplace' cannot be the right operand of 'eq', only values can",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+		"pattern is not a string literal": {
+			queryString:  "regexreplace(name,email,'') eq '123'",
+			databaseType: PostgreSQL,
+			options:      []Option{WithExtensionFunctions("regexreplace")},
+			expectedErr:  "invalid query: 'regexreplace' expects string literals as its pattern and replacement, got 'email'",
+			expectedIs:   ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(testData.databaseType)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Member{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_RegexReplace(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	expr, err := Parse("regexreplace(phone,'[^0-9]','') eq '123'")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Func("regexreplace", Field("phone"), "[^0-9]", "").Eq("123"), expr)
+	assert.Equal(t, "regexreplace(phone,'[^0-9]','') eq '123'", expr.String())
+}
+
+func Test_Builder_BuildExprRegexReplace(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(PostgreSQL), WithExtensionFunctions("regexreplace"), WithQueryValidations(WithInputModelValidation(Member{})))
+
+	// Act
+	var err error
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var dbQuery *gorm.DB
+		dbQuery, err = builder.BuildExpr(Func("regexreplace", Field("name"), "[^0-9]", "").Eq("123"), tx)
+		return dbQuery.Find(&Member{})
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `members` WHERE REGEXP_REPLACE(name, \"[^0-9]\", \"\", 'g') = \"123\"", sqlQuery)
+}
@@ -0,0 +1,203 @@
+package gormodata
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rootReferencePrefix
+// starts the references to an entity of another entity set (see WithRootEntitySets)
+const rootReferencePrefix = "$root/"
+
+// rootReferencePattern
+// matches the references to a property of an entity of another entity set: $root/<entity set>(<key>)/<property>
+var rootReferencePattern = regexp.MustCompile(`^\$root/([A-Za-z_][A-Za-z0-9_]*)\((.+)\)/([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// WithRootEntitySets
+// registers the models of the entity sets that filters can refer to with $root (e.g. "Products": Product{}),
+// so filters can compare with a property of an entity of another entity set, identified by its primary key
+//
+//	builder := gormodata.New(gormodata.WithRootEntitySets(map[string]any{"Products": Product{}}))
+//	dbQuery, err := builder.Build("price gt $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price", db.Model(&Offer{}))
+//
+// the reference is translated into a subquery on the table of the model, it can only be the right operand of a comparison,
+// the field validations (e.g. WithAllowedFields) do not apply to the properties of references, so only register the entity sets clients may read
+func WithRootEntitySets(sets map[string]any) Option {
+	return func(b *Builder) {
+		if b.rootEntitySets == nil {
+			b.rootEntitySets = make(map[string]any, len(sets))
+		}
+		maps.Copy(b.rootEntitySets, sets)
+	}
+}
+
+// isRootReference
+// returns whether the value of a node is a reference to another entity set (e.g. $root/Products('id')/price)
+func isRootReference(value string) bool {
+	return strings.HasPrefix(value, rootReferencePrefix)
+}
+
+// rootReferenceEnd
+// returns the index after the key and the property of a reference to another entity set that starts with the word,
+// the key can contain any character in a string literal (e.g. $root/Products('a (b)')/price)
+func (c *parserConfig) rootReferenceEnd(query string, word string, end int) int {
+	if !isRootReference(word) || end >= len(query) || query[end] != c.lexer.OpenDelimiter {
+		return end
+	}
+
+	i := end + 1
+	for i < len(query) && query[i] != c.lexer.CloseDelimiter {
+		if query[i] != c.lexer.StringDelimiter {
+			i++
+			continue
+		}
+
+		literalEnd, terminated := c.stringLiteralEnd(query, i)
+		if !terminated {
+			return end
+		}
+		i = literalEnd
+	}
+	if i >= len(query) {
+		return end
+	}
+
+	for i++; i < len(query) && !c.isWordEnd(query[i]); i++ {
+	}
+
+	return i
+}
+
+// patternValues
+// returns the parts of the value of a node that are matched against bad patterns (see WithBadPatternValidation),
+// the key of a reference to another entity set and the values of a list (e.g. name in ('a','b')) are matched on their own like any other literal,
+// datetimeoffset literals are not matched since they can only hold a timestamp (see dateTimeOffsetPattern)
+func patternValues(value string) []string {
+	if isListLiteral(value) {
+		return listLiteralElements(value)
+	}
+	if dateTimeOffsetPattern.MatchString(value) {
+		return nil
+	}
+
+	match := rootReferencePattern.FindStringSubmatch(value)
+	if match == nil {
+		return []string{value}
+	}
+
+	return []string{rootReferencePrefix + match[1] + "/" + match[3], match[2]}
+}
+
+// buildRootReference
+// returns the subquery of the property of the entity of the reference
+//
+//	$root/Products('abc')/price  ->  (SELECT products.price FROM products WHERE products.id = 'abc')
+func buildRootReference(db *gorm.DB, translation *queryTranslation, node *syntaxtree.Node) (clause.Expr, error) {
+	match := rootReferencePattern.FindStringSubmatch(node.Value)
+	if match == nil {
+		return clause.Expr{}, &InvalidQueryError{
+			Msg:        fmt.Sprintf("invalid reference '%s', expected $root/<entity set>(<key>)/<property>", node.Value),
+			Err:        ErrInvalidSyntax,
+			Expression: node.Value,
+			Node:       node,
+		}
+	}
+	set, key, property := match[1], match[2], match[3]
+
+	model, ok := translation.rootEntitySets[set]
+	if !ok {
+		return clause.Expr{}, &InvalidQueryError{
+			Msg:        fmt.Sprintf("unknown entity set '%s'", set),
+			Err:        ErrUnknownProperty,
+			Expression: node.Value,
+			Node:       node,
+		}
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return clause.Expr{}, err
+	}
+
+	field := statement.Schema.LookUpField(translation.namer.ColumnName("", property))
+	if field == nil || field.DBName == "" || IsHiddenField(field) {
+		return clause.Expr{}, &InvalidQueryError{
+			Msg:        fmt.Sprintf("unknown column name '%s' on '%s'", property, set),
+			Err:        ErrUnknownProperty,
+			Expression: node.Value,
+			Node:       node,
+		}
+	}
+
+	if len(statement.Schema.PrimaryFields) != 1 {
+		return clause.Expr{}, &InvalidQueryError{
+			Msg:        fmt.Sprintf("entity set '%s' needs a single primary key to be referenced", set),
+			Err:        ErrUnsupportedOperator,
+			Expression: node.Value,
+			Node:       node,
+		}
+	}
+	primaryField := statement.Schema.PrimaryFields[0]
+
+	keyValue, err := convertLiteral(primaryField, literalValue(key))
+	if err != nil {
+		return clause.Expr{}, err
+	}
+
+	table := statement.Schema.Table
+
+	return clause.Expr{
+		SQL: "(SELECT ? FROM ? WHERE ? = ?)",
+		Vars: []any{
+			clause.Column{Table: table, Name: field.DBName},
+			clause.Table{Name: table},
+			clause.Column{Table: table, Name: primaryField.DBName},
+			keyValue,
+		},
+	}, nil
+}
+
+// checkRootReferences
+// checks that the references to other entity sets in the condition are the right operand of a comparison,
+// references cannot be properties, function arguments or patterns
+func checkRootReferences(root *syntaxtree.Node) error {
+	stack := []*syntaxtree.Node{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil {
+			continue
+		}
+		stack = append(stack, node.LeftChild, node.RightChild)
+
+		if !isRootReference(node.Value) || node.Type == syntaxtree.Operator || node.Type == syntaxtree.UnaryOperator {
+			continue
+		}
+		parent := node.Parent
+		if parent != nil && parent.Type == syntaxtree.Operator && parent.RightChild == node && slices.Contains(comparisonOperators, parent.Value) {
+			continue
+		}
+
+		return rootReferenceError(node, fmt.Sprintf("reference '%s' can only be the right operand of a comparison", node.Value))
+	}
+
+	return nil
+}
+
+// rootReferenceError
+// returns the error of a reference that cannot be used where it is
+func rootReferenceError(node *syntaxtree.Node, msg string) error {
+	return &InvalidQueryError{
+		Msg:        msg,
+		Err:        ErrUnsupportedOperator,
+		Expression: nodeExpression(node.Parent),
+		Node:       node,
+	}
+}
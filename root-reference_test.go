@@ -0,0 +1,171 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Product struct {
+	ID    uuid.UUID
+	Name  string
+	Price int
+}
+
+type Brand struct {
+	Code    string `gorm:"primaryKey"`
+	Country string
+}
+
+type Offer struct {
+	ID     uuid.UUID
+	Seller string
+	Price  int
+}
+
+func Test_Builder_RootReference(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"comparison with a property of another entity set": {
+			queryString: "price lt $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price",
+			expectedSql: "SELECT * FROM `offers` WHERE price < (SELECT `products`.`price` FROM `products` WHERE `products`.`id` = \"885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6\")",
+		},
+		"key with brackets": {
+			queryString: "seller eq $root/Brands('a (b)')/country and price gt 5",
+			expectedSql: "SELECT * FROM `offers` WHERE seller = (SELECT `brands`.`country` FROM `brands` WHERE `brands`.`code` = \"a (b)\") AND price > 5",
+		},
+		"negated": {
+			queryString: "not(price ge $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price)",
+			expectedSql: "SELECT * FROM `offers` WHERE price < (SELECT `products`.`price` FROM `products` WHERE `products`.`id` = \"885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6\")",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithRootEntitySets(map[string]any{"Products": Product{}, "Brands": Brand{}}))
+
+			// Act
+			var dbQuery *gorm.DB
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				dbQuery, err = builder.Build(testData.queryString, tx.Model(&Offer{}))
+				return dbQuery.Find(&Offer{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_RootReferenceResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Product{}, &Offer{})
+	db.Create(&Product{ID: uuid.MustParse("885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6"), Name: "lamp", Price: 40})
+	db.Create(&Offer{ID: uuid.New(), Seller: "cheap", Price: 30})
+	db.Create(&Offer{ID: uuid.New(), Seller: "expensive", Price: 50})
+
+	builder := New(WithDatabaseType(SQLite), WithRootEntitySets(map[string]any{"Products": &Product{}}))
+
+	// Act
+	dbQuery, err := builder.Build("price lt $root/Products('885B50A8-F2D2-4FC2-B8E8-4DB54F5EF5B6')/price", db.Model(&Offer{}))
+
+	// Assert
+	assert.NoError(t, err)
+
+	var result []Offer
+	assert.NoError(t, dbQuery.Find(&result).Error)
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, "cheap", result[0].Seller)
+	}
+}
+
+func Test_Builder_RootReferenceErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"unknown entity set": {
+			queryString: "price lt $root/Orders('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price",
+			expectedErr: "invalid query: unknown entity set 'Orders'",
+			expectedIs:  ErrUnknownProperty,
+		},
+		"unknown property": {
+			queryString: "price lt $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/weight",
+			expectedErr: "invalid query: unknown column name 'weight' on 'Products'",
+			expectedIs:  ErrUnknownProperty,
+		},
+		"missing key": {
+			queryString: "price lt $root/Products/price",
+			expectedErr: "invalid query: invalid reference '$root/Products/price', expected $root/<entity set>(<key>)/<property>",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"left operand": {
+			queryString: "$root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price gt 5",
+			expectedErr: "invalid query: reference '$root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/price' can only be the right operand of a comparison",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"function argument": {
+			queryString: "contains(seller, $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/name)",
+			expectedErr: "invalid query: reference '$root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/name' can only be the right operand of a comparison",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"relation property": {
+			queryString: "metadata/name eq $root/Products('885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6')/name",
+			expectedErr: "invalid query: relation property 'metadata/name' cannot be compared with a reference",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithRootEntitySets(map[string]any{"Products": Product{}}))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Offer{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_RootReference(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("price lt $root/Products('a b')/price")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Expr{Kind: ComparisonExpr, Op: "lt", Args: []*Expr{exprProperty("price"), exprProperty("$root/Products('a b')/price")}}, expr)
+	assert.Equal(t, "price lt $root/Products('a b')/price", expr.String())
+}
@@ -0,0 +1,60 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// roundFunction
+// rounds a number, to whole numbers with one argument (round(price)) or to a number of decimals with a precision (round(price,2))
+const roundFunction = "round"
+
+// roundTranslation
+// rounds an operand to a number of decimals for every database type, the first %s is the operand and the %d the precision,
+// PostgreSQL only rounds numerics to decimals, so the operand is cast in case it is a double precision
+var roundTranslation = map[DbType]string{
+	PostgreSQL: "ROUND(CAST(%s AS numeric), %d)",
+	MySQL:      "ROUND(%s, %d)",
+	SQLite:     "ROUND(%s, %d)",
+	SQLServer:  "ROUND(%s, %d)",
+}
+
+// roundCall
+// is a call of round that is being tokenized, round becomes a binary function when it has a precision (see tokenize)
+type roundCall struct {
+	// depth is the bracket depth of the arguments of the call
+	depth int
+
+	// token is the index of the token of round
+	token int
+}
+
+// isRoundNode
+// returns whether the node is a call of round with a precision, round without a precision is a unary function
+func isRoundNode(node *syntaxtree.Node) bool {
+	return node != nil && node.Type == syntaxtree.Operator && node.Value == roundFunction
+}
+
+// buildRound
+// builds the rounding of the operand of round to the precision
+//
+//	round(price,2) eq 19.99  ->  ROUND(price, 2) = 19.99
+func buildRound(translation *queryTranslation, node *syntaxtree.Node) (string, error) {
+	precision, ok := integerLiteral(node.RightChild.Value)
+	if !ok {
+		return "", &InvalidQueryError{
+			Msg:        fmt.Sprintf("precision of '%s' must be an integer, got '%s'", node.Value, nodeExpression(node.RightChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(node),
+			Node:       node.RightChild,
+		}
+	}
+
+	operand, err := buildNumericOperand(translation, node, node.LeftChild)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(roundTranslation[translation.databaseType], operand, precision), nil
+}
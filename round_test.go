@@ -0,0 +1,154 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_RoundPrecision(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"precision": {
+			queryString: "round(amount,2) eq 19.99",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE ROUND(CAST(amount AS numeric), 2) = 19.99",
+				MySQL:      "SELECT * FROM `invoices` WHERE ROUND(amount, 2) = 19.99",
+				SQLite:     "SELECT * FROM `invoices` WHERE ROUND(amount, 2) = 19.99",
+				SQLServer:  "SELECT * FROM `invoices` WHERE ROUND(amount, 2) = 19.99",
+			},
+		},
+		"negative precision of arithmetic": {
+			queryString: "round(amount mul 1.21,-2) ge 100",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE ROUND(CAST((amount * 1.21) AS numeric), -2) >= 100",
+				MySQL:      "SELECT * FROM `invoices` WHERE ROUND((amount * 1.21), -2) >= 100",
+				SQLite:     "SELECT * FROM `invoices` WHERE ROUND((amount * 1.21), -2) >= 100",
+				SQLServer:  "SELECT * FROM `invoices` WHERE ROUND((amount * 1.21), -2) >= 100",
+			},
+		},
+		"without precision": {
+			queryString: "round(amount) eq 20 and greatest(round(amount,1),sequence) gt 5",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `invoices` WHERE ROUND(amount) = 20 AND GREATEST(ROUND(CAST(amount AS numeric), 1), sequence) > 5",
+				MySQL:      "SELECT * FROM `invoices` WHERE ROUND(amount) = 20 AND GREATEST(ROUND(amount, 1), sequence) > 5",
+				SQLite:     "SELECT * FROM `invoices` WHERE ROUND(amount) = 20 AND MAX(ROUND(amount, 1), sequence) > 5",
+				SQLServer:  "SELECT * FROM `invoices` WHERE ROUND(amount) = 20 AND (SELECT MAX(extremum) FROM (VALUES (ROUND(amount, 1)), (sequence)) AS extremums(extremum)) > 5",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(WithDatabaseType(dbType))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Invoice{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_RoundPrecisionResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Invoice{})
+	db.Create(&Invoice{ID: uuid.New(), Number: "a", Amount: 19.987})
+	db.Create(&Invoice{ID: uuid.New(), Number: "b", Amount: 19.9})
+
+	// Act
+	dbQuery, err := New(WithDatabaseType(SQLite)).Build("round(amount,2) eq 19.99", db)
+
+	// Assert
+	assert.NoError(t, err)
+
+	var numbers []string
+	assert.NoError(t, dbQuery.Model(&Invoice{}).Pluck("number", &numbers).Error)
+	assert.Equal(t, []string{"a"}, numbers)
+}
+
+func Test_Builder_RoundPrecisionErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"precision is not an integer": {
+			queryString: "round(amount,1.5) eq 2",
+			expectedErr: "invalid query: precision of 'round' must be an integer, got '1.5'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"string operand": {
+			queryString: "round('1.5',1) eq 2",
+			expectedErr: "invalid query: operand '1.5' of 'round' must be a number, a property of the model or a function",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"root of the filter": {
+			queryString: "round(amount,2)",
+			expectedErr: "invalid query: function 'round' cannot be the root of a filter, compare it with a value instead (e.g. round(amount,2) gt 5)",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"three arguments": {
+			queryString: "round(amount,2,3) eq 2",
+			expectedErr: "failed to parse query: expected closing bracket after binary function round, got \",\"",
+			expectedIs:  ErrInvalidSyntax,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := New(WithDatabaseType(PostgreSQL)).Build(testData.queryString, db.Model(&Invoice{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_RoundPrecision(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	expr, err := Parse("round(amount,2) eq 19.99 and round(amount) lt 5")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Func("round", Field("amount"), 2).Eq(19.99).And(Func("round", Field("amount")).Lt(5)), expr)
+	assert.Equal(t, "round(amount,2) eq 19.99 and round(amount) lt 5", expr.String())
+}
@@ -0,0 +1,92 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// WithModelValidation
+// checks that the properties and relation paths of the filter exist on the gorm schema of the model, like BuildQueryFor does,
+// unlike WithInputModelValidation it follows relations and embedded structs and the model can be a pointer
+//
+//	builder := gormodata.New(gormodata.WithQueryValidations(gormodata.WithModelValidation(&MyModel{})))
+//
+// the schema is parsed with the naming strategy of the db and cached by gorm
+func WithModelValidation(model any) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+
+		return schemaValidation(statement.Schema)(tree, db)
+	}
+}
+
+// schemaValidation
+// returns a QueryValidation that checks that the properties of the filter exist on the gorm schema of the model,
+// relation paths are followed through the relations and embedded structs of the schemas (e.g. "metadata/tag/value")
+func schemaValidation(modelSchema *schema.Schema) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			// The elements of lambdas are checked against the collection, their keys are not part of the schema
+			if !isPropertyNode(currentNode) || inLambdaElement(currentNode) {
+				return nil
+			}
+
+			path := strings.Split(modelProperty(currentNode.Value), "/")
+			for i, part := range path {
+				path[i] = db.NamingStrategy.ColumnName("", part)
+			}
+
+			// The collection of a lambda can also be a relation, its elements are the related rows
+			if isLambdaNode(currentNode.Parent) && currentNode.Parent.LeftChild == currentNode && len(path) == 1 &&
+				findRelationship(db.NamingStrategy, modelSchema, path[0]) != nil {
+				return nil
+			}
+
+			if msg := unknownPropertyPath(db.NamingStrategy, modelSchema, path, false); msg != "" {
+				return &InvalidQueryError{
+					Msg:        msg,
+					Err:        ErrUnknownProperty,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
+
+// unknownPropertyPath
+// returns why the property path (column names) does not exist on the schema, or an empty string if it does
+func unknownPropertyPath(namer schema.Namer, modelSchema *schema.Schema, path []string, related bool) string {
+	if len(path) == 1 {
+		if field := modelSchema.LookUpField(path[0]); field != nil && field.DBName != "" && !IsHiddenField(field) {
+			return ""
+		}
+		if related {
+			return fmt.Sprintf("unknown column name '%s' on '%s'", path[0], modelSchema.Table)
+		}
+
+		return fmt.Sprintf("unknown column name '%s'", path[0])
+	}
+
+	if field := findEmbeddedField(namer, modelSchema, path); field != nil && !IsHiddenField(field) {
+		return ""
+	}
+
+	relationship := findRelationship(namer, modelSchema, path[0])
+	if relationship == nil || IsHiddenField(relationship.Field) {
+		return fmt.Sprintf("unknown relation '%s' on '%s'", path[0], modelSchema.Table)
+	}
+
+	return unknownPropertyPath(namer, relationship.FieldSchema, path[1:], true)
+}
@@ -0,0 +1,72 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_WithModelValidation(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		model       any
+		expectedSql string
+		expectedErr string
+	}{
+		"column of a model": {
+			queryString: "name eq 'test'",
+			model:       MockModel{},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"relation path of a pointer to a model": {
+			queryString: "metadata/tag/value eq 'test'",
+			model:       &MockModel{},
+			expectedSql: "SELECT * FROM `mock_models` WHERE metadata_id IN (SELECT `id` FROM `metadata` WHERE tag_id IN (SELECT `id` FROM `tags` WHERE `tags`.`value` = \"test\"))",
+		},
+		"unknown column": {
+			queryString: "unknown eq 'test'",
+			model:       &MockModel{},
+			expectedErr: "invalid query: unknown column name 'unknown'",
+		},
+		"unknown column on relation": {
+			queryString: "metadata/unknown eq 'test'",
+			model:       &MockModel{},
+			expectedErr: "invalid query: unknown column name 'unknown' on 'metadata'",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{}, &Tag{})
+			builder := New(WithDatabaseType(SQLite), WithQueryValidations(WithModelValidation(testData.model)))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				tx, err = builder.Build(testData.queryString, tx.Model(&MockModel{}))
+
+				return tx.Find(&[]MockModel{})
+			})
+
+			// Assert
+			if testData.expectedErr != "" {
+				assert.EqualError(t, err, testData.expectedErr)
+				assert.True(t, errors.Is(err, ErrUnknownProperty))
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+)
+
+// Scope
+// returns the filter as a gorm scope, so it can be combined with other scopes (e.g. pagination or tenancy)
+//
+//	db.Scopes(gormodata.Scope(queryString, gormodata.PostgreSQL), Paginate(page, pageSize)).Find(&results)
+//
+// the filter is added as one group of conditions, so the conditions of other scopes are not mixed with its 'or' operators,
+// an invalid filter is added as an error to the db, it is returned by the finisher (e.g. Find) and no query is executed
+func Scope(query string, dbType DbType) func(*gorm.DB) *gorm.DB {
+	return New(WithDatabaseType(dbType)).Scope(query)
+}
+
+// Scope
+// returns the filter as a gorm scope that is built with the configuration of the builder (see Scope)
+func (b *Builder) Scope(query string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		filter, tree, err := b.build(query, db.Session(&gorm.Session{NewDB: true}))
+		if err != nil {
+			_ = db.AddError(err)
+
+			return db
+		}
+
+		// Only the conditions of the filter are taken over by Where, so the query hints, the statement timeout
+		// and the gormqonvert config are added to the db itself
+		return b.applyQonvertConfig(b.applyStatementTimeout(b.applyQueryHints(db.Where(filter), tree)))
+	}
+}
@@ -0,0 +1,97 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Scope(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	paginate := func(db *gorm.DB) *gorm.DB {
+		return db.Offset(10).Limit(5)
+	}
+	tenant := func(db *gorm.DB) *gorm.DB {
+		return db.Where("test_value = ?", "tenant")
+	}
+
+	tests := map[string]struct {
+		queryString string
+		scopes      func(filter func(*gorm.DB) *gorm.DB) []func(*gorm.DB) *gorm.DB
+		expectedSql string
+	}{
+		"filter only": {
+			queryString: "name eq 'test'",
+			scopes: func(filter func(*gorm.DB) *gorm.DB) []func(*gorm.DB) *gorm.DB {
+				return []func(*gorm.DB) *gorm.DB{filter}
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\"",
+		},
+		"with pagination": {
+			queryString: "name eq 'test' or length(name) gt 3",
+			scopes: func(filter func(*gorm.DB) *gorm.DB) []func(*gorm.DB) *gorm.DB {
+				return []func(*gorm.DB) *gorm.DB{filter, paginate}
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"test\" OR LENGTH(name) > 3 LIMIT 5 OFFSET 10",
+		},
+		"with tenancy after an or filter": {
+			queryString: "name eq 'test' or length(name) gt 3",
+			scopes: func(filter func(*gorm.DB) *gorm.DB) []func(*gorm.DB) *gorm.DB {
+				return []func(*gorm.DB) *gorm.DB{filter, tenant}
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE (name = \"test\" OR LENGTH(name) > 3) AND test_value = \"tenant\"",
+		},
+		"with tenancy before the filter": {
+			queryString: "metadata/name eq 'prd'",
+			scopes: func(filter func(*gorm.DB) *gorm.DB) []func(*gorm.DB) *gorm.DB {
+				return []func(*gorm.DB) *gorm.DB{tenant, filter}
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE test_value = \"tenant\" AND metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\")",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+			// Act
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&MockModel{}).Scopes(testData.scopes(Scope(testData.queryString, SQLite))...).Find(&[]MockModel{})
+			})
+
+			// Assert
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Scope_InvalidQuery(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+	db.Create(&MockModel{Name: "test"})
+	builder := New(WithDatabaseType(SQLite), WithDisabledFunctions("length"))
+
+	var result []MockModel
+
+	// Act
+	err := db.Scopes(builder.Scope("length(name) gt 3")).Find(&result).Error
+
+	// Assert
+	var invalidQueryError *InvalidQueryError
+	assert.True(t, errors.As(err, &invalidQueryError))
+	assert.True(t, errors.Is(err, ErrFunctionNotAllowed))
+	assert.Empty(t, result)
+}
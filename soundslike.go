@@ -0,0 +1,61 @@
+package gormodata
+
+import (
+	"fmt"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+)
+
+// soundsLikeTranslation
+// compares how a column and a string sound for every database type, %s is the column:
+// the double metaphone of the fuzzystrmatch extension on PostgreSQL, SOUNDEX on MySQL and SQLite
+// (which needs SQLite to be compiled with SQLITE_SOUNDEX) and DIFFERENCE on SQL Server, where 4 is the closest match
+var soundsLikeTranslation = map[DbType]string{
+	PostgreSQL: "DMETAPHONE(%s) = DMETAPHONE(?)",
+	MySQL:      "SOUNDEX(%s) = SOUNDEX(?)",
+	SQLite:     "SOUNDEX(%s) = SOUNDEX(?)",
+	SQLServer:  "DIFFERENCE(%s, ?) = 4",
+}
+
+// buildSoundsLike
+// builds the extension function soundslike, which matches the values of a column that sound like a string (see WithExtensionFunctions)
+//
+//	soundslike(lastName,'smith')  ->  SOUNDEX(last_name) = SOUNDEX('smith')
+func buildSoundsLike(root *syntaxtree.Node, db *gorm.DB, translation *queryTranslation, notEnabled bool) (*gorm.DB, error) {
+	leftChild, rightChild := root.LeftChild, root.RightChild
+	column := ""
+	switch {
+	case leftChild.Type == syntaxtree.UnaryOperator:
+		column = buildUnaryFuncChain(translation, leftChild)
+	case leftChild.Type == syntaxtree.LeftOperand && isPropertyNode(leftChild) && !translation.isRelationPath(leftChild.Value):
+		column = translation.columnName(leftChild.Value)
+	default:
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a column of the model as its first argument, got '%s'", root.Value, nodeExpression(leftChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(root),
+			Node:       leftChild,
+		}
+	}
+
+	if !isStringLiteral(rightChild.Value) {
+		return db, &InvalidQueryError{
+			Msg:        fmt.Sprintf("'%s' expects a string literal as its second argument, got '%s'", root.Value, nodeExpression(rightChild)),
+			Err:        ErrUnsupportedOperator,
+			Expression: nodeExpression(root),
+			Node:       rightChild,
+		}
+	}
+
+	queryString := fmt.Sprintf(soundsLikeTranslation[translation.databaseType], column)
+	if notEnabled {
+		queryString = "NOT (" + queryString + ")"
+		// Following odata, comparisons with null are false, so negated comparisons are true for null values
+		if translation.nullSafe {
+			queryString = "(" + queryString + " OR " + column + " IS NULL)"
+		}
+	}
+
+	return db.Where(queryString, unquote(rightChild.Value)), nil
+}
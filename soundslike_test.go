@@ -0,0 +1,120 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_SoundsLike(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		options      []Option
+		expectedSqls map[DbType]string
+	}{
+		"column": {
+			queryString: "soundslike(name,'smith')",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE DMETAPHONE(name) = DMETAPHONE(\"smith\")",
+				MySQL:      "SELECT * FROM `members` WHERE SOUNDEX(name) = SOUNDEX(\"smith\")",
+				SQLite:     "SELECT * FROM `members` WHERE SOUNDEX(name) = SOUNDEX(\"smith\")",
+				SQLServer:  "SELECT * FROM `members` WHERE DIFFERENCE(name, \"smith\") = 4",
+			},
+		},
+		"function": {
+			queryString: "soundslike(tolower(name),'smith')",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE DMETAPHONE(LOWER(name)) = DMETAPHONE(\"smith\")",
+				MySQL:      "SELECT * FROM `members` WHERE SOUNDEX(LOWER(name)) = SOUNDEX(\"smith\")",
+				SQLite:     "SELECT * FROM `members` WHERE SOUNDEX(LOWER(name)) = SOUNDEX(\"smith\")",
+				SQLServer:  "SELECT * FROM `members` WHERE DIFFERENCE(LOWER(name), \"smith\") = 4",
+			},
+		},
+		"negated": {
+			queryString: "not(soundslike(name,'smith'))",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `members` WHERE NOT (DMETAPHONE(name) = DMETAPHONE(\"smith\"))",
+				MySQL:      "SELECT * FROM `members` WHERE NOT (SOUNDEX(name) = SOUNDEX(\"smith\"))",
+				SQLite:     "SELECT * FROM `members` WHERE NOT (SOUNDEX(name) = SOUNDEX(\"smith\"))",
+				SQLServer:  "SELECT * FROM `members` WHERE NOT (DIFFERENCE(name, \"smith\") = 4)",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+				builder := New(append([]Option{WithDatabaseType(dbType), WithExtensionFunctions("SoundsLike")}, testData.options...)...)
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = builder.Build(testData.queryString, tx)
+					return dbQuery.Find(&Member{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_Builder_SoundsLikeErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		options     []Option
+		expectedErr string
+		expectedIs  error
+	}{
+		"not enabled": {
+			queryString: "soundslike(name,'smith')",
+			expectedErr: "invalid query: function 'soundslike' is an extension function that is not enabled, enable it with WithExtensionFunctions",
+			expectedIs:  ErrFunctionNotAllowed,
+		},
+		"not a string literal": {
+			queryString: "soundslike(name,12)",
+			options:     []Option{WithExtensionFunctions("soundslike")},
+			expectedErr: "invalid query: 'soundslike' expects a string literal as its second argument, got '12'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+		"relation path": {
+			queryString: "soundslike(metadata/name,'smith')",
+			options:     []Option{WithExtensionFunctions("soundslike")},
+			expectedErr: "invalid query: 'soundslike' expects a column of the model as its first argument, got 'metadata/name'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(append([]Option{WithDatabaseType(SQLite)}, testData.options...)...)
+
+			// Act
+			_, err := builder.Build(testData.queryString, db.Model(&Member{}))
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package gormodata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	statementTimeoutPluginName = "gormodata:statement_timeout"
+
+	// statementTimeoutSetting is the gorm setting that holds the statementTimeout of a built query
+	statementTimeoutSetting = "gormodata:statement_timeout"
+)
+
+// statementTimeout
+// is the timeout of the queries that run on a built query (see WithStatementTimeout)
+type statementTimeout struct {
+	timeout      time.Duration
+	databaseType DbType
+}
+
+// milliseconds
+// returns the timeout in whole milliseconds for the dialects, at least 1 since 0 disables their timeouts
+func (s statementTimeout) milliseconds() int64 {
+	return max(s.timeout.Milliseconds(), 1)
+}
+
+// runningStatement
+// holds the context of the statement before its timeout was added, so the db can run another query after it
+type runningStatement struct {
+	context context.Context
+	cancel  context.CancelFunc
+}
+
+// WithStatementTimeout
+// limits how long the queries that run on built queries can take, so expensive filters of clients cannot hold connections indefinitely,
+// the queries get a context deadline when they start and fail with context.DeadlineExceeded when it passes
+//
+//	builder := gormodata.New(gormodata.WithStatementTimeout(5 * time.Second))
+//
+// the databases also stop the query themselves where they support it: MySQL with the MAX_EXECUTION_TIME optimizer hint
+// and PostgreSQL with SET LOCAL statement_timeout when the query runs in a transaction (for the rest of the transaction),
+// the deadline applies to the queries of Find, First, Count and Pluck, Rows and Scan only get the timeout of the database
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(b *Builder) {
+		b.statementTimeout = timeout
+	}
+}
+
+// applyStatementTimeout
+// adds the statement timeout of the builder to the built query (see WithStatementTimeout)
+func (b *Builder) applyStatementTimeout(db *gorm.DB) *gorm.DB {
+	if b.statementTimeout <= 0 {
+		return db
+	}
+
+	databaseType, err := b.resolveDatabaseType(db)
+	if err != nil {
+		return db
+	}
+
+	timeout := statementTimeout{timeout: b.statementTimeout, databaseType: databaseType}
+	db = db.Set(statementTimeoutSetting, timeout)
+	if databaseType == MySQL {
+		db = db.Clauses(OptimizerHint(fmt.Sprintf("MAX_EXECUTION_TIME(%d)", timeout.milliseconds())))
+	}
+
+	return db
+}
+
+// statementTimeoutPlugin
+// adds the deadline of the statement timeout to the queries of built queries when they start and removes it when they are done
+type statementTimeoutPlugin struct{}
+
+func (s *statementTimeoutPlugin) Name() string {
+	return statementTimeoutPluginName
+}
+
+func (s *statementTimeoutPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(statementTimeoutPluginName+":start", s.startCallback); err != nil {
+		return err
+	}
+
+	// The deadline is removed after the preloads, which run with the context of the query as well
+	return db.Callback().Query().After("*").Register(statementTimeoutPluginName+":end", s.endCallback)
+}
+
+func (s *statementTimeoutPlugin) startCallback(db *gorm.DB) {
+	setting, ok := db.Get(statementTimeoutSetting)
+	if !ok || db.Error != nil {
+		return
+	}
+	timeout, ok := setting.(statementTimeout)
+	if !ok {
+		return
+	}
+
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout.timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(statementTimeoutPluginName, runningStatement{context: parent, cancel: cancel})
+
+	// SET LOCAL only lasts until the end of the transaction, outside of one it would not do anything
+	if timeout.databaseType != PostgreSQL || db.DryRun {
+		return
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return
+	}
+	if _, err := db.Statement.ConnPool.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.milliseconds())); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+func (s *statementTimeoutPlugin) endCallback(db *gorm.DB) {
+	setting, ok := db.InstanceGet(statementTimeoutPluginName)
+	if !ok {
+		return
+	}
+	running, ok := setting.(runningStatement)
+	if !ok {
+		return
+	}
+
+	running.cancel()
+	db.Statement.Context = running.context
+	db.InstanceSet(statementTimeoutPluginName, nil)
+}
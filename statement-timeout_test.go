@@ -0,0 +1,165 @@
+package gormodata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_WithStatementTimeoutSql(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		databaseType DbType
+		timeout      time.Duration
+		expectedSql  string
+	}{
+		"mysql": {
+			databaseType: MySQL,
+			timeout:      2 * time.Second,
+			expectedSql:  "SELECT /*+ MAX_EXECUTION_TIME(2000) */ * FROM `members` WHERE name = \"Alice\"",
+		},
+		"mysql below a millisecond": {
+			databaseType: MySQL,
+			timeout:      time.Microsecond,
+			expectedSql:  "SELECT /*+ MAX_EXECUTION_TIME(1) */ * FROM `members` WHERE name = \"Alice\"",
+		},
+		"sqlite": {
+			databaseType: SQLite,
+			timeout:      2 * time.Second,
+			expectedSql:  "SELECT * FROM `members` WHERE name = \"Alice\"",
+		},
+		"no timeout": {
+			databaseType: MySQL,
+			expectedSql:  "SELECT * FROM `members` WHERE name = \"Alice\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(testData.databaseType), WithStatementTimeout(testData.timeout))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build("name eq 'Alice'", tx)
+				return dbQuery.Find(&Member{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_WithStatementTimeoutDeadline(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Member{})
+	db.Create(&[]Member{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}})
+
+	builder := New(WithDatabaseType(SQLite), WithStatementTimeout(time.Minute))
+	dbQuery, err := builder.Build("name eq 'Alice'", db.Model(&Member{}))
+
+	// The plugin is registered by the build
+	var deadlines []bool
+	_ = db.Callback().Query().Before("gorm:query").After(statementTimeoutPluginName+":start").Register(t.Name(), func(tx *gorm.DB) {
+		_, ok := tx.Statement.Context.Deadline()
+		deadlines = append(deadlines, ok)
+	})
+
+	// Act
+	var members []Member
+	findErr := dbQuery.Find(&members).Error
+	var count int64
+	countErr := dbQuery.Count(&count).Error
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, findErr)
+	assert.NoError(t, countErr)
+	assert.Len(t, members, 1)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, []bool{true, true}, deadlines)
+
+	_, hasDeadline := dbQuery.Statement.Context.Deadline()
+	assert.False(t, hasDeadline)
+}
+
+func Test_Builder_WithStatementTimeoutExceeded(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&Member{})
+	builder := New(WithDatabaseType(SQLite), WithStatementTimeout(time.Nanosecond))
+	dbQuery, err := builder.Build("name eq 'Alice'", db.Model(&Member{}))
+
+	// Act
+	findErr := dbQuery.Find(&[]Member{}).Error
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(findErr, context.DeadlineExceeded))
+}
+
+func Test_Builder_WithStatementTimeoutScopeAndPlugin(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		query func(db *gorm.DB, builder *Builder) *gorm.DB
+	}{
+		"scope": {
+			query: func(db *gorm.DB, builder *Builder) *gorm.DB {
+				return db.Scopes(builder.Scope("name eq 'Alice'"))
+			},
+		},
+		"plugin": {
+			query: func(db *gorm.DB, builder *Builder) *gorm.DB {
+				_ = db.Use(builder)
+
+				return db.Set(FilterSetting, "name eq 'Alice'")
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			mysqlDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_mysql"))
+			mysqlBuilder := New(WithDatabaseType(MySQL), WithStatementTimeout(2*time.Second))
+			sqliteDB := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()+"_sqlite"))
+			_ = sqliteDB.AutoMigrate(&Member{})
+			sqliteBuilder := New(WithDatabaseType(SQLite), WithStatementTimeout(time.Nanosecond))
+
+			// Act
+			sqlQuery := mysqlDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return testData.query(tx, mysqlBuilder).Find(&[]Member{})
+			})
+			findErr := testData.query(sqliteDB, sqliteBuilder).Find(&[]Member{}).Error
+
+			// Assert
+			assert.Equal(t, "SELECT /*+ MAX_EXECUTION_TIME(2000) */ * FROM `members` WHERE name = \"Alice\"", sqlQuery)
+			assert.True(t, errors.Is(findErr, context.DeadlineExceeded))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SystemFilter
+// returns the filter that the server adds to every query built on the db (see WithSystemFilter),
+// e.g. with the tenant of the context of the db, nil to add no filter to the query
+type SystemFilter func(db *gorm.DB) (*Expr, error)
+
+// WithSystemFilter
+// adds the filters to every built query, so the filters of clients can never match rows outside of them (e.g. of other tenants)
+//
+//	builder := gormodata.New(gormodata.WithSystemFilter(func(db *gorm.DB) (*gormodata.Expr, error) {
+//		return gormodata.Field("tenantId").Eq(TenantFromContext(db.Statement.Context)), nil
+//	}))
+//
+// the filter of the client is grouped and combined with the system filters with 'and',
+// the system filters are not checked by the validations, allowed fields and limits of the builder, so they can use any column of the model
+func WithSystemFilter(filters ...SystemFilter) Option {
+	return func(b *Builder) {
+		b.systemFilters = append(b.systemFilters, filters...)
+	}
+}
+
+// applySystemFilters
+// groups the conditions that the filter of the client added to the result after the existing conditions of the db,
+// and adds the grouped conditions of every system filter after them
+func (b *Builder) applySystemFilters(db *gorm.DB, result *gorm.DB, existingConditions int) (*gorm.DB, error) {
+	var systemConditions []clause.Expression
+	for _, systemFilter := range b.systemFilters {
+		expr, err := systemFilter(db)
+		if err != nil {
+			return result, err
+		}
+		if expr == nil {
+			continue
+		}
+
+		conditions, err := b.systemConditions(expr, db)
+		if err != nil {
+			return result, err
+		}
+		systemConditions = append(systemConditions, clause.AndConditions{Exprs: conditions})
+	}
+
+	if len(systemConditions) == 0 {
+		return result, nil
+	}
+
+	conditions := whereConditions(result)
+	exprs := append([]clause.Expression{}, conditions[:existingConditions]...)
+	if len(conditions) > existingConditions {
+		// The groups keep the 'or' operators of the filters from joining the other conditions
+		exprs = append(exprs, clause.AndConditions{Exprs: conditions[existingConditions:]})
+	}
+	exprs = append(exprs, systemConditions...)
+
+	result.Statement.Clauses["WHERE"] = clause.Clause{Name: "WHERE", Expression: clause.Where{Exprs: exprs}}
+
+	return result, nil
+}
+
+// systemConditions
+// builds the conditions of a system filter on a new session of the db, without the validations of the builder
+func (b *Builder) systemConditions(expr *Expr, db *gorm.DB) ([]clause.Expression, error) {
+	tx := db.Session(&gorm.Session{NewDB: true}).Model(db.Statement.Model)
+	if db.Statement.Table != "" {
+		tx = tx.Table(db.Statement.Table)
+	}
+
+	translation, tx, err := b.prepareDB(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := expr.syntaxTree()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := buildGormQuery(tree.Root, tx, translation, operatorTranslation, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return whereConditions(result), nil
+}
@@ -0,0 +1,165 @@
+package gormodata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type tenantContextKey struct{}
+
+func Test_WithSystemFilter(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tenant := func(db *gorm.DB) (*Expr, error) {
+		return Field("testValue").Eq(db.Statement.Context.Value(tenantContextKey{})), nil
+	}
+
+	tests := map[string]struct {
+		queryString   string
+		systemFilters []SystemFilter
+		options       []Option
+		query         func(db *gorm.DB) *gorm.DB
+		expectedSql   string
+	}{
+		"or filter": {
+			queryString:   "name eq 'a' or name eq 'b'",
+			systemFilters: []SystemFilter{tenant},
+			expectedSql:   "SELECT * FROM `mock_models` WHERE (name = \"a\" OR name = \"b\") AND test_value = \"t1\"",
+		},
+		"after existing conditions": {
+			queryString:   "name eq 'a' or name eq 'b'",
+			systemFilters: []SystemFilter{tenant},
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Where("id IS NOT NULL")
+			},
+			expectedSql: "SELECT * FROM `mock_models` WHERE id IS NOT NULL AND (name = \"a\" OR name = \"b\") AND test_value = \"t1\"",
+		},
+		"field that is not allowed": {
+			queryString:   "name eq 'a'",
+			systemFilters: []SystemFilter{tenant},
+			options:       []Option{WithAllowedFields("name")},
+			expectedSql:   "SELECT * FROM `mock_models` WHERE name = \"a\" AND test_value = \"t1\"",
+		},
+		"multiple system filters": {
+			queryString: "name eq 'a'",
+			systemFilters: []SystemFilter{tenant, func(*gorm.DB) (*Expr, error) {
+				return Field("metadata/name").Eq("prd").Or(Field("name").Eq("b")), nil
+			}},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"a\" AND test_value = \"t1\" AND (metadata_id IN (SELECT `id` FROM `metadata` WHERE `metadata`.`name` = \"prd\") OR name = \"b\")",
+		},
+		"no system filter": {
+			queryString: "name eq 'a' or name eq 'b'",
+			systemFilters: []SystemFilter{func(*gorm.DB) (*Expr, error) {
+				return nil, nil
+			}},
+			expectedSql: "SELECT * FROM `mock_models` WHERE name = \"a\" OR name = \"b\"",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+			options := append([]Option{WithDatabaseType(SQLite), WithSystemFilter(testData.systemFilters...)}, testData.options...)
+			builder := New(options...)
+
+			query := db.WithContext(context.WithValue(context.Background(), tenantContextKey{}, "t1")).Model(&MockModel{})
+			if testData.query != nil {
+				query = testData.query(query)
+			}
+
+			// Act
+			dbQuery, err := builder.Build(testData.queryString, query)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, dbQuery.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Find(&[]MockModel{})
+			}))
+		})
+	}
+}
+
+func Test_WithSystemFilter_Scope(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+	builder := New(WithDatabaseType(SQLite), WithSystemFilter(func(*gorm.DB) (*Expr, error) {
+		return Field("testValue").Eq("t1"), nil
+	}))
+
+	// Act
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&MockModel{}).Scopes(builder.Scope("name eq 'a' or name eq 'b'")).Find(&[]MockModel{})
+	})
+
+	// Assert
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE (name = \"a\" OR name = \"b\") AND test_value = \"t1\"", sqlQuery)
+}
+
+func Test_WithSystemFilter_Error(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+	errNoTenant := errors.New("no tenant")
+	builder := New(WithDatabaseType(SQLite), WithSystemFilter(func(*gorm.DB) (*Expr, error) {
+		return nil, errNoTenant
+	}))
+
+	// Act
+	dbQuery, err := builder.Build("name eq 'a'", db.Model(&MockModel{}))
+
+	// Assert
+	assert.True(t, errors.Is(err, errNoTenant))
+	assert.NotNil(t, dbQuery)
+}
+
+func Test_WithSystemFilter_PreparedFilter(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{}, &Metadata{})
+
+	builder := New(WithDatabaseType(SQLite), WithSystemFilter(func(db *gorm.DB) (*Expr, error) {
+		return Field("testValue").Eq(db.Statement.Context.Value(tenantContextKey{})), nil
+	}))
+	filter, _ := builder.Prepare("name eq 'a'")
+
+	// Act
+	results := make([]string, 0, 2)
+	for _, tenant := range []string{"t1", "t2"} {
+		ctx := context.WithValue(context.Background(), tenantContextKey{}, tenant)
+		dbQuery, err := filter.Apply(db.WithContext(ctx).Model(&MockModel{}))
+		assert.NoError(t, err)
+
+		results = append(results, dbQuery.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Find(&[]MockModel{})
+		}))
+	}
+
+	// Assert
+	assert.Equal(t, []string{
+		"SELECT * FROM `mock_models` WHERE name = \"a\" AND test_value = \"t1\"",
+		"SELECT * FROM `mock_models` WHERE name = \"a\" AND test_value = \"t2\"",
+	}, results)
+}
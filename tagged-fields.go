@@ -0,0 +1,126 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// The settings of the odata tag that declare what clients can do with a field (see WithTaggedFields and HasODataTag)
+const (
+	// FilterableTag allows filters on the field, or through the relation
+	FilterableTag = "filterable"
+
+	// SortableTag allows sorting on the field, e.g. with $orderby in gormodatahttp
+	SortableTag = "sortable"
+
+	// ExpandableTag allows expanding the relation, e.g. with $expand in gormodatahttp
+	ExpandableTag = "expandable"
+)
+
+// HasODataTag
+// returns whether the odata tag of the field has the setting (e.g. FilterableTag), settings are case-insensitive
+//
+//	type Pet struct {
+//		Name  string `odata:"filterable,sortable"`
+//		Owner *Owner `odata:"filterable,expandable"`
+//	}
+func HasODataTag(field *schema.Field, setting string) bool {
+	for _, value := range strings.Split(field.Tag.Get(odataTag), ",") {
+		if strings.EqualFold(strings.TrimSpace(value), setting) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithTaggedFields
+// only allows filters on the fields of the model that are tagged as filterable, so the model declares its allowlist inline
+// instead of WithAllowedFields, filters on other fields fail with an InvalidQueryError wrapping ErrFieldNotAllowed
+//
+//	type Pet struct {
+//		Name       string `odata:"filterable"`
+//		SecretNote string
+//		Owner      *Owner `odata:"filterable"`
+//	}
+//
+//	builder := gormodata.New(gormodata.WithTaggedFields(&Pet{}))
+//
+// a relation path needs the relation and the field of the related model to be filterable (e.g. owner/name),
+// the keys of JSON columns only need the column to be filterable, properties that are not on the model are never allowed
+func WithTaggedFields(model any) Option {
+	return func(b *Builder) {
+		b.queryValidations = append(b.queryValidations, taggedFieldsValidation(model))
+	}
+}
+
+// taggedFieldsValidation
+// returns a QueryValidation that rejects the properties that are not tagged as filterable on the model (see WithTaggedFields)
+func taggedFieldsValidation(model any) QueryValidation {
+	return func(tree *syntaxtree.SyntaxTree, db *gorm.DB) error {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+
+		validationCheck := func(depth int, currentNode *syntaxtree.Node) error {
+			if !isPropertyNode(currentNode) {
+				return nil
+			}
+
+			names := strings.Split(modelProperty(currentNode.Value), "/")
+			path := strings.Split(propertyPath(db.NamingStrategy, currentNode.Value), "/")
+			if msg := unfilterablePath(db.NamingStrategy, statement.Schema, path, names, currentNode.Value); msg != "" {
+				return &InvalidQueryError{
+					Msg:        msg,
+					Err:        ErrFieldNotAllowed,
+					Expression: nodeExpression(currentNode),
+					Node:       currentNode,
+				}
+			}
+
+			return nil
+		}
+
+		return validateQueryDepthFirstSearch(db, tree, validationCheck)
+	}
+}
+
+// unfilterablePath
+// returns why the property path (column names) is not filterable on the schema, or an empty string if it is,
+// the names are the parts of the property as they are written in the filter
+func unfilterablePath(namer schema.Namer, modelSchema *schema.Schema, path []string, names []string, property string) string {
+	for i := range path {
+		if len(path)-i > 1 {
+			if field := findEmbeddedField(namer, modelSchema, path[i:]); field != nil {
+				if !HasODataTag(field, FilterableTag) {
+					return fmt.Sprintf("field '%s' is not filterable", property)
+				}
+
+				return ""
+			}
+		}
+
+		if relationship := findRelationship(namer, modelSchema, path[i]); relationship != nil {
+			if !HasODataTag(relationship.Field, FilterableTag) {
+				return fmt.Sprintf("relation '%s' of '%s' is not filterable", names[i], property)
+			}
+			modelSchema = relationship.FieldSchema
+
+			continue
+		}
+
+		// The rest of the path are the keys of the elements of a JSON column
+		if field := modelSchema.LookUpField(path[i]); field != nil && field.DBName != "" && HasODataTag(field, FilterableTag) {
+			return ""
+		}
+
+		return fmt.Sprintf("field '%s' is not filterable", property)
+	}
+
+	return ""
+}
@@ -0,0 +1,120 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type TaggedOwner struct {
+	ID     uint   `odata:"filterable"`
+	Name   string `odata:"filterable"`
+	Secret string
+}
+
+type TaggedPet struct {
+	ID      uint
+	Name    string `odata:"filterable,sortable"`
+	Notes   string
+	Tags    []string     `gorm:"serializer:json" odata:"filterable"`
+	Owner   *TaggedOwner `odata:"Filterable"`
+	OwnerID *uint
+	Vet     *TaggedOwner `gorm:"foreignKey:VetID"`
+	VetID   *uint
+}
+
+func Test_Builder_WithTaggedFields(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedSql string
+	}{
+		"filterable field": {
+			queryString: "name eq 'rex' and contains(name,'e')",
+			expectedSql: "SELECT * FROM `tagged_pets` WHERE name = \"rex\" AND name LIKE \"%e%\"",
+		},
+		"filterable relation": {
+			queryString: "owner/name eq 'tom'",
+			expectedSql: "SELECT * FROM `tagged_pets` WHERE owner_id IN (SELECT `id` FROM `tagged_owners` WHERE `tagged_owners`.`name` = \"tom\")",
+		},
+		"filterable json column": {
+			queryString: "tags/any(t: t eq 'cute')",
+			expectedSql: "SELECT * FROM `tagged_pets` WHERE EXISTS (SELECT 1 FROM json_each(tags) AS lambda_1 WHERE (lambda_1.value = \"cute\"))",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithTaggedFields(&TaggedPet{}))
+
+			// Act
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, err = builder.Build(testData.queryString, tx.Model(&TaggedPet{}))
+				return dbQuery.Find(&TaggedPet{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+		})
+	}
+}
+
+func Test_Builder_WithTaggedFieldsRejected(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedError string
+	}{
+		"field without tag": {
+			queryString:   "name eq 'rex' and notes eq 'friendly'",
+			expectedError: "invalid query: field 'notes' is not filterable",
+		},
+		"field without tag in a function": {
+			queryString:   "length(notes) gt 3",
+			expectedError: "invalid query: field 'notes' is not filterable",
+		},
+		"field without tag of a relation": {
+			queryString:   "owner/secret eq 'x'",
+			expectedError: "invalid query: field 'owner/secret' is not filterable",
+		},
+		"relation without tag": {
+			queryString:   "vet/name eq 'tom'",
+			expectedError: "invalid query: relation 'vet' of 'vet/name' is not filterable",
+		},
+		"unknown field": {
+			queryString:   "color eq 'brown'",
+			expectedError: "invalid query: field 'color' is not filterable",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite), WithTaggedFields(&TaggedPet{}))
+
+			// Act
+			_, err := builder.Build(testData.queryString, db)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedError)
+			assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package gormodata
+
+import (
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm/clause"
+)
+
+// timeOfDayLiteralPattern
+// matches the time of day literals of a filter: time'hh:mm[:ss[.fffffff]]' (e.g. time'13:45:00')
+var timeOfDayLiteralPattern = regexp.MustCompile(`^(?i:time)'([01][0-9]|2[0-3]):([0-5][0-9])(?::([0-5][0-9])(\.[0-9]{1,7})?)?'$`)
+
+// TimeOfDayLiteral
+// is the value of a time of day literal of a filter with its seconds (e.g. 13:45:00 of time'13:45'),
+// it is compared with time columns and the results of time()
+type TimeOfDayLiteral string
+
+// String
+// returns the literal as it is written in a filter
+func (t TimeOfDayLiteral) String() string {
+	return "time'" + string(t) + "'"
+}
+
+// MarshalText
+// encodes the literal as it is written in a filter, so the json of expressions keeps time literals apart from strings
+func (t TimeOfDayLiteral) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// isTimeOfDayLiteral
+// returns whether the value of a node is a time of day literal
+func isTimeOfDayLiteral(value string) bool {
+	return timeOfDayLiteralPattern.MatchString(value)
+}
+
+// timeOfDayLiteral
+// returns the time of a time of day literal, literals without seconds get zero seconds (e.g. time'13:45' -> 13:45:00)
+func timeOfDayLiteral(value string) TimeOfDayLiteral {
+	match := timeOfDayLiteralPattern.FindStringSubmatch(value)
+
+	seconds := match[3]
+	if seconds == "" {
+		seconds = "00"
+	}
+
+	return TimeOfDayLiteral(fmt.Sprintf("%s:%s:%s%s", match[1], match[2], seconds, match[4]))
+}
+
+// bindTimeOfDayLiteral
+// returns the time of a time of day literal cast to the time type of the database,
+// so it is compared as a time instead of as text (e.g. '9:05:00' would be after '13:45:00' as text):
+//
+//	startTime lt time'13:45'  ->  start_time < CAST('13:45:00' AS time)
+//
+// SQLite has no time type, its TIME function returns the time as hh:mm:ss text that is ordered like the time
+func bindTimeOfDayLiteral(literal TimeOfDayLiteral, databaseType DbType) clause.Expr {
+	switch databaseType {
+	case MySQL:
+		return clause.Expr{SQL: "CAST(? AS TIME(6))", Vars: []any{string(literal)}}
+	case SQLite:
+		return clause.Expr{SQL: "TIME(?)", Vars: []any{string(literal)}}
+	default:
+		return clause.Expr{SQL: "CAST(? AS time)", Vars: []any{string(literal)}}
+	}
+}
+
+// timeOfDayColumn
+// returns the column compared with a time of day literal, SQLite stores times as text or numbers
+// so the column is normalized with TIME as well (e.g. '13:45' -> '13:45:00')
+func timeOfDayColumn(column string, databaseType DbType) string {
+	if databaseType == SQLite {
+		return "TIME(" + column + ")"
+	}
+
+	return column
+}
@@ -0,0 +1,197 @@
+package gormodata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Shift struct {
+	ID        uuid.UUID
+	Name      string
+	StartTime string
+	CreatedAt time.Time
+}
+
+func Test_BuildQuery_TimeOfDayLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString  string
+		expectedSqls map[DbType]string
+	}{
+		"time column": {
+			queryString: "startTime lt time'13:45'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `shifts` WHERE start_time < CAST(\"13:45:00\" AS time)",
+				MySQL:      "SELECT * FROM `shifts` WHERE start_time < CAST(\"13:45:00\" AS TIME(6))",
+				SQLite:     "SELECT * FROM `shifts` WHERE TIME(start_time) < TIME(\"13:45:00\")",
+				SQLServer:  "SELECT * FROM `shifts` WHERE start_time < CAST(\"13:45:00\" AS time)",
+			},
+		},
+		"time function": {
+			queryString: "time(createdAt) ge time'08:30:15.250'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `shifts` WHERE CAST(created_at::timestamp AS time) >= CAST(\"08:30:15.250\" AS time)",
+				MySQL:      "SELECT * FROM `shifts` WHERE TIME(created_at) >= CAST(\"08:30:15.250\" AS TIME(6))",
+				SQLite:     "SELECT * FROM `shifts` WHERE TIME(created_at) >= TIME(\"08:30:15.250\")",
+				SQLServer:  "SELECT * FROM `shifts` WHERE CAST(created_at AS time) >= CAST(\"08:30:15.250\" AS time)",
+			},
+		},
+		"string literal": {
+			queryString: "startTime eq '13:45'",
+			expectedSqls: map[DbType]string{
+				PostgreSQL: "SELECT * FROM `shifts` WHERE start_time = \"13:45\"",
+				MySQL:      "SELECT * FROM `shifts` WHERE start_time = \"13:45\"",
+				SQLite:     "SELECT * FROM `shifts` WHERE start_time = \"13:45\"",
+				SQLServer:  "SELECT * FROM `shifts` WHERE start_time = \"13:45\"",
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		for dbName, dbType := range map[string]DbType{"postgres": PostgreSQL, "mysql": MySQL, "sqlite": SQLite, "sqlserver": SQLServer} {
+			t.Run(name+" on "+dbName, func(t *testing.T) {
+				t.Parallel()
+
+				// Arrange
+				db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+				// Act
+				var dbQuery *gorm.DB
+				var err error
+				sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+					dbQuery, err = BuildQuery(testData.queryString, tx.Model(&Shift{}), dbType)
+					return dbQuery.Find(&Shift{})
+				})
+
+				// Assert
+				assert.NoError(t, err)
+				assert.Equal(t, testData.expectedSqls[dbType], sqlQuery)
+			})
+		}
+	}
+}
+
+func Test_BuildQuery_TimeOfDayLiteralResult(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		expectedNames []string
+	}{
+		"time without seconds": {
+			queryString:   "startTime eq time'09:05'",
+			expectedNames: []string{"early"},
+		},
+		"ordered as time": {
+			queryString:   "startTime lt time'13:45:00'",
+			expectedNames: []string{"early"},
+		},
+		"time function": {
+			queryString:   "time(createdAt) ge time'13:00'",
+			expectedNames: []string{"late"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&Shift{})
+			db.Create(&Shift{ID: uuid.New(), Name: "early", StartTime: "09:05", CreatedAt: time.Date(2025, 1, 1, 9, 5, 0, 0, time.UTC)})
+			db.Create(&Shift{ID: uuid.New(), Name: "late", StartTime: "13:45:00", CreatedAt: time.Date(2025, 1, 1, 13, 45, 0, 0, time.UTC)})
+
+			// Act
+			dbQuery, err := BuildQuery(testData.queryString, db.Model(&Shift{}), SQLite)
+
+			// Assert
+			assert.NoError(t, err)
+
+			var result []Shift
+			assert.NoError(t, dbQuery.Order("name").Find(&result).Error)
+
+			names := []string{}
+			for _, shift := range result {
+				names = append(names, shift.Name)
+			}
+			assert.Equal(t, testData.expectedNames, names)
+		})
+	}
+}
+
+func Test_BuildQuery_TimeOfDayLiteralErrors(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString string
+		expectedErr string
+		expectedIs  error
+	}{
+		"hour out of range": {
+			queryString: "startTime eq time'24:00'",
+			expectedErr: "failed to parse query: invalid time literal time'24:00', expected hh:mm[:ss[.fffffff]] (e.g. time'13:45:00')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"single digit hour": {
+			queryString: "startTime eq time'9:05'",
+			expectedErr: "failed to parse query: invalid time literal time'9:05', expected hh:mm[:ss[.fffffff]] (e.g. time'13:45:00')",
+			expectedIs:  ErrInvalidSyntax,
+		},
+		"relation property": {
+			queryString: "metadata/startTime eq time'13:45'",
+			expectedErr: "invalid query: relation property 'metadata/startTime' cannot be compared with literal time'13:45'",
+			expectedIs:  ErrUnsupportedOperator,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+
+			// Act
+			_, err := BuildQuery(testData.queryString, db.Model(&Shift{}), PostgreSQL)
+
+			// Assert
+			assert.EqualError(t, err, testData.expectedErr)
+			assert.True(t, errors.Is(err, testData.expectedIs))
+		})
+	}
+}
+
+func Test_Parse_TimeOfDayLiteral(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Act
+	expr, err := Parse("time(createdAt) lt TIME'13:45'")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Expr{Kind: ComparisonExpr, Op: "lt", Args: []*Expr{
+		{Kind: FunctionExpr, Func: "time", Args: []*Expr{exprProperty("createdAt")}},
+		exprLiteral(TimeOfDayLiteral("13:45:00")),
+	}}, expr)
+	assert.Equal(t, "time(createdAt) lt time'13:45:00'", expr.String())
+
+	data, err := json.Marshal(expr)
+	assert.NoError(t, err)
+
+	var decoded Expr
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, expr, &decoded)
+}
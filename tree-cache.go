@@ -0,0 +1,187 @@
+package gormodata
+
+import (
+	"container/list"
+	"sync"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// defaultTreeCacheSize
+// is the size of a TreeCache that is created with a size of 0 or less
+const defaultTreeCacheSize = 1024
+
+// TreeCache
+// is a bounded least recently used cache of parsed filters, keyed by the filter string,
+// so a filter that is built again is not parsed again (see WithTreeCache)
+//
+// the parsed filters do not depend on the configuration of a builder (aliases and validations are applied after parsing),
+// so one cache can be shared by multiple builders, it is safe for concurrent use
+type TreeCache struct {
+	mutex sync.Mutex
+
+	size    int
+	entries map[string]*list.Element
+
+	// order holds the entries from the most to the least recently used
+	order *list.List
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// TreeCacheStats
+// are the statistics of a TreeCache (see TreeCache.Stats)
+type TreeCacheStats struct {
+	// Hits is the number of filters that were found in the cache
+	Hits uint64
+
+	// Misses is the number of filters that were parsed because they were not in the cache
+	Misses uint64
+
+	// Evictions is the number of filters that were removed from the cache to make room for other filters
+	Evictions uint64
+
+	// Entries is the number of filters in the cache
+	Entries int
+
+	// Size is the maximum number of filters in the cache
+	Size int
+}
+
+// treeCacheEntry
+// is a parsed filter in a TreeCache
+type treeCacheEntry struct {
+	query string
+	tree  *syntaxtree.SyntaxTree
+}
+
+// NewTreeCache
+// creates a TreeCache that holds at most size parsed filters, a size of 0 or less uses a size of 1024
+func NewTreeCache(size int) *TreeCache {
+	if size <= 0 {
+		size = defaultTreeCacheSize
+	}
+
+	return &TreeCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// Stats
+// returns the statistics of the cache
+func (c *TreeCache) Stats() TreeCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return TreeCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.order.Len(),
+		Size:      c.size,
+	}
+}
+
+// Clear
+// removes all parsed filters from the cache, the statistics are kept
+func (c *TreeCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clear(c.entries)
+	c.order.Init()
+}
+
+// Invalidate
+// removes the parsed filter from the cache, it is parsed again on its next build
+func (c *TreeCache) Invalidate(query string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[query]; ok {
+		c.order.Remove(element)
+		delete(c.entries, query)
+	}
+}
+
+// parse
+// returns the syntax tree of the query from the cache and whether it was found, or parses it and adds it to the cache,
+// every call gets its own copy of the tree so it can be changed (e.g. by field aliases) without changing the cache
+func (c *TreeCache) parse(query string) (*syntaxtree.SyntaxTree, bool, error) {
+	c.mutex.Lock()
+	if element, ok := c.entries[query]; ok {
+		c.order.MoveToFront(element)
+		c.hits++
+		tree := element.Value.(*treeCacheEntry).tree
+		c.mutex.Unlock()
+
+		return cloneTree(tree), true, nil
+	}
+	c.misses++
+	c.mutex.Unlock()
+
+	// Parse outside of the lock, so other filters are not blocked by a slow parse
+	tree, err := GetAST(query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[query]; ok {
+		// The filter was added by a concurrent call while it was parsed
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[query] = c.order.PushFront(&treeCacheEntry{query: query, tree: tree})
+		for c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*treeCacheEntry).query)
+			c.evictions++
+		}
+	}
+
+	return cloneTree(tree), false, nil
+}
+
+// cloneTree
+// returns a deep copy of the syntax tree, the nodes keep their ids and order
+func cloneTree(tree *syntaxtree.SyntaxTree) *syntaxtree.SyntaxTree {
+	clones := make(map[*syntaxtree.Node]*syntaxtree.Node, len(tree.Nodes))
+	var cloneNode func(node *syntaxtree.Node) *syntaxtree.Node
+	cloneNode = func(node *syntaxtree.Node) *syntaxtree.Node {
+		if node == nil {
+			return nil
+		}
+		if clone, ok := clones[node]; ok {
+			return clone
+		}
+
+		clone := &syntaxtree.Node{
+			Id:      node.Id,
+			Value:   node.Value,
+			Type:    node.Type,
+			IsGroup: node.IsGroup,
+		}
+		clones[node] = clone
+		clone.Parent = cloneNode(node.Parent)
+		clone.LeftChild = cloneNode(node.LeftChild)
+		clone.RightChild = cloneNode(node.RightChild)
+
+		return clone
+	}
+
+	clone := *tree
+	clone.Root = cloneNode(tree.Root)
+	clone.Nodes = make([]*syntaxtree.Node, len(tree.Nodes))
+	for i, node := range tree.Nodes {
+		clone.Nodes[i] = cloneNode(node)
+	}
+
+	return &clone
+}
@@ -0,0 +1,156 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_TreeCache_Stats(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		size          int
+		queryStrings  []string
+		expectedStats TreeCacheStats
+	}{
+		"repeated filter": {
+			size:          10,
+			queryStrings:  []string{"name eq 'test'", "name eq 'test'", "name eq 'test'"},
+			expectedStats: TreeCacheStats{Hits: 2, Misses: 1, Entries: 1, Size: 10},
+		},
+		"different filters": {
+			size:          10,
+			queryStrings:  []string{"name eq 'test'", "name eq 'prd'", "name eq 'test'"},
+			expectedStats: TreeCacheStats{Hits: 1, Misses: 2, Entries: 2, Size: 10},
+		},
+		"least recently used filter is evicted": {
+			size:          2,
+			queryStrings:  []string{"name eq 'a'", "name eq 'b'", "name eq 'a'", "name eq 'c'", "name eq 'b'"},
+			expectedStats: TreeCacheStats{Hits: 1, Misses: 4, Evictions: 2, Entries: 2, Size: 2},
+		},
+		"invalid filters are not cached": {
+			size:          10,
+			queryStrings:  []string{"name eq 'test' and", "name eq 'test' and"},
+			expectedStats: TreeCacheStats{Misses: 2, Size: 10},
+		},
+		"default size": {
+			queryStrings:  []string{"name eq 'test'"},
+			expectedStats: TreeCacheStats{Misses: 1, Entries: 1, Size: defaultTreeCacheSize},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			_ = db.AutoMigrate(&MockModel{})
+			cache := NewTreeCache(testData.size)
+			builder := New(WithDatabaseType(SQLite), WithTreeCache(cache))
+
+			// Act
+			for _, queryString := range testData.queryStrings {
+				_, _ = builder.Build(queryString, db)
+			}
+
+			// Assert
+			assert.Equal(t, testData.expectedStats, cache.Stats())
+		})
+	}
+}
+
+func Test_TreeCache_Clear(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	cache := NewTreeCache(10)
+	builder := New(WithDatabaseType(SQLite), WithTreeCache(cache))
+	_, _ = builder.Build("name eq 'test'", db)
+
+	// Act
+	cache.Clear()
+	_, _ = builder.Build("name eq 'test'", db)
+
+	// Assert
+	assert.Equal(t, TreeCacheStats{Misses: 2, Entries: 1, Size: 10}, cache.Stats())
+}
+
+func Test_TreeCache_Invalidate(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	cache := NewTreeCache(10)
+	builder := New(WithDatabaseType(SQLite), WithTreeCache(cache))
+	_, _ = builder.Build("name eq 'test'", db)
+	_, _ = builder.Build("name eq 'other'", db)
+
+	// Act
+	cache.Invalidate("name eq 'test'")
+	cache.Invalidate("name eq 'unknown'")
+	_, _ = builder.Build("name eq 'test'", db)
+	_, _ = builder.Build("name eq 'other'", db)
+
+	// Assert
+	assert.Equal(t, TreeCacheStats{Hits: 1, Misses: 3, Entries: 2, Size: 10}, cache.Stats())
+}
+
+func Test_TreeCache_SharedByBuilders(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	_ = db.AutoMigrate(&MockModel{})
+	cache := NewTreeCache(10)
+	aliasBuilder := New(WithDatabaseType(SQLite), WithTreeCache(cache), WithFieldAliases(map[string]string{"title": "name"}))
+	builder := New(WithDatabaseType(SQLite), WithTreeCache(cache))
+	queryString := "title eq 'test' and length(title) gt 3"
+
+	// Act
+	aliasSql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, _ := aliasBuilder.Build(queryString, tx)
+		return dbQuery.Find(&[]MockModel{})
+	})
+	sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		dbQuery, _ := builder.Build(queryString, tx)
+		return dbQuery.Find(&[]MockModel{})
+	})
+
+	// Assert
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE name = \"test\" AND LENGTH(name) > 3", aliasSql)
+	assert.Equal(t, "SELECT * FROM `mock_models` WHERE title = \"test\" AND LENGTH(title) > 3", sqlQuery)
+	assert.Equal(t, uint64(1), cache.Stats().Hits)
+}
+
+func Test_cloneTree(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	tree, _ := GetAST("name eq 'test' and (contains(testValue,'prd') or not(length(name) gt 3))")
+
+	// Act
+	clone := cloneTree(tree)
+
+	// Assert
+	assert.Equal(t, tree.String(), clone.String())
+	assert.Len(t, clone.Nodes, len(tree.Nodes))
+	for i, node := range clone.Nodes {
+		assert.False(t, tree.Nodes[i] == node)
+		assert.Equal(t, tree.Nodes[i].Id, node.Id)
+		if node.Parent != nil {
+			assert.True(t, node.Parent.LeftChild == node || node.Parent.RightChild == node)
+		}
+	}
+	assert.Nil(t, clone.Root.Parent)
+}
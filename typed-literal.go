@@ -0,0 +1,47 @@
+package gormodata
+
+import (
+	"fmt"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+)
+
+// typedLiteral
+// is a literal of a filter that starts with its type followed by a string literal (e.g. binary'00FF'),
+// valid checks the whole literal and expected describes its format in errors
+type typedLiteral struct {
+	valid    func(literal string) bool
+	expected string
+}
+
+// typedLiterals
+// are the typed literals by their lowercase type
+var typedLiterals = map[string]typedLiteral{
+	"binary":    {valid: isBinaryLiteral, expected: "pairs of hexadecimal digits"},
+	"geography": {valid: isGeoLiteral, expected: "SRID=<srid>;<well-known text> (e.g. geography'SRID=4326;POINT(4.35 50.85)')"},
+	"geometry":  {valid: isGeoLiteral, expected: "SRID=<srid>;<well-known text> (e.g. geometry'SRID=4326;POINT(4.35 50.85)')"},
+	"time":      {valid: isTimeOfDayLiteral, expected: "hh:mm[:ss[.fffffff]] (e.g. time'13:45:00')"},
+}
+
+// typedLiteralEnd
+// returns the index after the typed literal that starts with the word (e.g. binary'00FF'),
+// the value is a string literal that directly follows the type and must have the format of the type
+func (c *parserConfig) typedLiteralEnd(query string, word string, end int) (int, error) {
+	literalType, ok := typedLiterals[strings.ToLower(word)]
+	if end >= len(query) || query[end] != c.lexer.StringDelimiter || !ok {
+		return end, nil
+	}
+
+	literalEnd, terminated := c.stringLiteralEnd(query, end)
+	if !terminated {
+		return end, nil
+	}
+
+	literal := query[end-len(word) : literalEnd]
+	if !literalType.valid(literal) {
+		return end, &syntaxtree.ParseError{Msg: fmt.Sprintf("invalid %s literal %s, expected %s", strings.ToLower(word), literal, literalType.expected)}
+	}
+
+	return literalEnd, nil
+}
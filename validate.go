@@ -0,0 +1,47 @@
+package gormodata
+
+import (
+	"gorm.io/gorm"
+)
+
+// Validate
+// parses the filter and checks it against the model without a database connection,
+// so request validation can reject invalid filters before a transaction is opened
+//
+//	if err := gormodata.Validate(queryString, MyModel{}, gormodata.WithAllowedFields("name", "createdAt")); err != nil {
+//		// respond with 400 Bad Request
+//	}
+//
+// the columns of the model are named with the default gorm naming strategy,
+// use Builder.Validate with a Builder that is created once to not prepare the options on every call
+func Validate(query string, model any, opts ...Option) error {
+	return New(opts...).Validate(query, model)
+}
+
+// Validate
+// parses the filter and checks it against the model without a database connection (see Validate),
+// next to the options of the Builder this checks that the properties and relation paths exist on the model
+// and that the literals compared with uuid and time columns are valid
+func (b *Builder) Validate(query string, model any) error {
+	db, err := gorm.Open(nil, &gorm.Config{DryRun: true})
+	if err != nil {
+		return err
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return err
+	}
+
+	validator := *b
+	validator.databaseTypeSet = true
+	validator.validateLiterals = true
+	validator.queryValidations = append([]QueryValidation{schemaValidation(statement.Schema)}, b.queryValidations...)
+
+	// The filter is only checked, no query is built for the audit hook
+	validator.auditHook = nil
+
+	_, _, err = validator.build(query, db.Model(model))
+
+	return err
+}
@@ -0,0 +1,76 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString   string
+		options       []Option
+		expectedError string
+		expectedIs    error
+	}{
+		"valid filter": {
+			queryString: "name eq 'test' and metadata/name eq 'prd'",
+		},
+		"valid uuid literal": {
+			queryString: "id eq '885b50a8-f2d2-4fc2-b8e8-4db54f5ef5b6'",
+		},
+		"syntax error": {
+			queryString:   "length(name",
+			expectedError: "failed to parse query: expected closing bracket after unary function length, got \"\"",
+			expectedIs:    ErrUnbalancedParens,
+		},
+		"unknown property": {
+			queryString:   "unknown eq 'test'",
+			expectedError: "invalid query: unknown column name 'unknown'",
+			expectedIs:    ErrUnknownProperty,
+		},
+		"unknown relation path": {
+			queryString:   "metadata/tag/unknown eq 'test'",
+			expectedError: "invalid query: unknown column name 'unknown' on 'tags'",
+			expectedIs:    ErrUnknownProperty,
+		},
+		"invalid uuid literal": {
+			queryString:   "id eq 'not-a-uuid'",
+			expectedError: "invalid query: invalid uuid literal 'not-a-uuid' for column 'id'",
+		},
+		"invalid filter": {
+			queryString:   "length(name)",
+			expectedError: "invalid query: unary function 'length' cannot be the root of a filter, compare it with a value instead",
+			expectedIs:    ErrUnsupportedOperator,
+		},
+		"option": {
+			queryString:   "testValue eq 'test'",
+			options:       []Option{WithAllowedFields("name")},
+			expectedError: "invalid query: field 'testValue' is not allowed",
+			expectedIs:    ErrFieldNotAllowed,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := Validate(testData.queryString, &MockModel{}, testData.options...)
+
+			// Assert
+			if testData.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testData.expectedError)
+			if testData.expectedIs != nil {
+				assert.True(t, errors.Is(err, testData.expectedIs))
+			}
+		})
+	}
+}
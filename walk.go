@@ -0,0 +1,69 @@
+package gormodata
+
+// Visitor
+// visits the expressions of a parsed filter (see Walk), like ast.Visitor of go/ast
+//
+// Visit is called for every expression, the returned Visitor visits the arguments of the expression,
+// return nil to skip the arguments
+type Visitor interface {
+	Visit(expr *Expr) Visitor
+}
+
+// Walk
+// visits the expression and all its arguments depth first in the order they appear in the filter,
+// after the arguments of an expression are visited v.Visit(nil) is called on the Visitor that visited them
+func Walk(v Visitor, expr *Expr) {
+	if v = v.Visit(expr); v == nil {
+		return
+	}
+
+	for _, arg := range expr.Args {
+		Walk(v, arg)
+	}
+
+	v.Visit(nil)
+}
+
+// inspector
+// is the Visitor of Inspect
+type inspector func(*Expr) bool
+
+func (f inspector) Visit(expr *Expr) Visitor {
+	if f(expr) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect
+// calls f for the expression and all its arguments depth first in the order they appear in the filter,
+// when f returns false the arguments of that expression are skipped, after the arguments are visited f(nil) is called
+//
+//	gormodata.Inspect(expr, func(expr *gormodata.Expr) bool {
+//		if expr != nil && expr.Kind == gormodata.FunctionExpr {
+//			functions = append(functions, expr.Func)
+//		}
+//		return true
+//	})
+func Inspect(expr *Expr, f func(*Expr) bool) {
+	Walk(inspector(f), expr)
+}
+
+// Properties
+// returns the properties the filter refers to in the order they appear in the filter, each property once (e.g. "name", "metadata/name"),
+// to check which fields and relations a filter touches (e.g. for authorization or auditing)
+func (e *Expr) Properties() []string {
+	properties := []string{}
+	seen := map[string]bool{}
+	Inspect(e, func(expr *Expr) bool {
+		if expr != nil && expr.Kind == PropertyExpr && !seen[expr.Property] {
+			seen[expr.Property] = true
+			properties = append(properties, expr.Property)
+		}
+
+		return true
+	})
+
+	return properties
+}
@@ -0,0 +1,101 @@
+package gormodata
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+// kindCounter
+// counts the visited expressions per kind and skips the arguments of functions
+type kindCounter map[ExprKind]int
+
+func (k kindCounter) Visit(expr *Expr) Visitor {
+	if expr == nil {
+		return nil
+	}
+	k[expr.Kind]++
+	if expr.Kind == FunctionExpr {
+		return nil
+	}
+
+	return k
+}
+
+func Test_Walk(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	expr, _ := Parse("name eq 'test' and (length(tolower(name)) gt 3 or not(contains(metadata/name,'prd')))")
+	counter := kindCounter{}
+
+	// Act
+	Walk(counter, expr)
+
+	// Assert
+	assert.Equal(t, kindCounter{
+		LogicalExpr:    3,
+		ComparisonExpr: 2,
+		FunctionExpr:   2,
+		PropertyExpr:   1,
+		LiteralExpr:    2,
+	}, counter)
+}
+
+func Test_Inspect(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	expr, _ := Parse("name eq 'test' and (length(tolower(name)) gt 3 or not(contains(metadata/name,'prd')))")
+	visited := []string{}
+
+	// Act
+	Inspect(expr, func(expr *Expr) bool {
+		if expr == nil {
+			visited = append(visited, "end")
+			return false
+		}
+		visited = append(visited, expr.Kind.String()+expr.Op+expr.Func+expr.Property)
+
+		return expr.Kind == LogicalExpr
+	})
+
+	// Assert
+	assert.Equal(t, []string{"Logicaland", "Comparisoneq", "Logicalor", "Comparisongt", "Logicalnot", "Functioncontains", "end", "end", "end"}, visited)
+}
+
+func Test_Expr_Properties(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString        string
+		expectedProperties []string
+	}{
+		"single property": {
+			queryString:        "name eq 'test'",
+			expectedProperties: []string{"name"},
+		},
+		"properties in functions and relations": {
+			queryString:        "name eq 'test' and (length(tolower(name)) gt 3 or not(contains(metadata/name,'prd'))) and concat(testValue,'x') eq 'ax'",
+			expectedProperties: []string{"name", "metadata/name", "testValue"},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expr, _ := Parse(testData.queryString)
+
+			// Act
+			properties := expr.Properties()
+
+			// Assert
+			assert.Equal(t, testData.expectedProperties, properties)
+		})
+	}
+}
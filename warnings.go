@@ -0,0 +1,159 @@
+package gormodata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	syntaxtree "github.com/bramca/go-syntax-tree"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// substringOfFunction
+// is the odata v2 version of contains with the arguments the other way around (substringof('value',name) is contains(name,'value'))
+const substringOfFunction = "substringof"
+
+// deprecatedFunctions
+// are the functions of older odata versions that are still accepted, mapped to the function that replaces them
+var deprecatedFunctions = map[string]string{
+	substringOfFunction: "contains",
+}
+
+// Warning
+// is a problem of a filter that does not stop it from being built (see Builder.BuildWithWarnings),
+// e.g. so API gateways can tell client developers about it
+type Warning struct {
+	Msg string `json:"msg"`
+
+	// Expression is the part of the filter the warning is about
+	Expression string `json:"expression,omitempty"`
+}
+
+// String
+// returns the message of the warning
+func (w Warning) String() string {
+	return w.Msg
+}
+
+// BuildWithWarnings
+// builds a gorm query based on an odata query string like Build, and returns the warnings of the filter with it:
+//
+//	deprecated functions (e.g. substringof, use contains)
+//	implicit conversions, when a string is compared with a number column or a number with a string column of the model (e.g. age eq '30')
+//	conditions that were dropped from a partial filter (see WithPartialFilters)
+//
+// the implicit conversions are only found when the model is set on the db (db.Model(...)), no warnings are returned when the filter is rejected
+func (b *Builder) BuildWithWarnings(query string, db *gorm.DB) (*gorm.DB, []Warning, error) {
+	dbQuery, tree, err := b.build(query, db)
+	if err != nil {
+		return dbQuery, nil, err
+	}
+
+	warnings := deprecationWarnings(query)
+	if b.partialFilters {
+		warnings = append(warnings, droppedConditions(dbQuery)...)
+	}
+	if tree != nil {
+		warnings = append(warnings, conversionWarnings(tree, db)...)
+	}
+
+	return dbQuery, warnings, nil
+}
+
+// deprecationWarnings
+// returns a warning for every deprecated function the query calls
+func deprecationWarnings(query string) []Warning {
+	warnings := []Warning{}
+	tokens, _ := odataParser.tokenize(query)
+	for _, token := range tokens {
+		if token.Type != syntaxtree.BinaryFunc && token.Type != syntaxtree.UnaryFunc {
+			continue
+		}
+
+		if replacement, ok := deprecatedFunctions[strings.ToLower(token.Value)]; ok {
+			warnings = append(warnings, Warning{
+				Msg:        fmt.Sprintf("%s is deprecated, use %s", token.Value, replacement),
+				Expression: token.Value,
+			})
+		}
+	}
+
+	return warnings
+}
+
+// conversionWarnings
+// returns a warning for every comparison of a column of the model on the db with a literal of another type,
+// which the database converts implicitly (e.g. age eq '30' or name eq 30)
+func conversionWarnings(tree *syntaxtree.SyntaxTree, db *gorm.DB) []Warning {
+	warnings := []Warning{}
+	if db.Statement.Model == nil {
+		return warnings
+	}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(db.Statement.Model); err != nil {
+		return warnings
+	}
+
+	_ = validateQueryDepthFirstSearch(nil, tree, func(depth int, currentNode *syntaxtree.Node) error {
+		if currentNode.Type != syntaxtree.Operator || !slices.Contains(comparisonOperators, currentNode.Value) || currentNode.LeftChild == nil || currentNode.RightChild == nil {
+			return nil
+		}
+		leftChild, rightChild := currentNode.LeftChild, currentNode.RightChild
+		if !isPropertyNode(leftChild) || strings.Contains(modelProperty(leftChild.Value), "/") || rightChild.Type != syntaxtree.RightOperand || inLambdaElement(leftChild) ||
+			isRootReference(rightChild.Value) || rightChild.Value == "null" {
+			return nil
+		}
+
+		field := statement.Schema.LookUpField(db.NamingStrategy.ColumnName("", modelProperty(leftChild.Value)))
+		if field == nil {
+			return nil
+		}
+
+		switch {
+		case isNumberField(field) && isStringLiteral(rightChild.Value) && isNumberLiteral(unquote(rightChild.Value)):
+			warnings = append(warnings, Warning{
+				Msg:        fmt.Sprintf("implicit string-to-number comparison, '%s' is a number, compare it with %s instead of %s", leftChild.Value, unquote(rightChild.Value), rightChild.Value),
+				Expression: nodeExpression(currentNode),
+			})
+		case field.DataType == schema.String && !isUUIDField(field) && !isStringLiteral(rightChild.Value) && isNumberLiteral(rightChild.Value):
+			warnings = append(warnings, Warning{
+				Msg:        fmt.Sprintf("implicit number-to-string comparison, '%s' is a string, compare it with %s instead of %s", leftChild.Value, quote(rightChild.Value), rightChild.Value),
+				Expression: nodeExpression(currentNode),
+			})
+		}
+
+		return nil
+	})
+
+	return warnings
+}
+
+// isNumberField
+// returns whether the field is an integer or a decimal
+func isNumberField(field *schema.Field) bool {
+	return field.DataType == schema.Int || field.DataType == schema.Uint || field.DataType == schema.Float
+}
+
+// rewriteDeprecatedFunctions
+// replaces the calls of deprecated functions in the tree by the functions that replace them (see deprecatedFunctions),
+// so they are validated and built like the new function
+func rewriteDeprecatedFunctions(tree *syntaxtree.SyntaxTree) {
+	_ = validateQueryDepthFirstSearch(nil, tree, func(depth int, currentNode *syntaxtree.Node) error {
+		if currentNode.Type != syntaxtree.Operator || strings.ToLower(currentNode.Value) != substringOfFunction {
+			return nil
+		}
+
+		currentNode.Value = deprecatedFunctions[substringOfFunction]
+		currentNode.LeftChild, currentNode.RightChild = currentNode.RightChild, currentNode.LeftChild
+		if currentNode.LeftChild.Type == syntaxtree.RightOperand {
+			currentNode.LeftChild.Type = syntaxtree.LeftOperand
+		}
+		if currentNode.RightChild.Type == syntaxtree.LeftOperand {
+			currentNode.RightChild.Type = syntaxtree.RightOperand
+		}
+
+		return nil
+	})
+}
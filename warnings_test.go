@@ -0,0 +1,118 @@
+package gormodata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/gormtestutil"
+	"github.com/test-go/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_Builder_BuildWithWarnings(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	tests := map[string]struct {
+		queryString      string
+		expectedSql      string
+		expectedWarnings []Warning
+	}{
+		"no warnings": {
+			queryString:      "name eq 'Alice' and id gt 5",
+			expectedSql:      "SELECT * FROM `members` WHERE name = \"Alice\" AND id > 5",
+			expectedWarnings: []Warning{},
+		},
+		"substringof": {
+			queryString: "substringof('lic',name)",
+			expectedSql: "SELECT * FROM `members` WHERE name LIKE \"%lic%\"",
+			expectedWarnings: []Warning{
+				{Msg: "substringof is deprecated, use contains", Expression: "substringof"},
+			},
+		},
+		"substringof with a function": {
+			queryString: "not(substringof('lic',tolower(name)))",
+			expectedSql: "SELECT * FROM `members` WHERE LOWER(name) NOT LIKE \"%lic%\"",
+			expectedWarnings: []Warning{
+				{Msg: "substringof is deprecated, use contains", Expression: "substringof"},
+			},
+		},
+		"string compared with a number column": {
+			queryString: "id eq '5'",
+			expectedSql: "SELECT * FROM `members` WHERE id = 5",
+			expectedWarnings: []Warning{
+				{Msg: "implicit string-to-number comparison, 'id' is a number, compare it with 5 instead of '5'", Expression: "id eq '5'"},
+			},
+		},
+		"number compared with a string column": {
+			queryString: "name ne 5",
+			expectedSql: "SELECT * FROM `members` WHERE name != 5",
+			expectedWarnings: []Warning{
+				{Msg: "implicit number-to-string comparison, 'name' is a string, compare it with '5' instead of 5", Expression: "name ne 5"},
+			},
+		},
+		"string that is not a number": {
+			queryString:      "id eq 'five' or name eq null",
+			expectedSql:      "SELECT * FROM `members` WHERE id = \"five\" OR name IS NULL",
+			expectedWarnings: []Warning{},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+			builder := New(WithDatabaseType(SQLite))
+
+			// Act
+			var warnings []Warning
+			var err error
+			sqlQuery := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var dbQuery *gorm.DB
+				dbQuery, warnings, err = builder.BuildWithWarnings(testData.queryString, tx.Model(&Member{}))
+				return dbQuery.Find(&Member{})
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedSql, sqlQuery)
+			assert.Equal(t, testData.expectedWarnings, warnings)
+		})
+	}
+}
+
+func Test_Builder_BuildWithWarningsRejected(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithDisabledFunctions("contains"))
+
+	// Act
+	_, warnings, err := builder.BuildWithWarnings("substringof('lic',name)", db.Model(&Member{}))
+
+	// Assert
+	assert.Nil(t, warnings)
+	assert.EqualError(t, err, "invalid query: function 'substringof' is disabled")
+	assert.True(t, errors.Is(err, ErrFunctionNotAllowed))
+}
+
+func Test_Builder_BuildWithWarningsCached(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(cleanupCache)
+
+	// Arrange
+	db := gormtestutil.NewMemoryDatabase(t, gormtestutil.WithName(t.Name()))
+	builder := New(WithDatabaseType(SQLite), WithTreeCache(NewTreeCache(10)))
+
+	// Act
+	_, _, _ = builder.BuildWithWarnings("substringof('lic',name)", db.Model(&Member{}))
+	_, warnings, err := builder.BuildWithWarnings("substringof('lic',name)", db.Model(&Member{}))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{{Msg: "substringof is deprecated, use contains", Expression: "substringof"}}, warnings)
+}